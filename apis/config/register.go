@@ -35,7 +35,9 @@ var (
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&CoschedulingArgs{},
+		&CapacitySchedulingArgs{},
 		&NodeResourcesAllocatableArgs{},
+		&NodeResourceLimitsArgs{},
 		&TargetLoadPackingArgs{},
 		&LoadVariationRiskBalancingArgs{},
 		&LowRiskOverCommitmentArgs{},
@@ -45,6 +47,7 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&NetworkOverheadArgs{},
 		&SySchedArgs{},
 		&PIDControllerArgs{},
+		&DiskIOAwareArgs{},
 	)
 	return nil
 }
@@ -30,7 +30,9 @@ import (
 
 	"sigs.k8s.io/scheduler-plugins/apis/config"
 	v1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
+	"sigs.k8s.io/scheduler-plugins/pkg/capacityscheduling"
 	"sigs.k8s.io/scheduler-plugins/pkg/coscheduling"
+	"sigs.k8s.io/scheduler-plugins/pkg/diskioaware"
 	"sigs.k8s.io/scheduler-plugins/pkg/networkaware/networkoverhead"
 	"sigs.k8s.io/scheduler-plugins/pkg/networkaware/topologicalsort"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesources"
@@ -74,16 +76,26 @@ profiles:
 - schedulerName: scheduler-plugins
   pluginConfig:
   - name: Coscheduling # Test argument defaulting logic
+  - name: CapacityScheduling
+    args:
+      borrowingPolicy: FairSharing
   - name: TopologicalSort
     args:
       namespaces:
       - "networkAware"
+      fairness: Age
   - name: NetworkOverhead
     args:
       namespaces:
       - "networkAware"
       weightsName: "netCosts"
       networkTopologyName: "net-topology-v1"
+      topologyKeys:
+      - "kubernetes.io/hostname"
+      - "topology.kubernetes.io/zone"
+  - name: DiskIOAware
+    args:
+      scoringStrategy: LeastAllocated
 `),
 			wantProfiles: []schedconfig.KubeSchedulerProfile{
 				{
@@ -94,12 +106,20 @@ profiles:
 							Name: coscheduling.Name,
 							Args: &config.CoschedulingArgs{
 								PermitWaitingTimeSeconds: 60,
+								PreemptionMode:           config.PreemptionModeNone,
+							},
+						},
+						{
+							Name: capacityscheduling.Name,
+							Args: &config.CapacitySchedulingArgs{
+								BorrowingPolicy: config.BorrowingPolicyFairSharing,
 							},
 						},
 						{
 							Name: topologicalsort.Name,
 							Args: &config.TopologicalSortArgs{
 								Namespaces: []string{"networkAware"},
+								Fairness:   config.FairnessAge,
 							},
 						},
 						{
@@ -108,6 +128,14 @@ profiles:
 								Namespaces:          []string{"networkAware"},
 								WeightsName:         "netCosts",
 								NetworkTopologyName: "net-topology-v1",
+								TopologyKeys:        []string{"kubernetes.io/hostname", "topology.kubernetes.io/zone"},
+							},
+						},
+						{
+							Name: diskioaware.Name,
+							Args: &config.DiskIOAwareArgs{
+								ScoringStrategy:         config.LeastAllocated,
+								NodeDiskIOInfoNamespace: "kube-system",
 							},
 						},
 						{
@@ -169,6 +197,7 @@ profiles:
 							Name: topologicalsort.Name,
 							Args: &config.TopologicalSortArgs{
 								Namespaces: []string{"default"},
+								Fairness:   config.FairnessRoundRobin,
 							},
 						},
 						{
@@ -177,6 +206,7 @@ profiles:
 								Namespaces:          []string{"default"},
 								WeightsName:         "UserDefined",
 								NetworkTopologyName: "nt-default",
+								TopologyKeys:        []string{"topology.kubernetes.io/zone", "topology.kubernetes.io/region"},
 							},
 						},
 						{
@@ -280,12 +310,17 @@ func TestCodecsEncodePluginConfig(t *testing.T) {
 											Type:    config.Prometheus,
 											Address: "http://prometheus-k8s.monitoring.svc.cluster.local:9090",
 										},
-										WatcherAddress: "http://deadbeef:2020"},
+										WatcherAddress:               "http://deadbeef:2020",
+										MetricsUpdateIntervalSeconds: 30,
+										MetricsMaxAgeSeconds:         300,
+									},
 									TargetUtilization: 60,
 									DefaultRequests: corev1.ResourceList{
 										corev1.ResourceCPU: testCPUQuantity,
 									},
-									DefaultRequestsMultiplier: "1.8",
+									DefaultRequestsMultiplier:  "1.8",
+									FallbackGracePeriodSeconds: 120,
+									ScoreMode:                  config.TargetLoadPackingScoreModeWeightedSum,
 								},
 							},
 							{
@@ -297,7 +332,10 @@ func TestCodecsEncodePluginConfig(t *testing.T) {
 											Address:            "http://prometheus-k8s.monitoring.svc.cluster.local:9090",
 											InsecureSkipVerify: false,
 										},
-										WatcherAddress: "http://deadbeef:2020"},
+										WatcherAddress:               "http://deadbeef:2020",
+										MetricsUpdateIntervalSeconds: 30,
+										MetricsMaxAgeSeconds:         300,
+									},
 									SafeVarianceMargin:      v1.DefaultSafeVarianceMargin,
 									SafeVarianceSensitivity: v1.DefaultSafeVarianceSensitivity,
 								},
@@ -311,7 +349,10 @@ func TestCodecsEncodePluginConfig(t *testing.T) {
 											Address:            "http://prometheus-k8s.monitoring.svc.cluster.local:9090",
 											InsecureSkipVerify: false,
 										},
-										WatcherAddress: "http://deadbeef:2020"},
+										WatcherAddress:               "http://deadbeef:2020",
+										MetricsUpdateIntervalSeconds: 30,
+										MetricsMaxAgeSeconds:         300,
+									},
 									SmoothingWindowSize: v1.DefaultSmoothingWindowSize,
 									RiskLimitWeights: map[corev1.ResourceName]float64{
 										corev1.ResourceCPU:    v1.DefaultRiskLimitWeight,
@@ -323,6 +364,7 @@ func TestCodecsEncodePluginConfig(t *testing.T) {
 								Name: topologicalsort.Name,
 								Args: &config.TopologicalSortArgs{
 									Namespaces: []string{"default"},
+									Fairness:   config.FairnessRoundRobin,
 								},
 							},
 							{
@@ -381,12 +423,16 @@ profiles:
       defaultRequests:
         cpu: "1"
       defaultRequestsMultiplier: "1.8"
+      fallbackGracePeriodSeconds: 120
       kind: TargetLoadPackingArgs
       metricProvider:
         address: http://prometheus-k8s.monitoring.svc.cluster.local:9090
         insecureSkipVerify: false
         token: ""
         type: Prometheus
+      metricsMaxAgeSeconds: 300
+      metricsUpdateIntervalSeconds: 30
+      scoreMode: WeightedSum
       targetUtilization: 60
       watcherAddress: http://deadbeef:2020
     name: TargetLoadPacking
@@ -398,6 +444,8 @@ profiles:
         insecureSkipVerify: false
         token: ""
         type: Prometheus
+      metricsMaxAgeSeconds: 300
+      metricsUpdateIntervalSeconds: 30
       safeVarianceMargin: 1
       safeVarianceSensitivity: 1
       watcherAddress: http://deadbeef:2020
@@ -410,6 +458,8 @@ profiles:
         insecureSkipVerify: false
         token: ""
         type: Prometheus
+      metricsMaxAgeSeconds: 300
+      metricsUpdateIntervalSeconds: 30
       riskLimitWeights:
         cpu: 0.5
         memory: 0.5
@@ -418,6 +468,7 @@ profiles:
     name: LowRiskOverCommitment
   - args:
       apiVersion: kubescheduler.config.k8s.io/v1
+      fairness: RoundRobin
       kind: TopologicalSortArgs
       namespaces:
       - default
@@ -32,8 +32,43 @@ type CoschedulingArgs struct {
 	PermitWaitingTimeSeconds int64
 	// PodGroupBackoffSeconds is the backoff time in seconds before a pod group can be scheduled again.
 	PodGroupBackoffSeconds int64
+	// PreemptionMode decides what PostFilter does when a member of a PodGroup is unschedulable.
+	PreemptionMode PreemptionModeType
 }
 
+// PreemptionModeType is a "string" type.
+type PreemptionModeType string
+
+const (
+	// PreemptionModeNone only rejects the waiting siblings of an unschedulable PodGroup.
+	PreemptionModeNone PreemptionModeType = "None"
+	// PreemptionModeGang preempts lower-priority pods so that the whole PodGroup fits at once.
+	PreemptionModeGang PreemptionModeType = "Gang"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CapacitySchedulingArgs defines the parameters for CapacityScheduling plugin.
+type CapacitySchedulingArgs struct {
+	metav1.TypeMeta
+
+	// BorrowingPolicy decides how the ElasticQuotas share the resources lent by the quotas under their min.
+	// "FirstComeFirstServed" (the default) lets a quota borrow any of them up to its max.
+	// "FairSharing" splits them among the quotas in proportion to their weights, and lets a quota
+	// within its fair share preempt the pods of the quotas over theirs.
+	BorrowingPolicy BorrowingPolicyType
+}
+
+// BorrowingPolicyType is a "string" type.
+type BorrowingPolicyType string
+
+const (
+	// BorrowingPolicyFirstComeFirstServed lets a quota borrow any lent resources up to its max.
+	BorrowingPolicyFirstComeFirstServed BorrowingPolicyType = "FirstComeFirstServed"
+	// BorrowingPolicyFairSharing splits the lent resources among the quotas in proportion to their weights.
+	BorrowingPolicyFairSharing BorrowingPolicyType = "FairSharing"
+)
+
 // ModeType is a "string" type.
 type ModeType string
 
@@ -61,6 +96,18 @@ type NodeResourcesAllocatableArgs struct {
 	Mode ModeType `json:"mode,omitempty"`
 }
 
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeResourceLimitsArgs holds arguments used to configure NodeResourceLimits plugin.
+type NodeResourceLimitsArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Resources to be considered when scoring, with their weights.
+	// The score of each resource is the share of its allocatable not committed as
+	// limits, so the weights do not depend on the resource units.
+	Resources []schedconfig.ResourceSpec `json:"resources,omitempty"`
+}
+
 // MetricProviderType is a "string" type.
 type MetricProviderType string
 
@@ -88,6 +135,11 @@ type TrimaranSpec struct {
 	MetricProvider MetricProviderSpec
 	// Address of load watcher service
 	WatcherAddress string
+	// Interval in seconds between two refreshes of the load watcher metrics
+	MetricsUpdateIntervalSeconds int64
+	// Age in seconds of the load watcher metrics window after which metrics are considered stale.
+	// Zero disables the staleness detection.
+	MetricsMaxAgeSeconds int64
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -102,10 +154,38 @@ type TargetLoadPackingArgs struct {
 	DefaultRequests v1.ResourceList
 	// Default requests multiplier for busrtable QoS
 	DefaultRequestsMultiplier string
-	// Node target CPU Utilization for bin packing
+	// Node target CPU Utilization for bin packing, used when Resources is empty
 	TargetUtilization int64
+	// Seconds the metrics of a node may be missing before the node is scored
+	// from its allocated resources instead. A negative value disables the fallback.
+	FallbackGracePeriodSeconds int64
+	// Resources to pack, with their target utilization and weight.
+	// If empty, only CPU is packed, around TargetUtilization.
+	Resources []TargetLoadPackingResource
+	// How the scores of the resources are combined into the node score
+	ScoreMode TargetLoadPackingScoreMode
 }
 
+// TargetLoadPackingResource holds the target utilization and the weight of a resource packed by TargetLoadPacking.
+type TargetLoadPackingResource struct {
+	// Name of the resource, cpu or memory
+	Name v1.ResourceName
+	// Node target utilization % of the resource
+	TargetUtilization int64
+	// Weight of the resource score in the WeightedSum score mode
+	Weight int64
+}
+
+// TargetLoadPackingScoreMode is a "string" type.
+type TargetLoadPackingScoreMode string
+
+const (
+	// TargetLoadPackingScoreModeWeightedSum scores a node with the weighted average of its resource scores.
+	TargetLoadPackingScoreModeWeightedSum TargetLoadPackingScoreMode = "WeightedSum"
+	// TargetLoadPackingScoreModeMin scores a node with its lowest resource score.
+	TargetLoadPackingScoreModeMin TargetLoadPackingScoreMode = "Min"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // LoadVariationRiskBalancingArgs holds arguments used to configure LoadVariationRiskBalancing plugin.
@@ -184,6 +264,22 @@ const (
 	CacheInformerDedicated CacheInformerMode = "Dedicated"
 )
 
+// CacheAccountingMode is a "string" type.
+type CacheAccountingMode string
+
+const (
+	CacheAccountingPessimistic CacheAccountingMode = "Pessimistic"
+	CacheAccountingPerNUMA     CacheAccountingMode = "PerNUMA"
+)
+
+// CacheResyncTrigger is a "string" type.
+type CacheResyncTrigger string
+
+const (
+	CacheResyncTriggerPeriodic CacheResyncTrigger = "Periodic"
+	CacheResyncTriggerEvents   CacheResyncTrigger = "Events"
+)
+
 // NodeResourceTopologyCache define configuration details for the NodeResourceTopology cache.
 type NodeResourceTopologyCache struct {
 	// ForeignPodsDetect sets how foreign pods should be handled.
@@ -205,6 +301,19 @@ type NodeResourceTopologyCache struct {
 	// guaranteed to best suit the cache needs, at cost of one extra connection.
 	// If unspecified, default is "Dedicated"
 	InformerMode *CacheInformerMode
+	// AccountingMode sets how the resources of the pods scheduled after the last NRT update are deducted.
+	// "Pessimistic" deducts them from all the NUMA zones of the node. "PerNUMA" deducts them only from
+	// the NUMA zones the filter picked for the pod, and falls back to "Pessimistic" on a node until its
+	// next resync if a later NRT update of the node disagrees with the NUMA zones picked.
+	// Has no effect if caching is disabled (CacheResyncPeriod is zero) or if DiscardReservedNodes
+	// is enabled. If unspecified, default is "Pessimistic".
+	AccountingMode *CacheAccountingMode
+	// ResyncTrigger sets when the cache tries to resync the nodes. "Periodic" tries every CacheResyncPeriodSeconds.
+	// "Events" additionally watches the NRT objects, and tries to resync a node shortly after its NRT object
+	// changes, coalescing the changes which happen in a short time.
+	// Has no effect if caching is disabled (CacheResyncPeriod is zero) or if DiscardReservedNodes
+	// is enabled. If unspecified, default is "Periodic".
+	ResyncTrigger *CacheResyncTrigger
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -237,8 +346,23 @@ type TopologicalSortArgs struct {
 
 	// Namespaces to be considered by TopologySort plugin
 	Namespaces []string
+
+	// Fairness decides how the pods of the same priority but of different AppGroups are ordered.
+	// "RoundRobin" (the default) takes one workload of each AppGroup in turn, in their topology order.
+	// "Age" puts the pods of the oldest AppGroup first, which may starve the newer AppGroups.
+	Fairness FairnessType
 }
 
+// FairnessType is a "string" type.
+type FairnessType string
+
+const (
+	// FairnessAge orders the AppGroups by their creation time.
+	FairnessAge FairnessType = "Age"
+	// FairnessRoundRobin interleaves the AppGroups by the topology index of their workloads.
+	FairnessRoundRobin FairnessType = "RoundRobin"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type NetworkOverheadArgs struct {
@@ -252,6 +376,11 @@ type NetworkOverheadArgs struct {
 
 	// The NetworkTopology CRD name
 	NetworkTopologyName string
+
+	// Node labels of the topology levels network costs are defined for, from the finest to the
+	// coarsest level, e.g. kubernetes.io/hostname, a rack label, topology.kubernetes.io/zone and
+	// topology.kubernetes.io/region (Default: zone and region)
+	TopologyKeys []string
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -266,7 +395,32 @@ type SySchedArgs struct {
 	DefaultProfileName string
 }
 
+// PIDControllerMode is a "string" type.
+type PIDControllerMode string
+
+const (
+	// PIDControllerModeExternal asks the service at EndpointURL for the node scores.
+	PIDControllerModeExternal PIDControllerMode = "External"
+	// PIDControllerModeBuiltIn runs the PID loop inside the plugin.
+	PIDControllerModeBuiltIn PIDControllerMode = "BuiltIn"
+)
+
+// PIDControllerFailurePolicy is a "string" type.
+type PIDControllerFailurePolicy string
+
+const (
+	// PIDControllerFailurePolicyFail fails the scheduling cycle of the pod.
+	PIDControllerFailurePolicyFail PIDControllerFailurePolicy = "Fail"
+	// PIDControllerFailurePolicySkip leaves the nodes unscored, so the plugin has no say for the pod.
+	PIDControllerFailurePolicySkip PIDControllerFailurePolicy = "Skip"
+	// PIDControllerFailurePolicyLastKnownGood reuses the last score the endpoint returned for each node,
+	// as long as it is not older than LastKnownGoodMaxAgeSeconds.
+	PIDControllerFailurePolicyLastKnownGood PIDControllerFailurePolicy = "LastKnownGood"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PIDControllerArgs holds arguments used to configure the PIDController plugin.
 type PIDControllerArgs struct {
 	metav1.TypeMeta
 
@@ -281,4 +435,42 @@ type PIDControllerArgs struct {
 
 	// RequestTimeoutSec defines the timeout for requests in seconds.
 	RequestTimeoutSec *int
+
+	// Mode selects where node scores come from: the external endpoint or the built-in PID loop.
+	Mode PIDControllerMode
+
+	// TargetUtilization is the node utilization percentage the built-in PID loop steers towards.
+	TargetUtilization int64
+
+	// Kp, Ki and Kd are the proportional, integral and derivative gains of the built-in PID loop.
+	Kp float64
+	Ki float64
+	Kd float64
+
+	// FailurePolicy decides how the pod is scored when the endpoint can't be reached
+	// or its answer can't be used.
+	FailurePolicy PIDControllerFailurePolicy
+
+	// LastKnownGoodMaxAgeSeconds is how long a node score is reused by the LastKnownGood policy.
+	LastKnownGoodMaxAgeSeconds int64
+
+	// CircuitBreakerFailureThreshold is the number of consecutive failures after which the endpoint
+	// is no longer called for CircuitBreakerOpenSeconds. Zero disables the circuit breaker.
+	CircuitBreakerFailureThreshold int64
+
+	// CircuitBreakerOpenSeconds is how long the circuit breaker stays open before a trial request.
+	CircuitBreakerOpenSeconds int64
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DiskIOAwareArgs holds arguments used to configure the DiskIOAware plugin.
+type DiskIOAwareArgs struct {
+	metav1.TypeMeta
+
+	// ScoringStrategy selects how nodes are scored from their disk IO bandwidth,
+	// MostAllocated or LeastAllocated.
+	ScoringStrategy ScoringStrategyType
+	// NodeDiskIOInfoNamespace is the namespace of the NodeDiskIOInfo objects, one per node.
+	NodeDiskIOInfoNamespace string
 }
@@ -30,6 +30,12 @@ var (
 	defaultPermitWaitingTimeSeconds int64 = 60
 	defaultPodGroupBackoffSeconds   int64 = 0
 
+	defaultPreemptionMode = PreemptionModeNone
+
+	defaultBorrowingPolicy = BorrowingPolicyFirstComeFirstServed
+
+	defaultFairness = FairnessRoundRobin
+
 	defaultNodeResourcesAllocatableMode = Least
 
 	// defaultResourcesToWeightMap is used to set the default resourceToWeight map for CPU and memory
@@ -41,6 +47,11 @@ var (
 		{Name: "cpu", Weight: 1 << 20}, {Name: "memory", Weight: 1},
 	}
 
+	// defaultNodeResourceLimitsResources are the resources scored by the NodeResourceLimits plugin.
+	defaultNodeResourceLimitsResources = []schedulerconfigv1.ResourceSpec{
+		{Name: "cpu", Weight: 1}, {Name: "memory", Weight: 1},
+	}
+
 	// Defaults for TargetLoadPacking plugin
 
 	// Default 1 core CPU usage for containers without requests and limits i.e. Best Effort QoS.
@@ -49,6 +60,13 @@ var (
 	DefaultRequestsMultiplier = "1.5"
 	// DefaultTargetUtilizationPercent Recommended to keep -10 than desired limit.
 	DefaultTargetUtilizationPercent int64 = 40
+	// DefaultFallbackGracePeriodSeconds is two metrics agent reporting intervals, so that new nodes
+	// get their first metrics reported before being scored by allocation.
+	DefaultFallbackGracePeriodSeconds int64 = 120
+	// DefaultTargetLoadPackingScoreMode combines the resource scores with a weighted average.
+	DefaultTargetLoadPackingScoreMode = TargetLoadPackingScoreModeWeightedSum
+	// DefaultTargetLoadPackingResourceWeight is the weight of a resource without one.
+	DefaultTargetLoadPackingResourceWeight int64 = 1
 
 	// Defaults for LoadVariationRiskBalancing plugin
 
@@ -79,6 +97,10 @@ var (
 	DefaultMetricProviderType = KubernetesMetricsServer
 	// DefaultInsecureSkipVerify is whether to skip the certificate verification
 	DefaultInsecureSkipVerify = true
+	// DefaultMetricsUpdateIntervalSeconds is the interval between two refreshes of the load watcher metrics
+	DefaultMetricsUpdateIntervalSeconds int64 = 30
+	// DefaultMetricsMaxAgeSeconds is the age after which load watcher metrics are considered stale
+	DefaultMetricsMaxAgeSeconds int64 = 300
 
 	defaultResourceSpec = []schedulerconfigv1.ResourceSpec{
 		{Name: string(v1.ResourceCPU), Weight: 1},
@@ -91,17 +113,49 @@ var (
 
 	defaultInformerMode = CacheInformerDedicated
 
+	defaultAccountingMode = CacheAccountingPessimistic
+
+	defaultResyncTrigger = CacheResyncTriggerPeriodic
+
 	// Defaults for NetworkOverhead
 	// DefaultWeightsName contains the default costs to be used by networkAware plugins
 	DefaultWeightsName = "UserDefined"
 	// DefaultNetworkTopologyName contains the networkTopology CR name to be used by networkAware plugins
 	DefaultNetworkTopologyName = "nt-default"
+	// DefaultTopologyKeys contains the topology levels network costs are defined for, from the finest to the coarsest
+	DefaultTopologyKeys = []string{v1.LabelTopologyZone, v1.LabelTopologyRegion}
 
 	// Defaults for SySched
 	// DefaultSySchedProfileNamespace is the namesapce of the default syscall profile CR for SySched plugin
 	DefaultSySchedProfileNamespace = "default"
 	// DefaultSySchedProfileName is the name of the default syscall profile CR for SySched plugin
 	DefaultSySchedProfileName = "all-syscalls"
+
+	// Defaults for PIDController
+	// DefaultPIDControllerMode asks the external endpoint for the node scores
+	DefaultPIDControllerMode = PIDControllerModeExternal
+	// DefaultPIDTargetUtilizationPercent is the node utilization the built-in PID loop steers towards
+	DefaultPIDTargetUtilizationPercent int64 = 60
+	// DefaultPIDKp is the proportional gain of the built-in PID loop
+	DefaultPIDKp = 1.0
+	// DefaultPIDKi is the integral gain of the built-in PID loop
+	DefaultPIDKi = 0.1
+	// DefaultPIDKd is the derivative gain of the built-in PID loop
+	DefaultPIDKd = 0.0
+	// DefaultPIDControllerFailurePolicy leaves the nodes unscored when the endpoint fails
+	DefaultPIDControllerFailurePolicy = PIDControllerFailurePolicySkip
+	// DefaultPIDLastKnownGoodMaxAgeSeconds is how long node scores are reused by the LastKnownGood policy
+	DefaultPIDLastKnownGoodMaxAgeSeconds int64 = 300
+	// DefaultPIDCircuitBreakerFailureThreshold is the number of consecutive failures opening the circuit breaker
+	DefaultPIDCircuitBreakerFailureThreshold int64 = 5
+	// DefaultPIDCircuitBreakerOpenSeconds is how long the circuit breaker stays open
+	DefaultPIDCircuitBreakerOpenSeconds int64 = 30
+
+	// Defaults for DiskIOAware
+	// DefaultDiskIOScoringStrategy packs the pods on the disks with the least free bandwidth
+	DefaultDiskIOScoringStrategy = MostAllocated
+	// DefaultNodeDiskIOInfoNamespace is the namespace of the NodeDiskIOInfo objects
+	DefaultNodeDiskIOInfoNamespace = "kube-system"
 )
 
 // SetDefaults_CoschedulingArgs sets the default parameters for Coscheduling plugin.
@@ -112,6 +166,16 @@ func SetDefaults_CoschedulingArgs(obj *CoschedulingArgs) {
 	if obj.PodGroupBackoffSeconds == nil {
 		obj.PodGroupBackoffSeconds = &defaultPodGroupBackoffSeconds
 	}
+	if obj.PreemptionMode == "" {
+		obj.PreemptionMode = defaultPreemptionMode
+	}
+}
+
+// SetDefaults_CapacitySchedulingArgs sets the default parameters for CapacityScheduling plugin.
+func SetDefaults_CapacitySchedulingArgs(obj *CapacitySchedulingArgs) {
+	if obj.BorrowingPolicy == "" {
+		obj.BorrowingPolicy = defaultBorrowingPolicy
+	}
 }
 
 // SetDefaults_NodeResourcesAllocatableArgs sets the defaults parameters for NodeResourceAllocatable.
@@ -125,6 +189,13 @@ func SetDefaults_NodeResourcesAllocatableArgs(obj *NodeResourcesAllocatableArgs)
 	}
 }
 
+// SetDefaults_NodeResourceLimitsArgs sets the defaults parameters for NodeResourceLimits.
+func SetDefaults_NodeResourceLimitsArgs(obj *NodeResourceLimitsArgs) {
+	if len(obj.Resources) == 0 {
+		obj.Resources = append([]schedulerconfigv1.ResourceSpec(nil), defaultNodeResourceLimitsResources...)
+	}
+}
+
 // SetDefaultTrimaranSpec sets the default parameters for common Trimaran plugins
 func SetDefaultTrimaranSpec(args *TrimaranSpec) {
 	if args.WatcherAddress == nil && args.MetricProvider.Type == "" {
@@ -133,6 +204,12 @@ func SetDefaultTrimaranSpec(args *TrimaranSpec) {
 	if args.MetricProvider.Type == Prometheus && args.MetricProvider.InsecureSkipVerify == nil {
 		args.MetricProvider.InsecureSkipVerify = &DefaultInsecureSkipVerify
 	}
+	if args.MetricsUpdateIntervalSeconds == nil || *args.MetricsUpdateIntervalSeconds <= 0 {
+		args.MetricsUpdateIntervalSeconds = &DefaultMetricsUpdateIntervalSeconds
+	}
+	if args.MetricsMaxAgeSeconds == nil {
+		args.MetricsMaxAgeSeconds = &DefaultMetricsMaxAgeSeconds
+	}
 }
 
 // SetDefaults_TargetLoadPackingArgs sets the default parameters for TargetLoadPacking plugin
@@ -148,6 +225,20 @@ func SetDefaults_TargetLoadPackingArgs(args *TargetLoadPackingArgs) {
 	if args.TargetUtilization == nil || *args.TargetUtilization <= 0 {
 		args.TargetUtilization = &DefaultTargetUtilizationPercent
 	}
+	if args.FallbackGracePeriodSeconds == nil {
+		args.FallbackGracePeriodSeconds = &DefaultFallbackGracePeriodSeconds
+	}
+	for i := range args.Resources {
+		if args.Resources[i].TargetUtilization == nil {
+			args.Resources[i].TargetUtilization = &DefaultTargetUtilizationPercent
+		}
+		if args.Resources[i].Weight == nil {
+			args.Resources[i].Weight = &DefaultTargetLoadPackingResourceWeight
+		}
+	}
+	if args.ScoreMode == "" {
+		args.ScoreMode = DefaultTargetLoadPackingScoreMode
+	}
 }
 
 // SetDefaults_LoadVariationRiskBalancingArgs sets the default parameters for LoadVariationRiskBalancing plugin
@@ -211,6 +302,12 @@ func SetDefaults_NodeResourceTopologyMatchArgs(obj *NodeResourceTopologyMatchArg
 	if obj.Cache.InformerMode == nil {
 		obj.Cache.InformerMode = &defaultInformerMode
 	}
+	if obj.Cache.AccountingMode == nil {
+		obj.Cache.AccountingMode = &defaultAccountingMode
+	}
+	if obj.Cache.ResyncTrigger == nil {
+		obj.Cache.ResyncTrigger = &defaultResyncTrigger
+	}
 }
 
 // SetDefaults_PreemptionTolerationArgs reuses SetDefaults_DefaultPreemptionArgs
@@ -223,6 +320,10 @@ func SetDefaults_TopologicalSortArgs(obj *TopologicalSortArgs) {
 	if len(obj.Namespaces) == 0 {
 		obj.Namespaces = []string{metav1.NamespaceDefault}
 	}
+
+	if obj.Fairness == "" {
+		obj.Fairness = defaultFairness
+	}
 }
 
 // SetDefaults_NetworkOverheadArgs sets the default parameters for NetworkMinCostArgs plugin.
@@ -238,6 +339,10 @@ func SetDefaults_NetworkOverheadArgs(obj *NetworkOverheadArgs) {
 	if obj.NetworkTopologyName == nil {
 		obj.NetworkTopologyName = &DefaultNetworkTopologyName
 	}
+
+	if len(obj.TopologyKeys) == 0 {
+		obj.TopologyKeys = append([]string(nil), DefaultTopologyKeys...)
+	}
 }
 
 // SetDefaults_SySchedArgs sets the default parameters for SySchedArgs plugin.
@@ -251,8 +356,8 @@ func SetDefaults_SySchedArgs(obj *SySchedArgs) {
 	}
 }
 
-// SetDefaultPIDControllerArgs sets the default parameters for the PIDController plugin.
-func SetDefaultPIDControllerArgs(args *PIDControllerArgs) {
+// SetDefaults_PIDControllerArgs sets the default parameters for the PIDController plugin.
+func SetDefaults_PIDControllerArgs(args *PIDControllerArgs) {
 	if args.EndpointURL == nil {
 		defaultURL := "http://localhost:5100/score"
 		args.EndpointURL = &defaultURL
@@ -272,4 +377,50 @@ func SetDefaultPIDControllerArgs(args *PIDControllerArgs) {
 		defaultRequestTimeout := 10
 		args.RequestTimeoutSec = &defaultRequestTimeout
 	}
+
+	if args.Mode == "" {
+		args.Mode = DefaultPIDControllerMode
+	}
+
+	if args.TargetUtilization == nil {
+		args.TargetUtilization = &DefaultPIDTargetUtilizationPercent
+	}
+
+	if args.Kp == nil {
+		args.Kp = &DefaultPIDKp
+	}
+
+	if args.Ki == nil {
+		args.Ki = &DefaultPIDKi
+	}
+
+	if args.Kd == nil {
+		args.Kd = &DefaultPIDKd
+	}
+
+	if args.FailurePolicy == "" {
+		args.FailurePolicy = DefaultPIDControllerFailurePolicy
+	}
+
+	if args.LastKnownGoodMaxAgeSeconds == nil {
+		args.LastKnownGoodMaxAgeSeconds = &DefaultPIDLastKnownGoodMaxAgeSeconds
+	}
+
+	if args.CircuitBreakerFailureThreshold == nil {
+		args.CircuitBreakerFailureThreshold = &DefaultPIDCircuitBreakerFailureThreshold
+	}
+
+	if args.CircuitBreakerOpenSeconds == nil {
+		args.CircuitBreakerOpenSeconds = &DefaultPIDCircuitBreakerOpenSeconds
+	}
+}
+
+// SetDefaults_DiskIOAwareArgs sets the default parameters for the DiskIOAware plugin.
+func SetDefaults_DiskIOAwareArgs(args *DiskIOAwareArgs) {
+	if args.ScoringStrategy == "" {
+		args.ScoringStrategy = DefaultDiskIOScoringStrategy
+	}
+	if args.NodeDiskIOInfoNamespace == nil {
+		args.NodeDiskIOInfoNamespace = &DefaultNodeDiskIOInfoNamespace
+	}
 }
@@ -30,6 +30,7 @@ import (
 )
 
 func TestSchedulingDefaults(t *testing.T) {
+	periodicResyncTrigger := CacheResyncTriggerPeriodic
 	tests := []struct {
 		name   string
 		config runtime.Object
@@ -41,6 +42,7 @@ func TestSchedulingDefaults(t *testing.T) {
 			expect: &CoschedulingArgs{
 				PermitWaitingTimeSeconds: pointer.Int64Ptr(60),
 				PodGroupBackoffSeconds:   pointer.Int64Ptr(0),
+				PreemptionMode:           PreemptionModeNone,
 			},
 		},
 		{
@@ -48,10 +50,28 @@ func TestSchedulingDefaults(t *testing.T) {
 			config: &CoschedulingArgs{
 				PermitWaitingTimeSeconds: pointer.Int64Ptr(60),
 				PodGroupBackoffSeconds:   pointer.Int64Ptr(20),
+				PreemptionMode:           PreemptionModeGang,
 			},
 			expect: &CoschedulingArgs{
 				PermitWaitingTimeSeconds: pointer.Int64Ptr(60),
 				PodGroupBackoffSeconds:   pointer.Int64Ptr(20),
+				PreemptionMode:           PreemptionModeGang,
+			},
+		},
+		{
+			name:   "empty config CapacitySchedulingArgs",
+			config: &CapacitySchedulingArgs{},
+			expect: &CapacitySchedulingArgs{
+				BorrowingPolicy: BorrowingPolicyFirstComeFirstServed,
+			},
+		},
+		{
+			name: "set non default CapacitySchedulingArgs",
+			config: &CapacitySchedulingArgs{
+				BorrowingPolicy: BorrowingPolicyFairSharing,
+			},
+			expect: &CapacitySchedulingArgs{
+				BorrowingPolicy: BorrowingPolicyFairSharing,
 			},
 		},
 		{
@@ -79,6 +99,28 @@ func TestSchedulingDefaults(t *testing.T) {
 				Mode: Most,
 			},
 		},
+		{
+			name:   "empty config NodeResourceLimitsArgs",
+			config: &NodeResourceLimitsArgs{},
+			expect: &NodeResourceLimitsArgs{
+				Resources: []schedulerconfigv1.ResourceSpec{
+					{Name: "cpu", Weight: 1}, {Name: "memory", Weight: 1},
+				},
+			},
+		},
+		{
+			name: "set non default NodeResourceLimitsArgs",
+			config: &NodeResourceLimitsArgs{
+				Resources: []schedulerconfigv1.ResourceSpec{
+					{Name: "memory", Weight: 3}, {Name: "nvidia.com/gpu", Weight: 1},
+				},
+			},
+			expect: &NodeResourceLimitsArgs{
+				Resources: []schedulerconfigv1.ResourceSpec{
+					{Name: "memory", Weight: 3}, {Name: "nvidia.com/gpu", Weight: 1},
+				},
+			},
+		},
 		{
 			name:   "empty config TargetLoadPackingArgs",
 			config: &TargetLoadPackingArgs{},
@@ -86,28 +128,51 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64(30),
+					MetricsMaxAgeSeconds:         pointer.Int64(300),
+				},
 				DefaultRequests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(
 					strconv.FormatInt(DefaultRequestsMilliCores, 10) + "m")},
-				DefaultRequestsMultiplier: pointer.StringPtr("1.5"),
-				TargetUtilization:         pointer.Int64Ptr(40),
+				DefaultRequestsMultiplier:  pointer.StringPtr("1.5"),
+				TargetUtilization:          pointer.Int64Ptr(40),
+				FallbackGracePeriodSeconds: pointer.Int64(120),
+				ScoreMode:                  TargetLoadPackingScoreModeWeightedSum,
 			},
 		},
 		{
 			name: "set non default TargetLoadPackingArgs",
 			config: &TargetLoadPackingArgs{
 				TrimaranSpec: TrimaranSpec{
-					WatcherAddress: pointer.StringPtr("http://localhost:2020")},
-				DefaultRequests:           v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
-				DefaultRequestsMultiplier: pointer.StringPtr("2.5"),
-				TargetUtilization:         pointer.Int64Ptr(50),
+					WatcherAddress:               pointer.StringPtr("http://localhost:2020"),
+					MetricsUpdateIntervalSeconds: pointer.Int64(10),
+					MetricsMaxAgeSeconds:         pointer.Int64(0),
+				},
+				DefaultRequests:            v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+				DefaultRequestsMultiplier:  pointer.StringPtr("2.5"),
+				TargetUtilization:          pointer.Int64Ptr(50),
+				FallbackGracePeriodSeconds: pointer.Int64(-1),
+				Resources: []TargetLoadPackingResource{
+					{Name: v1.ResourceCPU},
+					{Name: v1.ResourceMemory, TargetUtilization: pointer.Int64(70), Weight: pointer.Int64(2)},
+				},
+				ScoreMode: TargetLoadPackingScoreModeMin,
 			},
 			expect: &TargetLoadPackingArgs{
 				TrimaranSpec: TrimaranSpec{
-					WatcherAddress: pointer.StringPtr("http://localhost:2020")},
-				DefaultRequests:           v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
-				DefaultRequestsMultiplier: pointer.StringPtr("2.5"),
-				TargetUtilization:         pointer.Int64Ptr(50),
+					WatcherAddress:               pointer.StringPtr("http://localhost:2020"),
+					MetricsUpdateIntervalSeconds: pointer.Int64(10),
+					MetricsMaxAgeSeconds:         pointer.Int64(0),
+				},
+				DefaultRequests:            v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+				DefaultRequestsMultiplier:  pointer.StringPtr("2.5"),
+				TargetUtilization:          pointer.Int64Ptr(50),
+				FallbackGracePeriodSeconds: pointer.Int64(-1),
+				Resources: []TargetLoadPackingResource{
+					{Name: v1.ResourceCPU, TargetUtilization: pointer.Int64(40), Weight: pointer.Int64(1)},
+					{Name: v1.ResourceMemory, TargetUtilization: pointer.Int64(70), Weight: pointer.Int64(2)},
+				},
+				ScoreMode: TargetLoadPackingScoreModeMin,
 			},
 		},
 		{
@@ -117,7 +182,10 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64(30),
+					MetricsMaxAgeSeconds:         pointer.Int64(300),
+				},
 				SafeVarianceMargin:      pointer.Float64Ptr(1.0),
 				SafeVarianceSensitivity: pointer.Float64Ptr(1.0),
 			},
@@ -132,7 +200,10 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64(30),
+					MetricsMaxAgeSeconds:         pointer.Int64(300),
+				},
 				SafeVarianceMargin:      pointer.Float64Ptr(2.0),
 				SafeVarianceSensitivity: pointer.Float64Ptr(2.0),
 			},
@@ -144,7 +215,10 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64(30),
+					MetricsMaxAgeSeconds:         pointer.Int64(300),
+				},
 				SmoothingWindowSize: pointer.Int64Ptr(5),
 				RiskLimitWeights: map[v1.ResourceName]float64{
 					v1.ResourceCPU:    0.5,
@@ -165,7 +239,10 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64(30),
+					MetricsMaxAgeSeconds:         pointer.Int64(300),
+				},
 				SmoothingWindowSize: pointer.Int64Ptr(10),
 				RiskLimitWeights: map[v1.ResourceName]float64{
 					v1.ResourceCPU:    0.2,
@@ -186,7 +263,10 @@ func TestSchedulingDefaults(t *testing.T) {
 				TrimaranSpec: TrimaranSpec{
 					MetricProvider: MetricProviderSpec{
 						Type: "KubernetesMetricsServer",
-					}},
+					},
+					MetricsUpdateIntervalSeconds: pointer.Int64(30),
+					MetricsMaxAgeSeconds:         pointer.Int64(300),
+				},
 				SmoothingWindowSize: pointer.Int64Ptr(10),
 				RiskLimitWeights: map[v1.ResourceName]float64{
 					v1.ResourceCPU:    0.5,
@@ -206,6 +286,8 @@ func TestSchedulingDefaults(t *testing.T) {
 					ForeignPodsDetect: &defaultForeignPodsDetect,
 					ResyncMethod:      &defaultResyncMethod,
 					InformerMode:      &defaultInformerMode,
+					AccountingMode:    &defaultAccountingMode,
+					ResyncTrigger:     &periodicResyncTrigger,
 				},
 			},
 		},
@@ -222,15 +304,18 @@ func TestSchedulingDefaults(t *testing.T) {
 			config: &TopologicalSortArgs{},
 			expect: &TopologicalSortArgs{
 				Namespaces: []string{"default"},
+				Fairness:   FairnessRoundRobin,
 			},
 		},
 		{
 			name: "set non default TopologySortArgs",
 			config: &TopologicalSortArgs{
 				Namespaces: []string{"n1"},
+				Fairness:   FairnessAge,
 			},
 			expect: &TopologicalSortArgs{
 				Namespaces: []string{"n1"},
+				Fairness:   FairnessAge,
 			},
 		},
 		{
@@ -240,6 +325,7 @@ func TestSchedulingDefaults(t *testing.T) {
 				Namespaces:          []string{"default"},
 				WeightsName:         pointer.StringPtr("UserDefined"),
 				NetworkTopologyName: pointer.StringPtr("nt-default"),
+				TopologyKeys:        []string{"topology.kubernetes.io/zone", "topology.kubernetes.io/region"},
 			},
 		},
 		{
@@ -248,11 +334,13 @@ func TestSchedulingDefaults(t *testing.T) {
 				Namespaces:          []string{"n2"},
 				WeightsName:         pointer.StringPtr("latency"),
 				NetworkTopologyName: pointer.StringPtr("nt-latency-costs"),
+				TopologyKeys:        []string{"kubernetes.io/hostname", "example.com/rack", "topology.kubernetes.io/zone"},
 			},
 			expect: &NetworkOverheadArgs{
 				Namespaces:          []string{"n2"},
 				WeightsName:         pointer.StringPtr("latency"),
 				NetworkTopologyName: pointer.StringPtr("nt-latency-costs"),
+				TopologyKeys:        []string{"kubernetes.io/hostname", "example.com/rack", "topology.kubernetes.io/zone"},
 			},
 		},
 		{
@@ -274,6 +362,72 @@ func TestSchedulingDefaults(t *testing.T) {
 				DefaultProfileName:      pointer.StringPtr("all-syscalls"),
 			},
 		},
+		{
+			name:   "empty config PIDControllerArgs",
+			config: &PIDControllerArgs{},
+			expect: &PIDControllerArgs{
+				EndpointURL:                    pointer.String("http://localhost:5100/score"),
+				MaxIdleConnections:             pointer.Int(10),
+				IdleConnectionTimeoutSec:       pointer.Int(30),
+				RequestTimeoutSec:              pointer.Int(10),
+				Mode:                           PIDControllerModeExternal,
+				TargetUtilization:              pointer.Int64(60),
+				Kp:                             pointer.Float64(1),
+				Ki:                             pointer.Float64(0.1),
+				Kd:                             pointer.Float64(0),
+				FailurePolicy:                  PIDControllerFailurePolicySkip,
+				LastKnownGoodMaxAgeSeconds:     pointer.Int64(300),
+				CircuitBreakerFailureThreshold: pointer.Int64(5),
+				CircuitBreakerOpenSeconds:      pointer.Int64(30),
+			},
+		},
+		{
+			name: "set non default PIDControllerArgs",
+			config: &PIDControllerArgs{
+				Mode:                           PIDControllerModeBuiltIn,
+				TargetUtilization:              pointer.Int64(75),
+				Kp:                             pointer.Float64(2),
+				Ki:                             pointer.Float64(0),
+				Kd:                             pointer.Float64(0.5),
+				FailurePolicy:                  PIDControllerFailurePolicyLastKnownGood,
+				LastKnownGoodMaxAgeSeconds:     pointer.Int64(60),
+				CircuitBreakerFailureThreshold: pointer.Int64(0),
+			},
+			expect: &PIDControllerArgs{
+				EndpointURL:                    pointer.String("http://localhost:5100/score"),
+				MaxIdleConnections:             pointer.Int(10),
+				IdleConnectionTimeoutSec:       pointer.Int(30),
+				RequestTimeoutSec:              pointer.Int(10),
+				Mode:                           PIDControllerModeBuiltIn,
+				TargetUtilization:              pointer.Int64(75),
+				Kp:                             pointer.Float64(2),
+				Ki:                             pointer.Float64(0),
+				Kd:                             pointer.Float64(0.5),
+				FailurePolicy:                  PIDControllerFailurePolicyLastKnownGood,
+				LastKnownGoodMaxAgeSeconds:     pointer.Int64(60),
+				CircuitBreakerFailureThreshold: pointer.Int64(0),
+				CircuitBreakerOpenSeconds:      pointer.Int64(30),
+			},
+		},
+		{
+			name:   "empty config DiskIOAwareArgs",
+			config: &DiskIOAwareArgs{},
+			expect: &DiskIOAwareArgs{
+				ScoringStrategy:         MostAllocated,
+				NodeDiskIOInfoNamespace: pointer.String("kube-system"),
+			},
+		},
+		{
+			name: "set non default DiskIOAwareArgs",
+			config: &DiskIOAwareArgs{
+				ScoringStrategy:         LeastAllocated,
+				NodeDiskIOInfoNamespace: pointer.String("diskio"),
+			},
+			expect: &DiskIOAwareArgs{
+				ScoringStrategy:         LeastAllocated,
+				NodeDiskIOInfoNamespace: pointer.String("diskio"),
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -37,7 +37,9 @@ var (
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&CoschedulingArgs{},
+		&CapacitySchedulingArgs{},
 		&NodeResourcesAllocatableArgs{},
+		&NodeResourceLimitsArgs{},
 		&TargetLoadPackingArgs{},
 		&LoadVariationRiskBalancingArgs{},
 		&LowRiskOverCommitmentArgs{},
@@ -47,6 +49,7 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&NetworkOverheadArgs{},
 		&SySchedArgs{},
 		&PIDControllerArgs{},
+		&DiskIOAwareArgs{},
 	)
 	return nil
 }
@@ -19,68 +19,60 @@ package v1
 import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	schedulerconfigv1 "k8s.io/kube-scheduler/config/v1"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
-// +k8s:defaulter-gen=true
-type PIDControllerArgs struct {
-	metav1.TypeMeta `json:",inline"`
-
-	// EndpointURL is the URL to which score requests will be sent.
-	EndpointURL *string `json:"endpointURL,omitempty"`
-
-	// MaxIdleConnections defines the maximum number of idle connections to the server.
-	MaxIdleConnections *int `json:"maxIdleConnections,omitempty"`
 
-	// IdleConnectionTimeoutSec defines the timeout for idle connections in seconds.
-	IdleConnectionTimeoutSec *int `json:"idleConnectionTimeoutSec,omitempty"`
-
-	// RequestTimeoutSec defines the timeout for requests in seconds.
-	RequestTimeoutSec *int `json:"requestTimeoutSec,omitempty"`
-}
+// CoschedulingArgs defines the scheduling parameters for Coscheduling plugin.
+type CoschedulingArgs struct {
+	metav1.TypeMeta `json:",inline"`
 
-// DeepCopyInto is a manually implemented deepcopy function.
-func (in *PIDControllerArgs) DeepCopyInto(out *PIDControllerArgs) {
-	*out = *in
-	if in.EndpointURL != nil {
-		in, out := &in.EndpointURL, &out.EndpointURL
-		*out = new(string)
-		**out = **in
-	}
+	// PermitWaitingTimeSeconds is the waiting timeout in seconds.
+	PermitWaitingTimeSeconds *int64 `json:"permitWaitingTimeSeconds,omitempty"`
+	// PodGroupBackoffSeconds is the backoff time in seconds before a pod group can be scheduled again.
+	PodGroupBackoffSeconds *int64 `json:"podGroupBackoffSeconds,omitempty"`
+	// PreemptionMode decides what PostFilter does when a member of a PodGroup is unschedulable.
+	// "None" (the default) rejects the waiting siblings and backs off the PodGroup.
+	// "Gang" first tries to preempt lower-priority pods so that MinMember pods of the
+	// PodGroup fit at once, and nominates a node for each of them.
+	PreemptionMode PreemptionModeType `json:"preemptionMode,omitempty"`
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PIDControllerArgs.
-func (in *PIDControllerArgs) DeepCopy() *PIDControllerArgs {
-	if in == nil {
-		return nil
-	}
-	out := new(PIDControllerArgs)
-	in.DeepCopyInto(out)
-	return out
-}
+// PreemptionModeType is a "string" type.
+type PreemptionModeType string
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PIDControllerArgs) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
+const (
+	// PreemptionModeNone only rejects the waiting siblings of an unschedulable PodGroup.
+	PreemptionModeNone PreemptionModeType = "None"
+	// PreemptionModeGang preempts lower-priority pods so that the whole PodGroup fits at once.
+	PreemptionModeGang PreemptionModeType = "Gang"
+)
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:defaulter-gen=true
 
-// CoschedulingArgs defines the scheduling parameters for Coscheduling plugin.
-type CoschedulingArgs struct {
+// CapacitySchedulingArgs defines the parameters for CapacityScheduling plugin.
+type CapacitySchedulingArgs struct {
 	metav1.TypeMeta `json:",inline"`
 
-	// PermitWaitingTimeSeconds is the waiting timeout in seconds.
-	PermitWaitingTimeSeconds *int64 `json:"permitWaitingTimeSeconds,omitempty"`
-	// PodGroupBackoffSeconds is the backoff time in seconds before a pod group can be scheduled again.
-	PodGroupBackoffSeconds *int64 `json:"podGroupBackoffSeconds,omitempty"`
+	// BorrowingPolicy decides how the ElasticQuotas share the resources lent by the quotas under their min.
+	// "FirstComeFirstServed" (the default) lets a quota borrow any of them up to its max.
+	// "FairSharing" splits them among the quotas in proportion to their weights, and lets a quota
+	// within its fair share preempt the pods of the quotas over theirs.
+	BorrowingPolicy BorrowingPolicyType `json:"borrowingPolicy,omitempty"`
 }
 
+// BorrowingPolicyType is a "string" type.
+type BorrowingPolicyType string
+
+const (
+	// BorrowingPolicyFirstComeFirstServed lets a quota borrow any lent resources up to its max.
+	BorrowingPolicyFirstComeFirstServed BorrowingPolicyType = "FirstComeFirstServed"
+	// BorrowingPolicyFairSharing splits the lent resources among the quotas in proportion to their weights.
+	BorrowingPolicyFairSharing BorrowingPolicyType = "FairSharing"
+)
+
 // ModeType is a type "string".
 type ModeType string
 
@@ -108,6 +100,19 @@ type NodeResourcesAllocatableArgs struct {
 	Mode ModeType `json:"mode,omitempty"`
 }
 
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:defaulter-gen=true
+
+// NodeResourceLimitsArgs holds arguments used to configure NodeResourceLimits plugin.
+type NodeResourceLimitsArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Resources to be considered when scoring, with their weights.
+	// The score of each resource is the share of its allocatable not committed as
+	// limits, so the weights do not depend on the resource units.
+	Resources []schedulerconfigv1.ResourceSpec `json:"resources,omitempty"`
+}
+
 // MetricProviderType is a "string" type.
 type MetricProviderType string
 
@@ -135,6 +140,11 @@ type TrimaranSpec struct {
 	MetricProvider MetricProviderSpec `json:"metricProvider,omitempty"`
 	// Address of load watcher service
 	WatcherAddress *string `json:"watcherAddress,omitempty"`
+	// Interval in seconds between two refreshes of the load watcher metrics. Default is 30.
+	MetricsUpdateIntervalSeconds *int64 `json:"metricsUpdateIntervalSeconds,omitempty"`
+	// Age in seconds of the load watcher metrics window after which metrics are considered stale
+	// and are no longer used for scoring. Zero disables the staleness detection. Default is 300.
+	MetricsMaxAgeSeconds *int64 `json:"metricsMaxAgeSeconds,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -150,10 +160,38 @@ type TargetLoadPackingArgs struct {
 	DefaultRequests v1.ResourceList `json:"defaultRequests,omitempty"`
 	// Default requests multiplier for busrtable QoS
 	DefaultRequestsMultiplier *string `json:"defaultRequestsMultiplier,omitempty"`
-	// Node target CPU Utilization for bin packing
+	// Node target CPU Utilization for bin packing, used when Resources is empty
 	TargetUtilization *int64 `json:"targetUtilization,omitempty"`
+	// Seconds the metrics of a node may be missing before the node is scored
+	// from its allocated resources instead. A negative value disables the fallback.
+	FallbackGracePeriodSeconds *int64 `json:"fallbackGracePeriodSeconds,omitempty"`
+	// Resources to pack, with their target utilization and weight.
+	// If empty, only CPU is packed, around TargetUtilization.
+	Resources []TargetLoadPackingResource `json:"resources,omitempty"`
+	// How the scores of the resources are combined into the node score
+	ScoreMode TargetLoadPackingScoreMode `json:"scoreMode,omitempty"`
 }
 
+// TargetLoadPackingResource holds the target utilization and the weight of a resource packed by TargetLoadPacking.
+type TargetLoadPackingResource struct {
+	// Name of the resource, cpu or memory
+	Name v1.ResourceName `json:"name"`
+	// Node target utilization % of the resource
+	TargetUtilization *int64 `json:"targetUtilization,omitempty"`
+	// Weight of the resource score in the WeightedSum score mode
+	Weight *int64 `json:"weight,omitempty"`
+}
+
+// TargetLoadPackingScoreMode is a "string" type.
+type TargetLoadPackingScoreMode string
+
+const (
+	// TargetLoadPackingScoreModeWeightedSum scores a node with the weighted average of its resource scores.
+	TargetLoadPackingScoreModeWeightedSum TargetLoadPackingScoreMode = "WeightedSum"
+	// TargetLoadPackingScoreModeMin scores a node with its lowest resource score.
+	TargetLoadPackingScoreModeMin TargetLoadPackingScoreMode = "Min"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:defaulter-gen=true
 
@@ -229,6 +267,22 @@ const (
 	CacheInformerDedicated CacheInformerMode = "Dedicated"
 )
 
+// CacheAccountingMode is a "string" type.
+type CacheAccountingMode string
+
+const (
+	CacheAccountingPessimistic CacheAccountingMode = "Pessimistic"
+	CacheAccountingPerNUMA     CacheAccountingMode = "PerNUMA"
+)
+
+// CacheResyncTrigger is a "string" type.
+type CacheResyncTrigger string
+
+const (
+	CacheResyncTriggerPeriodic CacheResyncTrigger = "Periodic"
+	CacheResyncTriggerEvents   CacheResyncTrigger = "Events"
+)
+
 // NodeResourceTopologyCache define configuration details for the NodeResourceTopology cache.
 type NodeResourceTopologyCache struct {
 	// ForeignPodsDetect sets how foreign pods should be handled.
@@ -250,6 +304,19 @@ type NodeResourceTopologyCache struct {
 	// guaranteed to best suit the cache needs, at cost of one extra connection.
 	// If unspecified, default is "Dedicated"
 	InformerMode *CacheInformerMode `json:"informerMode,omitempty"`
+	// AccountingMode sets how the resources of the pods scheduled after the last NRT update are deducted.
+	// "Pessimistic" deducts them from all the NUMA zones of the node. "PerNUMA" deducts them only from
+	// the NUMA zones the filter picked for the pod, and falls back to "Pessimistic" on a node until its
+	// next resync if a later NRT update of the node disagrees with the NUMA zones picked.
+	// Has no effect if caching is disabled (CacheResyncPeriod is zero) or if DiscardReservedNodes
+	// is enabled. If unspecified, default is "Pessimistic".
+	AccountingMode *CacheAccountingMode `json:"accountingMode,omitempty"`
+	// ResyncTrigger sets when the cache tries to resync the nodes. "Periodic" tries every CacheResyncPeriodSeconds.
+	// "Events" additionally watches the NRT objects, and tries to resync a node shortly after its NRT object
+	// changes, coalescing the changes which happen in a short time.
+	// Has no effect if caching is disabled (CacheResyncPeriod is zero) or if DiscardReservedNodes
+	// is enabled. If unspecified, default is "Periodic".
+	ResyncTrigger *CacheResyncTrigger `json:"resyncTrigger,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -282,8 +349,23 @@ type TopologicalSortArgs struct {
 
 	// Namespaces to be considered by TopologySort plugin
 	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Fairness decides how the pods of the same priority but of different AppGroups are ordered.
+	// "RoundRobin" (the default) takes one workload of each AppGroup in turn, in their topology order.
+	// "Age" puts the pods of the oldest AppGroup first, which may starve the newer AppGroups.
+	Fairness FairnessType `json:"fairness,omitempty"`
 }
 
+// FairnessType is a "string" type.
+type FairnessType string
+
+const (
+	// FairnessAge orders the AppGroups by their creation time.
+	FairnessAge FairnessType = "Age"
+	// FairnessRoundRobin interleaves the AppGroups by the topology index of their workloads.
+	FairnessRoundRobin FairnessType = "RoundRobin"
+)
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type NetworkOverheadArgs struct {
@@ -297,6 +379,11 @@ type NetworkOverheadArgs struct {
 
 	// The NetworkTopology CRD name
 	NetworkTopologyName *string `json:"networkTopologyName,omitempty"`
+
+	// Node labels of the topology levels network costs are defined for, from the finest to the
+	// coarsest level, e.g. kubernetes.io/hostname, a rack label, topology.kubernetes.io/zone and
+	// topology.kubernetes.io/region (Default: zone and region)
+	TopologyKeys []string `json:"topologyKeys,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -310,3 +397,89 @@ type SySchedArgs struct {
 	// CR name of the default profile for all system calls
 	DefaultProfileName *string `json:"defaultProfileName,omitempty"`
 }
+
+// PIDControllerMode is a "string" type.
+type PIDControllerMode string
+
+const (
+	// PIDControllerModeExternal asks the service at EndpointURL for the node scores.
+	PIDControllerModeExternal PIDControllerMode = "External"
+	// PIDControllerModeBuiltIn runs the PID loop inside the plugin.
+	PIDControllerModeBuiltIn PIDControllerMode = "BuiltIn"
+)
+
+// PIDControllerFailurePolicy is a "string" type.
+type PIDControllerFailurePolicy string
+
+const (
+	// PIDControllerFailurePolicyFail fails the scheduling cycle of the pod.
+	PIDControllerFailurePolicyFail PIDControllerFailurePolicy = "Fail"
+	// PIDControllerFailurePolicySkip leaves the nodes unscored, so the plugin has no say for the pod.
+	PIDControllerFailurePolicySkip PIDControllerFailurePolicy = "Skip"
+	// PIDControllerFailurePolicyLastKnownGood reuses the last score the endpoint returned for each node,
+	// as long as it is not older than LastKnownGoodMaxAgeSeconds.
+	PIDControllerFailurePolicyLastKnownGood PIDControllerFailurePolicy = "LastKnownGood"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:defaulter-gen=true
+
+// PIDControllerArgs holds arguments used to configure the PIDController plugin.
+type PIDControllerArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// EndpointURL is the URL to which score requests will be sent.
+	EndpointURL *string `json:"endpointURL,omitempty"`
+
+	// MaxIdleConnections defines the maximum number of idle connections to the server.
+	MaxIdleConnections *int `json:"maxIdleConnections,omitempty"`
+
+	// IdleConnectionTimeoutSec defines the timeout for idle connections in seconds.
+	IdleConnectionTimeoutSec *int `json:"idleConnectionTimeoutSec,omitempty"`
+
+	// RequestTimeoutSec defines the timeout for requests in seconds.
+	RequestTimeoutSec *int `json:"requestTimeoutSec,omitempty"`
+
+	// Mode selects where node scores come from: "External" sends one batched request
+	// per pod to EndpointURL, "BuiltIn" runs the PID loop inside the plugin.
+	// If unspecified, default is "External".
+	Mode PIDControllerMode `json:"mode,omitempty"`
+
+	// TargetUtilization is the node utilization percentage the built-in PID loop steers towards.
+	TargetUtilization *int64 `json:"targetUtilization,omitempty"`
+
+	// Kp, Ki and Kd are the proportional, integral and derivative gains of the built-in PID loop.
+	Kp *float64 `json:"kp,omitempty"`
+	Ki *float64 `json:"ki,omitempty"`
+	Kd *float64 `json:"kd,omitempty"`
+
+	// FailurePolicy decides how the pod is scored when the endpoint can't be reached or its
+	// answer can't be used: "Fail" fails the scheduling cycle, "Skip" leaves the nodes unscored,
+	// "LastKnownGood" reuses recent node scores and skips if there are none.
+	// If unspecified, default is "Skip".
+	FailurePolicy PIDControllerFailurePolicy `json:"failurePolicy,omitempty"`
+
+	// LastKnownGoodMaxAgeSeconds is how long a node score is reused by the LastKnownGood policy.
+	LastKnownGoodMaxAgeSeconds *int64 `json:"lastKnownGoodMaxAgeSeconds,omitempty"`
+
+	// CircuitBreakerFailureThreshold is the number of consecutive failures after which the endpoint
+	// is no longer called for CircuitBreakerOpenSeconds. Zero disables the circuit breaker.
+	CircuitBreakerFailureThreshold *int64 `json:"circuitBreakerFailureThreshold,omitempty"`
+
+	// CircuitBreakerOpenSeconds is how long the circuit breaker stays open before a trial request.
+	CircuitBreakerOpenSeconds *int64 `json:"circuitBreakerOpenSeconds,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:defaulter-gen=true
+
+// DiskIOAwareArgs holds arguments used to configure the DiskIOAware plugin.
+type DiskIOAwareArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// ScoringStrategy selects how nodes are scored from their disk IO bandwidth,
+	// MostAllocated or LeastAllocated.
+	ScoringStrategy ScoringStrategyType `json:"scoringStrategy,omitempty"`
+	// NodeDiskIOInfoNamespace is the namespace of the NodeDiskIOInfo objects, one per node.
+	NodeDiskIOInfoNamespace *string `json:"nodeDiskIOInfoNamespace,omitempty"`
+}
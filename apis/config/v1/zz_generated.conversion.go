@@ -40,6 +40,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*CapacitySchedulingArgs)(nil), (*config.CapacitySchedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(a.(*CapacitySchedulingArgs), b.(*config.CapacitySchedulingArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.CapacitySchedulingArgs)(nil), (*CapacitySchedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(a.(*config.CapacitySchedulingArgs), b.(*CapacitySchedulingArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*CoschedulingArgs)(nil), (*config.CoschedulingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_CoschedulingArgs_To_config_CoschedulingArgs(a.(*CoschedulingArgs), b.(*config.CoschedulingArgs), scope)
 	}); err != nil {
@@ -50,6 +60,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*DiskIOAwareArgs)(nil), (*config.DiskIOAwareArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_DiskIOAwareArgs_To_config_DiskIOAwareArgs(a.(*DiskIOAwareArgs), b.(*config.DiskIOAwareArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.DiskIOAwareArgs)(nil), (*DiskIOAwareArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_DiskIOAwareArgs_To_v1_DiskIOAwareArgs(a.(*config.DiskIOAwareArgs), b.(*DiskIOAwareArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*LoadVariationRiskBalancingArgs)(nil), (*config.LoadVariationRiskBalancingArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_LoadVariationRiskBalancingArgs_To_config_LoadVariationRiskBalancingArgs(a.(*LoadVariationRiskBalancingArgs), b.(*config.LoadVariationRiskBalancingArgs), scope)
 	}); err != nil {
@@ -90,6 +110,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*NodeResourceLimitsArgs)(nil), (*config.NodeResourceLimitsArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_NodeResourceLimitsArgs_To_config_NodeResourceLimitsArgs(a.(*NodeResourceLimitsArgs), b.(*config.NodeResourceLimitsArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.NodeResourceLimitsArgs)(nil), (*NodeResourceLimitsArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_NodeResourceLimitsArgs_To_v1_NodeResourceLimitsArgs(a.(*config.NodeResourceLimitsArgs), b.(*NodeResourceLimitsArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*NodeResourceTopologyCache)(nil), (*config.NodeResourceTopologyCache)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_NodeResourceTopologyCache_To_config_NodeResourceTopologyCache(a.(*NodeResourceTopologyCache), b.(*config.NodeResourceTopologyCache), scope)
 	}); err != nil {
@@ -110,6 +140,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*PIDControllerArgs)(nil), (*config.PIDControllerArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_PIDControllerArgs_To_config_PIDControllerArgs(a.(*PIDControllerArgs), b.(*config.PIDControllerArgs), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.PIDControllerArgs)(nil), (*PIDControllerArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_PIDControllerArgs_To_v1_PIDControllerArgs(a.(*config.PIDControllerArgs), b.(*PIDControllerArgs), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*PreemptionTolerationArgs)(nil), (*config.PreemptionTolerationArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_PreemptionTolerationArgs_To_config_PreemptionTolerationArgs(a.(*PreemptionTolerationArgs), b.(*config.PreemptionTolerationArgs), scope)
 	}); err != nil {
@@ -150,6 +190,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*TargetLoadPackingResource)(nil), (*config.TargetLoadPackingResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource(a.(*TargetLoadPackingResource), b.(*config.TargetLoadPackingResource), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.TargetLoadPackingResource)(nil), (*TargetLoadPackingResource)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource(a.(*config.TargetLoadPackingResource), b.(*TargetLoadPackingResource), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*TopologicalSortArgs)(nil), (*config.TopologicalSortArgs)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1_TopologicalSortArgs_To_config_TopologicalSortArgs(a.(*TopologicalSortArgs), b.(*config.TopologicalSortArgs), scope)
 	}); err != nil {
@@ -183,6 +233,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(in *CapacitySchedulingArgs, out *config.CapacitySchedulingArgs, s conversion.Scope) error {
+	out.BorrowingPolicy = config.BorrowingPolicyType(in.BorrowingPolicy)
+	return nil
+}
+
+// Convert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs is an autogenerated conversion function.
+func Convert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(in *CapacitySchedulingArgs, out *config.CapacitySchedulingArgs, s conversion.Scope) error {
+	return autoConvert_v1_CapacitySchedulingArgs_To_config_CapacitySchedulingArgs(in, out, s)
+}
+
+func autoConvert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(in *config.CapacitySchedulingArgs, out *CapacitySchedulingArgs, s conversion.Scope) error {
+	out.BorrowingPolicy = BorrowingPolicyType(in.BorrowingPolicy)
+	return nil
+}
+
+// Convert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs is an autogenerated conversion function.
+func Convert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(in *config.CapacitySchedulingArgs, out *CapacitySchedulingArgs, s conversion.Scope) error {
+	return autoConvert_config_CapacitySchedulingArgs_To_v1_CapacitySchedulingArgs(in, out, s)
+}
+
 func autoConvert_v1_CoschedulingArgs_To_config_CoschedulingArgs(in *CoschedulingArgs, out *config.CoschedulingArgs, s conversion.Scope) error {
 	if err := metav1.Convert_Pointer_int64_To_int64(&in.PermitWaitingTimeSeconds, &out.PermitWaitingTimeSeconds, s); err != nil {
 		return err
@@ -190,6 +260,7 @@ func autoConvert_v1_CoschedulingArgs_To_config_CoschedulingArgs(in *Coscheduling
 	if err := metav1.Convert_Pointer_int64_To_int64(&in.PodGroupBackoffSeconds, &out.PodGroupBackoffSeconds, s); err != nil {
 		return err
 	}
+	out.PreemptionMode = config.PreemptionModeType(in.PreemptionMode)
 	return nil
 }
 
@@ -205,6 +276,7 @@ func autoConvert_config_CoschedulingArgs_To_v1_CoschedulingArgs(in *config.Cosch
 	if err := metav1.Convert_int64_To_Pointer_int64(&in.PodGroupBackoffSeconds, &out.PodGroupBackoffSeconds, s); err != nil {
 		return err
 	}
+	out.PreemptionMode = PreemptionModeType(in.PreemptionMode)
 	return nil
 }
 
@@ -213,6 +285,32 @@ func Convert_config_CoschedulingArgs_To_v1_CoschedulingArgs(in *config.Coschedul
 	return autoConvert_config_CoschedulingArgs_To_v1_CoschedulingArgs(in, out, s)
 }
 
+func autoConvert_v1_DiskIOAwareArgs_To_config_DiskIOAwareArgs(in *DiskIOAwareArgs, out *config.DiskIOAwareArgs, s conversion.Scope) error {
+	out.ScoringStrategy = config.ScoringStrategyType(in.ScoringStrategy)
+	if err := metav1.Convert_Pointer_string_To_string(&in.NodeDiskIOInfoNamespace, &out.NodeDiskIOInfoNamespace, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_DiskIOAwareArgs_To_config_DiskIOAwareArgs is an autogenerated conversion function.
+func Convert_v1_DiskIOAwareArgs_To_config_DiskIOAwareArgs(in *DiskIOAwareArgs, out *config.DiskIOAwareArgs, s conversion.Scope) error {
+	return autoConvert_v1_DiskIOAwareArgs_To_config_DiskIOAwareArgs(in, out, s)
+}
+
+func autoConvert_config_DiskIOAwareArgs_To_v1_DiskIOAwareArgs(in *config.DiskIOAwareArgs, out *DiskIOAwareArgs, s conversion.Scope) error {
+	out.ScoringStrategy = ScoringStrategyType(in.ScoringStrategy)
+	if err := metav1.Convert_string_To_Pointer_string(&in.NodeDiskIOInfoNamespace, &out.NodeDiskIOInfoNamespace, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_DiskIOAwareArgs_To_v1_DiskIOAwareArgs is an autogenerated conversion function.
+func Convert_config_DiskIOAwareArgs_To_v1_DiskIOAwareArgs(in *config.DiskIOAwareArgs, out *DiskIOAwareArgs, s conversion.Scope) error {
+	return autoConvert_config_DiskIOAwareArgs_To_v1_DiskIOAwareArgs(in, out, s)
+}
+
 func autoConvert_v1_LoadVariationRiskBalancingArgs_To_config_LoadVariationRiskBalancingArgs(in *LoadVariationRiskBalancingArgs, out *config.LoadVariationRiskBalancingArgs, s conversion.Scope) error {
 	if err := Convert_v1_TrimaranSpec_To_config_TrimaranSpec(&in.TrimaranSpec, &out.TrimaranSpec, s); err != nil {
 		return err
@@ -327,6 +425,7 @@ func autoConvert_v1_NetworkOverheadArgs_To_config_NetworkOverheadArgs(in *Networ
 	if err := metav1.Convert_Pointer_string_To_string(&in.NetworkTopologyName, &out.NetworkTopologyName, s); err != nil {
 		return err
 	}
+	out.TopologyKeys = *(*[]string)(unsafe.Pointer(&in.TopologyKeys))
 	return nil
 }
 
@@ -343,6 +442,7 @@ func autoConvert_config_NetworkOverheadArgs_To_v1_NetworkOverheadArgs(in *config
 	if err := metav1.Convert_string_To_Pointer_string(&in.NetworkTopologyName, &out.NetworkTopologyName, s); err != nil {
 		return err
 	}
+	out.TopologyKeys = *(*[]string)(unsafe.Pointer(&in.TopologyKeys))
 	return nil
 }
 
@@ -351,10 +451,32 @@ func Convert_config_NetworkOverheadArgs_To_v1_NetworkOverheadArgs(in *config.Net
 	return autoConvert_config_NetworkOverheadArgs_To_v1_NetworkOverheadArgs(in, out, s)
 }
 
+func autoConvert_v1_NodeResourceLimitsArgs_To_config_NodeResourceLimitsArgs(in *NodeResourceLimitsArgs, out *config.NodeResourceLimitsArgs, s conversion.Scope) error {
+	out.Resources = *(*[]apisconfig.ResourceSpec)(unsafe.Pointer(&in.Resources))
+	return nil
+}
+
+// Convert_v1_NodeResourceLimitsArgs_To_config_NodeResourceLimitsArgs is an autogenerated conversion function.
+func Convert_v1_NodeResourceLimitsArgs_To_config_NodeResourceLimitsArgs(in *NodeResourceLimitsArgs, out *config.NodeResourceLimitsArgs, s conversion.Scope) error {
+	return autoConvert_v1_NodeResourceLimitsArgs_To_config_NodeResourceLimitsArgs(in, out, s)
+}
+
+func autoConvert_config_NodeResourceLimitsArgs_To_v1_NodeResourceLimitsArgs(in *config.NodeResourceLimitsArgs, out *NodeResourceLimitsArgs, s conversion.Scope) error {
+	out.Resources = *(*[]configv1.ResourceSpec)(unsafe.Pointer(&in.Resources))
+	return nil
+}
+
+// Convert_config_NodeResourceLimitsArgs_To_v1_NodeResourceLimitsArgs is an autogenerated conversion function.
+func Convert_config_NodeResourceLimitsArgs_To_v1_NodeResourceLimitsArgs(in *config.NodeResourceLimitsArgs, out *NodeResourceLimitsArgs, s conversion.Scope) error {
+	return autoConvert_config_NodeResourceLimitsArgs_To_v1_NodeResourceLimitsArgs(in, out, s)
+}
+
 func autoConvert_v1_NodeResourceTopologyCache_To_config_NodeResourceTopologyCache(in *NodeResourceTopologyCache, out *config.NodeResourceTopologyCache, s conversion.Scope) error {
 	out.ForeignPodsDetect = (*config.ForeignPodsDetectMode)(unsafe.Pointer(in.ForeignPodsDetect))
 	out.ResyncMethod = (*config.CacheResyncMethod)(unsafe.Pointer(in.ResyncMethod))
 	out.InformerMode = (*config.CacheInformerMode)(unsafe.Pointer(in.InformerMode))
+	out.AccountingMode = (*config.CacheAccountingMode)(unsafe.Pointer(in.AccountingMode))
+	out.ResyncTrigger = (*config.CacheResyncTrigger)(unsafe.Pointer(in.ResyncTrigger))
 	return nil
 }
 
@@ -367,6 +489,8 @@ func autoConvert_config_NodeResourceTopologyCache_To_v1_NodeResourceTopologyCach
 	out.ForeignPodsDetect = (*ForeignPodsDetectMode)(unsafe.Pointer(in.ForeignPodsDetect))
 	out.ResyncMethod = (*CacheResyncMethod)(unsafe.Pointer(in.ResyncMethod))
 	out.InformerMode = (*CacheInformerMode)(unsafe.Pointer(in.InformerMode))
+	out.AccountingMode = (*CacheAccountingMode)(unsafe.Pointer(in.AccountingMode))
+	out.ResyncTrigger = (*CacheResyncTrigger)(unsafe.Pointer(in.ResyncTrigger))
 	return nil
 }
 
@@ -417,6 +541,78 @@ func Convert_config_NodeResourcesAllocatableArgs_To_v1_NodeResourcesAllocatableA
 	return autoConvert_config_NodeResourcesAllocatableArgs_To_v1_NodeResourcesAllocatableArgs(in, out, s)
 }
 
+func autoConvert_v1_PIDControllerArgs_To_config_PIDControllerArgs(in *PIDControllerArgs, out *config.PIDControllerArgs, s conversion.Scope) error {
+	out.EndpointURL = (*string)(unsafe.Pointer(in.EndpointURL))
+	out.MaxIdleConnections = (*int)(unsafe.Pointer(in.MaxIdleConnections))
+	out.IdleConnectionTimeoutSec = (*int)(unsafe.Pointer(in.IdleConnectionTimeoutSec))
+	out.RequestTimeoutSec = (*int)(unsafe.Pointer(in.RequestTimeoutSec))
+	out.Mode = config.PIDControllerMode(in.Mode)
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_float64_To_float64(&in.Kp, &out.Kp, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_float64_To_float64(&in.Ki, &out.Ki, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_float64_To_float64(&in.Kd, &out.Kd, s); err != nil {
+		return err
+	}
+	out.FailurePolicy = config.PIDControllerFailurePolicy(in.FailurePolicy)
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.LastKnownGoodMaxAgeSeconds, &out.LastKnownGoodMaxAgeSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.CircuitBreakerFailureThreshold, &out.CircuitBreakerFailureThreshold, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.CircuitBreakerOpenSeconds, &out.CircuitBreakerOpenSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_PIDControllerArgs_To_config_PIDControllerArgs is an autogenerated conversion function.
+func Convert_v1_PIDControllerArgs_To_config_PIDControllerArgs(in *PIDControllerArgs, out *config.PIDControllerArgs, s conversion.Scope) error {
+	return autoConvert_v1_PIDControllerArgs_To_config_PIDControllerArgs(in, out, s)
+}
+
+func autoConvert_config_PIDControllerArgs_To_v1_PIDControllerArgs(in *config.PIDControllerArgs, out *PIDControllerArgs, s conversion.Scope) error {
+	out.EndpointURL = (*string)(unsafe.Pointer(in.EndpointURL))
+	out.MaxIdleConnections = (*int)(unsafe.Pointer(in.MaxIdleConnections))
+	out.IdleConnectionTimeoutSec = (*int)(unsafe.Pointer(in.IdleConnectionTimeoutSec))
+	out.RequestTimeoutSec = (*int)(unsafe.Pointer(in.RequestTimeoutSec))
+	out.Mode = PIDControllerMode(in.Mode)
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_float64_To_Pointer_float64(&in.Kp, &out.Kp, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_float64_To_Pointer_float64(&in.Ki, &out.Ki, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_float64_To_Pointer_float64(&in.Kd, &out.Kd, s); err != nil {
+		return err
+	}
+	out.FailurePolicy = PIDControllerFailurePolicy(in.FailurePolicy)
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.LastKnownGoodMaxAgeSeconds, &out.LastKnownGoodMaxAgeSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.CircuitBreakerFailureThreshold, &out.CircuitBreakerFailureThreshold, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.CircuitBreakerOpenSeconds, &out.CircuitBreakerOpenSeconds, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_PIDControllerArgs_To_v1_PIDControllerArgs is an autogenerated conversion function.
+func Convert_config_PIDControllerArgs_To_v1_PIDControllerArgs(in *config.PIDControllerArgs, out *PIDControllerArgs, s conversion.Scope) error {
+	return autoConvert_config_PIDControllerArgs_To_v1_PIDControllerArgs(in, out, s)
+}
+
 func autoConvert_v1_PreemptionTolerationArgs_To_config_PreemptionTolerationArgs(in *PreemptionTolerationArgs, out *config.PreemptionTolerationArgs, s conversion.Scope) error {
 	if err := metav1.Convert_Pointer_int32_To_int32(&in.MinCandidateNodesPercentage, &out.MinCandidateNodesPercentage, s); err != nil {
 		return err
@@ -510,6 +706,21 @@ func autoConvert_v1_TargetLoadPackingArgs_To_config_TargetLoadPackingArgs(in *Ta
 	if err := metav1.Convert_Pointer_int64_To_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.FallbackGracePeriodSeconds, &out.FallbackGracePeriodSeconds, s); err != nil {
+		return err
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]config.TargetLoadPackingResource, len(*in))
+		for i := range *in {
+			if err := Convert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Resources = nil
+	}
+	out.ScoreMode = config.TargetLoadPackingScoreMode(in.ScoreMode)
 	return nil
 }
 
@@ -529,6 +740,21 @@ func autoConvert_config_TargetLoadPackingArgs_To_v1_TargetLoadPackingArgs(in *co
 	if err := metav1.Convert_int64_To_Pointer_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.FallbackGracePeriodSeconds, &out.FallbackGracePeriodSeconds, s); err != nil {
+		return err
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]TargetLoadPackingResource, len(*in))
+		for i := range *in {
+			if err := Convert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource(&(*in)[i], &(*out)[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.Resources = nil
+	}
+	out.ScoreMode = TargetLoadPackingScoreMode(in.ScoreMode)
 	return nil
 }
 
@@ -537,8 +763,41 @@ func Convert_config_TargetLoadPackingArgs_To_v1_TargetLoadPackingArgs(in *config
 	return autoConvert_config_TargetLoadPackingArgs_To_v1_TargetLoadPackingArgs(in, out, s)
 }
 
+func autoConvert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource(in *TargetLoadPackingResource, out *config.TargetLoadPackingResource, s conversion.Scope) error {
+	out.Name = corev1.ResourceName(in.Name)
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.Weight, &out.Weight, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource is an autogenerated conversion function.
+func Convert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource(in *TargetLoadPackingResource, out *config.TargetLoadPackingResource, s conversion.Scope) error {
+	return autoConvert_v1_TargetLoadPackingResource_To_config_TargetLoadPackingResource(in, out, s)
+}
+
+func autoConvert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource(in *config.TargetLoadPackingResource, out *TargetLoadPackingResource, s conversion.Scope) error {
+	out.Name = corev1.ResourceName(in.Name)
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.TargetUtilization, &out.TargetUtilization, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.Weight, &out.Weight, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource is an autogenerated conversion function.
+func Convert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource(in *config.TargetLoadPackingResource, out *TargetLoadPackingResource, s conversion.Scope) error {
+	return autoConvert_config_TargetLoadPackingResource_To_v1_TargetLoadPackingResource(in, out, s)
+}
+
 func autoConvert_v1_TopologicalSortArgs_To_config_TopologicalSortArgs(in *TopologicalSortArgs, out *config.TopologicalSortArgs, s conversion.Scope) error {
 	out.Namespaces = *(*[]string)(unsafe.Pointer(&in.Namespaces))
+	out.Fairness = config.FairnessType(in.Fairness)
 	return nil
 }
 
@@ -549,6 +808,7 @@ func Convert_v1_TopologicalSortArgs_To_config_TopologicalSortArgs(in *Topologica
 
 func autoConvert_config_TopologicalSortArgs_To_v1_TopologicalSortArgs(in *config.TopologicalSortArgs, out *TopologicalSortArgs, s conversion.Scope) error {
 	out.Namespaces = *(*[]string)(unsafe.Pointer(&in.Namespaces))
+	out.Fairness = FairnessType(in.Fairness)
 	return nil
 }
 
@@ -564,6 +824,12 @@ func autoConvert_v1_TrimaranSpec_To_config_TrimaranSpec(in *TrimaranSpec, out *c
 	if err := metav1.Convert_Pointer_string_To_string(&in.WatcherAddress, &out.WatcherAddress, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MetricsUpdateIntervalSeconds, &out.MetricsUpdateIntervalSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_Pointer_int64_To_int64(&in.MetricsMaxAgeSeconds, &out.MetricsMaxAgeSeconds, s); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -579,6 +845,12 @@ func autoConvert_config_TrimaranSpec_To_v1_TrimaranSpec(in *config.TrimaranSpec,
 	if err := metav1.Convert_string_To_Pointer_string(&in.WatcherAddress, &out.WatcherAddress, s); err != nil {
 		return err
 	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MetricsUpdateIntervalSeconds, &out.MetricsUpdateIntervalSeconds, s); err != nil {
+		return err
+	}
+	if err := metav1.Convert_int64_To_Pointer_int64(&in.MetricsMaxAgeSeconds, &out.MetricsMaxAgeSeconds, s); err != nil {
+		return err
+	}
 	return nil
 }
 
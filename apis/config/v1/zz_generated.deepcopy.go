@@ -27,6 +27,31 @@ import (
 	configv1 "k8s.io/kube-scheduler/config/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacitySchedulingArgs) DeepCopyInto(out *CapacitySchedulingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySchedulingArgs.
+func (in *CapacitySchedulingArgs) DeepCopy() *CapacitySchedulingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacitySchedulingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacitySchedulingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoschedulingArgs) DeepCopyInto(out *CoschedulingArgs) {
 	*out = *in
@@ -62,6 +87,36 @@ func (in *CoschedulingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskIOAwareArgs) DeepCopyInto(out *DiskIOAwareArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.NodeDiskIOInfoNamespace != nil {
+		in, out := &in.NodeDiskIOInfoNamespace, &out.NodeDiskIOInfoNamespace
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskIOAwareArgs.
+func (in *DiskIOAwareArgs) DeepCopy() *DiskIOAwareArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskIOAwareArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DiskIOAwareArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadVariationRiskBalancingArgs) DeepCopyInto(out *LoadVariationRiskBalancingArgs) {
 	*out = *in
@@ -186,6 +241,11 @@ func (in *NetworkOverheadArgs) DeepCopyInto(out *NetworkOverheadArgs) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.TopologyKeys != nil {
+		in, out := &in.TopologyKeys, &out.TopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -207,6 +267,36 @@ func (in *NetworkOverheadArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeResourceLimitsArgs) DeepCopyInto(out *NodeResourceLimitsArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]configv1.ResourceSpec, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeResourceLimitsArgs.
+func (in *NodeResourceLimitsArgs) DeepCopy() *NodeResourceLimitsArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeResourceLimitsArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeResourceLimitsArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeResourceTopologyCache) DeepCopyInto(out *NodeResourceTopologyCache) {
 	*out = *in
@@ -225,6 +315,16 @@ func (in *NodeResourceTopologyCache) DeepCopyInto(out *NodeResourceTopologyCache
 		*out = new(CacheInformerMode)
 		**out = **in
 	}
+	if in.AccountingMode != nil {
+		in, out := &in.AccountingMode, &out.AccountingMode
+		*out = new(CacheAccountingMode)
+		**out = **in
+	}
+	if in.ResyncTrigger != nil {
+		in, out := &in.ResyncTrigger, &out.ResyncTrigger
+		*out = new(CacheResyncTrigger)
+		**out = **in
+	}
 	return
 }
 
@@ -308,6 +408,86 @@ func (in *NodeResourcesAllocatableArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PIDControllerArgs) DeepCopyInto(out *PIDControllerArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.EndpointURL != nil {
+		in, out := &in.EndpointURL, &out.EndpointURL
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxIdleConnections != nil {
+		in, out := &in.MaxIdleConnections, &out.MaxIdleConnections
+		*out = new(int)
+		**out = **in
+	}
+	if in.IdleConnectionTimeoutSec != nil {
+		in, out := &in.IdleConnectionTimeoutSec, &out.IdleConnectionTimeoutSec
+		*out = new(int)
+		**out = **in
+	}
+	if in.RequestTimeoutSec != nil {
+		in, out := &in.RequestTimeoutSec, &out.RequestTimeoutSec
+		*out = new(int)
+		**out = **in
+	}
+	if in.TargetUtilization != nil {
+		in, out := &in.TargetUtilization, &out.TargetUtilization
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Kp != nil {
+		in, out := &in.Kp, &out.Kp
+		*out = new(float64)
+		**out = **in
+	}
+	if in.Ki != nil {
+		in, out := &in.Ki, &out.Ki
+		*out = new(float64)
+		**out = **in
+	}
+	if in.Kd != nil {
+		in, out := &in.Kd, &out.Kd
+		*out = new(float64)
+		**out = **in
+	}
+	if in.LastKnownGoodMaxAgeSeconds != nil {
+		in, out := &in.LastKnownGoodMaxAgeSeconds, &out.LastKnownGoodMaxAgeSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CircuitBreakerFailureThreshold != nil {
+		in, out := &in.CircuitBreakerFailureThreshold, &out.CircuitBreakerFailureThreshold
+		*out = new(int64)
+		**out = **in
+	}
+	if in.CircuitBreakerOpenSeconds != nil {
+		in, out := &in.CircuitBreakerOpenSeconds, &out.CircuitBreakerOpenSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PIDControllerArgs.
+func (in *PIDControllerArgs) DeepCopy() *PIDControllerArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(PIDControllerArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PIDControllerArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreemptionTolerationArgs) DeepCopyInto(out *PreemptionTolerationArgs) {
 	*out = *in
@@ -421,6 +601,18 @@ func (in *TargetLoadPackingArgs) DeepCopyInto(out *TargetLoadPackingArgs) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.FallbackGracePeriodSeconds != nil {
+		in, out := &in.FallbackGracePeriodSeconds, &out.FallbackGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]TargetLoadPackingResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -442,6 +634,32 @@ func (in *TargetLoadPackingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetLoadPackingResource) DeepCopyInto(out *TargetLoadPackingResource) {
+	*out = *in
+	if in.TargetUtilization != nil {
+		in, out := &in.TargetUtilization, &out.TargetUtilization
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int64)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetLoadPackingResource.
+func (in *TargetLoadPackingResource) DeepCopy() *TargetLoadPackingResource {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetLoadPackingResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TopologicalSortArgs) DeepCopyInto(out *TopologicalSortArgs) {
 	*out = *in
@@ -481,6 +699,16 @@ func (in *TrimaranSpec) DeepCopyInto(out *TrimaranSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MetricsUpdateIntervalSeconds != nil {
+		in, out := &in.MetricsUpdateIntervalSeconds, &out.MetricsUpdateIntervalSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MetricsMaxAgeSeconds != nil {
+		in, out := &in.MetricsMaxAgeSeconds, &out.MetricsMaxAgeSeconds
+		*out = new(int64)
+		**out = **in
+	}
 	return
 }
 
@@ -29,32 +29,41 @@ import (
 // Public to allow building arbitrary schemes.
 // All generated defaulters are covering - they call all nested defaulters.
 func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&CapacitySchedulingArgs{}, func(obj interface{}) { SetObjectDefaults_CapacitySchedulingArgs(obj.(*CapacitySchedulingArgs)) })
 	scheme.AddTypeDefaultingFunc(&CoschedulingArgs{}, func(obj interface{}) { SetObjectDefaults_CoschedulingArgs(obj.(*CoschedulingArgs)) })
+	scheme.AddTypeDefaultingFunc(&DiskIOAwareArgs{}, func(obj interface{}) { SetObjectDefaults_DiskIOAwareArgs(obj.(*DiskIOAwareArgs)) })
 	scheme.AddTypeDefaultingFunc(&LoadVariationRiskBalancingArgs{}, func(obj interface{}) {
 		SetObjectDefaults_LoadVariationRiskBalancingArgs(obj.(*LoadVariationRiskBalancingArgs))
 	})
 	scheme.AddTypeDefaultingFunc(&LowRiskOverCommitmentArgs{}, func(obj interface{}) { SetObjectDefaults_LowRiskOverCommitmentArgs(obj.(*LowRiskOverCommitmentArgs)) })
 	scheme.AddTypeDefaultingFunc(&NetworkOverheadArgs{}, func(obj interface{}) { SetObjectDefaults_NetworkOverheadArgs(obj.(*NetworkOverheadArgs)) })
+	scheme.AddTypeDefaultingFunc(&NodeResourceLimitsArgs{}, func(obj interface{}) { SetObjectDefaults_NodeResourceLimitsArgs(obj.(*NodeResourceLimitsArgs)) })
 	scheme.AddTypeDefaultingFunc(&NodeResourceTopologyMatchArgs{}, func(obj interface{}) {
 		SetObjectDefaults_NodeResourceTopologyMatchArgs(obj.(*NodeResourceTopologyMatchArgs))
 	})
 	scheme.AddTypeDefaultingFunc(&NodeResourcesAllocatableArgs{}, func(obj interface{}) {
 		SetObjectDefaults_NodeResourcesAllocatableArgs(obj.(*NodeResourcesAllocatableArgs))
 	})
+	scheme.AddTypeDefaultingFunc(&PIDControllerArgs{}, func(obj interface{}) { SetObjectDefaults_PIDControllerArgs(obj.(*PIDControllerArgs)) })
 	scheme.AddTypeDefaultingFunc(&PreemptionTolerationArgs{}, func(obj interface{}) { SetObjectDefaults_PreemptionTolerationArgs(obj.(*PreemptionTolerationArgs)) })
 	scheme.AddTypeDefaultingFunc(&SySchedArgs{}, func(obj interface{}) { SetObjectDefaults_SySchedArgs(obj.(*SySchedArgs)) })
 	scheme.AddTypeDefaultingFunc(&TargetLoadPackingArgs{}, func(obj interface{}) { SetObjectDefaults_TargetLoadPackingArgs(obj.(*TargetLoadPackingArgs)) })
 	scheme.AddTypeDefaultingFunc(&TopologicalSortArgs{}, func(obj interface{}) { SetObjectDefaults_TopologicalSortArgs(obj.(*TopologicalSortArgs)) })
-	scheme.AddTypeDefaultingFunc(&PIDControllerArgs{}, func(obj interface{}) {
-		SetObjectDefaultPIDControllerArgs(obj.(*PIDControllerArgs))
-	})
 	return nil
 }
 
+func SetObjectDefaults_CapacitySchedulingArgs(in *CapacitySchedulingArgs) {
+	SetDefaults_CapacitySchedulingArgs(in)
+}
+
 func SetObjectDefaults_CoschedulingArgs(in *CoschedulingArgs) {
 	SetDefaults_CoschedulingArgs(in)
 }
 
+func SetObjectDefaults_DiskIOAwareArgs(in *DiskIOAwareArgs) {
+	SetDefaults_DiskIOAwareArgs(in)
+}
+
 func SetObjectDefaults_LoadVariationRiskBalancingArgs(in *LoadVariationRiskBalancingArgs) {
 	SetDefaults_LoadVariationRiskBalancingArgs(in)
 }
@@ -67,6 +76,10 @@ func SetObjectDefaults_NetworkOverheadArgs(in *NetworkOverheadArgs) {
 	SetDefaults_NetworkOverheadArgs(in)
 }
 
+func SetObjectDefaults_NodeResourceLimitsArgs(in *NodeResourceLimitsArgs) {
+	SetDefaults_NodeResourceLimitsArgs(in)
+}
+
 func SetObjectDefaults_NodeResourceTopologyMatchArgs(in *NodeResourceTopologyMatchArgs) {
 	SetDefaults_NodeResourceTopologyMatchArgs(in)
 }
@@ -75,6 +88,10 @@ func SetObjectDefaults_NodeResourcesAllocatableArgs(in *NodeResourcesAllocatable
 	SetDefaults_NodeResourcesAllocatableArgs(in)
 }
 
+func SetObjectDefaults_PIDControllerArgs(in *PIDControllerArgs) {
+	SetDefaults_PIDControllerArgs(in)
+}
+
 func SetObjectDefaults_PreemptionTolerationArgs(in *PreemptionTolerationArgs) {
 	SetDefaults_PreemptionTolerationArgs(in)
 }
@@ -90,7 +107,3 @@ func SetObjectDefaults_TargetLoadPackingArgs(in *TargetLoadPackingArgs) {
 func SetObjectDefaults_TopologicalSortArgs(in *TopologicalSortArgs) {
 	SetDefaults_TopologicalSortArgs(in)
 }
-
-func SetObjectDefaultPIDControllerArgs(in *PIDControllerArgs) {
-	SetDefaultPIDControllerArgs(in)
-}
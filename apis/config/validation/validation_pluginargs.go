@@ -17,8 +17,10 @@ limitations under the License.
 package validation
 
 import (
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
 
 	"sigs.k8s.io/scheduler-plugins/apis/config"
 )
@@ -30,12 +32,28 @@ var validScoringStrategy = sets.NewString(
 	string(config.LeastNUMANodes),
 )
 
+var validCacheResyncTriggers = sets.NewString(
+	string(config.CacheResyncTriggerPeriodic),
+	string(config.CacheResyncTriggerEvents),
+)
+
+var validCacheAccountingModes = sets.NewString(
+	string(config.CacheAccountingPessimistic),
+	string(config.CacheAccountingPerNUMA),
+)
+
 func ValidateNodeResourceTopologyMatchArgs(path *field.Path, args *config.NodeResourceTopologyMatchArgs) error {
 	var allErrs field.ErrorList
 	scoringStrategyTypePath := path.Child("scoringStrategy.type")
 	if err := validateScoringStrategyType(args.ScoringStrategy.Type, scoringStrategyTypePath); err != nil {
 		allErrs = append(allErrs, err)
 	}
+	if args.Cache != nil && args.Cache.ResyncTrigger != nil && !validCacheResyncTriggers.Has(string(*args.Cache.ResyncTrigger)) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("cache", "resyncTrigger"), *args.Cache.ResyncTrigger, validCacheResyncTriggers.List()))
+	}
+	if args.Cache != nil && args.Cache.AccountingMode != nil && !validCacheAccountingModes.Has(string(*args.Cache.AccountingMode)) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("cache", "accountingMode"), *args.Cache.AccountingMode, validCacheAccountingModes.List()))
+	}
 
 	return allErrs.ToAggregate()
 }
@@ -46,3 +64,189 @@ func validateScoringStrategyType(scoringStrategy config.ScoringStrategyType, pat
 	}
 	return nil
 }
+
+var validPIDControllerModes = sets.NewString(
+	string(config.PIDControllerModeExternal),
+	string(config.PIDControllerModeBuiltIn),
+)
+
+var validPIDControllerFailurePolicies = sets.NewString(
+	string(config.PIDControllerFailurePolicyFail),
+	string(config.PIDControllerFailurePolicySkip),
+	string(config.PIDControllerFailurePolicyLastKnownGood),
+)
+
+func ValidatePIDControllerArgs(path *field.Path, args *config.PIDControllerArgs) error {
+	var allErrs field.ErrorList
+	if !validPIDControllerModes.Has(string(args.Mode)) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("mode"), args.Mode, validPIDControllerModes.List()))
+	}
+	if args.Mode == config.PIDControllerModeExternal && (args.EndpointURL == nil || *args.EndpointURL == "") {
+		allErrs = append(allErrs, field.Required(path.Child("endpointURL"), "required in External mode"))
+	}
+	if args.TargetUtilization < 0 || args.TargetUtilization > 100 {
+		allErrs = append(allErrs, field.Invalid(path.Child("targetUtilization"), args.TargetUtilization, "must be in the range [0, 100]"))
+	}
+	for _, gain := range []struct {
+		name  string
+		value float64
+	}{{"kp", args.Kp}, {"ki", args.Ki}, {"kd", args.Kd}} {
+		if gain.value < 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child(gain.name), gain.value, "must not be negative"))
+		}
+	}
+	if !validPIDControllerFailurePolicies.Has(string(args.FailurePolicy)) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("failurePolicy"), args.FailurePolicy, validPIDControllerFailurePolicies.List()))
+	}
+	if args.FailurePolicy == config.PIDControllerFailurePolicyLastKnownGood && args.LastKnownGoodMaxAgeSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("lastKnownGoodMaxAgeSeconds"), args.LastKnownGoodMaxAgeSeconds, "must be greater than 0 with the LastKnownGood failure policy"))
+	}
+	if args.CircuitBreakerFailureThreshold < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("circuitBreakerFailureThreshold"), args.CircuitBreakerFailureThreshold, "must not be negative"))
+	}
+	if args.CircuitBreakerFailureThreshold > 0 && args.CircuitBreakerOpenSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("circuitBreakerOpenSeconds"), args.CircuitBreakerOpenSeconds, "must be greater than 0 when the circuit breaker is enabled"))
+	}
+
+	return allErrs.ToAggregate()
+}
+
+var validTargetLoadPackingResources = sets.NewString(
+	string(v1.ResourceCPU),
+	string(v1.ResourceMemory),
+)
+
+var validTargetLoadPackingScoreModes = sets.NewString(
+	string(config.TargetLoadPackingScoreModeWeightedSum),
+	string(config.TargetLoadPackingScoreModeMin),
+)
+
+func ValidateTargetLoadPackingArgs(path *field.Path, args *config.TargetLoadPackingArgs) error {
+	var allErrs field.ErrorList
+	if len(args.Resources) == 0 {
+		if err := validateTargetUtilization(args.TargetUtilization, path.Child("targetUtilization")); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	seen := sets.NewString()
+	for i, resource := range args.Resources {
+		resourcePath := path.Child("resources").Index(i)
+		if !validTargetLoadPackingResources.Has(string(resource.Name)) {
+			allErrs = append(allErrs, field.NotSupported(resourcePath.Child("name"), resource.Name, validTargetLoadPackingResources.List()))
+		} else if seen.Has(string(resource.Name)) {
+			allErrs = append(allErrs, field.Duplicate(resourcePath.Child("name"), resource.Name))
+		}
+		seen.Insert(string(resource.Name))
+		if err := validateTargetUtilization(resource.TargetUtilization, resourcePath.Child("targetUtilization")); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		if resource.Weight <= 0 {
+			allErrs = append(allErrs, field.Invalid(resourcePath.Child("weight"), resource.Weight, "must be greater than 0"))
+		}
+	}
+	if !validTargetLoadPackingScoreModes.Has(string(args.ScoreMode)) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("scoreMode"), args.ScoreMode, validTargetLoadPackingScoreModes.List()))
+	}
+
+	return allErrs.ToAggregate()
+}
+
+func validateTargetUtilization(targetUtilization int64, path *field.Path) *field.Error {
+	if targetUtilization <= 0 || targetUtilization >= 100 {
+		return field.Invalid(path, targetUtilization, "must be in the range (0, 100)")
+	}
+	return nil
+}
+
+var validPreemptionModes = sets.NewString(
+	string(config.PreemptionModeNone),
+	string(config.PreemptionModeGang),
+)
+
+func ValidateCoschedulingArgs(path *field.Path, args *config.CoschedulingArgs) error {
+	if !validPreemptionModes.Has(string(args.PreemptionMode)) {
+		return field.NotSupported(path.Child("preemptionMode"), args.PreemptionMode, validPreemptionModes.List())
+	}
+	return nil
+}
+
+var validBorrowingPolicies = sets.NewString(
+	string(config.BorrowingPolicyFirstComeFirstServed),
+	string(config.BorrowingPolicyFairSharing),
+)
+
+func ValidateCapacitySchedulingArgs(path *field.Path, args *config.CapacitySchedulingArgs) error {
+	if !validBorrowingPolicies.Has(string(args.BorrowingPolicy)) {
+		return field.NotSupported(path.Child("borrowingPolicy"), args.BorrowingPolicy, validBorrowingPolicies.List())
+	}
+	return nil
+}
+
+var validFairnessTypes = sets.NewString(
+	string(config.FairnessAge),
+	string(config.FairnessRoundRobin),
+)
+
+func ValidateTopologicalSortArgs(path *field.Path, args *config.TopologicalSortArgs) error {
+	if !validFairnessTypes.Has(string(args.Fairness)) {
+		return field.NotSupported(path.Child("fairness"), args.Fairness, validFairnessTypes.List())
+	}
+	return nil
+}
+
+func ValidateNetworkOverheadArgs(path *field.Path, args *config.NetworkOverheadArgs) error {
+	var allErrs field.ErrorList
+	topologyKeysPath := path.Child("topologyKeys")
+	seen := sets.NewString()
+	for i, key := range args.TopologyKeys {
+		if key == "" {
+			allErrs = append(allErrs, field.Required(topologyKeysPath.Index(i), "node label of the topology level"))
+		} else if seen.Has(key) {
+			allErrs = append(allErrs, field.Duplicate(topologyKeysPath.Index(i), key))
+		}
+		seen.Insert(key)
+	}
+
+	return allErrs.ToAggregate()
+}
+
+var validDiskIOScoringStrategies = sets.NewString(
+	string(config.MostAllocated),
+	string(config.LeastAllocated),
+)
+
+func ValidateDiskIOAwareArgs(path *field.Path, args *config.DiskIOAwareArgs) error {
+	var allErrs field.ErrorList
+	if !validDiskIOScoringStrategies.Has(string(args.ScoringStrategy)) {
+		allErrs = append(allErrs, field.NotSupported(path.Child("scoringStrategy"), args.ScoringStrategy, validDiskIOScoringStrategies.List()))
+	}
+	if args.NodeDiskIOInfoNamespace == "" {
+		allErrs = append(allErrs, field.Required(path.Child("nodeDiskIOInfoNamespace"), "namespace of the NodeDiskIOInfo objects"))
+	}
+
+	return allErrs.ToAggregate()
+}
+
+func ValidateNodeResourceLimitsArgs(path *field.Path, args *config.NodeResourceLimitsArgs) error {
+	var allErrs field.ErrorList
+	resourcesPath := path.Child("resources")
+	if len(args.Resources) == 0 {
+		allErrs = append(allErrs, field.Required(resourcesPath, "at least one resource is required"))
+	}
+	seen := sets.NewString()
+	for i, resource := range args.Resources {
+		resourcePath := resourcesPath.Index(i)
+		name := v1.ResourceName(resource.Name)
+		if name != v1.ResourceCPU && name != v1.ResourceMemory && name != v1.ResourceEphemeralStorage && !schedutil.IsScalarResourceName(name) {
+			allErrs = append(allErrs, field.Invalid(resourcePath.Child("name"), resource.Name, "resource is not cpu, memory, ephemeral-storage or a scalar resource"))
+		} else if seen.Has(resource.Name) {
+			allErrs = append(allErrs, field.Duplicate(resourcePath.Child("name"), resource.Name))
+		}
+		seen.Insert(resource.Name)
+		if resource.Weight <= 0 {
+			allErrs = append(allErrs, field.Invalid(resourcePath.Child("weight"), resource.Weight, "weight must be greater than 0"))
+		}
+	}
+
+	return allErrs.ToAggregate()
+}
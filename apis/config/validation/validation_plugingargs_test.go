@@ -21,10 +21,17 @@ import (
 	"strings"
 	"testing"
 
+	v1 "k8s.io/api/core/v1"
+	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+
 	"sigs.k8s.io/scheduler-plugins/apis/config"
 )
 
 func TestValidateNodeResourceTopologyMatchArgs(t *testing.T) {
+	eventsTrigger := config.CacheResyncTriggerEvents
+	unknownTrigger := config.CacheResyncTrigger("OnDemand")
+	perNUMAAccounting := config.CacheAccountingPerNUMA
+	unknownAccounting := config.CacheAccountingMode("Optimistic")
 	testCases := []struct {
 		args        *config.NodeResourceTopologyMatchArgs
 		expectedErr error
@@ -47,6 +54,52 @@ func TestValidateNodeResourceTopologyMatchArgs(t *testing.T) {
 			},
 			expectedErr: fmt.Errorf("scoringStrategy.type: Invalid value:"),
 		},
+		{
+			description: "correct config, events resync trigger",
+			args: &config.NodeResourceTopologyMatchArgs{
+				ScoringStrategy: config.ScoringStrategy{
+					Type: config.MostAllocated,
+				},
+				Cache: &config.NodeResourceTopologyCache{
+					ResyncTrigger: &eventsTrigger,
+				},
+			},
+		},
+		{
+			description: "incorrect config, wrong resync trigger",
+			args: &config.NodeResourceTopologyMatchArgs{
+				ScoringStrategy: config.ScoringStrategy{
+					Type: config.MostAllocated,
+				},
+				Cache: &config.NodeResourceTopologyCache{
+					ResyncTrigger: &unknownTrigger,
+				},
+			},
+			expectedErr: fmt.Errorf("cache.resyncTrigger: Unsupported value:"),
+		},
+		{
+			description: "correct config, per NUMA accounting",
+			args: &config.NodeResourceTopologyMatchArgs{
+				ScoringStrategy: config.ScoringStrategy{
+					Type: config.MostAllocated,
+				},
+				Cache: &config.NodeResourceTopologyCache{
+					AccountingMode: &perNUMAAccounting,
+				},
+			},
+		},
+		{
+			description: "incorrect config, wrong accounting mode",
+			args: &config.NodeResourceTopologyMatchArgs{
+				ScoringStrategy: config.ScoringStrategy{
+					Type: config.MostAllocated,
+				},
+				Cache: &config.NodeResourceTopologyCache{
+					AccountingMode: &unknownAccounting,
+				},
+			},
+			expectedErr: fmt.Errorf("cache.accountingMode: Unsupported value:"),
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -67,3 +120,502 @@ func TestValidateNodeResourceTopologyMatchArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatePIDControllerArgs(t *testing.T) {
+	endpointURL := "http://localhost:5100/score"
+	emptyURL := ""
+	testCases := []struct {
+		args        *config.PIDControllerArgs
+		expectedErr error
+		description string
+	}{
+		{
+			description: "correct external config",
+			args: &config.PIDControllerArgs{
+				EndpointURL:       &endpointURL,
+				Mode:              config.PIDControllerModeExternal,
+				TargetUtilization: 60,
+				Kp:                1,
+				FailurePolicy:     config.PIDControllerFailurePolicySkip,
+			},
+		},
+		{
+			description: "correct built-in config without endpoint",
+			args: &config.PIDControllerArgs{
+				Mode:              config.PIDControllerModeBuiltIn,
+				TargetUtilization: 60,
+				Kp:                1,
+				Ki:                0.1,
+				FailurePolicy:     config.PIDControllerFailurePolicyFail,
+			},
+		},
+		{
+			description: "incorrect config, unknown mode",
+			args: &config.PIDControllerArgs{
+				EndpointURL: &endpointURL,
+				Mode:        "Remote",
+			},
+			expectedErr: fmt.Errorf("mode: Unsupported value:"),
+		},
+		{
+			description: "incorrect config, empty endpoint in external mode",
+			args: &config.PIDControllerArgs{
+				EndpointURL: &emptyURL,
+				Mode:        config.PIDControllerModeExternal,
+			},
+			expectedErr: fmt.Errorf("endpointURL: Required value"),
+		},
+		{
+			description: "incorrect config, target utilization out of range",
+			args: &config.PIDControllerArgs{
+				Mode:              config.PIDControllerModeBuiltIn,
+				TargetUtilization: 120,
+			},
+			expectedErr: fmt.Errorf("targetUtilization: Invalid value:"),
+		},
+		{
+			description: "incorrect config, negative gain",
+			args: &config.PIDControllerArgs{
+				Mode: config.PIDControllerModeBuiltIn,
+				Ki:   -0.5,
+			},
+			expectedErr: fmt.Errorf("ki: Invalid value:"),
+		},
+		{
+			description: "incorrect config, unknown failure policy",
+			args: &config.PIDControllerArgs{
+				Mode:          config.PIDControllerModeBuiltIn,
+				FailurePolicy: "Retry",
+			},
+			expectedErr: fmt.Errorf("failurePolicy: Unsupported value:"),
+		},
+		{
+			description: "incorrect config, LastKnownGood without max age",
+			args: &config.PIDControllerArgs{
+				EndpointURL:   &endpointURL,
+				Mode:          config.PIDControllerModeExternal,
+				FailurePolicy: config.PIDControllerFailurePolicyLastKnownGood,
+			},
+			expectedErr: fmt.Errorf("lastKnownGoodMaxAgeSeconds: Invalid value:"),
+		},
+		{
+			description: "incorrect config, circuit breaker enabled without open duration",
+			args: &config.PIDControllerArgs{
+				EndpointURL:                    &endpointURL,
+				Mode:                           config.PIDControllerModeExternal,
+				FailurePolicy:                  config.PIDControllerFailurePolicySkip,
+				CircuitBreakerFailureThreshold: 3,
+			},
+			expectedErr: fmt.Errorf("circuitBreakerOpenSeconds: Invalid value:"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := ValidatePIDControllerArgs(nil, testCase.args)
+			if testCase.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected err to equal %v not nil", testCase.expectedErr)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedErr.Error()) {
+					t.Errorf("expected err to contain %s in error message: %s", testCase.expectedErr.Error(), err.Error())
+				}
+			}
+			if testCase.expectedErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateTargetLoadPackingArgs(t *testing.T) {
+	testCases := []struct {
+		args        *config.TargetLoadPackingArgs
+		expectedErr error
+		description string
+	}{
+		{
+			description: "correct config, cpu only",
+			args: &config.TargetLoadPackingArgs{
+				TargetUtilization: 40,
+				ScoreMode:         config.TargetLoadPackingScoreModeWeightedSum,
+			},
+		},
+		{
+			description: "correct config, cpu and memory",
+			args: &config.TargetLoadPackingArgs{
+				Resources: []config.TargetLoadPackingResource{
+					{Name: v1.ResourceCPU, TargetUtilization: 60, Weight: 1},
+					{Name: v1.ResourceMemory, TargetUtilization: 70, Weight: 2},
+				},
+				ScoreMode: config.TargetLoadPackingScoreModeMin,
+			},
+		},
+		{
+			description: "incorrect config, target utilization out of range",
+			args: &config.TargetLoadPackingArgs{
+				TargetUtilization: 100,
+				ScoreMode:         config.TargetLoadPackingScoreModeWeightedSum,
+			},
+			expectedErr: fmt.Errorf("targetUtilization: Invalid value:"),
+		},
+		{
+			description: "incorrect config, unsupported resource",
+			args: &config.TargetLoadPackingArgs{
+				Resources: []config.TargetLoadPackingResource{
+					{Name: v1.ResourceEphemeralStorage, TargetUtilization: 60, Weight: 1},
+				},
+				ScoreMode: config.TargetLoadPackingScoreModeWeightedSum,
+			},
+			expectedErr: fmt.Errorf("resources[0].name: Unsupported value:"),
+		},
+		{
+			description: "incorrect config, duplicate resource",
+			args: &config.TargetLoadPackingArgs{
+				Resources: []config.TargetLoadPackingResource{
+					{Name: v1.ResourceMemory, TargetUtilization: 60, Weight: 1},
+					{Name: v1.ResourceMemory, TargetUtilization: 70, Weight: 1},
+				},
+				ScoreMode: config.TargetLoadPackingScoreModeWeightedSum,
+			},
+			expectedErr: fmt.Errorf("resources[1].name: Duplicate value:"),
+		},
+		{
+			description: "incorrect config, zero weight",
+			args: &config.TargetLoadPackingArgs{
+				Resources: []config.TargetLoadPackingResource{
+					{Name: v1.ResourceCPU, TargetUtilization: 60},
+				},
+				ScoreMode: config.TargetLoadPackingScoreModeWeightedSum,
+			},
+			expectedErr: fmt.Errorf("resources[0].weight: Invalid value:"),
+		},
+		{
+			description: "incorrect config, unknown score mode",
+			args: &config.TargetLoadPackingArgs{
+				TargetUtilization: 40,
+				ScoreMode:         "Max",
+			},
+			expectedErr: fmt.Errorf("scoreMode: Unsupported value:"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := ValidateTargetLoadPackingArgs(nil, testCase.args)
+			if testCase.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected err to equal %v not nil", testCase.expectedErr)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedErr.Error()) {
+					t.Errorf("expected err to contain %s in error message: %s", testCase.expectedErr.Error(), err.Error())
+				}
+			}
+			if testCase.expectedErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateCoschedulingArgs(t *testing.T) {
+	testCases := []struct {
+		args        *config.CoschedulingArgs
+		expectedErr error
+		description string
+	}{
+		{
+			description: "correct config, no preemption",
+			args: &config.CoschedulingArgs{
+				PermitWaitingTimeSeconds: 60,
+				PreemptionMode:           config.PreemptionModeNone,
+			},
+		},
+		{
+			description: "correct config, gang preemption",
+			args: &config.CoschedulingArgs{
+				PermitWaitingTimeSeconds: 60,
+				PreemptionMode:           config.PreemptionModeGang,
+			},
+		},
+		{
+			description: "incorrect config, unsupported preemption mode",
+			args: &config.CoschedulingArgs{
+				PermitWaitingTimeSeconds: 60,
+				PreemptionMode:           "Partial",
+			},
+			expectedErr: fmt.Errorf("preemptionMode: Unsupported value: \"Partial\""),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := ValidateCoschedulingArgs(nil, testCase.args)
+			if testCase.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected err to equal %v not nil", testCase.expectedErr)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedErr.Error()) {
+					t.Errorf("expected err to contain %s in error message: %s", testCase.expectedErr.Error(), err.Error())
+				}
+			}
+			if testCase.expectedErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateCapacitySchedulingArgs(t *testing.T) {
+	testCases := []struct {
+		args        *config.CapacitySchedulingArgs
+		expectedErr error
+		description string
+	}{
+		{
+			description: "correct config, first come first served",
+			args: &config.CapacitySchedulingArgs{
+				BorrowingPolicy: config.BorrowingPolicyFirstComeFirstServed,
+			},
+		},
+		{
+			description: "correct config, fair sharing",
+			args: &config.CapacitySchedulingArgs{
+				BorrowingPolicy: config.BorrowingPolicyFairSharing,
+			},
+		},
+		{
+			description: "incorrect config, unsupported borrowing policy",
+			args: &config.CapacitySchedulingArgs{
+				BorrowingPolicy: "RoundRobin",
+			},
+			expectedErr: fmt.Errorf("borrowingPolicy: Unsupported value: \"RoundRobin\""),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := ValidateCapacitySchedulingArgs(nil, testCase.args)
+			if testCase.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected err to equal %v not nil", testCase.expectedErr)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedErr.Error()) {
+					t.Errorf("expected err to contain %s in error message: %s", testCase.expectedErr.Error(), err.Error())
+				}
+			}
+			if testCase.expectedErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateTopologicalSortArgs(t *testing.T) {
+	testCases := []struct {
+		args        *config.TopologicalSortArgs
+		expectedErr error
+		description string
+	}{
+		{
+			description: "correct config, age",
+			args: &config.TopologicalSortArgs{
+				Fairness: config.FairnessAge,
+			},
+		},
+		{
+			description: "correct config, round robin",
+			args: &config.TopologicalSortArgs{
+				Fairness: config.FairnessRoundRobin,
+			},
+		},
+		{
+			description: "incorrect config, unsupported fairness",
+			args: &config.TopologicalSortArgs{
+				Fairness: "FairSharing",
+			},
+			expectedErr: fmt.Errorf("fairness: Unsupported value: \"FairSharing\""),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := ValidateTopologicalSortArgs(nil, testCase.args)
+			if testCase.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected err to equal %v not nil", testCase.expectedErr)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedErr.Error()) {
+					t.Errorf("expected err to contain %s in error message: %s", testCase.expectedErr.Error(), err.Error())
+				}
+			}
+			if testCase.expectedErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateNetworkOverheadArgs(t *testing.T) {
+	testCases := []struct {
+		args        *config.NetworkOverheadArgs
+		expectedErr error
+		description string
+	}{
+		{
+			description: "correct config",
+			args: &config.NetworkOverheadArgs{
+				TopologyKeys: []string{"topology.kubernetes.io/zone", "topology.kubernetes.io/region"},
+			},
+		},
+		{
+			description: "incorrect config, empty topology key",
+			args: &config.NetworkOverheadArgs{
+				TopologyKeys: []string{"topology.kubernetes.io/zone", ""},
+			},
+			expectedErr: fmt.Errorf("topologyKeys[1]: Required value"),
+		},
+		{
+			description: "incorrect config, duplicate topology key",
+			args: &config.NetworkOverheadArgs{
+				TopologyKeys: []string{"topology.kubernetes.io/zone", "topology.kubernetes.io/zone"},
+			},
+			expectedErr: fmt.Errorf("topologyKeys[1]: Duplicate value:"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := ValidateNetworkOverheadArgs(nil, testCase.args)
+			if testCase.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected err to equal %v not nil", testCase.expectedErr)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedErr.Error()) {
+					t.Errorf("expected err to contain %s in error message: %s", testCase.expectedErr.Error(), err.Error())
+				}
+			}
+			if testCase.expectedErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateDiskIOAwareArgs(t *testing.T) {
+	testCases := []struct {
+		args        *config.DiskIOAwareArgs
+		expectedErr error
+		description string
+	}{
+		{
+			description: "correct config",
+			args: &config.DiskIOAwareArgs{
+				ScoringStrategy:         config.LeastAllocated,
+				NodeDiskIOInfoNamespace: "kube-system",
+			},
+		},
+		{
+			description: "incorrect config, unsupported scoring strategy",
+			args: &config.DiskIOAwareArgs{
+				ScoringStrategy:         config.BalancedAllocation,
+				NodeDiskIOInfoNamespace: "kube-system",
+			},
+			expectedErr: fmt.Errorf("scoringStrategy: Unsupported value:"),
+		},
+		{
+			description: "incorrect config, empty namespace",
+			args: &config.DiskIOAwareArgs{
+				ScoringStrategy: config.MostAllocated,
+			},
+			expectedErr: fmt.Errorf("nodeDiskIOInfoNamespace: Required value"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := ValidateDiskIOAwareArgs(nil, testCase.args)
+			if testCase.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected err to equal %v not nil", testCase.expectedErr)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedErr.Error()) {
+					t.Errorf("expected err to contain %s in error message: %s", testCase.expectedErr.Error(), err.Error())
+				}
+			}
+			if testCase.expectedErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateNodeResourceLimitsArgs(t *testing.T) {
+	testCases := []struct {
+		args        *config.NodeResourceLimitsArgs
+		expectedErr error
+		description string
+	}{
+		{
+			description: "correct config",
+			args: &config.NodeResourceLimitsArgs{
+				Resources: []schedconfig.ResourceSpec{
+					{Name: "cpu", Weight: 1},
+					{Name: "memory", Weight: 2},
+					{Name: "nvidia.com/gpu", Weight: 1},
+				},
+			},
+		},
+		{
+			description: "incorrect config, no resources",
+			args:        &config.NodeResourceLimitsArgs{},
+			expectedErr: fmt.Errorf("resources: Required value"),
+		},
+		{
+			description: "incorrect config, unsupported resource",
+			args: &config.NodeResourceLimitsArgs{
+				Resources: []schedconfig.ResourceSpec{{Name: "pods", Weight: 1}},
+			},
+			expectedErr: fmt.Errorf("resources[0].name: Invalid value:"),
+		},
+		{
+			description: "incorrect config, duplicate resource",
+			args: &config.NodeResourceLimitsArgs{
+				Resources: []schedconfig.ResourceSpec{{Name: "cpu", Weight: 1}, {Name: "cpu", Weight: 2}},
+			},
+			expectedErr: fmt.Errorf("resources[1].name: Duplicate value:"),
+		},
+		{
+			description: "incorrect config, zero weight",
+			args: &config.NodeResourceLimitsArgs{
+				Resources: []schedconfig.ResourceSpec{{Name: "memory", Weight: 0}},
+			},
+			expectedErr: fmt.Errorf("resources[0].weight: Invalid value:"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := ValidateNodeResourceLimitsArgs(nil, testCase.args)
+			if testCase.expectedErr != nil {
+				if err == nil {
+					t.Fatalf("expected err to equal %v not nil", testCase.expectedErr)
+				}
+
+				if !strings.Contains(err.Error(), testCase.expectedErr.Error()) {
+					t.Errorf("expected err to contain %s in error message: %s", testCase.expectedErr.Error(), err.Error())
+				}
+			}
+			if testCase.expectedErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
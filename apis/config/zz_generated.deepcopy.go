@@ -27,6 +27,31 @@ import (
 	apisconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacitySchedulingArgs) DeepCopyInto(out *CapacitySchedulingArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacitySchedulingArgs.
+func (in *CapacitySchedulingArgs) DeepCopy() *CapacitySchedulingArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacitySchedulingArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacitySchedulingArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CoschedulingArgs) DeepCopyInto(out *CoschedulingArgs) {
 	*out = *in
@@ -52,6 +77,31 @@ func (in *CoschedulingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskIOAwareArgs) DeepCopyInto(out *DiskIOAwareArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskIOAwareArgs.
+func (in *DiskIOAwareArgs) DeepCopy() *DiskIOAwareArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskIOAwareArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DiskIOAwareArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadVariationRiskBalancingArgs) DeepCopyInto(out *LoadVariationRiskBalancingArgs) {
 	*out = *in
@@ -136,6 +186,11 @@ func (in *NetworkOverheadArgs) DeepCopyInto(out *NetworkOverheadArgs) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.TopologyKeys != nil {
+		in, out := &in.TopologyKeys, &out.TopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -157,6 +212,36 @@ func (in *NetworkOverheadArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeResourceLimitsArgs) DeepCopyInto(out *NodeResourceLimitsArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]apisconfig.ResourceSpec, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeResourceLimitsArgs.
+func (in *NodeResourceLimitsArgs) DeepCopy() *NodeResourceLimitsArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeResourceLimitsArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeResourceLimitsArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeResourceTopologyCache) DeepCopyInto(out *NodeResourceTopologyCache) {
 	*out = *in
@@ -175,6 +260,16 @@ func (in *NodeResourceTopologyCache) DeepCopyInto(out *NodeResourceTopologyCache
 		*out = new(CacheInformerMode)
 		**out = **in
 	}
+	if in.AccountingMode != nil {
+		in, out := &in.AccountingMode, &out.AccountingMode
+		*out = new(CacheAccountingMode)
+		**out = **in
+	}
+	if in.ResyncTrigger != nil {
+		in, out := &in.ResyncTrigger, &out.ResyncTrigger
+		*out = new(CacheResyncTrigger)
+		**out = **in
+	}
 	return
 }
 
@@ -249,6 +344,51 @@ func (in *NodeResourcesAllocatableArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PIDControllerArgs) DeepCopyInto(out *PIDControllerArgs) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	if in.EndpointURL != nil {
+		in, out := &in.EndpointURL, &out.EndpointURL
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxIdleConnections != nil {
+		in, out := &in.MaxIdleConnections, &out.MaxIdleConnections
+		*out = new(int)
+		**out = **in
+	}
+	if in.IdleConnectionTimeoutSec != nil {
+		in, out := &in.IdleConnectionTimeoutSec, &out.IdleConnectionTimeoutSec
+		*out = new(int)
+		**out = **in
+	}
+	if in.RequestTimeoutSec != nil {
+		in, out := &in.RequestTimeoutSec, &out.RequestTimeoutSec
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PIDControllerArgs.
+func (in *PIDControllerArgs) DeepCopy() *PIDControllerArgs {
+	if in == nil {
+		return nil
+	}
+	out := new(PIDControllerArgs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PIDControllerArgs) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PreemptionTolerationArgs) DeepCopyInto(out *PreemptionTolerationArgs) {
 	*out = *in
@@ -332,6 +472,11 @@ func (in *TargetLoadPackingArgs) DeepCopyInto(out *TargetLoadPackingArgs) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]TargetLoadPackingResource, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -353,6 +498,22 @@ func (in *TargetLoadPackingArgs) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetLoadPackingResource) DeepCopyInto(out *TargetLoadPackingResource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetLoadPackingResource.
+func (in *TargetLoadPackingResource) DeepCopy() *TargetLoadPackingResource {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetLoadPackingResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TopologicalSortArgs) DeepCopyInto(out *TopologicalSortArgs) {
 	*out = *in
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeDiskIOInfo describes the disk IO bandwidth of the devices of a node.
+// The spec is written by the scheduler, the status by the node disk IO driver.
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName={ndio}
+// +kubebuilder:subresource:status
+// +kubebuilder:metadata:annotations="api-approved.kubernetes.io=unapproved, experimental-only"
+type NodeDiskIOInfo struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// NodeDiskIOInfoSpec holds the pods the scheduler reserved disk IO bandwidth for.
+	// +optional
+	Spec NodeDiskIOInfoSpec `json:"spec,omitempty"`
+
+	// NodeDiskIOInfoStatus holds the disk IO bandwidth reported by the driver.
+	// +optional
+	Status NodeDiskIOInfoStatus `json:"status,omitempty"`
+}
+
+// NodeDiskIOInfoSpec holds the pods the scheduler reserved disk IO bandwidth for.
+type NodeDiskIOInfoSpec struct {
+	// NodeName is the name of the node the devices belong to.
+	NodeName string `json:"nodeName"`
+
+	// ReservedPods are the UIDs of the pods bound to the node with a disk IO request,
+	// whose bandwidth is not yet accounted in the status.
+	// +optional
+	ReservedPods []string `json:"reservedPods,omitempty"`
+}
+
+// NodeDiskIOInfoStatus holds the disk IO bandwidth reported by the driver.
+type NodeDiskIOInfoStatus struct {
+	// ObservedGeneration is the generation of the spec the driver accounted
+	// the reserved pods of.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// AllocatableBandwidth is the bandwidth left for new pods on each device, keyed by device ID.
+	// +optional
+	AllocatableBandwidth map[string]DeviceAllocatableBandwidth `json:"allocatableBandwidth,omitempty"`
+}
+
+// DeviceAllocatableBandwidth is the bandwidth left for new pods on a device.
+type DeviceAllocatableBandwidth struct {
+	// Name is the name of the device, e.g. /dev/sda.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Model is the device model, used to select the IO calculation model normalizing
+	// the pod requests to the device.
+	// +optional
+	Model string `json:"model,omitempty"`
+
+	// BlockIOStatus is the allocatable read, write and total bandwidth of the device.
+	BlockIOStatus `json:",inline"`
+}
+
+// BlockIOStatus is a bandwidth in bytes per second.
+type BlockIOStatus struct {
+	// Total is the combined read and write bandwidth.
+	// +optional
+	Total resource.Quantity `json:"total,omitempty"`
+
+	// Read is the read bandwidth, not limited if not set.
+	// +optional
+	Read *resource.Quantity `json:"read,omitempty"`
+
+	// Write is the write bandwidth, not limited if not set.
+	// +optional
+	Write *resource.Quantity `json:"write,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeDiskIOInfoList is a list of NodeDiskIOInfo items.
+type NodeDiskIOInfoList struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Standard list metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	// Items is a list of NodeDiskIOInfo objects.
+	Items []NodeDiskIOInfo `json:"items"`
+}
@@ -28,6 +28,10 @@ import (
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:shortName={eq,eqs}
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Over Min",type=string,JSONPath=`.status.conditions[?(@.type=="OverMin")].status`
+// +kubebuilder:printcolumn:name="At Max",type=string,JSONPath=`.status.conditions[?(@.type=="AtMax")].status`
+// +kubebuilder:printcolumn:name="Invalid",type=string,JSONPath=`.status.conditions[?(@.type=="Invalid")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +kubebuilder:metadata:annotations="api-approved.kubernetes.io=https://github.com/kubernetes-sigs/scheduler-plugins/pull/52"
 type ElasticQuota struct {
 	metav1.TypeMeta `json:",inline"`
@@ -55,15 +59,97 @@ type ElasticQuotaSpec struct {
 	// successfully scheduled pods.
 	// +optional
 	Max v1.ResourceList `json:"max,omitempty" protobuf:"bytes,2,rep,name=max, casttype=ResourceList,castkey=ResourceName"`
+
+	// Parent is the namespace of the ElasticQuota this quota is a part of. The Min and Max of the parent
+	// apply to the usage of its whole subtree, and the unused Min of a subtree is lent to the quotas
+	// of the subtree before the quotas outside of it. A quota without a parent is a top-level quota.
+	// +optional
+	Parent string `json:"parent,omitempty" protobuf:"bytes,3,opt,name=parent"`
+
+	// NamespaceSelector selects the namespaces, besides its own namespace, the quota applies to.
+	// A namespace must not be covered by more than one ElasticQuota: the pods of a namespace matched
+	// by several quotas are not accounted for, and are not schedulable until the conflict is resolved.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty" protobuf:"bytes,4,opt,name=namespaceSelector"`
+
+	// Weight is the share of the quota in the resources lent by the quotas under their Min, relative
+	// to the weights of its siblings, when the scheduler splits them fairly. Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Weight int32 `json:"weight,omitempty" protobuf:"varint,5,opt,name=weight"`
 }
 
 // ElasticQuotaStatus defines the observed use.
 type ElasticQuotaStatus struct {
-	// Used is the current observed total usage of the resource in the namespace.
+	// Used is the current observed total usage of the resource in the namespace,
+	// and in the namespaces of the descendant quotas if any.
 	// +optional
 	Used v1.ResourceList `json:"used,omitempty" protobuf:"bytes,1,rep,name=used,casttype=ResourceList,castkey=ResourceName"`
+
+	// FairShare is the share of the resources the quota is entitled to when they are split fairly:
+	// its Min, plus its weighted part of the resources lent by the quotas under their Min.
+	// It is only reported when fair sharing is enabled.
+	// +optional
+	FairShare v1.ResourceList `json:"fairShare,omitempty" protobuf:"bytes,3,rep,name=fairShare,casttype=ResourceList,castkey=ResourceName"`
+
+	// Borrowed is the part of Used above Min, i.e. the resources borrowed from other quotas.
+	// +optional
+	Borrowed v1.ResourceList `json:"borrowed,omitempty" protobuf:"bytes,4,rep,name=borrowed,casttype=ResourceList,castkey=ResourceName"`
+
+	// Lent is the part of the unused Min that is borrowed by the sibling quotas, i.e. the other
+	// quotas with the same parent, or the other top-level quotas.
+	// +optional
+	Lent v1.ResourceList `json:"lent,omitempty" protobuf:"bytes,5,rep,name=lent,casttype=ResourceList,castkey=ResourceName"`
+
+	// PendingRequests is the total request of the pods of the quota that are not scheduled yet
+	// because they would take the quota over its Max.
+	// +optional
+	PendingRequests v1.ResourceList `json:"pendingRequests,omitempty" protobuf:"bytes,6,rep,name=pendingRequests,casttype=ResourceList,castkey=ResourceName"`
+
+	// Conditions represent the latest available observations of the quota.
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,2,rep,name=conditions"`
 }
 
+// These are the condition types of an ElasticQuota.
+const (
+	// ElasticQuotaNamespaceConflict means some namespaces matched by the namespace selector of the
+	// quota are also covered by other quotas, so their pods are not accounted for.
+	ElasticQuotaNamespaceConflict = "NamespaceConflict"
+	// ElasticQuotaOverMin means the quota uses more than its Min of some resource, i.e. it borrows resources.
+	ElasticQuotaOverMin = "OverMin"
+	// ElasticQuotaAtMax means the quota uses all of its Max of some resource, so its pending pods that
+	// request that resource cannot be scheduled.
+	ElasticQuotaAtMax = "AtMax"
+	// ElasticQuotaInvalid means the spec of the quota is invalid, e.g. its Min of some resource is
+	// greater than its Max.
+	ElasticQuotaInvalid = "Invalid"
+)
+
+// These are the reasons of the conditions of an ElasticQuota.
+const (
+	// ElasticQuotaReasonMatchedByMultipleQuotas is set when a namespace is covered by more than one quota.
+	ElasticQuotaReasonMatchedByMultipleQuotas = "MatchedByMultipleQuotas"
+	// ElasticQuotaReasonNoConflict is set once every namespace of the quota is covered by this quota only.
+	ElasticQuotaReasonNoConflict = "NoConflict"
+	// ElasticQuotaReasonBorrowing is set when the quota uses more than its Min of some resource.
+	ElasticQuotaReasonBorrowing = "Borrowing"
+	// ElasticQuotaReasonWithinMin is set when the quota uses at most its Min of every resource.
+	ElasticQuotaReasonWithinMin = "WithinMin"
+	// ElasticQuotaReasonMaxReached is set when the quota uses all of its Max of some resource.
+	ElasticQuotaReasonMaxReached = "MaxReached"
+	// ElasticQuotaReasonBelowMax is set when the quota uses less than its Max of every resource.
+	ElasticQuotaReasonBelowMax = "BelowMax"
+	// ElasticQuotaReasonMinGreaterThanMax is set when the Min of some resource is greater than its Max.
+	ElasticQuotaReasonMinGreaterThanMax = "MinGreaterThanMax"
+	// ElasticQuotaReasonValid is set when the spec of the quota is valid.
+	ElasticQuotaReasonValid = "Valid"
+)
+
 // +kubebuilder:object:root=true
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -109,6 +195,41 @@ const (
 	PodGroupLabel = scheduling.GroupName + "/pod-group"
 )
 
+// These are the condition types of podGroups.
+const (
+	// PodGroupScheduled means at least `spec.minMember` pods of the pod group are bound to nodes.
+	// While it is false, the reason and message tell why the scheduler cannot place the pod group.
+	PodGroupScheduled = "Scheduled"
+
+	// PodGroupReady means at least `spec.minMember` pods of the pod group are running or succeeded.
+	PodGroupReady = "Ready"
+
+	// PodGroupQuorumLost means the pod group had `spec.minMember` pods but now has fewer.
+	PodGroupQuorumLost = "QuorumLost"
+
+	// PodGroupTimedOut means the pod group has not started running for longer than the timeout of
+	// the controller, which stops reconciling it.
+	PodGroupTimedOut = "TimedOut"
+)
+
+// These are the reasons of the podGroup conditions.
+const (
+	// PodGroupReasonUnschedulable is set by the scheduler when a pod of the pod group cannot be scheduled.
+	PodGroupReasonUnschedulable = "Unschedulable"
+	// PodGroupReasonWaitingForPods is set while fewer than `spec.minMember` pods are scheduled or running.
+	PodGroupReasonWaitingForPods = "WaitingForPods"
+	// PodGroupReasonMinMemberScheduled is set once `spec.minMember` pods are bound to nodes.
+	PodGroupReasonMinMemberScheduled = "MinMemberScheduled"
+	// PodGroupReasonMinMemberReady is set once `spec.minMember` pods are running or succeeded.
+	PodGroupReasonMinMemberReady = "MinMemberReady"
+	// PodGroupReasonMinMemberNotMet is set while the pod group has fewer than `spec.minMember` pods.
+	PodGroupReasonMinMemberNotMet = "MinMemberNotMet"
+	// PodGroupReasonMinMemberMet is set once the pod group has `spec.minMember` pods again.
+	PodGroupReasonMinMemberMet = "MinMemberMet"
+	// PodGroupReasonScheduleTimeout is set when the controller stops reconciling the pod group.
+	PodGroupReasonScheduleTimeout = "ScheduleTimeout"
+)
+
 // PodGroup is a collection of Pod; used for batch workload.
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -146,6 +267,25 @@ type PodGroupSpec struct {
 
 	// ScheduleTimeoutSeconds defines the maximal time of members/tasks to wait before run the pod group;
 	ScheduleTimeoutSeconds *int32 `json:"scheduleTimeoutSeconds,omitempty"`
+
+	// TopologyConstraint keeps all the members/tasks of the pod group within one topology domain,
+	// i.e. on nodes that share the same values of some node labels such as a zone or a rack.
+	// +optional
+	TopologyConstraint *PodGroupTopologyConstraint `json:"topologyConstraint,omitempty"`
+}
+
+// PodGroupTopologyConstraint lists the node label keys whose domains a pod group is confined to.
+type PodGroupTopologyConstraint struct {
+	// RequiredTopologyKeys are node label keys, e.g. "topology.kubernetes.io/zone". All the
+	// members/tasks of the pod group are placed on nodes that have the same value for each key.
+	// +optional
+	RequiredTopologyKeys []string `json:"requiredTopologyKeys,omitempty"`
+
+	// PreferredTopologyKeys are node label keys ordered by preference, e.g. a rack label before a
+	// zone label. The members/tasks of the pod group are kept within one domain of the first key
+	// that has a domain where the pod group fits; if there is none, only the required keys apply.
+	// +optional
+	PreferredTopologyKeys []string `json:"preferredTopologyKeys,omitempty"`
 }
 
 // PodGroupStatus represents the current state of a pod group.
@@ -169,8 +309,24 @@ type PodGroupStatus struct {
 	// +optional
 	Failed int32 `json:"failed,omitempty"`
 
+	// The number of pods which have been bound to nodes.
+	// +optional
+	Scheduled int32 `json:"scheduled,omitempty"`
+
 	// ScheduleStartTime of the group
 	ScheduleStartTime metav1.Time `json:"scheduleStartTime,omitempty"`
+
+	// PhaseTransitionTimes records when the pod group last entered each phase.
+	// +optional
+	PhaseTransitionTimes map[PodGroupPhase]metav1.Time `json:"phaseTransitionTimes,omitempty"`
+
+	// Conditions represent the latest observations of the pod group.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
@@ -23,6 +23,7 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -102,6 +103,11 @@ func (in *ElasticQuotaSpec) DeepCopyInto(out *ElasticQuotaSpec) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticQuotaSpec.
@@ -124,6 +130,41 @@ func (in *ElasticQuotaStatus) DeepCopyInto(out *ElasticQuotaStatus) {
 			(*out)[key] = val.DeepCopy()
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FairShare != nil {
+		in, out := &in.FairShare, &out.FairShare
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Borrowed != nil {
+		in, out := &in.Borrowed, &out.Borrowed
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Lent != nil {
+		in, out := &in.Lent, &out.Lent
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.PendingRequests != nil {
+		in, out := &in.PendingRequests, &out.PendingRequests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticQuotaStatus.
@@ -210,6 +251,11 @@ func (in *PodGroupSpec) DeepCopyInto(out *PodGroupSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.TopologyConstraint != nil {
+		in, out := &in.TopologyConstraint, &out.TopologyConstraint
+		*out = new(PodGroupTopologyConstraint)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodGroupSpec.
@@ -226,6 +272,20 @@ func (in *PodGroupSpec) DeepCopy() *PodGroupSpec {
 func (in *PodGroupStatus) DeepCopyInto(out *PodGroupStatus) {
 	*out = *in
 	in.ScheduleStartTime.DeepCopyInto(&out.ScheduleStartTime)
+	if in.PhaseTransitionTimes != nil {
+		in, out := &in.PhaseTransitionTimes, &out.PhaseTransitionTimes
+		*out = make(map[PodGroupPhase]metav1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodGroupStatus.
@@ -237,3 +297,28 @@ func (in *PodGroupStatus) DeepCopy() *PodGroupStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodGroupTopologyConstraint) DeepCopyInto(out *PodGroupTopologyConstraint) {
+	*out = *in
+	if in.RequiredTopologyKeys != nil {
+		in, out := &in.RequiredTopologyKeys, &out.RequiredTopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreferredTopologyKeys != nil {
+		in, out := &in.PreferredTopologyKeys, &out.PreferredTopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodGroupTopologyConstraint.
+func (in *PodGroupTopologyConstraint) DeepCopy() *PodGroupTopologyConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(PodGroupTopologyConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
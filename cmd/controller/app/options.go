@@ -17,16 +17,26 @@ limitations under the License.
 package app
 
 import (
+	"time"
+
 	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/controllers"
 )
 
 type ServerRunOptions struct {
-	MetricsAddr          string
-	ProbeAddr            string
-	ApiServerQPS         int
-	ApiServerBurst       int
-	Workers              int
-	EnableLeaderElection bool
+	MetricsAddr                   string
+	ProbeAddr                     string
+	ApiServerQPS                  int
+	ApiServerBurst                int
+	Workers                       int
+	EnableLeaderElection          bool
+	PodGroupTimeout               time.Duration
+	EnableElasticQuotaFairSharing bool
+	EnableWebhooks                bool
+	WebhookPort                   int
+	WebhookCertDir                string
+	EnableNetworkAwareControllers bool
 }
 
 func NewServerRunOptions() *ServerRunOptions {
@@ -42,4 +52,10 @@ func (s *ServerRunOptions) addAllFlags() {
 	pflag.IntVar(&s.ApiServerBurst, "burst", 10, "burst of query apiserver.")
 	pflag.IntVar(&s.Workers, "workers", 1, "workers of scheduler-plugin-controllers.")
 	pflag.BoolVar(&s.EnableLeaderElection, "enableLeaderElection", s.EnableLeaderElection, "If EnableLeaderElection for controller.")
+	pflag.DurationVar(&s.PodGroupTimeout, "podGroupTimeout", controllers.DefaultScheduleTimeout, "How long a pod group can go without running pods before the controller stops reconciling it. A negative value disables the timeout.")
+	pflag.BoolVar(&s.EnableElasticQuotaFairSharing, "enableElasticQuotaFairSharing", s.EnableElasticQuotaFairSharing, "If the ElasticQuota controller reports the fair share of each quota, for the FairSharing borrowing policy of CapacityScheduling.")
+	pflag.BoolVar(&s.EnableWebhooks, "enableWebhooks", s.EnableWebhooks, "If the controller serves the admission webhooks, which default and validate ElasticQuotas.")
+	pflag.IntVar(&s.WebhookPort, "webhookPort", 9443, "Port the admission webhooks are served on.")
+	pflag.StringVar(&s.WebhookCertDir, "webhookCertDir", "", "Directory of the tls.crt and tls.key serving certificates of the admission webhooks. Defaults to <temp-dir>/k8s-webhook-server/serving-certs.")
+	pflag.BoolVar(&s.EnableNetworkAwareControllers, "enableNetworkAwareControllers", s.EnableNetworkAwareControllers, "If the controller computes the topology order of AppGroups and the network costs of NetworkTopologies, for the network-aware plugins.")
 }
@@ -17,16 +17,23 @@ limitations under the License.
 package app
 
 import (
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/klog/v2/klogr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	schedulingv1a1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/controllers"
+
+	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
 )
 
 var (
@@ -38,6 +45,8 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(schedulingv1a1.AddToScheme(scheme))
+	utilruntime.Must(agv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(ntv1alpha1.AddToScheme(scheme))
 }
 
 func Run(s *ServerRunOptions) error {
@@ -49,6 +58,12 @@ func Run(s *ServerRunOptions) error {
 	ctrl.SetLogger(klogr.New())
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache: cache.Options{
+			// Only the ConfigMaps with the network latencies are read.
+			ByObject: map[client.Object]cache.ByObject{
+				&v1.ConfigMap{}: {Label: controllers.NetworkLatenciesSelector},
+			},
+		},
 		Metrics: metricsserver.Options{
 			BindAddress: s.MetricsAddr,
 		},
@@ -56,6 +71,10 @@ func Run(s *ServerRunOptions) error {
 		LeaderElection:          s.EnableLeaderElection,
 		LeaderElectionID:        "sched-plugins-controllers",
 		LeaderElectionNamespace: "kube-system",
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    s.WebhookPort,
+			CertDir: s.WebhookCertDir,
+		}),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -63,23 +82,54 @@ func Run(s *ServerRunOptions) error {
 	}
 
 	if err = (&controllers.PodGroupReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Workers: s.Workers,
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Workers:         s.Workers,
+		ScheduleTimeout: s.PodGroupTimeout,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PodGroup")
 		return err
 	}
 
 	if err = (&controllers.ElasticQuotaReconciler{
-		Client:  mgr.GetClient(),
-		Scheme:  mgr.GetScheme(),
-		Workers: s.Workers,
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Workers:     s.Workers,
+		FairSharing: s.EnableElasticQuotaFairSharing,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ElasticQuota")
 		return err
 	}
 
+	if s.EnableNetworkAwareControllers {
+		if err = (&controllers.AppGroupReconciler{
+			Client:  mgr.GetClient(),
+			Scheme:  mgr.GetScheme(),
+			Workers: s.Workers,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "AppGroup")
+			return err
+		}
+
+		if err = (&controllers.NetworkTopologyReconciler{
+			Client:  mgr.GetClient(),
+			Scheme:  mgr.GetScheme(),
+			Workers: s.Workers,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "NetworkTopology")
+			return err
+		}
+	}
+
+	if s.EnableWebhooks {
+		if err = (&controllers.ElasticQuotaWebhook{
+			Client: mgr.GetClient(),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ElasticQuota")
+			return err
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		return err
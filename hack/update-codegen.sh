@@ -59,7 +59,7 @@ bash "${CODEGEN_PKG}/kube_codegen.sh" \
 
 echo "Generating controller objects"
 ${CONTROLLER_GEN} object:headerFile="${SCRIPT_ROOT}/hack/boilerplate/boilerplate.generatego.txt" \
-  paths="./apis/scheduling/..."
+  paths="./apis/scheduling/...;./apis/diskio/..."
 
 echo "Generating CRDs, RBAC, and Webhook configurations"
 ${CONTROLLER_GEN} ${CRD_OPTIONS} rbac:roleName=manager webhook \
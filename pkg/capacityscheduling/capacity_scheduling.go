@@ -24,10 +24,11 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	corelisters "k8s.io/client-go/listers/core/v1"
@@ -43,6 +44,8 @@ import (
 	ctrlruntimecache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
@@ -61,8 +64,12 @@ type CapacityScheduling struct {
 	fh                framework.Handle
 	podLister         corelisters.PodLister
 	pdbLister         policylisters.PodDisruptionBudgetLister
+	namespaceLister   corelisters.NamespaceLister
 	client            client.Client
 	elasticQuotaInfos ElasticQuotaInfos
+	namespaceQuotas   namespaceQuotas
+	// fairSharing splits the resources lent by the quotas under their min in proportion to the weights.
+	fairSharing bool
 }
 
 // PreFilterState computed at PreFilter and used at PostFilter or Reserve.
@@ -78,6 +85,10 @@ type PreFilterState struct {
 	// 1. the pods subject to the same quota(namespace) and is more important than the preemptor.
 	// 2. the pods subject to the different quota(namespace) and the usage of quota(namespace) does not exceed min.
 	nominatedPodsReqWithPodReq framework.Resource
+
+	// fairShares is the fair share of each quota, with fair sharing and when the preemptor is not
+	// guaranteed its request. It is computed once per cycle, for PostFilter to select the victims.
+	fairShares map[string]map[v1.ResourceName]int64
 }
 
 // Clone the preFilter state.
@@ -88,12 +99,15 @@ func (s *PreFilterState) Clone() framework.StateData {
 // ElasticQuotaSnapshotState stores the snapshot of elasticQuotas.
 type ElasticQuotaSnapshotState struct {
 	elasticQuotaInfos ElasticQuotaInfos
+	// namespaceQuotas is never modified in place, so it is shared by the snapshots.
+	namespaceQuotas namespaceQuotas
 }
 
 // Clone the ElasticQuotaSnapshot state.
 func (s *ElasticQuotaSnapshotState) Clone() framework.StateData {
 	return &ElasticQuotaSnapshotState{
 		elasticQuotaInfos: s.elasticQuotaInfos.clone(),
+		namespaceQuotas:   s.namespaceQuotas,
 	}
 }
 
@@ -119,11 +133,25 @@ func (c *CapacityScheduling) Name() string {
 
 // New initializes a new plugin and returns it.
 func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	if obj == nil {
+		// profiles without a PluginConfig entry for the plugin get no args
+		obj = &config.CapacitySchedulingArgs{BorrowingPolicy: config.BorrowingPolicyFirstComeFirstServed}
+	}
+	args, ok := obj.(*config.CapacitySchedulingArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type CapacitySchedulingArgs, got %T", obj)
+	}
+	if err := validation.ValidateCapacitySchedulingArgs(nil, args); err != nil {
+		return nil, err
+	}
+
 	c := &CapacityScheduling{
 		fh:                handle,
 		elasticQuotaInfos: NewElasticQuotaInfos(),
 		podLister:         handle.SharedInformerFactory().Core().V1().Pods().Lister(),
 		pdbLister:         getPDBLister(handle.SharedInformerFactory()),
+		namespaceLister:   handle.SharedInformerFactory().Core().V1().Namespaces().Lister(),
+		fairSharing:       args.BorrowingPolicy == config.BorrowingPolicyFairSharing,
 	}
 
 	client, err := client.New(handle.KubeConfig(), client.Options{Scheme: scheme})
@@ -187,6 +215,12 @@ func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framew
 			},
 		},
 	)
+	namespaceInformer := handle.SharedInformerFactory().Core().V1().Namespaces().Informer()
+	namespaceInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addNamespace,
+		UpdateFunc: c.updateNamespace,
+		DeleteFunc: c.deleteNamespace,
+	})
 	klog.InfoS("CapacityScheduling start")
 	return c, nil
 }
@@ -202,8 +236,8 @@ func (c *CapacityScheduling) EventsToRegister() []framework.ClusterEventWithHint
 }
 
 // PreFilter performs the following validations.
-// 1. Check if the (pod.request + eq.allocated) is less than eq.max.
-// 2. Check if the sum(eq's usage) > sum(eq's min).
+// 1. Check if the (pod.request + eq.allocated) is less than eq.max, for the eq and each of its ancestors.
+// 2. Check if the sum(eq's usage) > sum(top-level eq's min).
 func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
 	// TODO improve the efficiency of taking snapshot
 	// e.g. use a two-pointer data structure to only copy the updated EQs when necessary.
@@ -213,7 +247,13 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.Cyc
 	state.Write(ElasticQuotaSnapshotKey, snapshotElasticQuota)
 
 	elasticQuotaInfos := snapshotElasticQuota.elasticQuotaInfos
-	eq := snapshotElasticQuota.elasticQuotaInfos[pod.Namespace]
+	namespaceQuotas := snapshotElasticQuota.namespaceQuotas
+	if namespaceQuotas.conflicted(pod.Namespace) {
+		state.Write(preFilterStateKey, &PreFilterState{podReq: *podReq})
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because namespace %v is covered by more than one ElasticQuota", pod.Namespace, pod.Name, pod.Namespace))
+	}
+	quota := namespaceQuotas.quotaOf(pod.Namespace)
+	eq := elasticQuotaInfos[quota]
 	if eq == nil {
 		preFilterState := &PreFilterState{
 			podReq: *podReq,
@@ -236,24 +276,26 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.Cyc
 		return nil, framework.NewStatus(framework.Error, fmt.Sprintf("Error getting the nodelist: %v", err))
 	}
 
+	capacity := &framework.Resource{}
 	for _, node := range nodeList {
+		capacity.Add(util.ResourceList(node.Allocatable))
 		nominatedPods := c.fh.NominatedPodsForNode(node.Node().Name)
 		for _, p := range nominatedPods {
 			if p.Pod.UID == pod.UID {
 				continue
 			}
-			ns := p.Pod.Namespace
-			info := c.elasticQuotaInfos[ns]
+			ns := namespaceQuotas.quotaOf(p.Pod.Namespace)
+			info := elasticQuotaInfos[ns]
 			if info != nil {
 				pResourceRequest := util.ResourceList(computePodResourceRequest(p.Pod))
 				// If they are subject to the same quota(namespace) and p is more important than pod,
 				// p will be added to the nominatedResource and totalNominatedResource.
 				// If they aren't subject to the same quota(namespace) and the usage of quota(p's namespace) does not exceed min,
 				// p will be added to the totalNominatedResource.
-				if ns == pod.Namespace && corev1helpers.PodPriority(p.Pod) >= corev1helpers.PodPriority(pod) {
+				if ns == quota && corev1helpers.PodPriority(p.Pod) >= corev1helpers.PodPriority(pod) {
 					nominatedPodsReqInEQWithPodReq.Add(pResourceRequest)
 					nominatedPodsReqWithPodReq.Add(pResourceRequest)
-				} else if ns != pod.Namespace && !info.usedOverMin() {
+				} else if ns != quota && !elasticQuotaInfos.usedOverMin(ns) {
 					nominatedPodsReqWithPodReq.Add(pResourceRequest)
 				}
 			}
@@ -267,10 +309,13 @@ func (c *CapacityScheduling) PreFilter(ctx context.Context, state *framework.Cyc
 		nominatedPodsReqInEQWithPodReq: *nominatedPodsReqInEQWithPodReq,
 		nominatedPodsReqWithPodReq:     *nominatedPodsReqWithPodReq,
 	}
+	if c.fairSharing && elasticQuotaInfos.guaranteedLevel(quota, nominatedPodsReqInEQWithPodReq) < 0 {
+		preFilterState.fairShares = elasticQuotaInfos.fairShares(quota, nominatedPodsReqInEQWithPodReq, capacity)
+	}
 	state.Write(preFilterStateKey, preFilterState)
 
-	if eq.usedOverMaxWith(nominatedPodsReqInEQWithPodReq) {
-		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because ElasticQuota %v is more than Max", pod.Namespace, pod.Name, eq.Namespace))
+	if overMax := elasticQuotaInfos.quotaOverMaxWith(quota, nominatedPodsReqInEQWithPodReq); overMax != nil {
+		return nil, framework.NewStatus(framework.Unschedulable, fmt.Sprintf("Pod %v/%v is rejected in PreFilter because ElasticQuota %v is more than Max", pod.Namespace, pod.Name, overMax.Namespace))
 	}
 
 	if elasticQuotaInfos.aggregatedUsedOverMinWith(*nominatedPodsReqWithPodReq) {
@@ -293,7 +338,7 @@ func (c *CapacityScheduling) AddPod(ctx context.Context, cycleState *framework.C
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
-	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos[podToAdd.Pod.Namespace]
+	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos[elasticQuotaSnapshotState.namespaceQuotas.quotaOf(podToAdd.Pod.Namespace)]
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.addPodIfNotPresent(podToAdd.Pod)
 		if err != nil {
@@ -312,7 +357,7 @@ func (c *CapacityScheduling) RemovePod(ctx context.Context, cycleState *framewor
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
-	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos[podToRemove.Pod.Namespace]
+	elasticQuotaInfo := elasticQuotaSnapshotState.elasticQuotaInfos[elasticQuotaSnapshotState.namespaceQuotas.quotaOf(podToRemove.Pod.Namespace)]
 	if elasticQuotaInfo != nil {
 		err = elasticQuotaInfo.deletePodIfPresent(podToRemove.Pod)
 		if err != nil {
@@ -347,7 +392,7 @@ func (c *CapacityScheduling) Reserve(ctx context.Context, state *framework.Cycle
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos[c.namespaceQuotas.quotaOf(pod.Namespace)]
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.addPodIfNotPresent(pod)
 		if err != nil {
@@ -362,7 +407,7 @@ func (c *CapacityScheduling) Unreserve(ctx context.Context, state *framework.Cyc
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos[c.namespaceQuotas.quotaOf(pod.Namespace)]
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.deletePodIfPresent(pod)
 		if err != nil {
@@ -431,27 +476,41 @@ func (p *preemptor) PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus
 		}
 
 		podPriority := corev1helpers.PodPriority(pod)
-		preemptorEQInfo, preemptorWithEQ := elasticQuotaSnapshotState.elasticQuotaInfos[pod.Namespace]
+		elasticQuotaInfos := elasticQuotaSnapshotState.elasticQuotaInfos
+		namespaceQuotas := elasticQuotaSnapshotState.namespaceQuotas
+		quota := namespaceQuotas.quotaOf(pod.Namespace)
+		_, preemptorWithEQ := elasticQuotaInfos[quota]
 		if preemptorWithEQ {
-			moreThanMinWithPreemptor := preemptorEQInfo.usedOverMinWith(&preFilterState.nominatedPodsReqInEQWithPodReq)
+			guaranteedLevel := elasticQuotaInfos.guaranteedLevel(quota, &preFilterState.nominatedPodsReqInEQWithPodReq)
+			fairShares := preFilterState.fairShares
+			reclaimsFairShare := fairShares != nil && elasticQuotaInfos.withinFairShareWith(quota, &preFilterState.nominatedPodsReqInEQWithPodReq, fairShares)
 			for _, p := range nodeInfo.Pods {
 				// Checking terminating pods
 				if p.Pod.DeletionTimestamp != nil {
-					eqInfo, withEQ := elasticQuotaSnapshotState.elasticQuotaInfos[p.Pod.Namespace]
+					pQuota := namespaceQuotas.quotaOf(p.Pod.Namespace)
+					_, withEQ := elasticQuotaInfos[pQuota]
 					if !withEQ {
 						continue
 					}
-					if p.Pod.Namespace == pod.Namespace && corev1helpers.PodPriority(p.Pod) < podPriority {
+					if pQuota == quota && corev1helpers.PodPriority(p.Pod) < podPriority {
 						// There is a terminating pod on the nominated node.
 						// If the terminating pod is in the same namespace with preemptor
 						// and it is less important than preemptor,
 						// return false to avoid preempting more pods.
 						return false, "not eligible due to a terminating pod on the nominated node."
-					} else if p.Pod.Namespace != pod.Namespace && !moreThanMinWithPreemptor && eqInfo.usedOverMin() {
+					} else if elasticQuotaInfos.borrowingFrom(quota, guaranteedLevel, pQuota) {
 						// There is a terminating pod on the nominated node.
 						// The terminating pod isn't in the same namespace with preemptor.
-						// If moreThanMinWithPreemptor is false, it indicates that preemptor can preempt the pods in other EQs whose used is over min.
-						// And if the used of terminating pod's quota is over min, so the room released by terminating pod on the nominated node can be used by the preemptor.
+						// If the preemptor is guaranteed its request at some level of the quota tree, it can preempt
+						// the pods of the quotas that borrow the resources guaranteed at that level.
+						// And if the terminating pod's quota is one of them, the room released by terminating pod
+						// on the nominated node can be used by the preemptor.
+						// return false to avoid preempting more pods.
+						return false, "not eligible due to a terminating pod on the nominated node."
+					} else if reclaimsFairShare && indexOf(elasticQuotaInfos.path(quota), pQuota) < 0 && elasticQuotaInfos.overFairShare(pQuota, fairShares) {
+						// There is a terminating pod on the nominated node.
+						// With fair sharing, a preemptor within its fair share can preempt the pods of
+						// the quotas over theirs, and the terminating pod's quota is one of them.
 						// return false to avoid preempting more pods.
 						return false, "not eligible due to a terminating pod on the nominated node."
 					}
@@ -459,7 +518,7 @@ func (p *preemptor) PodEligibleToPreemptOthers(pod *v1.Pod, nominatedNodeStatus
 			}
 		} else {
 			for _, p := range nodeInfo.Pods {
-				_, withEQ := elasticQuotaSnapshotState.elasticQuotaInfos[p.Pod.Namespace]
+				_, withEQ := elasticQuotaInfos[namespaceQuotas.quotaOf(p.Pod.Namespace)]
 				if withEQ {
 					continue
 				}
@@ -518,8 +577,10 @@ func (p *preemptor) SelectVictimsOnNode(
 	}
 
 	elasticQuotaInfos := elasticQuotaSnapshotState.elasticQuotaInfos
+	namespaceQuotas := elasticQuotaSnapshotState.namespaceQuotas
+	quota := namespaceQuotas.quotaOf(pod.Namespace)
 	podPriority := corev1helpers.PodPriority(pod)
-	preemptorElasticQuotaInfo, preemptorWithElasticQuota := elasticQuotaInfos[pod.Namespace]
+	_, preemptorWithElasticQuota := elasticQuotaInfos[quota]
 
 	// sort the pods in node by the priority class
 	sort.Slice(nodeInfo.Pods, func(i, j int) bool { return !schedutil.MoreImportantPod(nodeInfo.Pods[i].Pod, nodeInfo.Pods[j].Pod) })
@@ -528,20 +589,36 @@ func (p *preemptor) SelectVictimsOnNode(
 	if preemptorWithElasticQuota {
 		nominatedPodsReqInEQWithPodReq = preFilterState.nominatedPodsReqInEQWithPodReq
 		nominatedPodsReqWithPodReq = preFilterState.nominatedPodsReqWithPodReq
-		moreThanMinWithPreemptor := preemptorElasticQuotaInfo.usedOverMinWith(&nominatedPodsReqInEQWithPodReq)
+		guaranteedLevel := elasticQuotaInfos.guaranteedLevel(quota, &nominatedPodsReqInEQWithPodReq)
+		// With fair sharing, a preemptor that is not guaranteed its request but is within its fair
+		// share can also reclaim the resources borrowed beyond their fair share by other quotas.
+		fairShares := preFilterState.fairShares
+		if fairShares != nil && !elasticQuotaInfos.withinFairShareWith(quota, &nominatedPodsReqInEQWithPodReq, fairShares) {
+			fairShares = nil
+		}
+		preemptorPath := elasticQuotaInfos.path(quota)
 		for _, p := range nodeInfo.Pods {
-			eqInfo, withEQ := elasticQuotaInfos[p.Pod.Namespace]
+			pQuota := namespaceQuotas.quotaOf(p.Pod.Namespace)
+			_, withEQ := elasticQuotaInfos[pQuota]
 			if !withEQ {
 				continue
 			}
 
-			if moreThanMinWithPreemptor {
+			if guaranteedLevel < 0 {
 				// If Preemptor.Request + Quota.Used > Quota.Min:
 				// It means that its guaranteed isn't borrowed by other
 				// quotas. So that we will select the pods which subject to the
 				// same quota(namespace) with the lower priority than the
 				// preemptor's priority as potential victims in a node.
-				if p.Pod.Namespace == pod.Namespace && corev1helpers.PodPriority(p.Pod) < podPriority {
+				if pQuota == quota && corev1helpers.PodPriority(p.Pod) < podPriority {
+					potentialVictims = append(potentialVictims, p)
+					if err := removePod(p); err != nil {
+						return nil, 0, framework.AsStatus(err)
+					}
+				} else if fairShares != nil && indexOf(preemptorPath, pQuota) < 0 && elasticQuotaInfos.overFairShare(pQuota, fairShares) {
+					// The fair shares are computed once, while the usage of the victim's quota decreases
+					// as its pods are removed, so no more of its pods are selected once it is within its
+					// fair share.
 					potentialVictims = append(potentialVictims, p)
 					if err := removePod(p); err != nil {
 						return nil, 0, framework.AsStatus(err)
@@ -554,8 +631,10 @@ func (p *preemptor) SelectVictimsOnNode(
 				// `borrowed` by other Quota. Potential victims in a node
 				// will be chosen from Quotas that allocates more resources
 				// than its min, i.e., borrowing resources from other
-				// Quotas.
-				if p.Pod.Namespace != pod.Namespace && eqInfo.usedOverMin() {
+				// Quotas. With a quota tree, the same applies to the lowest
+				// ancestor whose min covers the request, and the quotas
+				// that borrow from its subtree.
+				if elasticQuotaInfos.borrowingFrom(quota, guaranteedLevel, pQuota) {
 					potentialVictims = append(potentialVictims, p)
 					if err := removePod(p); err != nil {
 						return nil, 0, framework.AsStatus(err)
@@ -565,7 +644,7 @@ func (p *preemptor) SelectVictimsOnNode(
 		}
 	} else {
 		for _, p := range nodeInfo.Pods {
-			_, withEQ := elasticQuotaInfos[p.Pod.Namespace]
+			_, withEQ := elasticQuotaInfos[namespaceQuotas.quotaOf(p.Pod.Namespace)]
 			if withEQ {
 				continue
 			}
@@ -598,7 +677,7 @@ func (p *preemptor) SelectVictimsOnNode(
 	// after removing all the lower priority pods,
 	// we are almost done and this node is not suitable for preemption.
 	if preemptorWithElasticQuota {
-		if preemptorElasticQuotaInfo.usedOverMaxWith(&podReq) ||
+		if elasticQuotaInfos.usedOverMaxWith(quota, &podReq) ||
 			elasticQuotaInfos.aggregatedUsedOverMinWith(podReq) {
 			return nil, 0, framework.NewStatus(framework.Unschedulable, "global quota max exceeded")
 		}
@@ -612,7 +691,7 @@ func (p *preemptor) SelectVictimsOnNode(
 	// Try to reprieve as many pods as possible. We first try to reprieve the PDB
 	// violating victims and then other non-violating ones. In both cases, we start
 	// from the highest priority victims.
-	violatingVictims, nonViolatingVictims := filterPodsWithPDBViolation(potentialVictims, pdbs)
+	violatingVictims, nonViolatingVictims := util.FilterPodsWithPDBViolation(potentialVictims, pdbs)
 	reprievePod := func(pi *framework.PodInfo) (bool, error) {
 		if err := addPod(pi); err != nil {
 			return false, err
@@ -627,7 +706,7 @@ func (p *preemptor) SelectVictimsOnNode(
 			klog.V(5).InfoS("Found a potential preemption victim on node", "pod", klog.KObj(pi.Pod), "node", klog.KObj(nodeInfo.Node()))
 		}
 
-		if preemptorWithElasticQuota && (preemptorElasticQuotaInfo.usedOverMaxWith(&nominatedPodsReqInEQWithPodReq) || elasticQuotaInfos.aggregatedUsedOverMinWith(nominatedPodsReqWithPodReq)) {
+		if preemptorWithElasticQuota && (elasticQuotaInfos.usedOverMaxWith(quota, &nominatedPodsReqInEQWithPodReq) || elasticQuotaInfos.aggregatedUsedOverMinWith(nominatedPodsReqWithPodReq)) {
 			if err := removePod(pi); err != nil {
 				return false, err
 			}
@@ -662,17 +741,22 @@ func (c *CapacityScheduling) addElasticQuota(obj interface{}) {
 		return
 	}
 
-	elasticQuotaInfo := newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max, nil)
+	elasticQuotaInfo := elasticQuotaInfoOf(eq)
 
 	c.Lock()
 	defer c.Unlock()
 	c.elasticQuotaInfos[eq.Namespace] = elasticQuotaInfo
+	c.updateNamespaceQuotas()
 }
 
 func (c *CapacityScheduling) updateElasticQuota(oldObj, newObj interface{}) {
 	oldEQ := oldObj.(*v1alpha1.ElasticQuota)
 	newEQ := newObj.(*v1alpha1.ElasticQuota)
-	newEQInfo := newElasticQuotaInfo(newEQ.Namespace, newEQ.Spec.Min, newEQ.Spec.Max, nil)
+	// The status updates of the controller leave the spec unchanged.
+	if apiequality.Semantic.DeepEqual(oldEQ.Spec, newEQ.Spec) {
+		return
+	}
+	newEQInfo := elasticQuotaInfoOf(newEQ)
 
 	c.Lock()
 	defer c.Unlock()
@@ -683,6 +767,10 @@ func (c *CapacityScheduling) updateElasticQuota(oldObj, newObj interface{}) {
 		newEQInfo.Used = oldEQInfo.Used
 	}
 	c.elasticQuotaInfos[newEQ.Namespace] = newEQInfo
+	// The namespaces of the quota only change with its namespace selector.
+	if !apiequality.Semantic.DeepEqual(oldEQ.Spec.NamespaceSelector, newEQ.Spec.NamespaceSelector) {
+		c.updateNamespaceQuotas()
+	}
 }
 
 func (c *CapacityScheduling) deleteElasticQuota(obj interface{}) {
@@ -690,6 +778,119 @@ func (c *CapacityScheduling) deleteElasticQuota(obj interface{}) {
 	c.Lock()
 	defer c.Unlock()
 	delete(c.elasticQuotaInfos, elasticQuota.Namespace)
+	c.updateNamespaceQuotas()
+}
+
+func (c *CapacityScheduling) addNamespace(obj interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	c.updateNamespaceQuotas()
+}
+
+func (c *CapacityScheduling) updateNamespace(oldObj, newObj interface{}) {
+	oldNamespace := oldObj.(*v1.Namespace)
+	newNamespace := newObj.(*v1.Namespace)
+	if labels.Equals(oldNamespace.Labels, newNamespace.Labels) {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.updateNamespaceQuotas()
+}
+
+func (c *CapacityScheduling) deleteNamespace(obj interface{}) {
+	c.Lock()
+	defer c.Unlock()
+	c.updateNamespaceQuotas()
+}
+
+// elasticQuotaInfoOf returns a new ElasticQuotaInfo, without usage, for the ElasticQuota.
+func elasticQuotaInfoOf(eq *v1alpha1.ElasticQuota) *ElasticQuotaInfo {
+	elasticQuotaInfo := newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max, nil)
+	elasticQuotaInfo.Parent = eq.Spec.Parent
+	elasticQuotaInfo.Weight = int64(eq.Spec.Weight)
+	selector, err := util.GetElasticQuotaSelector(eq)
+	if err != nil {
+		klog.ErrorS(err, "Failed to parse the namespace selector of elasticQuota, only its namespace is covered", "elasticQuota", klog.KObj(eq))
+	}
+	elasticQuotaInfo.selector = selector
+	return elasticQuotaInfo
+}
+
+// updateNamespaceQuotas recomputes the quotas of the namespaces selected by the namespace selectors
+// of the ElasticQuotas, and moves the assigned pods of the namespaces whose quota changed to their
+// new quota. The caller must hold the lock.
+func (c *CapacityScheduling) updateNamespaceQuotas() {
+	selectors := make(map[string]labels.Selector, len(c.elasticQuotaInfos))
+	withSelector := false
+	for quota, info := range c.elasticQuotaInfos {
+		selectors[quota] = info.selector
+		withSelector = withSelector || info.selector != nil
+	}
+
+	newNamespaceQuotas := make(namespaceQuotas)
+	// Without any selector, every namespace is covered by its own quota and there is nothing to list.
+	if withSelector {
+		namespaces, err := c.namespaceLister.List(labels.Everything())
+		if err != nil {
+			klog.ErrorS(err, "Failed to list namespaces")
+			return
+		}
+		for _, namespace := range namespaces {
+			quotas := util.GetElasticQuotasOfNamespace(namespace, selectors)
+			if len(quotas) > 1 {
+				newNamespaceQuotas[namespace.Name] = ""
+			} else if len(quotas) == 1 && quotas[0] != namespace.Name {
+				newNamespaceQuotas[namespace.Name] = quotas[0]
+			}
+		}
+	}
+
+	oldNamespaceQuotas := c.namespaceQuotas
+	c.namespaceQuotas = newNamespaceQuotas
+
+	changed := sets.New[string]()
+	for _, m := range []namespaceQuotas{oldNamespaceQuotas, newNamespaceQuotas} {
+		for namespace := range m {
+			if oldNamespaceQuotas.quotaOf(namespace) != newNamespaceQuotas.quotaOf(namespace) {
+				changed.Insert(namespace)
+			}
+		}
+	}
+	for namespace := range changed {
+		c.moveNamespacePods(namespace, oldNamespaceQuotas.quotaOf(namespace), newNamespaceQuotas.quotaOf(namespace))
+	}
+}
+
+// moveNamespacePods moves the assigned pods of the namespace from the quota from to the quota to.
+// The caller must hold the lock.
+func (c *CapacityScheduling) moveNamespacePods(namespace, from, to string) {
+	oldInfo, newInfo := c.elasticQuotaInfos[from], c.elasticQuotaInfos[to]
+	if oldInfo == nil && newInfo == nil {
+		return
+	}
+
+	pods, err := c.podLister.Pods(namespace).List(labels.Everything())
+	if err != nil {
+		klog.ErrorS(err, "Failed to list pods", "namespace", namespace)
+		return
+	}
+	for _, pod := range pods {
+		if !assignedPod(pod) || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		if oldInfo != nil {
+			if err := oldInfo.deletePodIfPresent(pod); err != nil {
+				klog.ErrorS(err, "Failed to delete Pod from its previous elasticQuota", "pod", klog.KObj(pod))
+			}
+		}
+		if newInfo != nil {
+			if err := newInfo.addPodIfNotPresent(pod); err != nil {
+				klog.ErrorS(err, "Failed to add Pod to its associated elasticQuota", "pod", klog.KObj(pod))
+			}
+		}
+	}
 }
 
 func (c *CapacityScheduling) addPod(obj interface{}) {
@@ -698,7 +899,11 @@ func (c *CapacityScheduling) addPod(obj interface{}) {
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	if c.namespaceQuotas.conflicted(pod.Namespace) {
+		return
+	}
+
+	elasticQuotaInfo := c.elasticQuotaInfos[c.namespaceQuotas.quotaOf(pod.Namespace)]
 	// If elasticQuotaInfo is nil, try to list ElasticQuotas through elasticQuotaLister
 	if elasticQuotaInfo == nil {
 		var eqList v1alpha1.ElasticQuotaList
@@ -716,8 +921,14 @@ func (c *CapacityScheduling) addPod(obj interface{}) {
 		if len(eqs) > 0 {
 			// only one elasticquota is supported in each namespace
 			eq := eqs[0]
-			elasticQuotaInfo = newElasticQuotaInfo(eq.Namespace, eq.Spec.Min, eq.Spec.Max, nil)
-			c.elasticQuotaInfos[eq.Namespace] = elasticQuotaInfo
+			c.elasticQuotaInfos[eq.Namespace] = elasticQuotaInfoOf(&eq)
+			c.updateNamespaceQuotas()
+		}
+
+		// The namespace selector of the new quota may match other namespaces, including this one.
+		elasticQuotaInfo = c.elasticQuotaInfos[c.namespaceQuotas.quotaOf(pod.Namespace)]
+		if elasticQuotaInfo == nil {
+			return
 		}
 	}
 
@@ -739,7 +950,7 @@ func (c *CapacityScheduling) updatePod(oldObj, newObj interface{}) {
 		c.Lock()
 		defer c.Unlock()
 
-		elasticQuotaInfo := c.elasticQuotaInfos[newPod.Namespace]
+		elasticQuotaInfo := c.elasticQuotaInfos[c.namespaceQuotas.quotaOf(newPod.Namespace)]
 		if elasticQuotaInfo != nil {
 			err := elasticQuotaInfo.deletePodIfPresent(newPod)
 			if err != nil {
@@ -754,7 +965,7 @@ func (c *CapacityScheduling) deletePod(obj interface{}) {
 	c.Lock()
 	defer c.Unlock()
 
-	elasticQuotaInfo := c.elasticQuotaInfos[pod.Namespace]
+	elasticQuotaInfo := c.elasticQuotaInfos[c.namespaceQuotas.quotaOf(pod.Namespace)]
 	if elasticQuotaInfo != nil {
 		err := elasticQuotaInfo.deletePodIfPresent(pod)
 		if err != nil {
@@ -771,6 +982,7 @@ func (c *CapacityScheduling) snapshotElasticQuota() *ElasticQuotaSnapshotState {
 	elasticQuotaInfosDeepCopy := c.elasticQuotaInfos.clone()
 	return &ElasticQuotaSnapshotState{
 		elasticQuotaInfos: elasticQuotaInfosDeepCopy,
+		namespaceQuotas:   c.namespaceQuotas,
 	}
 }
 
@@ -853,58 +1065,6 @@ func computePodResourceRequest(pod *v1.Pod) *framework.Resource {
 	return result
 }
 
-// filterPodsWithPDBViolation groups the given "pods" into two groups of "violatingPods"
-// and "nonViolatingPods" based on whether their PDBs will be violated if they are
-// preempted.
-// This function is stable and does not change the order of received pods. So, if it
-// receives a sorted list, grouping will preserve the order of the input list.
-func filterPodsWithPDBViolation(podInfos []*framework.PodInfo, pdbs []*policy.PodDisruptionBudget) (violatingPods, nonViolatingPods []*framework.PodInfo) {
-	pdbsAllowed := make([]int32, len(pdbs))
-	for i, pdb := range pdbs {
-		pdbsAllowed[i] = pdb.Status.DisruptionsAllowed
-	}
-
-	for _, podInfo := range podInfos {
-		pod := podInfo.Pod
-		pdbForPodIsViolated := false
-		// A pod with no labels will not match any PDB. So, no need to check.
-		if len(pod.Labels) != 0 {
-			for i, pdb := range pdbs {
-				if pdb.Namespace != pod.Namespace {
-					continue
-				}
-				selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
-				if err != nil {
-					continue
-				}
-				// A PDB with a nil or empty selector matches nothing.
-				if selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
-					continue
-				}
-
-				// Existing in DisruptedPods means it has been processed in API server,
-				// we don't treat it as a violating case.
-				if _, exist := pdb.Status.DisruptedPods[pod.Name]; exist {
-					continue
-				}
-				// Only decrement the matched pdb when it's not in its <DisruptedPods>;
-				// otherwise we may over-decrement the budget number.
-				pdbsAllowed[i]--
-				// We have found a matching PDB.
-				if pdbsAllowed[i] < 0 {
-					pdbForPodIsViolated = true
-				}
-			}
-		}
-		if pdbForPodIsViolated {
-			violatingPods = append(violatingPods, podInfo)
-		} else {
-			nonViolatingPods = append(nonViolatingPods, podInfo)
-		}
-	}
-	return violatingPods, nonViolatingPods
-}
-
 // assignedPod selects pods that are assigned (scheduled and running).
 func assignedPod(pod *v1.Pod) bool {
 	return len(pod.Spec.NodeName) != 0
@@ -46,6 +46,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 	testutil "sigs.k8s.io/scheduler-plugins/test/util"
 )
 
@@ -63,10 +64,11 @@ func TestPreFilter(t *testing.T) {
 	}
 
 	tests := []struct {
-		name          string
-		podInfos      []podInfo
-		elasticQuotas map[string]*ElasticQuotaInfo
-		expected      []framework.Code
+		name            string
+		podInfos        []podInfo
+		elasticQuotas   map[string]*ElasticQuotaInfo
+		namespaceQuotas namespaceQuotas
+		expected        []framework.Code
 	}{
 		{
 			name: "pod subjects to ElasticQuota",
@@ -138,6 +140,56 @@ func TestPreFilter(t *testing.T) {
 				framework.Success,
 			},
 		},
+		{
+			name: "pods of the namespaces selected by an ElasticQuota subject to it",
+			podInfos: []podInfo{
+				{podName: "ns2-p1", podNamespace: "ns2", memReq: 500},
+				{podName: "ns3-p1", podNamespace: "ns3", memReq: 1500},
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1": {
+					Namespace: "ns1",
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Max: &framework.Resource{
+						Memory: 2000,
+					},
+					Used: &framework.Resource{
+						Memory: 300,
+					},
+				},
+			},
+			namespaceQuotas: namespaceQuotas{"ns2": "ns1", "ns3": "ns1"},
+			expected: []framework.Code{
+				framework.Success,
+				framework.Unschedulable,
+			},
+		},
+		{
+			name: "pod of a namespace covered by several ElasticQuotas",
+			podInfos: []podInfo{
+				{podName: "ns2-p1", podNamespace: "ns2", memReq: 500},
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1": {
+					Namespace: "ns1",
+					Min: &framework.Resource{
+						Memory: 1000,
+					},
+					Max: &framework.Resource{
+						Memory: 2000,
+					},
+					Used: &framework.Resource{
+						Memory: 300,
+					},
+				},
+			},
+			namespaceQuotas: namespaceQuotas{"ns2": ""},
+			expected: []framework.Code{
+				framework.UnschedulableAndUnresolvable,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -163,6 +215,7 @@ func TestPreFilter(t *testing.T) {
 
 			cs := &CapacityScheduling{
 				elasticQuotaInfos: tt.elasticQuotas,
+				namespaceQuotas:   tt.namespaceQuotas,
 				fh:                fwk,
 			}
 
@@ -609,6 +662,7 @@ func TestDryRunPreemption(t *testing.T) {
 		nodes         []*v1.Node
 		nodesStatuses framework.NodeToStatusMap
 		elasticQuotas map[string]*ElasticQuotaInfo
+		fairSharing   bool
 		want          []preemption.Candidate
 	}{
 		{
@@ -715,6 +769,187 @@ func TestDryRunPreemption(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "preemption within a department of a quota tree",
+			pod:  makePod("t1-p", "a2", 50, 0, 0, highPriority, "t1-p", ""),
+			pods: []*v1.Pod{
+				makePod("t1-p1", "a1", 50, 0, 0, midPriority, "t1-p1", "node-a"),
+				makePod("t1-p2", "a1", 50, 0, 0, midPriority, "t1-p2", "node-a"),
+				makePod("t1-p3", "dept-b", 50, 0, 0, midPriority, "t1-p3", "node-a"),
+			},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node-a").Capacity(res).Obj(),
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"dept-a": {
+					Namespace: "dept-a",
+					Min: &framework.Resource{
+						Memory: 100,
+					},
+					Used: &framework.Resource{},
+				},
+				"a1": {
+					Namespace: "a1",
+					Parent:    "dept-a",
+					Min: &framework.Resource{
+						Memory: 50,
+					},
+					Used: &framework.Resource{
+						Memory: 100,
+					},
+				},
+				"a2": {
+					Namespace: "a2",
+					Parent:    "dept-a",
+					Min: &framework.Resource{
+						Memory: 50,
+					},
+					Used: &framework.Resource{},
+				},
+				"dept-b": {
+					Namespace: "dept-b",
+					Min: &framework.Resource{
+						Memory: 100,
+					},
+					Used: &framework.Resource{
+						Memory: 50,
+					},
+				},
+			},
+			nodesStatuses: framework.NodeToStatusMap{
+				"node-a": framework.NewStatus(framework.Unschedulable),
+			},
+			want: []preemption.Candidate{
+				&candidate{
+					victims: &extenderv1.Victims{
+						Pods: []*v1.Pod{
+							makePod("t1-p2", "a1", 50, 0, 0, midPriority, "t1-p2", "node-a"),
+						},
+						NumPDBViolations: 0,
+					},
+					name: "node-a",
+				},
+			},
+		},
+		{
+			name: "fair sharing preemption of a quota over its fair share",
+			pod:  makePod("t4-p", "ns1", 50, 0, 0, highPriority, "t4-p", ""),
+			pods: []*v1.Pod{
+				makePod("t4-p1", "ns1", 50, 0, 0, highPriority, "t4-p1", "node-a"),
+				makePod("t4-p2", "ns2", 50, 0, 0, midPriority, "t4-p2", "node-a"),
+				makePod("t4-p3", "ns2", 50, 0, 0, highPriority, "t4-p3", "node-a"),
+			},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node-a").Capacity(res).Obj(),
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1": {
+					Namespace: "ns1",
+					Weight:    3,
+					Max: &framework.Resource{
+						Memory: 200,
+					},
+					Min: &framework.Resource{
+						Memory: 50,
+					},
+					Used: &framework.Resource{
+						Memory: 50,
+					},
+				},
+				"ns2": {
+					Namespace: "ns2",
+					Weight:    1,
+					Max: &framework.Resource{
+						Memory: 200,
+					},
+					Min: &framework.Resource{
+						Memory: 50,
+					},
+					Used: &framework.Resource{
+						Memory: 100,
+					},
+				},
+				"ns3": {
+					Namespace: "ns3",
+					Max: &framework.Resource{
+						Memory: 200,
+					},
+					Min: &framework.Resource{
+						Memory: 100,
+					},
+					Used: &framework.Resource{},
+				},
+			},
+			nodesStatuses: framework.NodeToStatusMap{
+				"node-a": framework.NewStatus(framework.Unschedulable),
+			},
+			fairSharing: true,
+			want: []preemption.Candidate{
+				&candidate{
+					victims: &extenderv1.Victims{
+						Pods: []*v1.Pod{
+							makePod("t4-p2", "ns2", 50, 0, 0, midPriority, "t4-p2", "node-a"),
+						},
+						NumPDBViolations: 0,
+					},
+					name: "node-a",
+				},
+			},
+		},
+		{
+			name: "no preemption of a quota over its fair share without fair sharing",
+			pod:  makePod("t5-p", "ns1", 50, 0, 0, highPriority, "t5-p", ""),
+			pods: []*v1.Pod{
+				makePod("t5-p1", "ns1", 50, 0, 0, highPriority, "t5-p1", "node-a"),
+				makePod("t5-p2", "ns2", 50, 0, 0, midPriority, "t5-p2", "node-a"),
+				makePod("t5-p3", "ns2", 50, 0, 0, highPriority, "t5-p3", "node-a"),
+			},
+			nodes: []*v1.Node{
+				st.MakeNode().Name("node-a").Capacity(res).Obj(),
+			},
+			elasticQuotas: map[string]*ElasticQuotaInfo{
+				"ns1": {
+					Namespace: "ns1",
+					Weight:    3,
+					Max: &framework.Resource{
+						Memory: 200,
+					},
+					Min: &framework.Resource{
+						Memory: 50,
+					},
+					Used: &framework.Resource{
+						Memory: 50,
+					},
+				},
+				"ns2": {
+					Namespace: "ns2",
+					Weight:    1,
+					Max: &framework.Resource{
+						Memory: 200,
+					},
+					Min: &framework.Resource{
+						Memory: 50,
+					},
+					Used: &framework.Resource{
+						Memory: 100,
+					},
+				},
+				"ns3": {
+					Namespace: "ns3",
+					Max: &framework.Resource{
+						Memory: 200,
+					},
+					Min: &framework.Resource{
+						Memory: 100,
+					},
+					Used: &framework.Resource{},
+				},
+			},
+			nodesStatuses: framework.NodeToStatusMap{
+				"node-a": framework.NewStatus(framework.Unschedulable),
+			},
+			want: []preemption.Candidate{},
+		},
 	}
 
 	for _, tt := range tests {
@@ -755,6 +990,14 @@ func TestDryRunPreemption(t *testing.T) {
 				nominatedPodsReqWithPodReq:     *podReq,
 				nominatedPodsReqInEQWithPodReq: *podReq,
 			}
+			nodeInfos, _ := fwk.SnapshotSharedLister().NodeInfos().List()
+			if _, ok := tt.elasticQuotas[tt.pod.Namespace]; ok && tt.fairSharing && ElasticQuotaInfos(tt.elasticQuotas).guaranteedLevel(tt.pod.Namespace, podReq) < 0 {
+				capacity := &framework.Resource{}
+				for _, nodeInfo := range nodeInfos {
+					capacity.Add(util.ResourceList(nodeInfo.Allocatable))
+				}
+				prefilterState.fairShares = ElasticQuotaInfos(tt.elasticQuotas).fairShares(tt.pod.Namespace, podReq, capacity)
+			}
 			state.Write(preFilterStateKey, prefilterState)
 			state.Write(ElasticQuotaSnapshotKey, elasticQuotaSnapshotState)
 
@@ -770,7 +1013,6 @@ func TestDryRunPreemption(t *testing.T) {
 				},
 			}
 
-			nodeInfos, _ := fwk.SnapshotSharedLister().NodeInfos().List()
 			got, _, err := pe.DryRunPreemption(ctx, tt.pod, nodeInfos, nil, 0, int32(len(nodeInfos)))
 			if err != nil {
 				t.Fatalf("unexpected error during DryRunPreemption(): %v", err)
@@ -1599,6 +1841,111 @@ func TestDeletePod(t *testing.T) {
 	}
 }
 
+func TestNamespaceSelector(t *testing.T) {
+	makeNamespace := func(name string, labels map[string]string) *v1.Namespace {
+		return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	withSelector := func(eq *v1alpha1.ElasticQuota, matchLabels map[string]string) *v1alpha1.ElasticQuota {
+		eq.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: matchLabels}
+		return eq
+	}
+	shared := makeNamespace("shared", map[string]string{"tenant": "a", "team": "b"})
+	namespaces := []*v1.Namespace{
+		makeNamespace("tenant-a", nil),
+		makeNamespace("team-b", nil),
+		makeNamespace("a-1", map[string]string{"tenant": "a"}),
+		makeNamespace("a-2", map[string]string{"tenant": "a"}),
+		shared,
+	}
+	pods := []*v1.Pod{
+		makePod("a-1-p1", "a-1", 50, 10, 0, midPriority, "a-1-p1", "node-a"),
+		makePod("a-2-p1", "a-2", 50, 10, 0, midPriority, "a-2-p1", "node-a"),
+		makePod("a-2-p2", "a-2", 50, 10, 0, midPriority, "a-2-p2", ""),
+		makePod("shared-p1", "shared", 50, 10, 0, midPriority, "shared-p1", "node-a"),
+	}
+
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(), 0)
+	for _, ns := range namespaces {
+		if err := informerFactory.Core().V1().Namespaces().Informer().GetStore().Add(ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, pod := range pods {
+		if err := informerFactory.Core().V1().Pods().Informer().GetStore().Add(pod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cs := &CapacityScheduling{
+		elasticQuotaInfos: NewElasticQuotaInfos(),
+		podLister:         informerFactory.Core().V1().Pods().Lister(),
+		namespaceLister:   informerFactory.Core().V1().Namespaces().Lister(),
+	}
+	expectPods := func(step string, expected map[string]sets.String) {
+		t.Helper()
+		for ns, pods := range expected {
+			if got := cs.elasticQuotaInfos[ns].pods; !got.Equal(pods) {
+				t.Errorf("%v: expected pods %v in ElasticQuota %v, got %v", step, pods.List(), ns, got.List())
+			}
+		}
+	}
+
+	cs.addElasticQuota(withSelector(makeEQ("tenant-a", "eq", makeResourceList(100, 1000), makeResourceList(10, 100)), map[string]string{"tenant": "a"}))
+	expectPods("add a quota with a selector", map[string]sets.String{
+		"tenant-a": sets.NewString("a-1-p1", "a-2-p1", "shared-p1"),
+	})
+	if got, expected := cs.elasticQuotaInfos["tenant-a"].Used.Memory, int64(150); got != expected {
+		t.Errorf("expected %v memory used by ElasticQuota tenant-a, got %v", expected, got)
+	}
+
+	cs.addElasticQuota(withSelector(makeEQ("team-b", "eq", makeResourceList(100, 1000), makeResourceList(10, 100)), map[string]string{"team": "b"}))
+	expectPods("add a conflicting quota", map[string]sets.String{
+		"tenant-a": sets.NewString("a-1-p1", "a-2-p1"),
+		"team-b":   sets.NewString(),
+	})
+	if !cs.namespaceQuotas.conflicted("shared") {
+		t.Errorf("expected namespace shared to be conflicted")
+	}
+
+	cs.addPod(makePod("shared-p2", "shared", 50, 10, 0, midPriority, "shared-p2", "node-a"))
+	expectPods("add a pod of a conflicted namespace", map[string]sets.String{
+		"tenant-a": sets.NewString("a-1-p1", "a-2-p1"),
+		"team-b":   sets.NewString(),
+	})
+
+	updated := makeNamespace("shared", map[string]string{"team": "b"})
+	if err := informerFactory.Core().V1().Namespaces().Informer().GetStore().Update(updated); err != nil {
+		t.Fatal(err)
+	}
+	cs.updateNamespace(shared, updated)
+	expectPods("resolve the conflict", map[string]sets.String{
+		"tenant-a": sets.NewString("a-1-p1", "a-2-p1"),
+		"team-b":   sets.NewString("shared-p1"),
+	})
+
+	// A status update of the controller is ignored.
+	oldEQ := withSelector(makeEQ("tenant-a", "eq", makeResourceList(100, 1000), makeResourceList(10, 100)), map[string]string{"tenant": "a"})
+	newEQ := oldEQ.DeepCopy()
+	newEQ.Status.Used = makeResourceList(20, 150)
+	info := cs.elasticQuotaInfos["tenant-a"]
+	cs.updateElasticQuota(oldEQ, newEQ)
+	if cs.elasticQuotaInfos["tenant-a"] != info {
+		t.Errorf("expected ElasticQuota tenant-a to be left as is on a status update")
+	}
+
+	// A spec update keeps the pods of the quota.
+	newEQ.Spec.Max = makeResourceList(200, 2000)
+	cs.updateElasticQuota(oldEQ, newEQ)
+	expectPods("update the max of a quota", map[string]sets.String{
+		"tenant-a": sets.NewString("a-1-p1", "a-2-p1"),
+	})
+
+	cs.deleteElasticQuota(makeEQ("team-b", "eq", nil, nil))
+	if got := cs.namespaceQuotas.quotaOf("shared"); got != "shared" {
+		t.Errorf("expected namespace shared to be covered by its own quota, got %q", got)
+	}
+}
+
 func makePod(podName string, namespace string, memReq int64, cpuReq int64, gpuReq int64, priority int32, uid string, nodeName string) *v1.Pod {
 	pause := imageutils.GetPauseImageName()
 	pod := st.MakePod().Namespace(namespace).Name(podName).Container(pause).
@@ -21,6 +21,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
@@ -45,27 +46,258 @@ func (e ElasticQuotaInfos) clone() ElasticQuotaInfos {
 	return elasticQuotas
 }
 
+// aggregatedUsedOverMinWith checks whether the total usage of all the quotas plus the
+// podRequest exceeds the sum of the min of the top-level quotas, whose min covers the
+// usage of their whole subtree.
 func (e ElasticQuotaInfos) aggregatedUsedOverMinWith(podRequest framework.Resource) bool {
 	used := framework.NewResource(nil)
 	min := framework.NewResource(nil)
 
 	for _, elasticQuotaInfo := range e {
 		used.Add(util.ResourceList(elasticQuotaInfo.Used))
-		min.Add(util.ResourceList(elasticQuotaInfo.Min))
+		if e.isTopLevel(elasticQuotaInfo) {
+			min.Add(util.ResourceList(elasticQuotaInfo.Min))
+		}
 	}
 
 	used.Add(util.ResourceList(&podRequest))
 	return cmp(used, min, LowerBoundOfMin)
 }
 
+// isTopLevel returns true if the quota has no parent, or if its parent quota does not exist.
+func (e ElasticQuotaInfos) isTopLevel(info *ElasticQuotaInfo) bool {
+	if len(info.Parent) == 0 {
+		return true
+	}
+	_, ok := e[info.Parent]
+	return !ok
+}
+
+// path returns the quota of the namespace followed by its ancestors up to its top-level quota.
+// It returns nil if the namespace has no quota.
+func (e ElasticQuotaInfos) path(namespace string) []*ElasticQuotaInfo {
+	var path []*ElasticQuotaInfo
+	visited := sets.New[string]()
+	for info := e[namespace]; info != nil && !visited.Has(info.Namespace); info = e[info.Parent] {
+		visited.Insert(info.Namespace)
+		path = append(path, info)
+	}
+	return path
+}
+
+// subtreeUsed returns the usage of the quota of the namespace and of all its descendants.
+func (e ElasticQuotaInfos) subtreeUsed(namespace string) *framework.Resource {
+	used := framework.NewResource(nil)
+	visited := sets.New(namespace)
+	queue := []string{namespace}
+	for len(queue) > 0 {
+		ns := queue[0]
+		queue = queue[1:]
+		if info := e[ns]; info != nil {
+			used.Add(util.ResourceList(info.Used))
+		}
+		for _, info := range e {
+			if info.Parent == ns && !visited.Has(info.Namespace) {
+				visited.Insert(info.Namespace)
+				queue = append(queue, info.Namespace)
+			}
+		}
+	}
+	return used
+}
+
+// usedOverMaxWith checks whether the podRequest exceeds the max of the quota of the
+// namespace or of any of its ancestors, taking the usage of their subtrees into account.
+func (e ElasticQuotaInfos) usedOverMaxWith(namespace string, podRequest *framework.Resource) bool {
+	return e.quotaOverMaxWith(namespace, podRequest) != nil
+}
+
+// quotaOverMaxWith returns the lowest quota on the path of the namespace whose max is
+// exceeded by the podRequest, or nil if there is none.
+func (e ElasticQuotaInfos) quotaOverMaxWith(namespace string, podRequest *framework.Resource) *ElasticQuotaInfo {
+	for _, info := range e.path(namespace) {
+		if info.Max != nil && cmp2(podRequest, e.subtreeUsed(info.Namespace), info.Max, UpperBoundOfMax) {
+			return info
+		}
+	}
+	return nil
+}
+
+// usedOverMin checks whether the usage of the subtree of the quota of the namespace exceeds its min.
+func (e ElasticQuotaInfos) usedOverMin(namespace string) bool {
+	info := e[namespace]
+	if info == nil {
+		return false
+	}
+	if info.Min == nil {
+		return true
+	}
+	return cmp(e.subtreeUsed(namespace), info.Min, LowerBoundOfMin)
+}
+
+// guaranteedLevel returns the index in the path of the namespace of the lowest quota that
+// can take the podRequest within its min, i.e. the level of the tree at which a pod is
+// guaranteed its resources. It returns -1 if there is none, which means that the pod can
+// only borrow resources.
+func (e ElasticQuotaInfos) guaranteedLevel(namespace string, podRequest *framework.Resource) int {
+	for i, info := range e.path(namespace) {
+		if info.Min != nil && !cmp2(podRequest, e.subtreeUsed(info.Namespace), info.Min, LowerBoundOfMin) {
+			return i
+		}
+	}
+	return -1
+}
+
+// borrowingFrom returns true if the pods of the victim namespace use resources that are
+// guaranteed to the pods of the preemptor namespace at the given level of its path, so
+// that the preemptor may reclaim them. This is the case if the victim's quota is in a
+// subtree that uses more than its min, and that subtree is a sibling of a quota on the
+// path of the preemptor at or above the level, or another top-level quota.
+func (e ElasticQuotaInfos) borrowingFrom(namespace string, level int, victimNamespace string) bool {
+	if level < 0 {
+		return false
+	}
+	preemptorPath := e.path(namespace)
+	victimPath := e.path(victimNamespace)
+	if len(victimPath) == 0 {
+		return false
+	}
+	// The subtree the victim borrows in is its top-level quota, unless both share an ancestor.
+	borrower := victimPath[len(victimPath)-1]
+	for i, info := range victimPath {
+		if j := indexOf(preemptorPath, info.Namespace); j >= 0 {
+			// The victim is in the quota of the preemptor or in one of its ancestors,
+			// or they only share an ancestor below the level the preemptor is guaranteed at.
+			if i == 0 || j < level {
+				return false
+			}
+			borrower = victimPath[i-1]
+			break
+		}
+	}
+	return e.usedOverMin(borrower.Namespace)
+}
+
+// fairShares returns the fair share of each quota, given the podRequest of a pod of the namespace
+// and the capacity of the cluster. See util.GetElasticQuotaFairShares.
+func (e ElasticQuotaInfos) fairShares(namespace string, podRequest, capacity *framework.Resource) map[string]map[v1.ResourceName]int64 {
+	quotas := make(map[string]*util.ElasticQuotaShare, len(e))
+	for ns, info := range e {
+		quotas[ns] = &util.ElasticQuotaShare{
+			Parent: info.Parent,
+			Weight: info.Weight,
+			Min:    quantities(info.Min),
+			Max:    quantities(info.Max),
+			Used:   quantities(e.subtreeUsed(ns)),
+		}
+	}
+	// The pod is a demand of its quota and of all its ancestors.
+	for _, info := range e.path(namespace) {
+		for name, value := range quantities(podRequest) {
+			quotas[info.Namespace].Used[name] += value
+		}
+	}
+	return util.GetElasticQuotaFairShares(quotas, quantities(capacity))
+}
+
+// overFairShare checks whether the usage of the subtree of the quota of the namespace exceeds
+// its fair share in any resource.
+func (e ElasticQuotaInfos) overFairShare(namespace string, fairShares map[string]map[v1.ResourceName]int64) bool {
+	fairShare, ok := fairShares[namespace]
+	if !ok {
+		return false
+	}
+	used := quantities(e.subtreeUsed(namespace))
+	for name, share := range fairShare {
+		if used[name] > share {
+			return true
+		}
+	}
+	return false
+}
+
+// withinFairShareWith checks whether the podRequest fits within the fair share of the quota of
+// the namespace, and of each of its ancestors, in every resource.
+func (e ElasticQuotaInfos) withinFairShareWith(namespace string, podRequest *framework.Resource, fairShares map[string]map[v1.ResourceName]int64) bool {
+	path := e.path(namespace)
+	if len(path) == 0 {
+		return false
+	}
+	request := quantities(podRequest)
+	for _, info := range path {
+		fairShare, ok := fairShares[info.Namespace]
+		if !ok {
+			return false
+		}
+		used := quantities(e.subtreeUsed(info.Namespace))
+		for name, share := range fairShare {
+			if used[name]+request[name] > share {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// quantities returns the quantities of the resources, or nil if resource is nil.
+func quantities(resource *framework.Resource) map[v1.ResourceName]int64 {
+	if resource == nil {
+		return nil
+	}
+	q := map[v1.ResourceName]int64{
+		v1.ResourceCPU:              resource.MilliCPU,
+		v1.ResourceMemory:           resource.Memory,
+		v1.ResourceEphemeralStorage: resource.EphemeralStorage,
+	}
+	for name, value := range resource.ScalarResources {
+		q[name] = value
+	}
+	return q
+}
+
+func indexOf(path []*ElasticQuotaInfo, namespace string) int {
+	for i, info := range path {
+		if info.Namespace == namespace {
+			return i
+		}
+	}
+	return -1
+}
+
+// namespaceQuotas maps the namespaces selected by the namespace selector of an ElasticQuota to
+// the namespace of that quota, i.e. its key in ElasticQuotaInfos. A namespace covered by several
+// quotas maps to the empty string. The other namespaces are not in the map: they are covered by
+// the quota in the namespace itself, if any.
+type namespaceQuotas map[string]string
+
+// quotaOf returns the key of the quota that covers the namespace.
+func (n namespaceQuotas) quotaOf(namespace string) string {
+	if quota, ok := n[namespace]; ok {
+		return quota
+	}
+	return namespace
+}
+
+// conflicted returns true if the namespace is covered by several quotas.
+func (n namespaceQuotas) conflicted(namespace string) bool {
+	quota, ok := n[namespace]
+	return ok && quota == ""
+}
+
 // ElasticQuotaInfo is a wrapper to a ElasticQuota with information.
 // Each namespace can only have one ElasticQuota.
 type ElasticQuotaInfo struct {
 	Namespace string
-	pods      sets.String
-	Min       *framework.Resource
-	Max       *framework.Resource
-	Used      *framework.Resource
+	// Parent is the namespace of the parent quota, empty for a top-level quota.
+	Parent string
+	pods   sets.String
+	Min    *framework.Resource
+	Max    *framework.Resource
+	Used   *framework.Resource
+	// Weight is the share of the quota in the resources lent by its siblings, with fair sharing.
+	Weight int64
+	// selector selects the namespaces the quota applies to besides its own namespace, nil if none.
+	selector labels.Selector
 }
 
 func newElasticQuotaInfo(namespace string, min, max, used v1.ResourceList) *ElasticQuotaInfo {
@@ -133,7 +365,10 @@ func (e *ElasticQuotaInfo) usedOverMin() bool {
 func (e *ElasticQuotaInfo) clone() *ElasticQuotaInfo {
 	newEQInfo := &ElasticQuotaInfo{
 		Namespace: e.Namespace,
+		Parent:    e.Parent,
 		pods:      sets.NewString(),
+		Weight:    e.Weight,
+		selector:  e.selector,
 	}
 
 	if e.Min != nil {
@@ -630,3 +630,135 @@ func TestNewElasticQuotaInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestElasticQuotaTree(t *testing.T) {
+	// dept-a (min 100, max 120)
+	// ├── a1 (min 50, used 80)
+	// └── a2 (min 50, used 0)
+	// dept-b (min 100, used 110)
+	elasticQuotaInfos := ElasticQuotaInfos{
+		"dept-a": {
+			Namespace: "dept-a",
+			Min:       &framework.Resource{Memory: 100},
+			Max:       &framework.Resource{Memory: 120},
+			Used:      &framework.Resource{},
+		},
+		"a1": {
+			Namespace: "a1",
+			Parent:    "dept-a",
+			Min:       &framework.Resource{Memory: 50},
+			Used:      &framework.Resource{Memory: 80},
+		},
+		"a2": {
+			Namespace: "a2",
+			Parent:    "dept-a",
+			Min:       &framework.Resource{Memory: 50},
+			Used:      &framework.Resource{},
+		},
+		"dept-b": {
+			Namespace: "dept-b",
+			Min:       &framework.Resource{Memory: 100},
+			Used:      &framework.Resource{Memory: 110},
+		},
+	}
+
+	t.Run("subtree usage", func(t *testing.T) {
+		for ns, want := range map[string]int64{"dept-a": 80, "a1": 80, "a2": 0, "dept-b": 110} {
+			if got := elasticQuotaInfos.subtreeUsed(ns).Memory; got != want {
+				t.Errorf("%v: expected %v, got %v", ns, want, got)
+			}
+		}
+	})
+
+	t.Run("max of the ancestors", func(t *testing.T) {
+		if elasticQuotaInfos.usedOverMaxWith("a2", &framework.Resource{Memory: 30}) {
+			t.Errorf("expected 30 to fit in the max of dept-a")
+		}
+		if !elasticQuotaInfos.usedOverMaxWith("a2", &framework.Resource{Memory: 50}) {
+			t.Errorf("expected 50 to exceed the max of dept-a")
+		}
+	})
+
+	t.Run("min of the top-level quotas", func(t *testing.T) {
+		if elasticQuotaInfos.aggregatedUsedOverMinWith(framework.Resource{Memory: 10}) {
+			t.Errorf("expected 10 to fit in the min of the top-level quotas")
+		}
+		if !elasticQuotaInfos.aggregatedUsedOverMinWith(framework.Resource{Memory: 20}) {
+			t.Errorf("expected 20 to exceed the min of the top-level quotas")
+		}
+	})
+
+	t.Run("guaranteed level", func(t *testing.T) {
+		request := &framework.Resource{Memory: 10}
+		for ns, want := range map[string]int{"a1": 1, "a2": 0, "dept-b": -1} {
+			if got := elasticQuotaInfos.guaranteedLevel(ns, request); got != want {
+				t.Errorf("%v: expected %v, got %v", ns, want, got)
+			}
+		}
+	})
+
+	tests := []struct {
+		name            string
+		namespace       string
+		level           int
+		victimNamespace string
+		expected        bool
+	}{
+		{
+			name:            "another department over its min",
+			namespace:       "a1",
+			level:           1,
+			victimNamespace: "dept-b",
+			expected:        true,
+		},
+		{
+			name:            "sibling over its min borrows within the department",
+			namespace:       "a2",
+			level:           0,
+			victimNamespace: "a1",
+			expected:        true,
+		},
+		{
+			name:            "sibling within its min",
+			namespace:       "a1",
+			level:           1,
+			victimNamespace: "a2",
+			expected:        false,
+		},
+		{
+			name:            "sibling below the guaranteed level",
+			namespace:       "a2",
+			level:           2,
+			victimNamespace: "a1",
+			expected:        false,
+		},
+		{
+			name:            "ancestor of the preemptor",
+			namespace:       "a1",
+			level:           1,
+			victimNamespace: "dept-a",
+			expected:        false,
+		},
+		{
+			name:            "same quota",
+			namespace:       "a1",
+			level:           0,
+			victimNamespace: "a1",
+			expected:        false,
+		},
+		{
+			name:            "preemptor not guaranteed at any level",
+			namespace:       "dept-b",
+			level:           -1,
+			victimNamespace: "a1",
+			expected:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := elasticQuotaInfos.borrowingFrom(tt.namespace, tt.level, tt.victimNamespace); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
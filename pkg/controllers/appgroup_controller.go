@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+
+	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+)
+
+// AppGroupReconciler reconciles an AppGroup object: it sorts the workloads of the group with its topology
+// sorting algorithm into the topology order the TopologicalSort plugin sorts pods with.
+type AppGroupReconciler struct {
+	recorder record.EventRecorder
+
+	client.Client
+	Scheme  *runtime.Scheme
+	Workers int
+}
+
+// +kubebuilder:rbac:groups=appgroup.diktyo.x-k8s.io,resources=appgroups,verbs=get;list;watch;update;patch
+
+func (r *AppGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.Info("reconciling")
+	ag := &agv1alpha1.AppGroup{}
+	if err := r.Get(ctx, req.NamespacedName, ag); err != nil {
+		if apierrs.IsNotFound(err) {
+			log.V(5).Info("AppGroup has been deleted")
+			return ctrl.Result{}, nil
+		}
+		log.V(3).Error(err, "Unable to retrieve AppGroup")
+		return ctrl.Result{}, err
+	}
+
+	podList := &v1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(ag.Namespace), client.MatchingLabels{agv1alpha1.AppGroupLabel: ag.Name}); err != nil {
+		log.Error(err, "List pods for AppGroup failed")
+		return ctrl.Result{}, err
+	}
+
+	agCopy := ag.DeepCopy()
+	agCopy.Status.RunningWorkloads = 0
+	for _, pod := range podList.Items {
+		if pod.Status.Phase == v1.PodRunning {
+			agCopy.Status.RunningWorkloads++
+		}
+	}
+	if agCopy.Status.ScheduleStartTime.IsZero() && len(podList.Items) != 0 {
+		agCopy.Status.ScheduleStartTime = metav1.Now()
+	}
+
+	topologyOrder, err := computeTopologyOrder(ag)
+	orderChanged := err == nil && !apiequality.Semantic.DeepEqual(topologyOrder, ag.Status.TopologyOrder)
+	if err != nil {
+		// The order is kept until the spec of the AppGroup is fixed.
+		r.recorder.Event(ag, v1.EventTypeWarning, "TopologySortFailed", err.Error())
+	} else if orderChanged {
+		agCopy.Status.TopologyOrder = topologyOrder
+		agCopy.Status.TopologyCalculationTime = metav1.Now()
+	}
+
+	if apiequality.Semantic.DeepEqual(agCopy.Status, ag.Status) {
+		return ctrl.Result{}, nil
+	}
+	// The status of an AppGroup is not a subresource.
+	if err := r.Update(ctx, agCopy); err != nil {
+		return ctrl.Result{}, err
+	}
+	if orderChanged {
+		r.recorder.Event(ag, v1.EventTypeNormal, "TopologyOrderUpdated", fmt.Sprintf("AppGroup %s sorted with %s", req.NamespacedName, ag.Spec.TopologySortingAlgorithm))
+	}
+	return ctrl.Result{}, nil
+}
+
+// computeTopologyOrder sorts the workloads of the AppGroup, and returns their index in the order, starting at 1,
+// sorted by workload selector so that the TopologicalSort plugin can binary search them.
+func computeTopologyOrder(ag *agv1alpha1.AppGroup) (agv1alpha1.AppGroupTopologyList, error) {
+	order, err := networkawareutil.TopologicalSort(ag.Spec.TopologySortingAlgorithm, networkawareutil.GetDependencyGraph(ag))
+	if err != nil {
+		return nil, err
+	}
+
+	// A dependency that is not a workload of the group is only known by its reference in the dependencies.
+	workloads := make(map[string]agv1alpha1.AppGroupWorkloadInfo, len(order))
+	for _, w := range ag.Spec.Workloads {
+		for _, dependency := range w.Dependencies {
+			workloads[dependency.Workload.Selector] = dependency.Workload
+		}
+	}
+	for _, w := range ag.Spec.Workloads {
+		workloads[w.Workload.Selector] = w.Workload
+	}
+
+	topologyOrder := make(agv1alpha1.AppGroupTopologyList, 0, len(order))
+	for i, selector := range order {
+		topologyOrder = append(topologyOrder, agv1alpha1.AppGroupTopologyInfo{
+			Workload: workloads[selector],
+			Index:    int32(i + 1),
+		})
+	}
+	sort.Sort(networkawareutil.ByWorkloadSelector(topologyOrder))
+	return topologyOrder, nil
+}
+
+func (r *AppGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("AppGroupController")
+	return ctrl.NewControllerManagedBy(mgr).
+		Watches(&v1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.podToAppGroup),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return len(obj.GetLabels()[agv1alpha1.AppGroupLabel]) != 0
+			}))).
+		For(&agv1alpha1.AppGroup{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
+		Complete(r)
+}
+
+// podToAppGroup enqueues the AppGroup of a pod, so that its running workloads are counted.
+func (r *AppGroupReconciler) podToAppGroup(ctx context.Context, obj client.Object) []ctrl.Request {
+	agName := obj.GetLabels()[agv1alpha1.AppGroupLabel]
+	if len(agName) == 0 {
+		return nil
+	}
+	return []ctrl.Request{{
+		NamespacedName: types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      agName,
+		}}}
+}
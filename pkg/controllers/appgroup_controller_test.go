@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	testutil "sigs.k8s.io/scheduler-plugins/test/integration"
+
+	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+)
+
+func TestAppGroupController(t *testing.T) {
+	workload := func(selector string) agv1alpha1.AppGroupWorkloadInfo {
+		return agv1alpha1.AppGroupWorkloadInfo{Kind: "Deployment", Name: selector, Selector: selector, APIVersion: "apps/v1", Namespace: "default"}
+	}
+	// p1 -> p2 -> p3, p1 -> p3
+	spec := func(algorithm string) agv1alpha1.AppGroupSpec {
+		return agv1alpha1.AppGroupSpec{
+			NumMembers:               3,
+			TopologySortingAlgorithm: algorithm,
+			Workloads: agv1alpha1.AppGroupWorkloadList{
+				{Workload: workload("p1"), Dependencies: agv1alpha1.DependenciesList{{Workload: workload("p2")}, {Workload: workload("p3")}}},
+				{Workload: workload("p2"), Dependencies: agv1alpha1.DependenciesList{{Workload: workload("p3")}}},
+				{Workload: workload("p3")},
+			},
+		}
+	}
+	pod := func(name string, phase v1.PodPhase) *v1.Pod {
+		p := testutil.MakePod("default", name).Phase(phase).Obj()
+		p.Labels = map[string]string{agv1alpha1.AppGroupLabel: "ag"}
+		return p
+	}
+
+	cases := []struct {
+		name                 string
+		agName               string
+		spec                 agv1alpha1.AppGroupSpec
+		pods                 []*v1.Pod
+		wantRunningWorkloads int32
+		wantOrder            []string
+		wantScheduleStart    bool
+	}{
+		{
+			name:      "AppGroup not found",
+			agName:    "ag1",
+			spec:      spec(agv1alpha1.AppGroupKahnSort),
+			wantOrder: nil,
+		},
+		{
+			name:      "AppGroup without pods sorted with KahnSort",
+			agName:    "ag",
+			spec:      spec(agv1alpha1.AppGroupKahnSort),
+			wantOrder: []string{"p1", "p2", "p3"},
+		},
+		{
+			name:      "AppGroup sorted with ReverseKahn",
+			agName:    "ag",
+			spec:      spec(agv1alpha1.AppGroupReverseKahn),
+			wantOrder: []string{"p3", "p2", "p1"},
+		},
+		{
+			name:                 "AppGroup with running and pending pods",
+			agName:               "ag",
+			spec:                 spec(agv1alpha1.AppGroupTarjanSort),
+			pods:                 []*v1.Pod{pod("pod1", v1.PodRunning), pod("pod2", v1.PodRunning), pod("pod3", v1.PodPending)},
+			wantRunningWorkloads: 2,
+			wantOrder:            []string{"p1", "p2", "p3"},
+			wantScheduleStart:    true,
+		},
+		{
+			name:      "AppGroup with an unknown algorithm keeps no order",
+			agName:    "ag",
+			spec:      spec("Unknown"),
+			wantOrder: nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			ag := testutil.MakeAppGroup("default", "ag").Spec(c.spec).Obj()
+			controller, kubeClient := setUpAG(ctx, t, ag, c.pods)
+			key := types.NamespacedName{Namespace: "default", Name: c.agName}
+			if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatal(err)
+			}
+			got := &agv1alpha1.AppGroup{}
+			if err := kubeClient.Get(ctx, types.NamespacedName{Namespace: "default", Name: "ag"}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.RunningWorkloads != c.wantRunningWorkloads {
+				t.Errorf("want %v running workloads, got %v", c.wantRunningWorkloads, got.Status.RunningWorkloads)
+			}
+			if got.Status.ScheduleStartTime.IsZero() == c.wantScheduleStart {
+				t.Errorf("want schedule start time set %v, got %v", c.wantScheduleStart, got.Status.ScheduleStartTime)
+			}
+			if len(got.Status.TopologyOrder) != len(c.wantOrder) {
+				t.Fatalf("want topology order %v, got %v", c.wantOrder, got.Status.TopologyOrder)
+			}
+			for i, selector := range c.wantOrder {
+				if index := topologyIndex(got.Status.TopologyOrder, selector); index != int32(i+1) {
+					t.Errorf("want %v at index %v, got %v", selector, i+1, index)
+				}
+			}
+		})
+	}
+}
+
+func topologyIndex(order agv1alpha1.AppGroupTopologyList, selector string) int32 {
+	for _, info := range order {
+		if info.Workload.Selector == selector {
+			return info.Index
+		}
+	}
+	return 0
+}
+
+func setUpAG(ctx context.Context, t *testing.T, ag *agv1alpha1.AppGroup, pods []*v1.Pod) (*AppGroupReconciler, client.WithWatch) {
+	s := scheme.Scheme
+	utilruntime.Must(agv1alpha1.AddToScheme(s))
+
+	client := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(ag).
+		Build()
+	for _, pod := range pods {
+		if err := client.Create(ctx, pod); err != nil {
+			t.Fatal("setup controller", err)
+		}
+	}
+	controller := &AppGroupReconciler{
+		Client:   client,
+		Scheme:   s,
+		recorder: record.NewFakeRecorder(10),
+	}
+	return controller, client
+}
@@ -19,21 +19,33 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	quota "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
 type ElasticQuotaReconciler struct {
@@ -42,11 +54,15 @@ type ElasticQuotaReconciler struct {
 	client.Client
 	Scheme  *runtime.Scheme
 	Workers int
+	// FairSharing reports the fair share of each quota in its status.
+	FairSharing bool
 }
 
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=elasticquota,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=elasticquota/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=elasticquota/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 func (r *ElasticQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := log.FromContext(ctx)
 	log.Info("reconciling")
@@ -67,24 +83,52 @@ func (r *ElasticQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	}
 
 	eq := &eqList.Items[0]
-	used, err := r.computeElasticQuotaUsed(ctx, req.Namespace, eq)
+	namespaces, conflicts, err := r.elasticQuotaNamespaces(ctx, eq)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-
-	// Ignore this loop if the usage value has not changed
-	if apiequality.Semantic.DeepEqual(used, eq.Status.Used) {
-		return ctrl.Result{}, nil
+	used, pending, err := r.computeElasticQuotaUsed(ctx, namespaces, eq)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	lent, err := r.computeElasticQuotaLent(ctx, eq, used)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
 	// create a usage object that is based on the elastic quota version that will handle updates
 	// by default, we set used to the current status
 	newEQ := eq.DeepCopy()
 	newEQ.Status.Used = used
+	newEQ.Status.Borrowed = computeElasticQuotaBorrowed(eq, used)
+	newEQ.Status.Lent = lent
+	newEQ.Status.PendingRequests = pending
+	newEQ.Status.FairShare = nil
+	if r.FairSharing {
+		if newEQ.Status.FairShare, err = r.computeElasticQuotaFairShare(ctx, eq); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	setNamespaceConflictCondition(newEQ, conflicts)
+	setUsageConditions(newEQ)
+
+	// Ignore this loop if the usage value and the conditions have not changed
+	if apiequality.Semantic.DeepEqual(newEQ.Status, eq.Status) {
+		return ctrl.Result{}, nil
+	}
+
 	if err = r.patchElasticQuota(ctx, eq, newEQ); err != nil {
 		return ctrl.Result{}, err
 	}
 	r.recorder.Event(eq, v1.EventTypeNormal, "Synced", fmt.Sprintf("Elastic Quota %s synced successfully", req.NamespacedName))
+	wasBorrowing := meta.IsStatusConditionTrue(eq.Status.Conditions, schedv1alpha1.ElasticQuotaOverMin)
+	if borrowing := meta.IsStatusConditionTrue(newEQ.Status.Conditions, schedv1alpha1.ElasticQuotaOverMin); borrowing != wasBorrowing {
+		if borrowing {
+			r.recorder.Event(eq, v1.EventTypeNormal, "StartedBorrowing", fmt.Sprintf("Elastic Quota %s started borrowing %s", req.NamespacedName, formatResourceList(newEQ.Status.Borrowed)))
+		} else {
+			r.recorder.Event(eq, v1.EventTypeNormal, "StoppedBorrowing", fmt.Sprintf("Elastic Quota %s stopped borrowing", req.NamespacedName))
+		}
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -93,19 +137,430 @@ func (r *ElasticQuotaReconciler) patchElasticQuota(ctx context.Context, old, new
 	return r.Status().Patch(ctx, new, patch)
 }
 
-func (r *ElasticQuotaReconciler) computeElasticQuotaUsed(ctx context.Context, namespace string, eq *schedv1alpha1.ElasticQuota) (v1.ResourceList, error) {
-	used := newZeroUsed(eq)
-	podList := &v1.PodList{}
-	if err := r.List(ctx, podList, client.InNamespace(namespace)); err != nil {
+// elasticQuotaNamespaces returns the namespaces covered by the quota: its own namespace and the namespaces
+// matched by its namespace selector, unless they are also covered by another quota. Those are returned as
+// conflicts, and are not accounted for by any quota.
+func (r *ElasticQuotaReconciler) elasticQuotaNamespaces(ctx context.Context, eq *schedv1alpha1.ElasticQuota) ([]string, []string, error) {
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, eqList); err != nil {
+		return nil, nil, err
+	}
+	covered, conflicts, err := r.namespacesOfElasticQuotas(ctx, eqList.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := covered[eq.Namespace]; !ok {
+		return []string{eq.Namespace}, nil, nil
+	}
+	return sets.List(covered[eq.Namespace]), sets.List(conflicts[eq.Namespace]), nil
+}
+
+// namespacesOfElasticQuotas returns the namespaces covered by each of the quotas, keyed by the namespace of
+// the quota, and the namespaces each of them conflicts on with other quotas. See elasticQuotaNamespaces.
+func (r *ElasticQuotaReconciler) namespacesOfElasticQuotas(ctx context.Context, eqs []schedv1alpha1.ElasticQuota) (map[string]sets.Set[string], map[string]sets.Set[string], error) {
+	covered := make(map[string]sets.Set[string], len(eqs))
+	conflicts := make(map[string]sets.Set[string], len(eqs))
+	selectors := make(map[string]labels.Selector, len(eqs))
+	withSelector := false
+	for i := range eqs {
+		selector, err := util.GetElasticQuotaSelector(&eqs[i])
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Ignoring the invalid namespace selector of an elasticquota", "elasticquota", klog.KObj(&eqs[i]))
+		}
+		selectors[eqs[i].Namespace] = selector
+		withSelector = withSelector || selector != nil
+		covered[eqs[i].Namespace] = sets.New(eqs[i].Namespace)
+		conflicts[eqs[i].Namespace] = sets.New[string]()
+	}
+	// Without any selector, every quota covers its own namespace only.
+	if !withSelector {
+		return covered, conflicts, nil
+	}
+
+	nsList := &v1.NamespaceList{}
+	if err := r.List(ctx, nsList); err != nil {
+		return nil, nil, err
+	}
+	for i := range nsList.Items {
+		quotas := util.GetElasticQuotasOfNamespace(&nsList.Items[i], selectors)
+		for _, quota := range quotas {
+			if len(quotas) == 1 {
+				covered[quota].Insert(nsList.Items[i].Name)
+			} else {
+				conflicts[quota].Insert(nsList.Items[i].Name)
+			}
+		}
+	}
+	for quota := range covered {
+		covered[quota] = covered[quota].Difference(conflicts[quota])
+	}
+	return covered, conflicts, nil
+}
+
+// setNamespaceConflictCondition reports the namespaces of the quota that are also covered by other quotas.
+// The condition is only added once there is a conflict.
+func setNamespaceConflictCondition(eq *schedv1alpha1.ElasticQuota, conflicts []string) {
+	condition := metav1.Condition{
+		Type:               schedv1alpha1.ElasticQuotaNamespaceConflict,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: eq.Generation,
+		Reason:             schedv1alpha1.ElasticQuotaReasonNoConflict,
+		Message:            "Every namespace of the quota is covered by this quota only",
+	}
+	if len(conflicts) != 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = schedv1alpha1.ElasticQuotaReasonMatchedByMultipleQuotas
+		condition.Message = fmt.Sprintf("Namespaces %s are covered by more than one ElasticQuota, their pods are not accounted for and cannot be scheduled", strings.Join(conflicts, ", "))
+	} else if meta.FindStatusCondition(eq.Status.Conditions, condition.Type) == nil {
+		return
+	}
+	meta.SetStatusCondition(&eq.Status.Conditions, condition)
+}
+
+// computeElasticQuotaUsed returns the usage of the running pods of the quota, and the requests of its pods that
+// are not scheduled yet because they would exceed its max. Both include the ones of the child quotas.
+func (r *ElasticQuotaReconciler) computeElasticQuotaUsed(ctx context.Context, namespaces []string, eq *schedv1alpha1.ElasticQuota) (v1.ResourceList, v1.ResourceList, error) {
+	running, pendingPods, err := r.computeNamespacesRequests(ctx, namespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	used := quota.Add(newZeroUsed(eq), running)
+
+	// Roll up the usage of the child quotas, which already includes the usage of their own children.
+	children, err := r.childElasticQuotas(ctx, eq.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	var pending v1.ResourceList
+	for _, child := range children {
+		used = quota.Add(used, child.Status.Used)
+		if len(child.Status.PendingRequests) != 0 {
+			pending = quota.Add(pending, child.Status.PendingRequests)
+		}
+	}
+	// Only the pending pods that would take the quota over its max are blocked by it.
+	for _, request := range pendingPods {
+		if overMax(quota.Add(used, request), eq.Spec.Max) {
+			pending = quota.Add(pending, request)
+		}
+	}
+	return used, pending, nil
+}
+
+// overMax checks whether the usage exceeds the max of some resource.
+func overMax(used, max v1.ResourceList) bool {
+	for name, value := range max {
+		if usedValue, ok := used[name]; ok && usedValue.Cmp(value) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// computeNamespacesRequests returns the requests of the running pods of the namespaces, and the request of
+// each of their pending pods that are not bound yet.
+func (r *ElasticQuotaReconciler) computeNamespacesRequests(ctx context.Context, namespaces []string) (v1.ResourceList, []v1.ResourceList, error) {
+	var running v1.ResourceList
+	var pending []v1.ResourceList
+	for _, namespace := range namespaces {
+		podList := &v1.PodList{}
+		if err := r.List(ctx, podList, client.InNamespace(namespace)); err != nil {
+			return nil, nil, err
+		}
+
+		for _, p := range podList.Items {
+			if p.Status.Phase == v1.PodRunning {
+				running = quota.Add(running, computePodResourceRequest(&p))
+			} else if p.Status.Phase == v1.PodPending && len(p.Spec.NodeName) == 0 && p.DeletionTimestamp == nil {
+				pending = append(pending, computePodResourceRequest(&p))
+			}
+		}
+	}
+	return running, pending, nil
+}
+
+// computeElasticQuotaBorrowed returns the part of the usage of the quota above its min.
+func computeElasticQuotaBorrowed(eq *schedv1alpha1.ElasticQuota, used v1.ResourceList) v1.ResourceList {
+	var borrowed v1.ResourceList
+	min := resourceValues(eq.Spec.Min)
+	for name, value := range resourceValues(used) {
+		if over := value - min[name]; over > 0 {
+			if borrowed == nil {
+				borrowed = v1.ResourceList{}
+			}
+			borrowed[name] = resourceQuantity(name, over)
+		}
+	}
+	return borrowed
+}
+
+// computeElasticQuotaLent returns the part of the unused min of the quota that is borrowed by its siblings,
+// given its current usage and the usage last reported by its siblings. When the siblings borrow less than
+// the unused min of all of them, each one lends in proportion to its unused min.
+func (r *ElasticQuotaReconciler) computeElasticQuotaLent(ctx context.Context, eq *schedv1alpha1.ElasticQuota, used v1.ResourceList) (v1.ResourceList, error) {
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, eqList); err != nil {
+		return nil, err
+	}
+
+	quotas := sets.New[string]()
+	for _, item := range eqList.Items {
+		quotas.Insert(item.Namespace)
+	}
+	parentOf := func(item *schedv1alpha1.ElasticQuota) string {
+		if quotas.Has(item.Spec.Parent) {
+			return item.Spec.Parent
+		}
+		return ""
+	}
+
+	unused := make(map[v1.ResourceName]int64)
+	usedValues := resourceValues(used)
+	for name, value := range resourceValues(eq.Spec.Min) {
+		if free := value - usedValues[name]; free > 0 {
+			unused[name] = free
+		}
+	}
+	if len(unused) == 0 {
+		return nil, nil
+	}
+
+	totalUnused, totalBorrowed := make(map[v1.ResourceName]int64), make(map[v1.ResourceName]int64)
+	for i := range eqList.Items {
+		item := &eqList.Items[i]
+		if parentOf(item) != parentOf(eq) {
+			continue
+		}
+		min, itemUsed := resourceValues(item.Spec.Min), resourceValues(item.Status.Used)
+		if item.Namespace == eq.Namespace {
+			itemUsed = usedValues
+		}
+		for name := range unused {
+			if diff := min[name] - itemUsed[name]; diff > 0 {
+				totalUnused[name] += diff
+			} else {
+				totalBorrowed[name] -= diff
+			}
+		}
+	}
+
+	var lent v1.ResourceList
+	for name, free := range unused {
+		value := free
+		if totalBorrowed[name] < totalUnused[name] {
+			value = int64(float64(free) * float64(totalBorrowed[name]) / float64(totalUnused[name]))
+		}
+		if value > 0 {
+			if lent == nil {
+				lent = v1.ResourceList{}
+			}
+			lent[name] = resourceQuantity(name, value)
+		}
+	}
+	return lent, nil
+}
+
+// setUsageConditions reports whether the quota borrows resources, whether it uses all of its max of some
+// resource, and whether its spec is valid.
+func setUsageConditions(eq *schedv1alpha1.ElasticQuota) {
+	overMin := metav1.Condition{
+		Type:               schedv1alpha1.ElasticQuotaOverMin,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: eq.Generation,
+		Reason:             schedv1alpha1.ElasticQuotaReasonWithinMin,
+		Message:            "The usage of the quota is within its min",
+	}
+	if len(eq.Status.Borrowed) != 0 {
+		overMin.Status = metav1.ConditionTrue
+		overMin.Reason = schedv1alpha1.ElasticQuotaReasonBorrowing
+		overMin.Message = fmt.Sprintf("The quota borrows %s above its min", formatResourceList(eq.Status.Borrowed))
+	}
+	meta.SetStatusCondition(&eq.Status.Conditions, overMin)
+
+	atMax := metav1.Condition{
+		Type:               schedv1alpha1.ElasticQuotaAtMax,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: eq.Generation,
+		Reason:             schedv1alpha1.ElasticQuotaReasonBelowMax,
+		Message:            "The usage of the quota is below its max",
+	}
+	used := resourceValues(eq.Status.Used)
+	var maxed []string
+	for name, value := range resourceValues(eq.Spec.Max) {
+		if used[name] >= value {
+			maxed = append(maxed, string(name))
+		}
+	}
+	if len(maxed) != 0 {
+		sort.Strings(maxed)
+		atMax.Status = metav1.ConditionTrue
+		atMax.Reason = schedv1alpha1.ElasticQuotaReasonMaxReached
+		atMax.Message = fmt.Sprintf("The quota uses all of its max of %s, the pods requesting more of them cannot be scheduled", strings.Join(maxed, ", "))
+	}
+	meta.SetStatusCondition(&eq.Status.Conditions, atMax)
+
+	invalid := metav1.Condition{
+		Type:               schedv1alpha1.ElasticQuotaInvalid,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: eq.Generation,
+		Reason:             schedv1alpha1.ElasticQuotaReasonValid,
+		Message:            "The spec of the quota is valid",
+	}
+	var invalidNames []string
+	for name, min := range eq.Spec.Min {
+		if max, ok := eq.Spec.Max[name]; ok && min.Cmp(max) > 0 {
+			invalidNames = append(invalidNames, string(name))
+		}
+	}
+	if len(invalidNames) != 0 {
+		sort.Strings(invalidNames)
+		invalid.Status = metav1.ConditionTrue
+		invalid.Reason = schedv1alpha1.ElasticQuotaReasonMinGreaterThanMax
+		invalid.Message = fmt.Sprintf("The min of %s is greater than the max", strings.Join(invalidNames, ", "))
+	}
+	meta.SetStatusCondition(&eq.Status.Conditions, invalid)
+}
+
+// formatResourceList formats a resource list in the order of the resource names, e.g. "cpu: 1, memory: 1Gi".
+func formatResourceList(list v1.ResourceList) string {
+	names := quota.ResourceNames(list)
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		value := list[name]
+		parts = append(parts, fmt.Sprintf("%s: %s", name, value.String()))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// computeElasticQuotaFairShare returns the fair share of the quota, given the allocatable of the nodes and the
+// usage of all the quotas, computed from their pods rather than from the usage they last reported.
+func (r *ElasticQuotaReconciler) computeElasticQuotaFairShare(ctx context.Context, eq *schedv1alpha1.ElasticQuota) (v1.ResourceList, error) {
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, eqList); err != nil {
+		return nil, err
+	}
+	covered, _, err := r.namespacesOfElasticQuotas(ctx, eqList.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	quotas := make(map[string]*util.ElasticQuotaShare, len(eqList.Items))
+	for _, item := range eqList.Items {
+		running, _, err := r.computeNamespacesRequests(ctx, sets.List(covered[item.Namespace]))
+		if err != nil {
+			return nil, err
+		}
+		quotas[item.Namespace] = &util.ElasticQuotaShare{
+			Parent: item.Spec.Parent,
+			Weight: int64(item.Spec.Weight),
+			Min:    resourceValues(item.Spec.Min),
+			Max:    resourceValues(item.Spec.Max),
+			Used:   resourceValues(running),
+		}
+	}
+	// Roll up the usage of each quota to its ancestors, stopping at a cycle of parent references.
+	subtreeUsed := make(map[string]map[v1.ResourceName]int64, len(quotas))
+	for name := range quotas {
+		subtreeUsed[name] = make(map[v1.ResourceName]int64)
+	}
+	for name, q := range quotas {
+		visited := sets.New[string]()
+		for ancestor := name; !visited.Has(ancestor); ancestor = quotas[ancestor].Parent {
+			visited.Insert(ancestor)
+			for resourceName, value := range q.Used {
+				subtreeUsed[ancestor][resourceName] += value
+			}
+			if _, ok := quotas[quotas[ancestor].Parent]; !ok {
+				break
+			}
+		}
+	}
+	for name, q := range quotas {
+		q.Used = subtreeUsed[name]
+	}
+
+	nodeList := &v1.NodeList{}
+	if err := r.List(ctx, nodeList); err != nil {
+		return nil, err
+	}
+	var capacity v1.ResourceList
+	for _, node := range nodeList.Items {
+		capacity = quota.Add(capacity, node.Status.Allocatable)
+	}
+
+	fairShare := util.GetElasticQuotaFairShares(quotas, resourceValues(capacity))[eq.Namespace]
+	if len(fairShare) == 0 {
+		return nil, nil
+	}
+	result := make(v1.ResourceList, len(fairShare))
+	for name, value := range fairShare {
+		result[name] = resourceQuantity(name, value)
+	}
+	return result, nil
+}
+
+// resourceValues converts a resource list to the values the fair shares are computed with, i.e. milli-cores for cpu.
+func resourceValues(list v1.ResourceList) map[v1.ResourceName]int64 {
+	values := make(map[v1.ResourceName]int64, len(list))
+	for name, quantity := range list {
+		if name == v1.ResourceCPU {
+			values[name] = quantity.MilliValue()
+		} else {
+			values[name] = quantity.Value()
+		}
+	}
+	return values
+}
+
+// resourceQuantity converts a value of resourceValues back to a quantity.
+func resourceQuantity(name v1.ResourceName, value int64) resource.Quantity {
+	switch name {
+	case v1.ResourceCPU:
+		return *resource.NewMilliQuantity(value, resource.DecimalSI)
+	case v1.ResourceMemory, v1.ResourceEphemeralStorage:
+		return *resource.NewQuantity(value, resource.BinarySI)
+	default:
+		return *resource.NewQuantity(value, resource.DecimalSI)
+	}
+}
+
+// childElasticQuotas returns the quotas whose parent is the quota of the namespace.
+// A child that is also an ancestor of the namespace is skipped, so a cycle of parent
+// references does not add up the usage endlessly.
+func (r *ElasticQuotaReconciler) childElasticQuotas(ctx context.Context, namespace string) ([]schedv1alpha1.ElasticQuota, error) {
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, eqList); err != nil {
 		return nil, err
 	}
 
-	for _, p := range podList.Items {
-		if p.Status.Phase == v1.PodRunning {
-			used = quota.Add(used, computePodResourceRequest(&p))
+	parents := make(map[string]string, len(eqList.Items))
+	for _, eq := range eqList.Items {
+		parents[eq.Namespace] = eq.Spec.Parent
+	}
+	isAncestor := func(ancestor string) bool {
+		visited := sets.New[string]()
+		for ns := parents[namespace]; len(ns) != 0 && !visited.Has(ns); ns = parents[ns] {
+			if ns == ancestor {
+				return true
+			}
+			visited.Insert(ns)
 		}
+		return false
 	}
-	return used, nil
+
+	var children []schedv1alpha1.ElasticQuota
+	for _, eq := range eqList.Items {
+		if eq.Spec.Parent != namespace || eq.Namespace == namespace {
+			continue
+		}
+		if isAncestor(eq.Namespace) {
+			log.FromContext(ctx).Info("Ignoring the usage of a child elasticquota that is also an ancestor", "namespace", eq.Namespace, "parent", eq.Spec.Parent)
+			continue
+		}
+		children = append(children, eq)
+	}
+	return children, nil
 }
 
 // computePodResourceRequest returns a v1.ResourceList that covers the largest
@@ -171,8 +626,150 @@ func newZeroUsed(eq *schedv1alpha1.ElasticQuota) v1.ResourceList {
 func (r *ElasticQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.recorder = mgr.GetEventRecorderFor("ElasticQuotaController")
 	return ctrl.NewControllerManagedBy(mgr).
-		Watches(&v1.Pod{}, &handler.EnqueueRequestForObject{}).
+		Watches(&v1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.podToElasticQuotas)).
+		Watches(&v1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.namespaceToElasticQuotas)).
+		Watches(&schedv1alpha1.ElasticQuota{}, handler.EnqueueRequestsFromMapFunc(r.elasticQuotaToRelatives),
+			builder.WithPredicates(predicate.Funcs{UpdateFunc: r.elasticQuotaRelativesChanged})).
 		For(&schedv1alpha1.ElasticQuota{}).
 		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
 		Complete(r)
 }
+
+// elasticQuotaToRelatives enqueues the quotas that depend on a quota: its parent rolls up its usage, its siblings
+// report the resources they lend to it, and with fair sharing its children split its fair share.
+func (r *ElasticQuotaReconciler) elasticQuotaToRelatives(ctx context.Context, obj client.Object) []ctrl.Request {
+	eq, ok := obj.(*schedv1alpha1.ElasticQuota)
+	if !ok {
+		return nil
+	}
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, eqList); err != nil {
+		log.FromContext(ctx).Error(err, "Unable to list elasticquotas")
+		return r.elasticQuotaToParent(ctx, obj)
+	}
+
+	quotas := sets.New[string]()
+	for _, item := range eqList.Items {
+		quotas.Insert(item.Namespace)
+	}
+	parentOf := func(item *schedv1alpha1.ElasticQuota) string {
+		if quotas.Has(item.Spec.Parent) {
+			return item.Spec.Parent
+		}
+		return ""
+	}
+
+	var requests []ctrl.Request
+	for i := range eqList.Items {
+		item := &eqList.Items[i]
+		if item.Namespace == eq.Namespace {
+			continue
+		}
+		isParent := item.Namespace == parentOf(eq)
+		isSibling := parentOf(item) == parentOf(eq)
+		isChild := r.FairSharing && parentOf(item) == eq.Namespace
+		if !isParent && !isSibling && !isChild {
+			continue
+		}
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: item.Namespace,
+				Name:      item.Name,
+			}})
+	}
+	return requests
+}
+
+// elasticQuotaRelativesChanged filters the updates of a quota down to the ones its relatives depend on, so that
+// the status updates of the relatives, e.g. of the resources they lend, do not enqueue each other in turn.
+func (r *ElasticQuotaReconciler) elasticQuotaRelativesChanged(e event.UpdateEvent) bool {
+	oldEQ, ok := e.ObjectOld.(*schedv1alpha1.ElasticQuota)
+	if !ok {
+		return true
+	}
+	newEQ, ok := e.ObjectNew.(*schedv1alpha1.ElasticQuota)
+	if !ok {
+		return true
+	}
+	return !apiequality.Semantic.DeepEqual(oldEQ.Spec, newEQ.Spec) ||
+		!quota.Equals(oldEQ.Status.Used, newEQ.Status.Used) ||
+		!quota.Equals(oldEQ.Status.PendingRequests, newEQ.Status.PendingRequests) ||
+		(r.FairSharing && !quota.Equals(oldEQ.Status.FairShare, newEQ.Status.FairShare))
+}
+
+// elasticQuotaToParent enqueues the parent of a quota, so that the change of its usage is rolled up.
+// Reconcile only looks at the namespace of the request.
+func (r *ElasticQuotaReconciler) elasticQuotaToParent(ctx context.Context, obj client.Object) []ctrl.Request {
+	eq, ok := obj.(*schedv1alpha1.ElasticQuota)
+	if !ok || len(eq.Spec.Parent) == 0 {
+		return nil
+	}
+	return []ctrl.Request{{
+		NamespacedName: types.NamespacedName{
+			Namespace: eq.Spec.Parent,
+			Name:      eq.Name,
+		}}}
+}
+
+// podToElasticQuotas enqueues the quota of the namespace of a pod, and the quotas whose namespace selector
+// matches the namespace, which cover the pod or conflict over it.
+func (r *ElasticQuotaReconciler) podToElasticQuotas(ctx context.Context, obj client.Object) []ctrl.Request {
+	requests := []ctrl.Request{{
+		NamespacedName: types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		}}}
+
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, eqList); err != nil {
+		log.FromContext(ctx).Error(err, "Unable to list elasticquotas")
+		return requests
+	}
+	namespace := &v1.Namespace{}
+	for i := range eqList.Items {
+		eq := &eqList.Items[i]
+		if eq.Spec.NamespaceSelector == nil || eq.Namespace == obj.GetNamespace() {
+			continue
+		}
+		// Only get the namespace once there is a quota with a selector.
+		if len(namespace.Name) == 0 {
+			if err := r.Get(ctx, types.NamespacedName{Name: obj.GetNamespace()}, namespace); err != nil {
+				log.FromContext(ctx).Error(err, "Unable to get namespace", "namespace", obj.GetNamespace())
+				return requests
+			}
+		}
+		selector, err := util.GetElasticQuotaSelector(eq)
+		if err != nil || !selector.Matches(labels.Set(namespace.Labels)) {
+			continue
+		}
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: eq.Namespace,
+				Name:      eq.Name,
+			}})
+	}
+	return requests
+}
+
+// namespaceToElasticQuotas enqueues the quota of a namespace and all the quotas with a namespace selector,
+// since the namespace may have been matched by the selector before its labels changed.
+func (r *ElasticQuotaReconciler) namespaceToElasticQuotas(ctx context.Context, obj client.Object) []ctrl.Request {
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := r.List(ctx, eqList); err != nil {
+		log.FromContext(ctx).Error(err, "Unable to list elasticquotas")
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, eq := range eqList.Items {
+		if eq.Spec.NamespaceSelector == nil && eq.Namespace != obj.GetName() {
+			continue
+		}
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: eq.Namespace,
+				Name:      eq.Name,
+			}})
+	}
+	return requests
+}
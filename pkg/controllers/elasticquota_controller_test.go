@@ -20,14 +20,17 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	quota "k8s.io/apiserver/pkg/quota/v1"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -45,9 +48,13 @@ func TestElasticQuotaController_Run(t *testing.T) {
 	ctx := context.TODO()
 	cases := []struct {
 		name          string
+		namespaces    []*v1.Namespace
 		elasticQuotas []*v1alpha1.ElasticQuota
 		pods          []*v1.Pod
+		fairSharing   bool
 		want          []*v1alpha1.ElasticQuota
+		// wantConflicts are the namespaces of the quotas whose NamespaceConflict condition is true.
+		wantConflicts []string
 	}{
 		{
 			name: "no init Containers pod",
@@ -181,11 +188,145 @@ func TestElasticQuotaController_Run(t *testing.T) {
 					Used(testutil.MakeResourceList().CPU(0).Mem(0).GPU(0).Obj()).Obj(),
 			},
 		},
+		{
+			name: "usage of child quotas is rolled up to the parent",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t7-ns1", "t7-eq1").Parent("t7-dept").
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+				testutil.MakeEQ("t7-ns2", "t7-eq2").Parent("t7-dept").
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+				testutil.MakeEQ("t7-dept", "t7-eq-dept").
+					Min(testutil.MakeResourceList().CPU(6).Mem(10).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t7-ns1", "pod1").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakePod("t7-ns2", "pod2").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+				testutil.MakePod("t7-dept", "pod3").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(1).Mem(1).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t7-ns1", "t7-eq1").
+					Used(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakeEQ("t7-ns2", "t7-eq2").
+					Used(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+				testutil.MakeEQ("t7-dept", "t7-eq-dept").
+					Used(testutil.MakeResourceList().CPU(4).Mem(4).Obj()).Obj(),
+			},
+		},
+		{
+			name: "usage is not rolled up along a cycle of parents",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t8-ns1", "t8-eq1").Parent("t8-ns2").
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+				testutil.MakeEQ("t8-ns2", "t8-eq2").Parent("t8-ns1").
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t8-ns1", "pod1").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakePod("t8-ns2", "pod2").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t8-ns1", "t8-eq1").
+					Used(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakeEQ("t8-ns2", "t8-eq2").
+					Used(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+			},
+		},
+		{
+			name: "usage of the namespaces selected by a quota is accounted for",
+			namespaces: []*v1.Namespace{
+				makeNamespace("t9-tenant", nil),
+				makeNamespace("t9-ns1", map[string]string{"tenant": "t9"}),
+				makeNamespace("t9-ns2", map[string]string{"tenant": "t9"}),
+				makeNamespace("t9-other", nil),
+			},
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t9-tenant", "t9-eq").NamespaceSelector(map[string]string{"tenant": "t9"}).
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t9-tenant", "pod1").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(1).Mem(1).Obj()).Obj(),
+				testutil.MakePod("t9-ns1", "pod2").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakePod("t9-ns2", "pod3").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+				testutil.MakePod("t9-other", "pod4").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t9-tenant", "t9-eq").
+					Used(testutil.MakeResourceList().CPU(4).Mem(4).Obj()).Obj(),
+			},
+		},
+		{
+			name: "namespace selected by two quotas is rejected",
+			namespaces: []*v1.Namespace{
+				makeNamespace("t10-tenant", nil),
+				makeNamespace("t10-team", nil),
+				makeNamespace("t10-ns1", map[string]string{"tenant": "t10"}),
+				makeNamespace("t10-shared", map[string]string{"tenant": "t10", "team": "t10"}),
+			},
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t10-tenant", "t10-eq1").NamespaceSelector(map[string]string{"tenant": "t10"}).
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+				testutil.MakeEQ("t10-team", "t10-eq2").NamespaceSelector(map[string]string{"team": "t10"}).
+					Min(testutil.MakeResourceList().CPU(3).Mem(5).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t10-ns1", "pod1").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakePod("t10-shared", "pod2").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(2).Mem(1).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t10-tenant", "t10-eq1").
+					Used(testutil.MakeResourceList().CPU(1).Mem(2).Obj()).Obj(),
+				testutil.MakeEQ("t10-team", "t10-eq2").
+					Used(testutil.MakeResourceList().CPU(0).Mem(0).Obj()).Obj(),
+			},
+			wantConflicts: []string{"t10-tenant", "t10-team"},
+		},
+		{
+			name: "fair share of the quotas is reported with fair sharing",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t11-ns1", "t11-eq1").Weight(3).
+					Min(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+				testutil.MakeEQ("t11-ns2", "t11-eq2").Weight(1).
+					Min(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+				testutil.MakeEQ("t11-ns3", "t11-eq3").
+					Min(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t11-ns1", "pod1").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(3).Obj()).Obj(),
+				testutil.MakePod("t11-ns2", "pod2").Phase(v1.PodRunning).
+					Container(testutil.MakeResourceList().CPU(3).Obj()).Obj(),
+			},
+			fairSharing: true,
+			want: []*v1alpha1.ElasticQuota{
+				// The 4 cpus lent by t11-ns3 are split 3:1 between t11-ns1 and t11-ns2 on top of their min.
+				testutil.MakeEQ("t11-ns1", "t11-eq1").
+					Used(testutil.MakeResourceList().CPU(3).Obj()).
+					FairShare(testutil.MakeResourceList().CPU(5).Obj()).Obj(),
+				testutil.MakeEQ("t11-ns2", "t11-eq2").
+					Used(testutil.MakeResourceList().CPU(3).Obj()).
+					FairShare(testutil.MakeResourceList().CPU(3).Obj()).Obj(),
+				testutil.MakeEQ("t11-ns3", "t11-eq3").
+					Used(testutil.MakeResourceList().CPU(0).Obj()).
+					FairShare(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+			},
+		},
 	}
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			controller, kClient := setUpEQ(ctx, t, c.elasticQuotas, c.pods)
+			controller, kClient := setUpEQ(ctx, t, c.namespaces, c.elasticQuotas, c.pods)
+			controller.FairSharing = c.fairSharing
 			for _, pod := range c.pods {
 				if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{
 					Namespace: pod.Namespace,
@@ -219,6 +360,13 @@ func TestElasticQuotaController_Run(t *testing.T) {
 					if !quota.Equals(eq.Status.Used, v.Status.Used) {
 						return false, fmt.Errorf("%v: want %v, got %v", c.name, v.Status.Used, eq.Status.Used)
 					}
+					if !quota.Equals(eq.Status.FairShare, v.Status.FairShare) {
+						return false, fmt.Errorf("%v: want fair share %v, got %v", c.name, v.Status.FairShare, eq.Status.FairShare)
+					}
+					wantConflict := sets.New(c.wantConflicts...).Has(eq.Namespace)
+					if got := meta.IsStatusConditionTrue(eq.Status.Conditions, v1alpha1.ElasticQuotaNamespaceConflict); got != wantConflict {
+						return false, fmt.Errorf("%v: want %v condition %v, got %v", c.name, v1alpha1.ElasticQuotaNamespaceConflict, wantConflict, got)
+					}
 				}
 				return true, nil
 			})
@@ -230,8 +378,156 @@ func TestElasticQuotaController_Run(t *testing.T) {
 	}
 }
 
+func TestElasticQuotaController_Status(t *testing.T) {
+	ctx := context.TODO()
+	cases := []struct {
+		name          string
+		elasticQuotas []*v1alpha1.ElasticQuota
+		pods          []*v1.Pod
+		want          []*v1alpha1.ElasticQuota
+		// wantConditions are the conditions of the quotas that are true, by namespace.
+		wantConditions map[string][]string
+		wantEvents     []string
+	}{
+		{
+			name: "borrowed, lent and pending resources",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t1-ns1", "t1-eq1").
+					Min(testutil.MakeResourceList().CPU(2).Obj()).
+					Max(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+				testutil.MakeEQ("t1-ns2", "t1-eq2").
+					Min(testutil.MakeResourceList().CPU(4).Obj()).
+					Max(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t1-ns1", "pod1").Phase(v1.PodRunning).Node("node-a").
+					Container(testutil.MakeResourceList().CPU(3).Obj()).Obj(),
+				testutil.MakePod("t1-ns1", "pod2").Phase(v1.PodPending).
+					Container(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+				testutil.MakePod("t1-ns2", "pod3").Phase(v1.PodRunning).Node("node-a").
+					Container(testutil.MakeResourceList().CPU(1).Obj()).Obj(),
+				// The pod fits under the max, so it is not blocked by the quota.
+				testutil.MakePod("t1-ns2", "pod4").Phase(v1.PodPending).
+					Container(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t1-ns1", "t1-eq1").
+					Used(testutil.MakeResourceList().CPU(3).Obj()).
+					Borrowed(testutil.MakeResourceList().CPU(1).Obj()).
+					PendingRequests(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+				// Only 1 of the 3 unused cpus of the min is borrowed.
+				testutil.MakeEQ("t1-ns2", "t1-eq2").
+					Used(testutil.MakeResourceList().CPU(1).Obj()).
+					Lent(testutil.MakeResourceList().CPU(1).Obj()).Obj(),
+			},
+			wantConditions: map[string][]string{
+				"t1-ns1": {v1alpha1.ElasticQuotaOverMin},
+			},
+			wantEvents: []string{"StartedBorrowing"},
+		},
+		{
+			name: "quota at its max",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t2-ns1", "t2-eq1").
+					Min(testutil.MakeResourceList().CPU(1).Mem(4).Obj()).
+					Max(testutil.MakeResourceList().CPU(2).Mem(8).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{
+				testutil.MakePod("t2-ns1", "pod1").Phase(v1.PodRunning).Node("node-a").
+					Container(testutil.MakeResourceList().CPU(2).Mem(2).Obj()).Obj(),
+			},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t2-ns1", "t2-eq1").
+					Used(testutil.MakeResourceList().CPU(2).Mem(2).Obj()).
+					Borrowed(testutil.MakeResourceList().CPU(1).Obj()).Obj(),
+			},
+			wantConditions: map[string][]string{
+				"t2-ns1": {v1alpha1.ElasticQuotaOverMin, v1alpha1.ElasticQuotaAtMax},
+			},
+			wantEvents: []string{"StartedBorrowing"},
+		},
+		{
+			name: "min greater than max",
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t3-ns1", "t3-eq1").
+					Min(testutil.MakeResourceList().CPU(4).Obj()).
+					Max(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+			},
+			pods: []*v1.Pod{},
+			want: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("t3-ns1", "t3-eq1").
+					Used(testutil.MakeResourceList().CPU(0).Obj()).Obj(),
+			},
+			wantConditions: map[string][]string{
+				"t3-ns1": {v1alpha1.ElasticQuotaInvalid},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			controller, kClient := setUpEQ(ctx, t, nil, c.elasticQuotas, c.pods)
+			for _, e := range c.elasticQuotas {
+				if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{
+					Namespace: e.Namespace,
+					Name:      e.Name,
+				}}); err != nil {
+					t.Errorf("reconcile: (%v)", err)
+				}
+			}
+			// Reconcile the quotas once more, so that they see the usage reported by each other.
+			for _, e := range c.elasticQuotas {
+				if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{
+					Namespace: e.Namespace,
+					Name:      e.Name,
+				}}); err != nil {
+					t.Errorf("reconcile: (%v)", err)
+				}
+			}
+
+			for _, v := range c.want {
+				eq := &v1alpha1.ElasticQuota{}
+				if err := kClient.Get(ctx, client.ObjectKeyFromObject(v), eq); err != nil {
+					t.Fatal(err)
+				}
+				for _, r := range []struct {
+					name      string
+					want, got v1.ResourceList
+				}{
+					{"used", v.Status.Used, eq.Status.Used},
+					{"borrowed", v.Status.Borrowed, eq.Status.Borrowed},
+					{"lent", v.Status.Lent, eq.Status.Lent},
+					{"pending requests", v.Status.PendingRequests, eq.Status.PendingRequests},
+				} {
+					if !quota.Equals(r.want, r.got) {
+						t.Errorf("%v: want %v %v, got %v", eq.Namespace, r.name, r.want, r.got)
+					}
+				}
+				wantConditions := sets.New(c.wantConditions[eq.Namespace]...)
+				for _, condition := range []string{v1alpha1.ElasticQuotaOverMin, v1alpha1.ElasticQuotaAtMax, v1alpha1.ElasticQuotaInvalid} {
+					if got := meta.IsStatusConditionTrue(eq.Status.Conditions, condition); got != wantConditions.Has(condition) {
+						t.Errorf("%v: want %v condition %v, got %v", eq.Namespace, condition, wantConditions.Has(condition), got)
+					}
+				}
+			}
+
+			events := controller.recorder.(*record.FakeRecorder).Events
+			gotEvents := sets.New[string]()
+			for len(events) > 0 {
+				gotEvents.Insert(strings.Fields(<-events)[1])
+			}
+			for _, reason := range c.wantEvents {
+				if !gotEvents.Has(reason) {
+					t.Errorf("want event %v, got %v", reason, sets.List(gotEvents))
+				}
+			}
+		})
+	}
+}
+
 func setUpEQ(ctx context.Context,
 	t *testing.T,
+	namespaces []*v1.Namespace,
 	eqs []*v1alpha1.ElasticQuota,
 	pods []*v1.Pod) (*ElasticQuotaReconciler, client.WithWatch) {
 	s := scheme.Scheme
@@ -241,6 +537,11 @@ func setUpEQ(ctx context.Context,
 		WithScheme(s).
 		WithStatusSubresource(&v1alpha1.ElasticQuota{}).
 		Build()
+	for _, ns := range namespaces {
+		if err := client.Create(ctx, ns); err != nil {
+			t.Fatal("setup controller", err)
+		}
+	}
 	for _, eq := range eqs {
 		err := client.Create(ctx, eq)
 		if errors.IsAlreadyExists(err) {
@@ -262,8 +563,12 @@ func setUpEQ(ctx context.Context,
 	controller := &ElasticQuotaReconciler{
 		Client:   client,
 		Scheme:   s,
-		recorder: record.NewFakeRecorder(3),
+		recorder: record.NewFakeRecorder(10),
 	}
 
 	return controller, client
 }
+
+func makeNamespace(name string, labels map[string]string) *v1.Namespace {
+	return &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	quota "k8s.io/apiserver/pkg/quota/v1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	schedv1alpha1 "sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+// ElasticQuotaWebhook defaults and validates ElasticQuotas on admission.
+type ElasticQuotaWebhook struct {
+	client.Client
+}
+
+var _ admission.CustomDefaulter = &ElasticQuotaWebhook{}
+var _ admission.CustomValidator = &ElasticQuotaWebhook{}
+
+// +kubebuilder:webhook:path=/mutate-scheduling-x-k8s-io-v1alpha1-elasticquota,mutating=true,failurePolicy=fail,sideEffects=None,groups=scheduling.x-k8s.io,resources=elasticquotas,verbs=create;update,versions=v1alpha1,name=melasticquota.scheduling.x-k8s.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-scheduling-x-k8s-io-v1alpha1-elasticquota,mutating=false,failurePolicy=fail,sideEffects=None,groups=scheduling.x-k8s.io,resources=elasticquotas,verbs=create;update,versions=v1alpha1,name=velasticquota.scheduling.x-k8s.io,admissionReviewVersions=v1
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+func (w *ElasticQuotaWebhook) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&schedv1alpha1.ElasticQuota{}).
+		WithDefaulter(w).
+		WithValidator(w).
+		Complete()
+}
+
+// Default sets the max of the quota to unlimited when it is omitted, which is what the scheduler assumes.
+func (w *ElasticQuotaWebhook) Default(ctx context.Context, obj runtime.Object) error {
+	eq, ok := obj.(*schedv1alpha1.ElasticQuota)
+	if !ok {
+		return fmt.Errorf("expected an ElasticQuota but got a %T", obj)
+	}
+	if eq.Spec.Max == nil {
+		eq.Spec.Max = unlimitedResourceList()
+	}
+	return nil
+}
+
+func (w *ElasticQuotaWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	eq, ok := obj.(*schedv1alpha1.ElasticQuota)
+	if !ok {
+		return nil, fmt.Errorf("expected an ElasticQuota but got a %T", obj)
+	}
+	return nil, w.validate(ctx, nil, eq)
+}
+
+func (w *ElasticQuotaWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldEQ, ok := oldObj.(*schedv1alpha1.ElasticQuota)
+	if !ok {
+		return nil, fmt.Errorf("expected an ElasticQuota but got a %T", oldObj)
+	}
+	eq, ok := newObj.(*schedv1alpha1.ElasticQuota)
+	if !ok {
+		return nil, fmt.Errorf("expected an ElasticQuota but got a %T", newObj)
+	}
+	return nil, w.validate(ctx, oldEQ, eq)
+}
+
+func (w *ElasticQuotaWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks that the min of the quota is at most its max, that the quota only has resources of the
+// cluster, that it is the only quota of its namespace, and that the min of the top-level quotas fits in
+// the cluster. On update, old is the current version of the quota, and the checks against the other
+// quotas are skipped unless the update changes the min or the parent, so that a quota that was valid
+// when it was created can still be updated.
+func (w *ElasticQuotaWebhook) validate(ctx context.Context, old, eq *schedv1alpha1.ElasticQuota) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	for name, min := range eq.Spec.Min {
+		if max, ok := eq.Spec.Max[name]; ok && min.Cmp(max) > 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("min").Key(string(name)), min.String(),
+				fmt.Sprintf("must be less than or equal to the max %s", max.String())))
+		}
+	}
+
+	eqList := &schedv1alpha1.ElasticQuotaList{}
+	if err := w.List(ctx, eqList); err != nil {
+		return err
+	}
+	for _, item := range eqList.Items {
+		if old == nil && item.Namespace == eq.Namespace && item.Name != eq.Name {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("metadata", "namespace"),
+				fmt.Sprintf("namespace %s already has the ElasticQuota %s", eq.Namespace, item.Name)))
+		}
+	}
+
+	nodeList := &v1.NodeList{}
+	if err := w.List(ctx, nodeList); err != nil {
+		return err
+	}
+	// Without any node, the resources of the cluster are not known yet.
+	if len(nodeList.Items) != 0 {
+		capacity := v1.ResourceList{}
+		for _, node := range nodeList.Items {
+			capacity = quota.Add(capacity, node.Status.Allocatable)
+		}
+		allErrs = append(allErrs, validateResourceNames(eq.Spec.Min, capacity, specPath.Child("min"))...)
+		allErrs = append(allErrs, validateResourceNames(eq.Spec.Max, capacity, specPath.Child("max"))...)
+		if old == nil || !quota.Equals(old.Spec.Min, eq.Spec.Min) || old.Spec.Parent != eq.Spec.Parent {
+			allErrs = append(allErrs, validateTotalMin(eq, eqList.Items, capacity, specPath.Child("min"))...)
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrs.NewInvalid(schedv1alpha1.SchemeGroupVersion.WithKind("ElasticQuota").GroupKind(), eq.Name, allErrs)
+}
+
+// validateResourceNames checks that the cluster has the resources of the list. The cpu, memory and
+// ephemeral storage of the unlimited max are always accepted.
+func validateResourceNames(list, capacity v1.ResourceList, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	native := sets.KeySet(unlimitedResourceList())
+	for name := range list {
+		if _, ok := capacity[name]; !ok && !native.Has(name) {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Key(string(name)), string(name), resourceNames(capacity)))
+		}
+	}
+	return allErrs
+}
+
+// validateTotalMin checks that the sum of the min of the top-level quotas, with the quota in place of its
+// current version, is at most the allocatable resources of the cluster.
+func validateTotalMin(eq *schedv1alpha1.ElasticQuota, items []schedv1alpha1.ElasticQuota, capacity v1.ResourceList, fldPath *field.Path) field.ErrorList {
+	quotas := map[string]*schedv1alpha1.ElasticQuota{eq.Namespace: eq}
+	for i := range items {
+		if items[i].Namespace != eq.Namespace {
+			quotas[items[i].Namespace] = &items[i]
+		}
+	}
+	total := v1.ResourceList{}
+	for _, q := range quotas {
+		if _, ok := quotas[q.Spec.Parent]; !ok {
+			total = quota.Add(total, q.Spec.Min)
+		}
+	}
+
+	var allErrs field.ErrorList
+	for name := range eq.Spec.Min {
+		allocatable, ok := capacity[name]
+		if !ok {
+			continue
+		}
+		if sum := total[name]; sum.Cmp(allocatable) > 0 {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Key(string(name)),
+				fmt.Sprintf("the min of the top-level ElasticQuotas adds up to %s, more than the %s allocatable in the cluster", sum.String(), allocatable.String())))
+		}
+	}
+	return allErrs
+}
+
+func resourceNames(list v1.ResourceList) []string {
+	names := make([]string, 0, len(list))
+	for _, name := range quota.ResourceNames(list) {
+		names = append(names, string(name))
+	}
+	return sets.List(sets.New(names...))
+}
+
+// unlimitedResourceList returns the max the scheduler assumes for a quota without max.
+func unlimitedResourceList() v1.ResourceList {
+	return v1.ResourceList{
+		v1.ResourceCPU:              *resource.NewMilliQuantity(math.MaxInt64, resource.DecimalSI),
+		v1.ResourceMemory:           *resource.NewQuantity(math.MaxInt64, resource.BinarySI),
+		v1.ResourceEphemeralStorage: *resource.NewQuantity(math.MaxInt64, resource.BinarySI),
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	quota "k8s.io/apiserver/pkg/quota/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	testutil "sigs.k8s.io/scheduler-plugins/test/integration"
+)
+
+func TestElasticQuotaWebhook_Default(t *testing.T) {
+	w := &ElasticQuotaWebhook{}
+
+	eq := testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(2).Obj()).Obj()
+	if err := w.Default(context.TODO(), eq); err != nil {
+		t.Fatal(err)
+	}
+	if !quota.Equals(eq.Spec.Max, unlimitedResourceList()) {
+		t.Errorf("want unlimited max %v, got %v", unlimitedResourceList(), eq.Spec.Max)
+	}
+
+	max := testutil.MakeResourceList().CPU(4).Obj()
+	eq = testutil.MakeEQ("ns1", "eq1").Max(max).Obj()
+	if err := w.Default(context.TODO(), eq); err != nil {
+		t.Fatal(err)
+	}
+	if !quota.Equals(eq.Spec.Max, max) {
+		t.Errorf("want max %v, got %v", max, eq.Spec.Max)
+	}
+}
+
+func TestElasticQuotaWebhook_Validate(t *testing.T) {
+	nodes := []*v1.Node{
+		makeNode("node-a", testutil.MakeResourceList().CPU(4).Mem(8).Obj()),
+		makeNode("node-b", testutil.MakeResourceList().CPU(4).Mem(8).Obj()),
+	}
+	cases := []struct {
+		name          string
+		nodes         []*v1.Node
+		elasticQuotas []*v1alpha1.ElasticQuota
+		old           *v1alpha1.ElasticQuota
+		eq            *v1alpha1.ElasticQuota
+		wantErr       bool
+	}{
+		{
+			name:  "valid quota",
+			nodes: nodes,
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Min(testutil.MakeResourceList().CPU(2).Mem(4).Obj()).
+				Max(testutil.MakeResourceList().CPU(4).Mem(8).Obj()).Obj(),
+		},
+		{
+			name:  "min greater than max",
+			nodes: nodes,
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Min(testutil.MakeResourceList().CPU(4).Obj()).
+				Max(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+			wantErr: true,
+		},
+		{
+			name:  "resource the cluster does not have",
+			nodes: nodes,
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Max(testutil.MakeResourceList().CPU(4).GPU(1).Obj()).Obj(),
+			wantErr: true,
+		},
+		{
+			name: "resources are not checked without nodes",
+			eq: testutil.MakeEQ("ns1", "eq1").
+				Min(testutil.MakeResourceList().CPU(16).GPU(1).Obj()).Obj(),
+		},
+		{
+			name:  "second quota in a namespace",
+			nodes: nodes,
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+			},
+			eq:      testutil.MakeEQ("ns1", "eq2").Min(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+			wantErr: true,
+		},
+		{
+			name:  "total min of the top-level quotas over the cluster capacity",
+			nodes: nodes,
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+			},
+			eq:      testutil.MakeEQ("ns2", "eq2").Min(testutil.MakeResourceList().CPU(3).Obj()).Obj(),
+			wantErr: true,
+		},
+		{
+			name:  "min of the child quotas is not added to the total",
+			nodes: nodes,
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("dept", "eq-dept").Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+				testutil.MakeEQ("ns1", "eq1").Parent("dept").Min(testutil.MakeResourceList().CPU(4).Obj()).Obj(),
+			},
+			eq: testutil.MakeEQ("ns2", "eq2").Parent("dept").Min(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+		},
+		{
+			name:  "update of a quota replaces its min in the total",
+			nodes: nodes,
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+			},
+			old: testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+			eq:  testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(8).Obj()).Obj(),
+		},
+		{
+			name:  "update of a quota over the cluster capacity",
+			nodes: nodes,
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+				testutil.MakeEQ("ns2", "eq2").Min(testutil.MakeResourceList().CPU(2).Obj()).Obj(),
+			},
+			old:     testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+			eq:      testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(7).Obj()).Obj(),
+			wantErr: true,
+		},
+		{
+			name:  "update of a quota that does not change its min",
+			nodes: nodes[:1],
+			elasticQuotas: []*v1alpha1.ElasticQuota{
+				testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+			},
+			old: testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(6).Obj()).Obj(),
+			eq: testutil.MakeEQ("ns1", "eq1").Min(testutil.MakeResourceList().CPU(6).Obj()).
+				Max(testutil.MakeResourceList().CPU(8).Obj()).Obj(),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.TODO()
+			w := &ElasticQuotaWebhook{Client: setUpWebhookClient(ctx, t, c.nodes, c.elasticQuotas)}
+
+			var err error
+			if c.old == nil {
+				_, err = w.ValidateCreate(ctx, c.eq)
+			} else {
+				_, err = w.ValidateUpdate(ctx, c.old, c.eq)
+			}
+			if gotErr := err != nil; gotErr != c.wantErr {
+				t.Fatalf("want error %v, got %v", c.wantErr, err)
+			}
+			if err != nil && !apierrs.IsInvalid(err) {
+				t.Errorf("want an invalid error, got %v", err)
+			}
+		})
+	}
+}
+
+func setUpWebhookClient(ctx context.Context, t *testing.T, nodes []*v1.Node, eqs []*v1alpha1.ElasticQuota) client.Client {
+	s := scheme.Scheme
+	utilruntime.Must(v1alpha1.AddToScheme(s))
+
+	client := fake.NewClientBuilder().WithScheme(s).Build()
+	for _, node := range nodes {
+		if err := client.Create(ctx, node.DeepCopy()); err != nil {
+			t.Fatal("setup webhook", err)
+		}
+	}
+	for _, eq := range eqs {
+		if err := client.Create(ctx, eq); err != nil {
+			t.Fatal("setup webhook", err)
+		}
+	}
+	return client
+}
+
+func makeNode(name string, allocatable v1.ResourceList) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     v1.NodeStatus{Allocatable: allocatable},
+	}
+}
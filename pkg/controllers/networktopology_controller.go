@@ -0,0 +1,321 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+
+	ntapi "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology"
+	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
+)
+
+// DefaultNetworkTopologyWeightsName is the name of the weights the NetworkTopology reconciler computes,
+// unless WeightsName is set. It is the weights the NetworkOverhead plugin uses by default.
+const DefaultNetworkTopologyWeightsName = "UserDefined"
+
+// NetworkLatenciesLabel marks the ConfigMaps that hold the latencies of the NetworkTopologies, with the value
+// "true". Only the ConfigMaps with the label are watched and cached.
+const NetworkLatenciesLabel = ntapi.GroupName + "/latencies"
+
+// NetworkLatenciesSelector selects the ConfigMaps with the NetworkLatenciesLabel.
+var NetworkLatenciesSelector = labels.SelectorFromSet(labels.Set{NetworkLatenciesLabel: "true"})
+
+// NetworkLatencySource provides the network latencies between the regions and between the zones of the cluster.
+type NetworkLatencySource interface {
+	// Latencies returns the latencies between the topology domains of each topology key. Returns a not found
+	// error if the latencies of the NetworkTopology are not known.
+	Latencies(ctx context.Context, nt *ntv1alpha1.NetworkTopology) (map[networkawareutil.CostKey]int64, error)
+}
+
+// ConfigMapLatencySource reads the latencies from the ConfigMap of the NetworkTopology, in its namespace,
+// which has the NetworkLatenciesLabel.
+// The "region" and "zone" keys of the ConfigMap hold the latencies between the regions and between the
+// zones, by origin and destination, e.g.:
+//
+//	region: |
+//	  us-west-1:
+//	    us-east-1: 20
+//
+// The latency from the destination to the origin is the same unless it is set too.
+type ConfigMapLatencySource struct {
+	client.Client
+}
+
+var configMapTopologyKeys = map[string]ntv1alpha1.TopologyKey{
+	"region": ntv1alpha1.NetworkTopologyRegion,
+	"zone":   ntv1alpha1.NetworkTopologyZone,
+}
+
+func (s *ConfigMapLatencySource) Latencies(ctx context.Context, nt *ntv1alpha1.NetworkTopology) (map[networkawareutil.CostKey]int64, error) {
+	cm := &v1.ConfigMap{}
+	if err := s.Get(ctx, types.NamespacedName{Namespace: nt.Namespace, Name: nt.Spec.ConfigmapName}, cm); err != nil {
+		return nil, err
+	}
+	if !NetworkLatenciesSelector.Matches(labels.Set(cm.Labels)) {
+		return nil, apierrs.NewNotFound(v1.Resource("configmaps"), cm.Name)
+	}
+
+	latencies := make(map[networkawareutil.CostKey]int64)
+	for key, topologyKey := range configMapTopologyKeys {
+		data, ok := cm.Data[key]
+		if !ok {
+			continue
+		}
+		origins := map[string]map[string]int64{}
+		if err := yaml.Unmarshal([]byte(data), &origins); err != nil {
+			return nil, fmt.Errorf("invalid %s latencies in ConfigMap %s/%s: %w", key, cm.Namespace, cm.Name, err)
+		}
+		for origin, destinations := range origins {
+			for destination, latency := range destinations {
+				latencies[networkawareutil.CostKey{TopologyKey: topologyKey, Origin: origin, Destination: destination}] = latency
+				reverse := networkawareutil.CostKey{TopologyKey: topologyKey, Origin: destination, Destination: origin}
+				if _, ok := origins[destination][origin]; !ok {
+					latencies[reverse] = latency
+				}
+			}
+		}
+	}
+	return latencies, nil
+}
+
+// NetworkTopologyReconciler reconciles a NetworkTopology object: it computes the network costs between
+// the regions and between the zones of the nodes of the cluster from their latencies.
+type NetworkTopologyReconciler struct {
+	recorder record.EventRecorder
+
+	client.Client
+	Scheme  *runtime.Scheme
+	Workers int
+	// WeightsName is the name of the weights of the NetworkTopology the costs are written to,
+	// DefaultNetworkTopologyWeightsName if empty. The other weights are left as they are.
+	WeightsName string
+	// LatencySource provides the latencies, a ConfigMapLatencySource if nil.
+	LatencySource NetworkLatencySource
+}
+
+// +kubebuilder:rbac:groups=networktopology.diktyo.x-k8s.io,resources=networktopologies,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+
+func (r *NetworkTopologyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	log.Info("reconciling")
+	nt := &ntv1alpha1.NetworkTopology{}
+	if err := r.Get(ctx, req.NamespacedName, nt); err != nil {
+		if apierrs.IsNotFound(err) {
+			log.V(5).Info("NetworkTopology has been deleted")
+			return ctrl.Result{}, nil
+		}
+		log.V(3).Error(err, "Unable to retrieve NetworkTopology")
+		return ctrl.Result{}, err
+	}
+
+	nodeList := &v1.NodeList{}
+	if err := r.List(ctx, nodeList); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	ntCopy := nt.DeepCopy()
+	ntCopy.Status.NodeCount = int64(len(nodeList.Items))
+	latencies, err := r.latencySource().Latencies(ctx, nt)
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		// The weights are kept until the latencies are known.
+		r.recorder.Event(nt, v1.EventTypeWarning, "LatenciesNotFound", err.Error())
+	} else {
+		setWeights(ntCopy, r.weightsName(), computeTopologyList(nodeList.Items, latencies, findWeights(nt.Spec.Weights, r.weightsName())))
+	}
+
+	weightsChanged := !apiequality.Semantic.DeepEqual(ntCopy.Spec.Weights, nt.Spec.Weights)
+	if weightsChanged {
+		ntCopy.Status.WeightCalculationTime = metav1.Now()
+	}
+	if !weightsChanged && apiequality.Semantic.DeepEqual(ntCopy.Status, nt.Status) {
+		return ctrl.Result{}, nil
+	}
+	// The status of a NetworkTopology is not a subresource.
+	if err := r.Update(ctx, ntCopy); err != nil {
+		return ctrl.Result{}, err
+	}
+	if weightsChanged {
+		r.recorder.Event(nt, v1.EventTypeNormal, "WeightsUpdated", fmt.Sprintf("NetworkTopology %s weights %s updated", req.NamespacedName, r.weightsName()))
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *NetworkTopologyReconciler) weightsName() string {
+	if len(r.WeightsName) == 0 {
+		return DefaultNetworkTopologyWeightsName
+	}
+	return r.WeightsName
+}
+
+func (r *NetworkTopologyReconciler) latencySource() NetworkLatencySource {
+	if r.LatencySource == nil {
+		return &ConfigMapLatencySource{Client: r.Client}
+	}
+	return r.LatencySource
+}
+
+// computeTopologyList returns the costs between the regions and between the zones of the nodes, sorted the way the
+// NetworkOverhead plugin binary searches them. The pairs of domains without a latency are left out, and the
+// bandwidth of the pairs of the previous topology list is kept.
+func computeTopologyList(nodes []v1.Node, latencies map[networkawareutil.CostKey]int64, previous ntv1alpha1.TopologyList) ntv1alpha1.TopologyList {
+	domains := map[ntv1alpha1.TopologyKey]sets.Set[string]{
+		ntv1alpha1.NetworkTopologyRegion: sets.New[string](),
+		ntv1alpha1.NetworkTopologyZone:   sets.New[string](),
+	}
+	for i := range nodes {
+		if region := networkawareutil.GetNodeRegion(&nodes[i]); len(region) != 0 {
+			domains[ntv1alpha1.NetworkTopologyRegion].Insert(region)
+		}
+		if zone := networkawareutil.GetNodeZone(&nodes[i]); len(zone) != 0 {
+			domains[ntv1alpha1.NetworkTopologyZone].Insert(zone)
+		}
+	}
+
+	var topologyList ntv1alpha1.TopologyList
+	for key, keyDomains := range domains {
+		previousOrigins := networkawareutil.FindTopologyKey(previous, key)
+		var originList ntv1alpha1.OriginList
+		for _, origin := range sets.List(keyDomains) {
+			previousCosts := networkawareutil.FindOriginCosts(previousOrigins, origin)
+			var costList ntv1alpha1.CostList
+			for _, destination := range sets.List(keyDomains) {
+				latency, ok := latencies[networkawareutil.CostKey{TopologyKey: key, Origin: origin, Destination: destination}]
+				if !ok || destination == origin {
+					continue
+				}
+				cost := ntv1alpha1.CostInfo{Destination: destination, NetworkCost: latency}
+				for _, previousCost := range previousCosts {
+					if previousCost.Destination == destination {
+						cost.BandwidthCapacity = previousCost.BandwidthCapacity
+						cost.BandwidthAllocated = previousCost.BandwidthAllocated
+					}
+				}
+				costList = append(costList, cost)
+			}
+			if len(costList) != 0 {
+				originList = append(originList, ntv1alpha1.OriginInfo{Origin: origin, CostList: costList})
+			}
+		}
+		if len(originList) != 0 {
+			topologyList = append(topologyList, ntv1alpha1.TopologyInfo{TopologyKey: key, OriginList: originList})
+		}
+	}
+	sort.Sort(networkawareutil.ByTopologyKey(topologyList))
+	return topologyList
+}
+
+func findWeights(weights ntv1alpha1.WeightList, name string) ntv1alpha1.TopologyList {
+	for _, w := range weights {
+		if w.Name == name {
+			return w.TopologyList
+		}
+	}
+	return nil
+}
+
+// setWeights replaces the topology list of the weights with the given name, or adds them.
+func setWeights(nt *ntv1alpha1.NetworkTopology, name string, topologyList ntv1alpha1.TopologyList) {
+	for i := range nt.Spec.Weights {
+		if nt.Spec.Weights[i].Name == name {
+			nt.Spec.Weights[i].TopologyList = topologyList
+			return
+		}
+	}
+	nt.Spec.Weights = append(nt.Spec.Weights, ntv1alpha1.WeightInfo{Name: name, TopologyList: topologyList})
+}
+
+func (r *NetworkTopologyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("NetworkTopologyController")
+	return ctrl.NewControllerManagedBy(mgr).
+		Watches(&v1.Node{}, handler.EnqueueRequestsFromMapFunc(r.toNetworkTopologies),
+			builder.WithPredicates(predicate.LabelChangedPredicate{})).
+		Watches(&v1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.configMapToNetworkTopologies),
+			builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return NetworkLatenciesSelector.Matches(labels.Set(obj.GetLabels()))
+			}))).
+		For(&ntv1alpha1.NetworkTopology{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.Workers}).
+		Complete(r)
+}
+
+// toNetworkTopologies enqueues all the NetworkTopologies, since the regions and zones of a node apply to all of them.
+func (r *NetworkTopologyReconciler) toNetworkTopologies(ctx context.Context, obj client.Object) []ctrl.Request {
+	ntList := &ntv1alpha1.NetworkTopologyList{}
+	if err := r.List(ctx, ntList); err != nil {
+		log.FromContext(ctx).Error(err, "Unable to list networktopologies")
+		return nil
+	}
+
+	requests := make([]ctrl.Request, 0, len(ntList.Items))
+	for _, nt := range ntList.Items {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: nt.Namespace,
+				Name:      nt.Name,
+			}})
+	}
+	return requests
+}
+
+// configMapToNetworkTopologies enqueues the NetworkTopologies whose latencies are in the ConfigMap.
+func (r *NetworkTopologyReconciler) configMapToNetworkTopologies(ctx context.Context, obj client.Object) []ctrl.Request {
+	ntList := &ntv1alpha1.NetworkTopologyList{}
+	if err := r.List(ctx, ntList, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.FromContext(ctx).Error(err, "Unable to list networktopologies")
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, nt := range ntList.Items {
+		if nt.Spec.ConfigmapName != obj.GetName() {
+			continue
+		}
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Namespace: nt.Namespace,
+				Name:      nt.Name,
+			}})
+	}
+	return requests
+}
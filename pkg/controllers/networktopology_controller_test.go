@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	testutil "sigs.k8s.io/scheduler-plugins/test/integration"
+
+	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
+)
+
+func TestNetworkTopologyController(t *testing.T) {
+	node := func(name, region, zone string) *v1.Node {
+		return &v1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{v1.LabelTopologyRegion: region, v1.LabelTopologyZone: zone},
+		}}
+	}
+	nodes := []*v1.Node{
+		node("n1", "us-west-1", "z1"),
+		node("n2", "us-west-1", "z2"),
+		node("n3", "us-east-1", "z3"),
+	}
+	configMap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "latencies", Labels: map[string]string{NetworkLatenciesLabel: "true"}},
+		Data: map[string]string{
+			"region": "us-west-1:\n  us-east-1: 20\n",
+			"zone":   "z1:\n  z2: 5\n  z3: 30\nz2:\n  z1: 6\n",
+		},
+	}
+	weights := func(name string, topologyList ntv1alpha1.TopologyList) ntv1alpha1.WeightList {
+		return ntv1alpha1.WeightList{{Name: name, TopologyList: topologyList}}
+	}
+	cost := func(destination string, networkCost int64) ntv1alpha1.CostInfo {
+		return ntv1alpha1.CostInfo{Destination: destination, NetworkCost: networkCost}
+	}
+	computed := ntv1alpha1.TopologyList{
+		{
+			TopologyKey: ntv1alpha1.NetworkTopologyRegion,
+			OriginList: ntv1alpha1.OriginList{
+				{Origin: "us-east-1", CostList: ntv1alpha1.CostList{cost("us-west-1", 20)}},
+				{Origin: "us-west-1", CostList: ntv1alpha1.CostList{cost("us-east-1", 20)}},
+			},
+		},
+		{
+			TopologyKey: ntv1alpha1.NetworkTopologyZone,
+			OriginList: ntv1alpha1.OriginList{
+				{Origin: "z1", CostList: ntv1alpha1.CostList{cost("z2", 5), cost("z3", 30)}},
+				{Origin: "z2", CostList: ntv1alpha1.CostList{cost("z1", 6)}},
+				{Origin: "z3", CostList: ntv1alpha1.CostList{cost("z1", 30)}},
+			},
+		},
+	}
+	withBandwidth := computed.DeepCopy()
+	withBandwidth[0].OriginList[1].CostList[0].BandwidthCapacity = resource.MustParse("10Gi")
+
+	cases := []struct {
+		name          string
+		spec          ntv1alpha1.NetworkTopologySpec
+		configMap     *v1.ConfigMap
+		wantWeights   ntv1alpha1.WeightList
+		wantNodeCount int64
+	}{
+		{
+			name:          "costs computed from the ConfigMap",
+			spec:          ntv1alpha1.NetworkTopologySpec{ConfigmapName: "latencies"},
+			configMap:     configMap,
+			wantWeights:   weights(DefaultNetworkTopologyWeightsName, computed),
+			wantNodeCount: 3,
+		},
+		{
+			name: "bandwidth and other weights are kept",
+			spec: ntv1alpha1.NetworkTopologySpec{
+				ConfigmapName: "latencies",
+				Weights: append(weights("Other", nil), weights(DefaultNetworkTopologyWeightsName, ntv1alpha1.TopologyList{{
+					TopologyKey: ntv1alpha1.NetworkTopologyRegion,
+					OriginList: ntv1alpha1.OriginList{{Origin: "us-west-1", CostList: ntv1alpha1.CostList{
+						{Destination: "us-east-1", NetworkCost: 1, BandwidthCapacity: resource.MustParse("10Gi")},
+					}}},
+				}})...),
+			},
+			configMap:     configMap,
+			wantWeights:   append(weights("Other", nil), weights(DefaultNetworkTopologyWeightsName, withBandwidth)...),
+			wantNodeCount: 3,
+		},
+		{
+			name:          "weights kept without the ConfigMap",
+			spec:          ntv1alpha1.NetworkTopologySpec{ConfigmapName: "latencies", Weights: weights(DefaultNetworkTopologyWeightsName, computed)},
+			wantWeights:   weights(DefaultNetworkTopologyWeightsName, computed),
+			wantNodeCount: 3,
+		},
+		{
+			name: "ConfigMap without the latencies label ignored",
+			spec: ntv1alpha1.NetworkTopologySpec{ConfigmapName: "latencies"},
+			configMap: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "latencies"},
+				Data:       configMap.Data,
+			},
+			wantNodeCount: 3,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			nt := testutil.MakeNetworkTopology("default", "nt").Spec(c.spec).Obj()
+			objs := []client.Object{nt}
+			for _, n := range nodes {
+				objs = append(objs, n.DeepCopy())
+			}
+			if c.configMap != nil {
+				objs = append(objs, c.configMap.DeepCopy())
+			}
+			controller, kubeClient := setUpNT(objs)
+			key := types.NamespacedName{Namespace: "default", Name: "nt"}
+			if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: key}); err != nil {
+				t.Fatal(err)
+			}
+			got := &ntv1alpha1.NetworkTopology{}
+			if err := kubeClient.Get(ctx, key, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.NodeCount != c.wantNodeCount {
+				t.Errorf("want %v nodes, got %v", c.wantNodeCount, got.Status.NodeCount)
+			}
+			if diff := cmp.Diff(c.wantWeights, got.Spec.Weights); diff != "" {
+				t.Errorf("unexpected weights (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
+func setUpNT(objs []client.Object) (*NetworkTopologyReconciler, client.WithWatch) {
+	s := scheme.Scheme
+	utilruntime.Must(ntv1alpha1.AddToScheme(s))
+
+	client := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(objs...).
+		Build()
+	controller := &NetworkTopologyReconciler{
+		Client:   client,
+		Scheme:   s,
+		recorder: record.NewFakeRecorder(10),
+	}
+	return controller, client
+}
@@ -26,6 +26,8 @@ import (
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,6 +42,10 @@ import (
 	"sigs.k8s.io/scheduler-plugins/pkg/util"
 )
 
+// DefaultScheduleTimeout is how long a pod group may go without running pods
+// before the controller stops reconciling it, unless ScheduleTimeout is set.
+const DefaultScheduleTimeout = 48 * time.Hour
+
 // PodGroupReconciler reconciles a PodGroup object
 type PodGroupReconciler struct {
 	log      logr.Logger
@@ -48,6 +54,9 @@ type PodGroupReconciler struct {
 	client.Client
 	Scheme  *runtime.Scheme
 	Workers int
+	// ScheduleTimeout is how long after its creation a pod group that has no running pods
+	// is given up on. Zero means DefaultScheduleTimeout, and a negative value disables the timeout.
+	ScheduleTimeout time.Duration
 }
 
 // +kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups,verbs=get;list;watch;create;update;patch;delete
@@ -80,13 +89,19 @@ func (r *PodGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		pg.Status.Phase == schedv1alpha1.PodGroupFailed {
 		return ctrl.Result{}, nil
 	}
-	// If startScheduleTime - createTime > 2days,
+	// If the pod group has not been running for longer than the timeout,
 	// do not reconcile again because pod may have been GCed
-	if (pg.Status.Phase == schedv1alpha1.PodGroupScheduling || pg.Status.Phase == schedv1alpha1.PodGroupPending) && pg.Status.Running == 0 &&
-		pg.Status.ScheduleStartTime.Sub(pg.CreationTimestamp.Time) > 48*time.Hour {
-		r.recorder.Event(pg, v1.EventTypeWarning,
-			"Timeout", "schedule time longer than 48 hours")
-		return ctrl.Result{}, nil
+	if timeout := r.scheduleTimeout(); timeout > 0 &&
+		(pg.Status.Phase == schedv1alpha1.PodGroupScheduling || pg.Status.Phase == schedv1alpha1.PodGroupPending) && pg.Status.Running == 0 &&
+		time.Since(pg.CreationTimestamp.Time) > timeout {
+		if meta.IsStatusConditionTrue(pg.Status.Conditions, schedv1alpha1.PodGroupTimedOut) {
+			return ctrl.Result{}, nil
+		}
+		message := fmt.Sprintf("schedule time longer than %v", timeout)
+		r.recorder.Event(pg, v1.EventTypeWarning, "Timeout", message)
+		pgCopy := pg.DeepCopy()
+		setCondition(pgCopy, schedv1alpha1.PodGroupTimedOut, metav1.ConditionTrue, schedv1alpha1.PodGroupReasonScheduleTimeout, message)
+		return r.patchPodGroup(ctx, pg, pgCopy)
 	}
 
 	podList := &v1.PodList{}
@@ -101,6 +116,7 @@ func (r *PodGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	pods := podList.Items
 
 	pgCopy := pg.DeepCopy()
+	pgCopy.Status.Scheduled = getScheduledPods(pods)
 	switch pgCopy.Status.Phase {
 	case "":
 		pgCopy.Status.Phase = schedv1alpha1.PodGroupPending
@@ -133,9 +149,83 @@ func (r *PodGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	if pgCopy.Status.Phase != pg.Status.Phase {
+		now := metav1.Now()
+		if pgCopy.Status.PhaseTransitionTimes == nil {
+			pgCopy.Status.PhaseTransitionTimes = make(map[schedv1alpha1.PodGroupPhase]metav1.Time)
+		}
+		pgCopy.Status.PhaseTransitionTimes[pgCopy.Status.Phase] = now
+		if pgCopy.Status.Phase == schedv1alpha1.PodGroupScheduling && pgCopy.Status.ScheduleStartTime.IsZero() {
+			pgCopy.Status.ScheduleStartTime = now
+		}
+		if len(pg.Status.Phase) != 0 {
+			r.recorder.Eventf(pg, v1.EventTypeNormal, "PhaseChanged",
+				"pod group phase changed from %s to %s", pg.Status.Phase, pgCopy.Status.Phase)
+		}
+	}
+	r.updateConditions(pgCopy, len(pods))
+
 	return r.patchPodGroup(ctx, pg, pgCopy)
 }
 
+// updateConditions sets the Scheduled, Ready and QuorumLost conditions of the pod group
+// from its pod counts. A Scheduled=False condition is kept as is, so the reason reported
+// by the scheduler stays visible until the pod group is scheduled.
+func (r *PodGroupReconciler) updateConditions(pg *schedv1alpha1.PodGroup, total int) {
+	minMember := pg.Spec.MinMember
+	hadQuorum := meta.IsStatusConditionTrue(pg.Status.Conditions, schedv1alpha1.PodGroupScheduled) ||
+		meta.IsStatusConditionTrue(pg.Status.Conditions, schedv1alpha1.PodGroupReady)
+
+	if pg.Status.Scheduled >= minMember {
+		setCondition(pg, schedv1alpha1.PodGroupScheduled, metav1.ConditionTrue, schedv1alpha1.PodGroupReasonMinMemberScheduled,
+			fmt.Sprintf("%d/%d pods are scheduled", pg.Status.Scheduled, minMember))
+	} else if !meta.IsStatusConditionFalse(pg.Status.Conditions, schedv1alpha1.PodGroupScheduled) {
+		setCondition(pg, schedv1alpha1.PodGroupScheduled, metav1.ConditionFalse, schedv1alpha1.PodGroupReasonWaitingForPods,
+			fmt.Sprintf("%d/%d pods are scheduled", pg.Status.Scheduled, minMember))
+	}
+
+	ready := pg.Status.Running + pg.Status.Succeeded
+	if ready >= minMember {
+		setCondition(pg, schedv1alpha1.PodGroupReady, metav1.ConditionTrue, schedv1alpha1.PodGroupReasonMinMemberReady,
+			fmt.Sprintf("%d/%d pods are running or succeeded", ready, minMember))
+	} else {
+		setCondition(pg, schedv1alpha1.PodGroupReady, metav1.ConditionFalse, schedv1alpha1.PodGroupReasonWaitingForPods,
+			fmt.Sprintf("%d/%d pods are running or succeeded", ready, minMember))
+	}
+
+	if total < int(minMember) {
+		if hadQuorum || meta.IsStatusConditionTrue(pg.Status.Conditions, schedv1alpha1.PodGroupQuorumLost) {
+			message := fmt.Sprintf("%d/%d pods exist", total, minMember)
+			if setCondition(pg, schedv1alpha1.PodGroupQuorumLost, metav1.ConditionTrue, schedv1alpha1.PodGroupReasonMinMemberNotMet, message) {
+				r.recorder.Event(pg, v1.EventTypeWarning, "QuorumLost", message)
+			}
+		}
+	} else if meta.FindStatusCondition(pg.Status.Conditions, schedv1alpha1.PodGroupQuorumLost) != nil {
+		setCondition(pg, schedv1alpha1.PodGroupQuorumLost, metav1.ConditionFalse, schedv1alpha1.PodGroupReasonMinMemberMet,
+			fmt.Sprintf("%d/%d pods exist", total, minMember))
+	}
+}
+
+func (r *PodGroupReconciler) scheduleTimeout() time.Duration {
+	if r.ScheduleTimeout == 0 {
+		return DefaultScheduleTimeout
+	}
+	return r.ScheduleTimeout
+}
+
+// setCondition sets a condition of the pod group and returns whether its status changed.
+func setCondition(pg *schedv1alpha1.PodGroup, conditionType string, status metav1.ConditionStatus, reason, message string) bool {
+	changed := !meta.IsStatusConditionPresentAndEqual(pg.Status.Conditions, conditionType, status)
+	meta.SetStatusCondition(&pg.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: pg.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return changed
+}
+
 func (r *PodGroupReconciler) patchPodGroup(ctx context.Context, old, new *schedv1alpha1.PodGroup) (ctrl.Result, error) {
 	patch := client.MergeFrom(old)
 	if err := r.Status().Patch(ctx, new, patch); err != nil {
@@ -168,6 +258,16 @@ func getCurrentPodStats(pods []v1.Pod) (int32, int32, int32) {
 	return running, succeeded, failed
 }
 
+func getScheduledPods(pods []v1.Pod) int32 {
+	var scheduled int32
+	for _, pod := range pods {
+		if len(pod.Spec.NodeName) != 0 {
+			scheduled++
+		}
+	}
+	return scheduled
+}
+
 func fillOccupiedObj(pg *schedv1alpha1.PodGroup, pod *v1.Pod) {
 	if len(pod.OwnerReferences) == 0 {
 		return
@@ -23,6 +23,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -268,6 +269,168 @@ func TestFillGroupStatusOccupied(t *testing.T) {
 	}
 }
 
+func TestPodGroupConditions(t *testing.T) {
+	ctx := context.TODO()
+	createTime := metav1.Time{Time: time.Now().Add(-2 * time.Hour)}
+	unschedulable := metav1.Condition{
+		Type:    v1alpha1.PodGroupScheduled,
+		Status:  metav1.ConditionFalse,
+		Reason:  v1alpha1.PodGroupReasonUnschedulable,
+		Message: "0/3 nodes are available: 3 Insufficient cpu.",
+	}
+	cases := []struct {
+		name               string
+		minMember          int32
+		podPhase           v1.PodPhase
+		nodeName           string
+		podNames           []string
+		previousPhase      v1alpha1.PodGroupPhase
+		previousConditions []metav1.Condition
+		podGroupCreateTime *metav1.Time
+		scheduleTimeout    time.Duration
+		desiredGroupPhase  v1alpha1.PodGroupPhase
+		desiredScheduled   int32
+		desiredConditions  map[string]string
+	}{
+		{
+			name:              "new group is waiting for pods",
+			minMember:         2,
+			podPhase:          v1.PodPending,
+			podNames:          []string{"pod1"},
+			previousPhase:     "",
+			desiredGroupPhase: v1alpha1.PodGroupPending,
+			desiredConditions: map[string]string{
+				v1alpha1.PodGroupScheduled: v1alpha1.PodGroupReasonWaitingForPods,
+				v1alpha1.PodGroupReady:     v1alpha1.PodGroupReasonWaitingForPods,
+			},
+		},
+		{
+			name:               "reason reported by the scheduler is kept",
+			minMember:          2,
+			podPhase:           v1.PodPending,
+			podNames:           []string{"pod1", "pod2"},
+			previousPhase:      v1alpha1.PodGroupScheduling,
+			previousConditions: []metav1.Condition{unschedulable},
+			desiredGroupPhase:  v1alpha1.PodGroupScheduling,
+			desiredConditions: map[string]string{
+				v1alpha1.PodGroupScheduled: v1alpha1.PodGroupReasonUnschedulable,
+				v1alpha1.PodGroupReady:     v1alpha1.PodGroupReasonWaitingForPods,
+			},
+		},
+		{
+			name:               "group is scheduled and ready",
+			minMember:          2,
+			podPhase:           v1.PodRunning,
+			nodeName:           "node1",
+			podNames:           []string{"pod1", "pod2"},
+			previousPhase:      v1alpha1.PodGroupScheduling,
+			previousConditions: []metav1.Condition{unschedulable},
+			desiredGroupPhase:  v1alpha1.PodGroupRunning,
+			desiredScheduled:   2,
+			desiredConditions: map[string]string{
+				v1alpha1.PodGroupScheduled: v1alpha1.PodGroupReasonMinMemberScheduled,
+				v1alpha1.PodGroupReady:     v1alpha1.PodGroupReasonMinMemberReady,
+			},
+		},
+		{
+			name:          "group loses its quorum",
+			minMember:     3,
+			podPhase:      v1.PodRunning,
+			nodeName:      "node1",
+			podNames:      []string{"pod1", "pod2"},
+			previousPhase: v1alpha1.PodGroupRunning,
+			previousConditions: []metav1.Condition{
+				{Type: v1alpha1.PodGroupReady, Status: metav1.ConditionTrue, Reason: v1alpha1.PodGroupReasonMinMemberReady},
+			},
+			desiredGroupPhase: v1alpha1.PodGroupPending,
+			desiredScheduled:  2,
+			desiredConditions: map[string]string{
+				v1alpha1.PodGroupScheduled:  v1alpha1.PodGroupReasonWaitingForPods,
+				v1alpha1.PodGroupReady:      v1alpha1.PodGroupReasonWaitingForPods,
+				v1alpha1.PodGroupQuorumLost: v1alpha1.PodGroupReasonMinMemberNotMet,
+			},
+		},
+		{
+			name:               "group times out after the configured timeout",
+			minMember:          2,
+			podPhase:           v1.PodPending,
+			podNames:           []string{"pod1", "pod2"},
+			previousPhase:      v1alpha1.PodGroupPending,
+			podGroupCreateTime: &createTime,
+			scheduleTimeout:    time.Hour,
+			desiredGroupPhase:  v1alpha1.PodGroupPending,
+			desiredConditions: map[string]string{
+				v1alpha1.PodGroupTimedOut: v1alpha1.PodGroupReasonScheduleTimeout,
+			},
+		},
+		{
+			name:               "group does not time out before the configured timeout",
+			minMember:          2,
+			podPhase:           v1.PodPending,
+			podNames:           []string{"pod1", "pod2"},
+			previousPhase:      v1alpha1.PodGroupPending,
+			podGroupCreateTime: &createTime,
+			scheduleTimeout:    3 * time.Hour,
+			desiredGroupPhase:  v1alpha1.PodGroupScheduling,
+			desiredConditions: map[string]string{
+				v1alpha1.PodGroupScheduled: v1alpha1.PodGroupReasonWaitingForPods,
+				v1alpha1.PodGroupReady:     v1alpha1.PodGroupReasonWaitingForPods,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := scheme.Scheme
+			pg := makePG("pg", c.minMember, c.previousPhase, c.podGroupCreateTime)
+			pg.Status.Conditions = c.previousConditions
+			s.AddKnownTypes(v1alpha1.SchemeGroupVersion, pg)
+			objs := []runtime.Object{pg}
+			for _, p := range makePods(c.podNames, "pg", c.podPhase, nil) {
+				p.Spec.NodeName = c.nodeName
+				objs = append(objs, p)
+			}
+			kClient := fake.NewClientBuilder().
+				WithScheme(s).
+				WithStatusSubresource(&v1alpha1.PodGroup{}).
+				WithRuntimeObjects(objs...).
+				Build()
+			controller := &PodGroupReconciler{
+				Client:          kClient,
+				Scheme:          s,
+				ScheduleTimeout: c.scheduleTimeout,
+				recorder:        record.NewFakeRecorder(3),
+				log:             klogr.New().WithName("podGroupTest"),
+			}
+
+			if _, err := controller.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(pg)}); err != nil {
+				t.Fatalf("reconcile: (%v)", err)
+			}
+			got := &v1alpha1.PodGroup{}
+			if err := kClient.Get(ctx, client.ObjectKeyFromObject(pg), got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.Phase != c.desiredGroupPhase {
+				t.Errorf("want phase %v, got %v", c.desiredGroupPhase, got.Status.Phase)
+			}
+			if got.Status.Scheduled != c.desiredScheduled {
+				t.Errorf("want %v scheduled pods, got %v", c.desiredScheduled, got.Status.Scheduled)
+			}
+			if got.Status.Phase != c.previousPhase {
+				if _, ok := got.Status.PhaseTransitionTimes[got.Status.Phase]; !ok {
+					t.Errorf("want a transition time for phase %v", got.Status.Phase)
+				}
+			}
+			reasons := make(map[string]string)
+			for _, cond := range got.Status.Conditions {
+				reasons[cond.Type] = cond.Reason
+			}
+			if diff := cmp.Diff(c.desiredConditions, reasons); diff != "" {
+				t.Errorf("unexpected condition reasons (-want, +got): %s", diff)
+			}
+		})
+	}
+}
+
 func setUp(ctx context.Context,
 	podNames []string,
 	pgName string,
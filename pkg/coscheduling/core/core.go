@@ -24,12 +24,16 @@ import (
 
 	gochache "github.com/patrickmn/go-cache"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	informerv1 "k8s.io/client-go/informers/core/v1"
 	listerv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -70,6 +74,9 @@ type Manager interface {
 	CalculateAssignedPods(string, string) int
 	ActivateSiblings(pod *corev1.Pod, state *framework.CycleState)
 	BackoffPodGroup(string, time.Duration)
+	GetTopologyDomain(context.Context, *corev1.Pod) (*TopologyDomain, error)
+	RejectTopologyDomain(string, string)
+	ReportUnschedulable(context.Context, *v1alpha1.PodGroup, string)
 }
 
 // PodGroupManager defines the scheduling operation called
@@ -85,24 +92,47 @@ type PodGroupManager struct {
 	permittedPG *gochache.Cache
 	// backedOffPG stores the podgorup name which failed scheudling recently.
 	backedOffPG *gochache.Cache
+	// topologyStates stores the topology domain a podgroup is pinned to and the domains it failed in.
+	topologyStates *gochache.Cache
 	// podLister is pod lister
 	podLister listerv1.PodLister
+	// unschedulableMessages stores the message to report in the Scheduled condition of each
+	// podgroup queued in statusQueue.
+	unschedulableMessages map[string]string
+	// statusQueue holds the podgroups whose status is to be patched, off the scheduling cycle.
+	statusQueue workqueue.RateLimitingInterface
 	sync.RWMutex
 }
 
 // NewPodGroupManager creates a new operation object.
 func NewPodGroupManager(client client.Client, snapshotSharedLister framework.SharedLister, scheduleTimeout *time.Duration, podInformer informerv1.PodInformer) *PodGroupManager {
 	pgMgr := &PodGroupManager{
-		client:               client,
-		snapshotSharedLister: snapshotSharedLister,
-		scheduleTimeout:      scheduleTimeout,
-		podLister:            podInformer.Lister(),
-		permittedPG:          gochache.New(3*time.Second, 3*time.Second),
-		backedOffPG:          gochache.New(10*time.Second, 10*time.Second),
+		client:                client,
+		snapshotSharedLister:  snapshotSharedLister,
+		scheduleTimeout:       scheduleTimeout,
+		podLister:             podInformer.Lister(),
+		permittedPG:           gochache.New(3*time.Second, 3*time.Second),
+		backedOffPG:           gochache.New(10*time.Second, 10*time.Second),
+		topologyStates:        gochache.New(topologyStateTTL, topologyStateTTL),
+		unschedulableMessages: make(map[string]string),
+		statusQueue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "PodGroupStatus"),
 	}
 	return pgMgr
 }
 
+// StartStatusWorker starts the worker patching the status of the podgroups reported by
+// ReportUnschedulable, until the context is done.
+func (pgMgr *PodGroupManager) StartStatusWorker(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		pgMgr.statusQueue.ShutDown()
+	}()
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		for pgMgr.processNextStatus(ctx) {
+		}
+	}, time.Second)
+}
+
 func (pgMgr *PodGroupManager) BackoffPodGroup(pgName string, backoff time.Duration) {
 	if backoff == time.Duration(0) {
 		return
@@ -260,6 +290,78 @@ func (pgMgr *PodGroupManager) DeletePermittedPodGroup(pgFullName string) {
 	pgMgr.permittedPG.Delete(pgFullName)
 }
 
+// ReportUnschedulable records why a PodGroup cannot be scheduled in its Scheduled condition,
+// so that it can be seen without reading the scheduler logs. The status is patched
+// asynchronously, with the last message reported.
+func (pgMgr *PodGroupManager) ReportUnschedulable(ctx context.Context, pg *v1alpha1.PodGroup, message string) {
+	if cond := meta.FindStatusCondition(pg.Status.Conditions, v1alpha1.PodGroupScheduled); cond != nil &&
+		cond.Status == metav1.ConditionFalse && cond.Reason == v1alpha1.PodGroupReasonUnschedulable && cond.Message == message {
+		return
+	}
+	key := types.NamespacedName{Namespace: pg.Namespace, Name: pg.Name}.String()
+	pgMgr.Lock()
+	pgMgr.unschedulableMessages[key] = message
+	pgMgr.Unlock()
+	pgMgr.statusQueue.Add(key)
+}
+
+// processNextStatus patches the status of the next podgroup in the queue.
+// Returns false once the queue is shut down.
+func (pgMgr *PodGroupManager) processNextStatus(ctx context.Context) bool {
+	item, shutdown := pgMgr.statusQueue.Get()
+	if shutdown {
+		return false
+	}
+	defer pgMgr.statusQueue.Done(item)
+
+	key := item.(string)
+	pgMgr.Lock()
+	message, ok := pgMgr.unschedulableMessages[key]
+	delete(pgMgr.unschedulableMessages, key)
+	pgMgr.Unlock()
+	if !ok {
+		return true
+	}
+	if err := pgMgr.patchUnschedulable(ctx, key, message); err != nil {
+		klog.ErrorS(err, "Failed to update the status of the pod group", "podGroup", key)
+		pgMgr.Lock()
+		// Do not overwrite a message reported since.
+		if _, ok := pgMgr.unschedulableMessages[key]; !ok {
+			pgMgr.unschedulableMessages[key] = message
+		}
+		pgMgr.Unlock()
+		pgMgr.statusQueue.AddRateLimited(item)
+		return true
+	}
+	pgMgr.statusQueue.Forget(item)
+	return true
+}
+
+// patchUnschedulable sets the Scheduled condition of the podgroup to Unschedulable with the message.
+func (pgMgr *PodGroupManager) patchUnschedulable(ctx context.Context, key, message string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	pg := &v1alpha1.PodGroup{}
+	if err := pgMgr.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, pg); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if cond := meta.FindStatusCondition(pg.Status.Conditions, v1alpha1.PodGroupScheduled); cond != nil &&
+		cond.Status == metav1.ConditionFalse && cond.Reason == v1alpha1.PodGroupReasonUnschedulable && cond.Message == message {
+		return nil
+	}
+	pgCopy := pg.DeepCopy()
+	meta.SetStatusCondition(&pgCopy.Status.Conditions, metav1.Condition{
+		Type:               v1alpha1.PodGroupScheduled,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: pg.Generation,
+		Reason:             v1alpha1.PodGroupReasonUnschedulable,
+		Message:            message,
+	})
+	return pgMgr.client.Status().Patch(ctx, pgCopy, client.MergeFrom(pg))
+}
+
 // GetPodGroup returns the PodGroup that a Pod belongs to in cache.
 func (pgMgr *PodGroupManager) GetPodGroup(ctx context.Context, pod *corev1.Pod) (string, *v1alpha1.PodGroup) {
 	pgName := util.GetPodGroupLabel(pod)
@@ -23,13 +23,19 @@ import (
 
 	gochache "github.com/patrickmn/go-cache"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/informers"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
 	clicache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	tu "sigs.k8s.io/scheduler-plugins/test/util"
 )
@@ -360,3 +366,50 @@ func TestCheckClusterResource(t *testing.T) {
 func newCache() *gochache.Cache {
 	return gochache.New(10*time.Second, 10*time.Second)
 }
+
+func TestReportUnschedulable(t *testing.T) {
+	ctx := context.Background()
+	pg := tu.MakePodGroup().Name("pg").Namespace("ns").MinMember(2).Obj()
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	// The status of a PodGroup is a subresource, so it can only be updated via the status client.
+	client := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&v1alpha1.PodGroup{}).
+		WithRuntimeObjects(pg).
+		Build()
+	pgMgr := &PodGroupManager{
+		client:                client,
+		unschedulableMessages: make(map[string]string),
+		statusQueue:           workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+
+	for _, message := range []string{"0/2 nodes are available: 2 Insufficient cpu.", "0/2 nodes are available: 2 Insufficient memory."} {
+		got := &v1alpha1.PodGroup{}
+		if err := client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pg"}, got); err != nil {
+			t.Fatal(err)
+		}
+		pgMgr.ReportUnschedulable(ctx, got, message)
+		// The status is only patched by the worker.
+		if cond := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.PodGroupScheduled); cond != nil && cond.Message == message {
+			t.Errorf("want the status patched by the worker, got message %q already", cond.Message)
+		}
+		if !pgMgr.processNextStatus(ctx) {
+			t.Fatal("status queue shut down")
+		}
+
+		if err := client.Get(ctx, types.NamespacedName{Namespace: "ns", Name: "pg"}, got); err != nil {
+			t.Fatal(err)
+		}
+		cond := meta.FindStatusCondition(got.Status.Conditions, v1alpha1.PodGroupScheduled)
+		if cond == nil {
+			t.Fatalf("want a %v condition, got none", v1alpha1.PodGroupScheduled)
+		}
+		if cond.Status != metav1.ConditionFalse || cond.Reason != v1alpha1.PodGroupReasonUnschedulable || cond.Message != message {
+			t.Errorf("want condition %v/%v with message %q, got %v/%v with message %q",
+				metav1.ConditionFalse, v1alpha1.PodGroupReasonUnschedulable, message, cond.Status, cond.Reason, cond.Message)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// topologyStateTTL is how long a PodGroup stays pinned to a topology domain and
+// remembers the domains it failed to assemble in.
+const topologyStateTTL = 10 * time.Minute
+
+// TopologyDomain is a set of nodes that share the same values of some node labels.
+type TopologyDomain struct {
+	// Labels maps each topology key of the domain to its value.
+	Labels map[string]string
+	// Nodes are the names of the nodes in the domain.
+	Nodes sets.Set[string]
+}
+
+// Name returns the domain as key=value pairs sorted by key.
+func (d *TopologyDomain) Name() string {
+	return labels.Set(d.Labels).String()
+}
+
+// Matches returns true if the node is in the domain.
+func (d *TopologyDomain) Matches(node *corev1.Node) bool {
+	return node != nil && labels.SelectorFromSet(d.Labels).Matches(labels.Set(node.Labels))
+}
+
+// topologyState is the topology domain bookkeeping of a PodGroup.
+type topologyState struct {
+	// pinned holds the labels of the domain the PodGroup assembles in, if any.
+	pinned map[string]string
+	// failed holds the names of the domains the PodGroup failed to assemble in.
+	failed sets.Set[string]
+}
+
+// GetTopologyDomain returns the topology domain the pod has to be placed in, or nil if
+// its PodGroup has no topology constraint or only preferred keys none of which fits.
+// The first domain where the whole PodGroup fits is pinned, so that the later members
+// are placed in the same domain, until RejectTopologyDomain is called.
+func (pgMgr *PodGroupManager) GetTopologyDomain(ctx context.Context, pod *corev1.Pod) (*TopologyDomain, error) {
+	pgFullName, pg := pgMgr.GetPodGroup(ctx, pod)
+	if pg == nil || pg.Spec.TopologyConstraint == nil {
+		return nil, nil
+	}
+	nodes, err := pgMgr.snapshotSharedLister.NodeInfos().List()
+	if err != nil {
+		return nil, err
+	}
+
+	pgMgr.Lock()
+	defer pgMgr.Unlock()
+	state := pgMgr.getTopologyState(pgFullName)
+	if state.pinned != nil {
+		return newTopologyDomain(state.pinned, nodes), nil
+	}
+
+	candidates := topologyKeyCandidates(pg.Spec.TopologyConstraint)
+	// The members that are already assigned, e.g. before the scheduler restarted, decide the domain.
+	// It is kept even after a failure there, as any other domain would split the PodGroup.
+	if domain := assignedTopologyDomain(pg, candidates, nodes); domain != nil {
+		state.failed.Delete(domain.Name())
+		state.pinned = domain.Labels
+		return domain, nil
+	}
+
+	request := groupRequest(pg, pod)
+	for _, keys := range candidates {
+		for _, domain := range topologyDomains(keys, nodes) {
+			if state.failed.Has(domain.Name()) {
+				continue
+			}
+			var domainNodes []*framework.NodeInfo
+			for _, node := range nodes {
+				if domain.Matches(node.Node()) {
+					domainNodes = append(domainNodes, node)
+				}
+			}
+			if err := CheckClusterResource(ctx, domainNodes, request.DeepCopy(), pgFullName); err != nil {
+				klog.V(5).InfoS("PodGroup does not fit in topology domain", "podGroup", klog.KObj(pg), "domain", domain.Name(), "err", err)
+				continue
+			}
+			klog.V(3).InfoS("Pinned PodGroup to topology domain", "podGroup", klog.KObj(pg), "domain", domain.Name())
+			state.pinned = domain.Labels
+			return domain, nil
+		}
+	}
+
+	// Every domain has been tried, so give them all another chance from the next attempt on.
+	state.failed = sets.New[string]()
+	if len(pg.Spec.TopologyConstraint.RequiredTopologyKeys) == 0 {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("no domain of topology keys %v can hold podGroup %v", pg.Spec.TopologyConstraint.RequiredTopologyKeys, pgFullName)
+}
+
+// RejectTopologyDomain unpins a PodGroup from its topology domain, which is then skipped
+// when a domain is picked for the PodGroup again. If nodeName is not empty, the domain
+// is only rejected if the node belongs to it.
+func (pgMgr *PodGroupManager) RejectTopologyDomain(pgFullName, nodeName string) {
+	pgMgr.Lock()
+	defer pgMgr.Unlock()
+	obj, ok := pgMgr.topologyStates.Get(pgFullName)
+	if !ok {
+		return
+	}
+	state := obj.(*topologyState)
+	if state.pinned == nil {
+		return
+	}
+	domain := &TopologyDomain{Labels: state.pinned}
+	if nodeName != "" {
+		nodeInfo, err := pgMgr.snapshotSharedLister.NodeInfos().Get(nodeName)
+		if err != nil || !domain.Matches(nodeInfo.Node()) {
+			return
+		}
+	}
+	klog.V(3).InfoS("PodGroup failed to assemble in topology domain", "podGroup", pgFullName, "domain", domain.Name())
+	state.failed.Insert(domain.Name())
+	state.pinned = nil
+}
+
+// getTopologyState returns the topology state of a PodGroup, creating it if needed.
+// The caller must hold the lock of the PodGroupManager.
+func (pgMgr *PodGroupManager) getTopologyState(pgFullName string) *topologyState {
+	if obj, ok := pgMgr.topologyStates.Get(pgFullName); ok {
+		return obj.(*topologyState)
+	}
+	state := &topologyState{failed: sets.New[string]()}
+	pgMgr.topologyStates.Set(pgFullName, state, topologyStateTTL)
+	return state
+}
+
+// topologyKeyCandidates returns the sets of keys whose domains a PodGroup may be placed
+// in, from the most to the least preferred one.
+func topologyKeyCandidates(tc *v1alpha1.PodGroupTopologyConstraint) [][]string {
+	var candidates [][]string
+	for _, key := range tc.PreferredTopologyKeys {
+		keys := sets.New(tc.RequiredTopologyKeys...).Insert(key)
+		candidates = append(candidates, sets.List(keys))
+	}
+	if len(tc.RequiredTopologyKeys) != 0 {
+		candidates = append(candidates, sets.List(sets.New(tc.RequiredTopologyKeys...)))
+	}
+	return candidates
+}
+
+// topologyDomains groups the nodes that have all the keys by their values, sorted by name.
+func topologyDomains(keys []string, nodes []*framework.NodeInfo) []*TopologyDomain {
+	domains := make(map[string]*TopologyDomain)
+	for _, nodeInfo := range nodes {
+		node := nodeInfo.Node()
+		if node == nil {
+			continue
+		}
+		values, ok := topologyValues(keys, node)
+		if !ok {
+			continue
+		}
+		name := labels.Set(values).String()
+		if _, ok := domains[name]; !ok {
+			domains[name] = &TopologyDomain{Labels: values, Nodes: sets.New[string]()}
+		}
+		domains[name].Nodes.Insert(node.Name)
+	}
+
+	result := make([]*TopologyDomain, 0, len(domains))
+	for _, domain := range domains {
+		result = append(result, domain)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name() < result[j].Name() })
+	return result
+}
+
+// assignedTopologyDomain returns the domain of the first candidate that holds all the
+// assigned members of the PodGroup, or nil if no member is assigned.
+func assignedTopologyDomain(pg *v1alpha1.PodGroup, candidates [][]string, nodes []*framework.NodeInfo) *TopologyDomain {
+	var assigned []*corev1.Node
+	for _, nodeInfo := range nodes {
+		for _, podInfo := range nodeInfo.Pods {
+			pod := podInfo.Pod
+			if util.GetPodGroupLabel(pod) == pg.Name && pod.Namespace == pg.Namespace && pod.Spec.NodeName != "" {
+				assigned = append(assigned, nodeInfo.Node())
+				break
+			}
+		}
+	}
+	if len(assigned) == 0 {
+		return nil
+	}
+
+	for _, keys := range candidates {
+		values, ok := topologyValues(keys, assigned[0])
+		if !ok {
+			continue
+		}
+		domain := &TopologyDomain{Labels: values}
+		matches := true
+		for _, node := range assigned[1:] {
+			if !domain.Matches(node) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return newTopologyDomain(values, nodes)
+		}
+	}
+	return nil
+}
+
+// newTopologyDomain returns the domain of the given labels with its current nodes.
+func newTopologyDomain(values map[string]string, nodes []*framework.NodeInfo) *TopologyDomain {
+	domain := &TopologyDomain{Labels: values, Nodes: sets.New[string]()}
+	for _, nodeInfo := range nodes {
+		if domain.Matches(nodeInfo.Node()) {
+			domain.Nodes.Insert(nodeInfo.Node().Name)
+		}
+	}
+	return domain
+}
+
+// topologyValues returns the values of the keys on the node, and false if one is missing.
+func topologyValues(keys []string, node *corev1.Node) (map[string]string, bool) {
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		value, ok := node.Labels[key]
+		if !ok {
+			return nil, false
+		}
+		values[key] = value
+	}
+	return values, true
+}
+
+// groupRequest returns the resources the whole PodGroup needs: its minResources if set,
+// or else minMember times the request of the given pod, plus minMember pods.
+func groupRequest(pg *v1alpha1.PodGroup, pod *corev1.Pod) corev1.ResourceList {
+	var request corev1.ResourceList
+	if pg.Spec.MinResources != nil {
+		request = pg.Spec.MinResources.DeepCopy()
+	} else {
+		request = util.GetPodEffectiveRequest(pod)
+		for name, quantity := range request {
+			quantity.Mul(int64(pg.Spec.MinMember))
+			request[name] = quantity
+		}
+	}
+	request[corev1.ResourcePods] = *resource.NewQuantity(int64(pg.Spec.MinMember), resource.DecimalSI)
+	return request
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestGetTopologyDomain(t *testing.T) {
+	const (
+		zoneKey = corev1.LabelTopologyZone
+		rackKey = "example.com/rack"
+	)
+	scheduleTimeout := 10 * time.Second
+	node := func(name, zone, rack, cpu string) *corev1.Node {
+		w := st.MakeNode().Name(name).Capacity(map[corev1.ResourceName]string{corev1.ResourceCPU: cpu, corev1.ResourcePods: "10"})
+		if zone != "" {
+			w = w.Label(zoneKey, zone)
+		}
+		if rack != "" {
+			w = w.Label(rackKey, rack)
+		}
+		return w.Obj()
+	}
+	nodes := []*corev1.Node{
+		node("n1", "a", "r1", "2"),
+		node("n2", "a", "r2", "3"),
+		node("n3", "b", "r3", "4"),
+		node("n4", "", "", "8"),
+	}
+	pod := st.MakePod().Name("p").Namespace("ns").UID("p").Label(v1alpha1.PodGroupLabel, "pg").
+		Req(map[corev1.ResourceName]string{corev1.ResourceCPU: "1"}).Obj()
+	pg := func(minMember int32, required, preferred []string) *tu.PodGroupWrapper {
+		w := tu.MakePodGroup().Name("pg").Namespace("ns").MinMember(minMember)
+		if required != nil || preferred != nil {
+			w = w.TopologyConstraint(required, preferred)
+		}
+		return w
+	}
+
+	tests := []struct {
+		name         string
+		pg           *v1alpha1.PodGroup
+		existingPods []*corev1.Pod
+		// rejections is the number of times the pinned domain is rejected before the last attempt.
+		rejections int
+		want       string
+		wantNodes  []string
+		wantErr    bool
+	}{
+		{
+			name: "no topology constraint",
+			pg:   pg(3, nil, nil).Obj(),
+		},
+		{
+			name:      "first zone where the group fits",
+			pg:        pg(3, []string{zoneKey}, nil).Obj(),
+			want:      zoneKey + "=a",
+			wantNodes: []string{"n1", "n2"},
+		},
+		{
+			name:    "no zone where the group fits",
+			pg:      pg(6, []string{zoneKey}, nil).Obj(),
+			wantErr: true,
+		},
+		{
+			name:      "minResources decide whether the group fits",
+			pg:        pg(2, []string{zoneKey}, nil).MinResources(map[corev1.ResourceName]string{corev1.ResourceCPU: "4"}).Obj(),
+			want:      zoneKey + "=a",
+			wantNodes: []string{"n1", "n2"},
+		},
+		{
+			name:      "preferred rack within the required zone",
+			pg:        pg(3, []string{zoneKey}, []string{rackKey}).Obj(),
+			want:      rackKey + "=r2," + zoneKey + "=a",
+			wantNodes: []string{"n2"},
+		},
+		{
+			name:      "fall back to the required zone when no rack fits",
+			pg:        pg(5, []string{zoneKey}, []string{rackKey}).Obj(),
+			want:      zoneKey + "=a",
+			wantNodes: []string{"n1", "n2"},
+		},
+		{
+			name: "no constraint when no preferred rack fits",
+			pg:   pg(5, nil, []string{rackKey}).Obj(),
+		},
+		{
+			name: "assigned members decide the domain",
+			pg:   pg(3, []string{zoneKey}, nil).Obj(),
+			existingPods: []*corev1.Pod{
+				st.MakePod().Name("p0").Namespace("ns").UID("p0").Label(v1alpha1.PodGroupLabel, "pg").Node("n3").Obj(),
+			},
+			want:      zoneKey + "=b",
+			wantNodes: []string{"n3"},
+		},
+		{
+			name: "keep the domain of the assigned members after a rejection",
+			pg:   pg(3, []string{zoneKey}, nil).Obj(),
+			existingPods: []*corev1.Pod{
+				st.MakePod().Name("p0").Namespace("ns").UID("p0").Label(v1alpha1.PodGroupLabel, "pg").Node("n3").Obj(),
+			},
+			rejections: 1,
+			want:       zoneKey + "=b",
+			wantNodes:  []string{"n3"},
+		},
+		{
+			name:       "try the next zone after a rejection",
+			pg:         pg(3, []string{zoneKey}, nil).Obj(),
+			rejections: 1,
+			want:       zoneKey + "=b",
+			wantNodes:  []string{"n3"},
+		},
+		{
+			name:       "start over once every zone has been rejected",
+			pg:         pg(3, []string{zoneKey}, nil).Obj(),
+			rejections: 2,
+			want:       zoneKey + "=a",
+			wantNodes:  []string{"n1", "n2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			client, err := tu.NewFakeClient(tt.pg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pgMgr := &PodGroupManager{
+				client:               client,
+				snapshotSharedLister: tu.NewFakeSharedLister(tt.existingPods, nodes),
+				scheduleTimeout:      &scheduleTimeout,
+				permittedPG:          newCache(),
+				backedOffPG:          newCache(),
+				topologyStates:       newCache(),
+			}
+
+			for i := 0; i < tt.rejections; i++ {
+				if _, err := pgMgr.GetTopologyDomain(ctx, pod); err != nil {
+					t.Fatal(err)
+				}
+				pgMgr.RejectTopologyDomain("ns/pg", "")
+			}
+			if tt.rejections == 2 {
+				// Both zones have been rejected, so this attempt fails and resets them.
+				if _, err := pgMgr.GetTopologyDomain(ctx, pod); err == nil {
+					t.Fatal("Expected an error once every zone has been rejected")
+				}
+			}
+
+			domain, err := pgMgr.GetTopologyDomain(ctx, pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Want error %v, but got %v", tt.wantErr, err)
+			}
+			var got string
+			var gotNodes []string
+			if domain != nil {
+				got = domain.Name()
+				gotNodes = sets.List(domain.Nodes)
+			}
+			if got != tt.want {
+				t.Errorf("Want domain %q, but got %q", tt.want, got)
+			}
+			if !reflect.DeepEqual(gotNodes, tt.wantNodes) {
+				t.Errorf("Want nodes %v, but got %v", tt.wantNodes, gotNodes)
+			}
+		})
+	}
+}
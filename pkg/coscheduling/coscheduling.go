@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	policylisters "k8s.io/client-go/listers/policy/v1"
 	"k8s.io/client-go/tools/cache"
 	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/klog/v2"
@@ -32,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/coscheduling/core"
@@ -44,10 +46,13 @@ type Coscheduling struct {
 	pgMgr            core.Manager
 	scheduleTimeout  *time.Duration
 	pgBackoff        *time.Duration
+	preemptionMode   config.PreemptionModeType
+	pdbLister        policylisters.PodDisruptionBudgetLister
 }
 
 var _ framework.QueueSortPlugin = &Coscheduling{}
 var _ framework.PreFilterPlugin = &Coscheduling{}
+var _ framework.FilterPlugin = &Coscheduling{}
 var _ framework.PostFilterPlugin = &Coscheduling{}
 var _ framework.PermitPlugin = &Coscheduling{}
 var _ framework.ReservePlugin = &Coscheduling{}
@@ -57,14 +62,28 @@ var _ framework.EnqueueExtensions = &Coscheduling{}
 const (
 	// Name is the name of the plugin used in Registry and configurations.
 	Name = "Coscheduling"
+
+	topologyDomainStateKey = "PreFilter" + Name
 )
 
+// topologyDomainState is the topology domain a pod is pinned to in a scheduling cycle.
+type topologyDomainState struct {
+	domain *core.TopologyDomain
+}
+
+func (s *topologyDomainState) Clone() framework.StateData {
+	return s
+}
+
 // New initializes and returns a new Coscheduling plugin.
-func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	args, ok := obj.(*config.CoschedulingArgs)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type CoschedulingArgs, got %T", obj)
 	}
+	if err := validation.ValidateCoschedulingArgs(nil, args); err != nil {
+		return nil, err
+	}
 
 	scheme := runtime.NewScheme()
 	_ = clientscheme.AddToScheme(scheme)
@@ -86,10 +105,15 @@ func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framew
 		// Keep the podInformer (from frameworkHandle) as the single source of Pods.
 		handle.SharedInformerFactory().Core().V1().Pods(),
 	)
+	pgMgr.StartStatusWorker(ctx)
 	plugin := &Coscheduling{
 		frameworkHandler: handle,
 		pgMgr:            pgMgr,
 		scheduleTimeout:  &scheduleTimeDuration,
+		preemptionMode:   args.PreemptionMode,
+	}
+	if args.PreemptionMode == config.PreemptionModeGang {
+		plugin.pdbLister = handle.SharedInformerFactory().Policy().V1().PodDisruptionBudgets().Lister()
 	}
 	if args.PodGroupBackoffSeconds < 0 {
 		err := fmt.Errorf("parse arguments failed")
@@ -138,6 +162,8 @@ func (cs *Coscheduling) Less(podInfo1, podInfo2 *framework.QueuedPodInfo) bool {
 // PreFilter performs the following validations.
 // 1. Whether the PodGroup that the Pod belongs to is on the deny list.
 // 2. Whether the total number of pods in a PodGroup is less than its `minMember`.
+// 3. Whether there is a topology domain where the PodGroup fits, if it has a topology constraint.
+// In the last case, the nodes are narrowed down to the domain the PodGroup is pinned to.
 func (cs *Coscheduling) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
 	// If PreFilter fails, return framework.UnschedulableAndUnresolvable to avoid
 	// any preemption attempts.
@@ -145,10 +171,37 @@ func (cs *Coscheduling) PreFilter(ctx context.Context, state *framework.CycleSta
 		klog.ErrorS(err, "PreFilter failed", "pod", klog.KObj(pod))
 		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
 	}
-	return nil, framework.NewStatus(framework.Success, "")
+	domain, err := cs.pgMgr.GetTopologyDomain(ctx, pod)
+	if err != nil {
+		klog.ErrorS(err, "PreFilter failed", "pod", klog.KObj(pod))
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+	}
+	if domain == nil {
+		return nil, framework.NewStatus(framework.Success, "")
+	}
+	state.Write(topologyDomainStateKey, &topologyDomainState{domain: domain})
+	return &framework.PreFilterResult{NodeNames: domain.Nodes}, framework.NewStatus(framework.Success, "")
+}
+
+// Filter rejects the nodes out of the topology domain the PodGroup of the pod is pinned to.
+// PreFilter already narrows the nodes down to the domain, but preemption evaluates
+// the nodes with the filter plugins only.
+func (cs *Coscheduling) Filter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	c, err := state.Read(topologyDomainStateKey)
+	if err != nil {
+		return nil
+	}
+	s, ok := c.(*topologyDomainState)
+	if !ok || s.domain.Matches(nodeInfo.Node()) {
+		return nil
+	}
+	return framework.NewStatus(framework.UnschedulableAndUnresolvable,
+		fmt.Sprintf("node is out of the topology domain %v of the PodGroup", s.domain.Name()))
 }
 
 // PostFilter is used to reject a group of pods if a pod does not pass PreFilter or Filter.
+// In the Gang preemption mode, it first tries to preempt lower-priority pods so that
+// the whole PodGroup fits, and only rejects the group if that is not possible.
 func (cs *Coscheduling) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod,
 	filteredNodeStatusMap framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
 	pgName, pg := cs.pgMgr.GetPodGroup(ctx, pod)
@@ -165,6 +218,20 @@ func (cs *Coscheduling) PostFilter(ctx context.Context, state *framework.CycleSt
 		return &framework.PostFilterResult{}, framework.NewStatus(framework.Unschedulable)
 	}
 
+	if cs.preemptionMode == config.PreemptionModeGang {
+		// Victims of an earlier gang preemption are still terminating, so wait for them
+		// instead of rejecting the PodGroup or evicting more pods.
+		if cs.preemptionInProgress(pod) {
+			return &framework.PostFilterResult{}, framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("PodGroup %v is waiting for preempted pods to terminate", pgName))
+		}
+		result, status := cs.preemptPodGroup(ctx, state, pod, pg, assigned, filteredNodeStatusMap)
+		if status.IsSuccess() {
+			return result, status
+		}
+		klog.V(4).InfoS("Gang preemption failed", "podGroup", klog.KObj(pg), "pod", klog.KObj(pod), "reason", status.Message())
+	}
+
 	// If the gap is less than/equal 10%, we may want to try subsequent Pods
 	// to see they can satisfy the PodGroup
 	notAssignedPercentage := float32(int(pg.Spec.MinMember)-assigned) / float32(pg.Spec.MinMember)
@@ -191,11 +258,31 @@ func (cs *Coscheduling) PostFilter(ctx context.Context, state *framework.CycleSt
 		}
 	}
 
+	cs.pgMgr.RejectTopologyDomain(pgName, "")
 	cs.pgMgr.DeletePermittedPodGroup(pgName)
+	cs.pgMgr.ReportUnschedulable(ctx, pg, cs.unschedulableMessage(pod, filteredNodeStatusMap))
 	return &framework.PostFilterResult{}, framework.NewStatus(framework.Unschedulable,
 		fmt.Sprintf("PodGroup %v gets rejected due to Pod %v is unschedulable even after PostFilter", pgName, pod.Name))
 }
 
+// unschedulableMessage summarizes why the pod did not fit on the nodes, the same way
+// the scheduler reports it in the PodScheduled condition of the pod.
+func (cs *Coscheduling) unschedulableMessage(pod *v1.Pod, filteredNodeStatusMap framework.NodeToStatusMap) string {
+	numAllNodes := len(filteredNodeStatusMap)
+	if lister := cs.frameworkHandler.SnapshotSharedLister(); lister != nil {
+		if nodeInfos, err := lister.NodeInfos().List(); err == nil {
+			numAllNodes = len(nodeInfos)
+		}
+	}
+	fitErr := &framework.FitError{
+		Pod:         pod,
+		NumAllNodes: numAllNodes,
+		Diagnosis:   framework.Diagnosis{NodeToStatusMap: filteredNodeStatusMap},
+	}
+	// The message leaves the pod out, so that it does not change with every member of the PodGroup.
+	return fitErr.Error()
+}
+
 // PreFilterExtensions returns a PreFilterExtensions interface if the plugin implements one.
 func (cs *Coscheduling) PreFilterExtensions() framework.PreFilterExtensions {
 	return nil
@@ -253,5 +340,7 @@ func (cs *Coscheduling) Unreserve(ctx context.Context, state *framework.CycleSta
 			waitingPod.Reject(cs.Name(), "rejection in Unreserve")
 		}
 	})
+	// The PodGroup failed to assemble, so let it try another topology domain next time.
+	cs.pgMgr.RejectTopologyDomain(pgName, nodeName)
 	cs.pgMgr.DeletePermittedPodGroup(pgName)
 }
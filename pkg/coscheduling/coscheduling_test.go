@@ -27,6 +27,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	clientsetfake "k8s.io/client-go/kubernetes/fake"
 	clicache "k8s.io/client-go/tools/cache"
@@ -633,3 +634,58 @@ func TestPostFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestTopologyConstraint(t *testing.T) {
+	scheduleTimeout := 10 * time.Second
+	capacity := map[v1.ResourceName]string{v1.ResourceCPU: "2", v1.ResourcePods: "10"}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-a").Label(v1.LabelTopologyZone, "a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b").Label(v1.LabelTopologyZone, "b").Capacity(capacity).Obj(),
+	}
+	pod := st.MakePod().Name("p1").Namespace("ns").UID("p1").Label(v1alpha1.PodGroupLabel, "pg1").
+		Req(map[v1.ResourceName]string{v1.ResourceCPU: "1"}).Obj()
+	pods := []*v1.Pod{
+		pod,
+		st.MakePod().Name("p2").Namespace("ns").UID("p2").Label(v1alpha1.PodGroupLabel, "pg1").Obj(),
+	}
+	existingPods := []*v1.Pod{
+		st.MakePod().Name("busy").Namespace("ns").UID("busy").Node("node-a").Req(map[v1.ResourceName]string{v1.ResourceCPU: "1"}).Obj(),
+	}
+	pg := tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).TopologyConstraint([]string{v1.LabelTopologyZone}, nil).Obj()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, err := tu.NewFakeClient(pg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := clientsetfake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	podInformer := informerFactory.Core().V1().Pods()
+	for _, p := range pods {
+		podInformer.Informer().GetStore().Add(p)
+	}
+	snapshot := tu.NewFakeSharedLister(existingPods, nodes)
+	pl := &Coscheduling{
+		pgMgr:           core.NewPodGroupManager(client, snapshot, &scheduleTimeout, podInformer),
+		scheduleTimeout: &scheduleTimeout,
+	}
+
+	// Zone "a" only has room for one member, so the PodGroup is pinned to zone "b".
+	state := framework.NewCycleState()
+	result, status := pl.PreFilter(ctx, state, pod)
+	if !status.IsSuccess() {
+		t.Fatalf("Unexpected PreFilter status: %v", status)
+	}
+	if diff := cmp.Diff([]string{"node-b"}, sets.List(result.NodeNames)); diff != "" {
+		t.Errorf("Unexpected PreFilter nodes (-want,+got):\n%s", diff)
+	}
+	for _, node := range nodes {
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		got := pl.Filter(ctx, state, pod, nodeInfo)
+		if want := node.Name == "node-b"; got.IsSuccess() != want {
+			t.Errorf("Want Filter to succeed on %v: %v, but got %v", node.Name, want, got)
+		}
+	}
+}
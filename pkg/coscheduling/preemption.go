@@ -0,0 +1,402 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coscheduling
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/coscheduling/core"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// gangPlacement is the node picked for one member of a PodGroup by the dry run.
+type gangPlacement struct {
+	member *v1.Pod
+	node   string
+}
+
+// gangVictim is a pod to be evicted to make room for a PodGroup.
+type gangVictim struct {
+	pod  *v1.Pod
+	node string
+}
+
+// gangPreemptionPlan is the outcome of a successful gang preemption dry run.
+type gangPreemptionPlan struct {
+	placements []gangPlacement
+	victims    []gangVictim
+}
+
+// gangCandidate is the state of one node after victims have been selected on it.
+type gangCandidate struct {
+	node             string
+	nodeInfo         *framework.NodeInfo
+	state            *framework.CycleState
+	victims          []*v1.Pod
+	numPDBViolations int
+}
+
+// moreDisruptiveThan returns true if preempting on c hurts more than preempting on other:
+// it violates more PDBs, evicts more pods, or evicts a more important pod.
+func (c *gangCandidate) moreDisruptiveThan(other *gangCandidate) bool {
+	if c.numPDBViolations != other.numPDBViolations {
+		return c.numPDBViolations > other.numPDBViolations
+	}
+	if len(c.victims) != len(other.victims) {
+		return len(c.victims) > len(other.victims)
+	}
+	return highestPriority(c.victims) > highestPriority(other.victims)
+}
+
+// preemptionInProgress returns true if the pod has been nominated to a node where
+// lower-priority pods are still terminating, i.e. an earlier gang preemption has
+// not completed yet and evicting more pods would be premature.
+func (cs *Coscheduling) preemptionInProgress(pod *v1.Pod) bool {
+	nomNodeName := pod.Status.NominatedNodeName
+	if len(nomNodeName) == 0 {
+		return false
+	}
+	nodeInfo, err := cs.frameworkHandler.SnapshotSharedLister().NodeInfos().Get(nomNodeName)
+	if err != nil || nodeInfo == nil {
+		return false
+	}
+	podPriority := corev1helpers.PodPriority(pod)
+	for _, p := range nodeInfo.Pods {
+		if p.Pod.DeletionTimestamp != nil && corev1helpers.PodPriority(p.Pod) < podPriority {
+			return true
+		}
+	}
+	return false
+}
+
+// preemptPodGroup tries to make room for the MinMember pods of the PodGroup at once.
+// It returns Success together with the node nominated for the given pod only if
+// victims could be found for every missing member; the victims are then evicted
+// and every other pending member is nominated to its node.
+func (cs *Coscheduling) preemptPodGroup(ctx context.Context, state *framework.CycleState, pod *v1.Pod,
+	pg *v1alpha1.PodGroup, assigned int, m framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	if pod.Spec.PreemptionPolicy != nil && *pod.Spec.PreemptionPolicy == v1.PreemptNever {
+		return nil, framework.NewStatus(framework.Unschedulable, "not eligible due to preemptionPolicy=Never")
+	}
+
+	members, err := cs.pendingMembers(pod, int(pg.Spec.MinMember)-assigned)
+	if err != nil {
+		return nil, framework.NewStatus(framework.Unschedulable, err.Error())
+	}
+	nodeInfos, err := cs.frameworkHandler.SnapshotSharedLister().NodeInfos().List()
+	if err != nil {
+		return nil, framework.AsStatus(err)
+	}
+	pdbs, err := cs.pdbLister.List(labels.Everything())
+	if err != nil {
+		return nil, framework.AsStatus(err)
+	}
+
+	plan, status := cs.dryRunGangPreemption(ctx, state, pod, pg, members, nodeInfos, pdbs, m)
+	if !status.IsSuccess() {
+		return nil, status
+	}
+	if err := cs.executeGangPreemption(ctx, pod, pg, plan); err != nil {
+		return nil, framework.AsStatus(err)
+	}
+	return framework.NewPostFilterResultWithNominatedNode(plan.placements[0].node), framework.NewStatus(framework.Success)
+}
+
+// pendingMembers returns the given pod followed by its unassigned siblings, sorted by
+// name, so that the result holds the <needed> members the dry run has to place.
+func (cs *Coscheduling) pendingMembers(pod *v1.Pod, needed int) ([]*v1.Pod, error) {
+	pods, err := cs.frameworkHandler.SharedInformerFactory().Core().V1().Pods().Lister().Pods(pod.Namespace).List(
+		labels.SelectorFromSet(labels.Set{v1alpha1.PodGroupLabel: util.GetPodGroupLabel(pod)}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var siblings []*v1.Pod
+	for _, p := range pods {
+		if p.UID == pod.UID || len(p.Spec.NodeName) != 0 || p.DeletionTimestamp != nil {
+			continue
+		}
+		siblings = append(siblings, p)
+	}
+	if len(siblings)+1 < needed {
+		return nil, fmt.Errorf("podGroup %v needs %v more pods but only %v are pending",
+			util.GetPodGroupFullName(pod), needed, len(siblings)+1)
+	}
+	sort.Slice(siblings, func(i, j int) bool { return siblings[i].Name < siblings[j].Name })
+	return append([]*v1.Pod{pod}, siblings[:needed-1]...), nil
+}
+
+// dryRunGangPreemption places the members one by one on copies of the nodes. A member
+// goes to a node where it already fits if there is one; otherwise victims are selected
+// on every node where preemption might help, and the least disruptive node is taken.
+// The members are all evaluated with the cycle state of the pod being scheduled, so
+// the dry run assumes the members of a PodGroup have the same scheduling constraints.
+func (cs *Coscheduling) dryRunGangPreemption(ctx context.Context, state *framework.CycleState, pod *v1.Pod,
+	pg *v1alpha1.PodGroup, members []*v1.Pod, nodeInfos []*framework.NodeInfo, pdbs []*policy.PodDisruptionBudget,
+	m framework.NodeToStatusMap) (*gangPreemptionPlan, *framework.Status) {
+	state = state.Clone()
+	nodes := make(map[string]*framework.NodeInfo, len(nodeInfos))
+	var names []string
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Node() == nil {
+			continue
+		}
+		name := nodeInfo.Node().Name
+		nodes[name] = nodeInfo.Snapshot()
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	plan := &gangPreemptionPlan{}
+	for _, member := range members {
+		candidate, err := cs.placeMember(ctx, state, pod, names, nodes, pdbs, m)
+		if err != nil {
+			return nil, framework.AsStatus(err)
+		}
+		if candidate == nil {
+			return nil, framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("preemption cannot make room for %v pods of PodGroup %v", len(members), klog.KObj(pg)))
+		}
+
+		state = candidate.state
+		nodes[candidate.node] = candidate.nodeInfo
+		for _, victim := range candidate.victims {
+			plan.victims = append(plan.victims, gangVictim{pod: victim, node: candidate.node})
+		}
+		pdbs = consumeDisruptions(pdbs, candidate.victims)
+		plan.placements = append(plan.placements, gangPlacement{member: member, node: candidate.node})
+
+		// Account the member on its node, so that the next members see the room it takes.
+		placeholder := pod.DeepCopy()
+		placeholder.Name = member.Name
+		placeholder.UID = member.UID
+		placeholder.Spec.NodeName = candidate.node
+		podInfo, err := framework.NewPodInfo(placeholder)
+		if err != nil {
+			return nil, framework.AsStatus(err)
+		}
+		candidate.nodeInfo.AddPodInfo(podInfo)
+		if status := cs.frameworkHandler.RunPreFilterExtensionAddPod(ctx, state, pod, podInfo, candidate.nodeInfo); !status.IsSuccess() {
+			return nil, status
+		}
+	}
+
+	if pg.Spec.MinResources != nil {
+		var list []*framework.NodeInfo
+		for _, name := range names {
+			list = append(list, nodes[name])
+		}
+		minResources := pg.Spec.MinResources.DeepCopy()
+		minResources[v1.ResourcePods] = *resource.NewQuantity(int64(pg.Spec.MinMember), resource.DecimalSI)
+		if err := core.CheckClusterResource(ctx, list, minResources, util.GetPodGroupFullName(pod)); err != nil {
+			return nil, framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("preemption cannot satisfy minResources of PodGroup %v: %v", klog.KObj(pg), err))
+		}
+	}
+	return plan, framework.NewStatus(framework.Success)
+}
+
+// placeMember returns the node for the next member of the PodGroup, or nil if there is none.
+func (cs *Coscheduling) placeMember(ctx context.Context, state *framework.CycleState, pod *v1.Pod, names []string,
+	nodes map[string]*framework.NodeInfo, pdbs []*policy.PodDisruptionBudget, m framework.NodeToStatusMap) (*gangCandidate, error) {
+	for _, name := range names {
+		if s := cs.frameworkHandler.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodes[name]); s.IsSuccess() {
+			return &gangCandidate{node: name, nodeInfo: nodes[name], state: state}, nil
+		}
+	}
+
+	var best *gangCandidate
+	for _, name := range names {
+		// Nodes the pod can never fit on regardless of preemption are skipped.
+		if s, ok := m[name]; ok && s.Code() == framework.UnschedulableAndUnresolvable {
+			continue
+		}
+		candidate := &gangCandidate{node: name, nodeInfo: nodes[name].Snapshot(), state: state.Clone()}
+		found, err := cs.selectVictimsOnNode(ctx, pod, candidate, pdbs)
+		if err != nil {
+			return nil, err
+		}
+		if found && (best == nil || best.moreDisruptiveThan(candidate)) {
+			best = candidate
+		}
+	}
+	return best, nil
+}
+
+// selectVictimsOnNode finds the minimal set of pods to evict from the candidate node so
+// that the pod fits. Only pods with a lower priority than the pod and that are not part
+// of any PodGroup are considered, so the plugin never evicts a part of another gang.
+// Like the default preemption, it tries to reprieve the PDB violating victims first,
+// starting from the most important ones.
+func (cs *Coscheduling) selectVictimsOnNode(ctx context.Context, pod *v1.Pod, candidate *gangCandidate,
+	pdbs []*policy.PodDisruptionBudget) (bool, error) {
+	logger := klog.FromContext(ctx)
+	nodeInfo, state := candidate.nodeInfo, candidate.state
+	removePod := func(pi *framework.PodInfo) error {
+		if err := nodeInfo.RemovePod(logger, pi.Pod); err != nil {
+			return err
+		}
+		return cs.frameworkHandler.RunPreFilterExtensionRemovePod(ctx, state, pod, pi, nodeInfo).AsError()
+	}
+	addPod := func(pi *framework.PodInfo) error {
+		nodeInfo.AddPodInfo(pi)
+		return cs.frameworkHandler.RunPreFilterExtensionAddPod(ctx, state, pod, pi, nodeInfo).AsError()
+	}
+
+	podPriority := corev1helpers.PodPriority(pod)
+	var potentialVictims []*framework.PodInfo
+	for _, pi := range nodeInfo.Pods {
+		if corev1helpers.PodPriority(pi.Pod) < podPriority && len(util.GetPodGroupLabel(pi.Pod)) == 0 {
+			potentialVictims = append(potentialVictims, pi)
+		}
+	}
+	if len(potentialVictims) == 0 {
+		return false, nil
+	}
+	for _, pi := range potentialVictims {
+		if err := removePod(pi); err != nil {
+			return false, err
+		}
+	}
+	// If the pod does not fit even after all the potential victims are removed,
+	// the node is not suitable for preemption.
+	if s := cs.frameworkHandler.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo); !s.IsSuccess() {
+		return false, nil
+	}
+
+	sort.Slice(potentialVictims, func(i, j int) bool {
+		return schedutil.MoreImportantPod(potentialVictims[i].Pod, potentialVictims[j].Pod)
+	})
+	violatingVictims, nonViolatingVictims := util.FilterPodsWithPDBViolation(potentialVictims, pdbs)
+	reprievePod := func(pi *framework.PodInfo) (bool, error) {
+		if err := addPod(pi); err != nil {
+			return false, err
+		}
+		if s := cs.frameworkHandler.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo); s.IsSuccess() {
+			return true, nil
+		}
+		if err := removePod(pi); err != nil {
+			return false, err
+		}
+		candidate.victims = append(candidate.victims, pi.Pod)
+		klog.V(5).InfoS("Found a potential gang preemption victim on node", "pod", klog.KObj(pi.Pod), "node", klog.KObj(nodeInfo.Node()))
+		return false, nil
+	}
+	for _, pi := range violatingVictims {
+		if fits, err := reprievePod(pi); err != nil {
+			return false, err
+		} else if !fits {
+			candidate.numPDBViolations++
+		}
+	}
+	for _, pi := range nonViolatingVictims {
+		if _, err := reprievePod(pi); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// executeGangPreemption evicts the victims of the plan and nominates every pending
+// member other than the pod being scheduled to its node. The pod being scheduled is
+// nominated by the scheduler through the PostFilterResult.
+func (cs *Coscheduling) executeGangPreemption(ctx context.Context, pod *v1.Pod, pg *v1alpha1.PodGroup, plan *gangPreemptionPlan) error {
+	logger := klog.FromContext(ctx)
+	client := cs.frameworkHandler.ClientSet()
+	for _, victim := range plan.victims {
+		if waitingPod := cs.frameworkHandler.GetWaitingPod(victim.pod.UID); waitingPod != nil {
+			waitingPod.Reject(cs.Name(), "preempted")
+		} else if err := schedutil.DeletePod(ctx, client, victim.pod); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		klog.V(2).InfoS("PodGroup preempted victim pod", "podGroup", klog.KObj(pg), "victim", klog.KObj(victim.pod), "node", victim.node)
+		cs.frameworkHandler.EventRecorder().Eventf(victim.pod, pod, v1.EventTypeNormal, "Preempted", "Preempting",
+			"Preempted by PodGroup %v on node %v", core.GetNamespacedName(pg), victim.node)
+	}
+
+	for _, placement := range plan.placements[1:] {
+		member := placement.member
+		podInfo, err := framework.NewPodInfo(member)
+		if err != nil {
+			return err
+		}
+		cs.frameworkHandler.AddNominatedPod(logger, podInfo, &framework.NominatingInfo{
+			NominatedNodeName: placement.node,
+			NominatingMode:    framework.ModeOverride,
+		})
+		newStatus := member.Status.DeepCopy()
+		newStatus.NominatedNodeName = placement.node
+		if err := schedutil.PatchPodStatus(ctx, client, member, newStatus); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// consumeDisruptions returns a copy of the PDBs with the disruptions allowed reduced by
+// the victims they match, so that the victims picked for one member of a PodGroup
+// count against the budgets seen when picking the victims for the next ones.
+func consumeDisruptions(pdbs []*policy.PodDisruptionBudget, victims []*v1.Pod) []*policy.PodDisruptionBudget {
+	if len(victims) == 0 {
+		return pdbs
+	}
+	result := make([]*policy.PodDisruptionBudget, 0, len(pdbs))
+	for _, pdb := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			result = append(result, pdb)
+			continue
+		}
+		pdb = pdb.DeepCopy()
+		for _, victim := range victims {
+			if victim.Namespace != pdb.Namespace || !selector.Matches(labels.Set(victim.Labels)) {
+				continue
+			}
+			if _, exist := pdb.Status.DisruptedPods[victim.Name]; exist {
+				continue
+			}
+			pdb.Status.DisruptionsAllowed--
+		}
+		result = append(result, pdb)
+	}
+	return result
+}
+
+// highestPriority returns the highest priority among the given pods.
+func highestPriority(pods []*v1.Pod) int32 {
+	var highest int32
+	for i, p := range pods {
+		if prio := corev1helpers.PodPriority(p); i == 0 || prio > highest {
+			highest = prio
+		}
+	}
+	return highest
+}
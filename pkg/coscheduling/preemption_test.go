@@ -0,0 +1,288 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package coscheduling
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	plfeature "k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	fwkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/coscheduling/core"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestGangPreemption(t *testing.T) {
+	scheduleTimeout := 10 * time.Second
+	capacity := map[v1.ResourceName]string{v1.ResourceCPU: "2", v1.ResourcePods: "10"}
+	request := map[v1.ResourceName]string{v1.ResourceCPU: "1"}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-a").Capacity(capacity).Obj(),
+		st.MakeNode().Name("node-b").Capacity(capacity).Obj(),
+	}
+	nodeStatusMap := framework.NodeToStatusMap{
+		"node-a": framework.NewStatus(framework.Unschedulable),
+		"node-b": framework.NewStatus(framework.Unschedulable),
+	}
+	member := func(name string, priority int32) *st.PodWrapper {
+		return st.MakePod().Name(name).UID(name).Namespace("ns").Label(v1alpha1.PodGroupLabel, "pg1").Req(request).Priority(priority)
+	}
+	singleton := func(name, node string) *st.PodWrapper {
+		return st.MakePod().Name(name).UID(name).Namespace("ns").Node(node).Req(request).Priority(10)
+	}
+	fullNodes := []*v1.Pod{
+		singleton("a1", "node-a").Obj(),
+		singleton("a2", "node-a").Obj(),
+		singleton("b1", "node-b").Obj(),
+		singleton("b2", "node-b").Obj(),
+	}
+	neverPreempt := v1.PreemptNever
+
+	tests := []struct {
+		name          string
+		pod           *v1.Pod
+		siblings      []*v1.Pod
+		existingPods  []*v1.Pod
+		pdbs          []*policy.PodDisruptionBudget
+		pg            *v1alpha1.PodGroup
+		wantResult    *framework.PostFilterResult
+		wantStatus    *framework.Status
+		wantVictims   []string
+		wantNominated map[string]string
+	}{
+		{
+			name:          "preempt lower-priority pods for the whole gang",
+			pod:           member("p1", 100).Obj(),
+			siblings:      []*v1.Pod{member("p2", 100).Obj(), member("p3", 100).Obj()},
+			existingPods:  fullNodes,
+			pg:            tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj(),
+			wantResult:    framework.NewPostFilterResultWithNominatedNode("node-a"),
+			wantStatus:    framework.NewStatus(framework.Success),
+			wantVictims:   []string{"a1", "a2"},
+			wantNominated: map[string]string{"p2": "node-a"},
+		},
+		{
+			name:     "gang members already assigned reduce the pods to make room for",
+			pod:      member("p1", 100).Obj(),
+			siblings: []*v1.Pod{member("p2", 100).Obj()},
+			existingPods: []*v1.Pod{
+				member("p0", 100).Node("node-b").Obj(),
+				singleton("a1", "node-a").Obj(),
+				singleton("a2", "node-a").Priority(20).Obj(),
+				singleton("b1", "node-b").Obj(),
+			},
+			pg:          tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj(),
+			wantResult:  framework.NewPostFilterResultWithNominatedNode("node-a"),
+			wantStatus:  framework.NewStatus(framework.Success),
+			wantVictims: []string{"a1"},
+		},
+		{
+			name:     "avoid violating PDBs",
+			pod:      member("p1", 100).Obj(),
+			siblings: []*v1.Pod{member("p2", 100).Obj()},
+			existingPods: []*v1.Pod{
+				singleton("a1", "node-a").Label("app", "protected").Obj(),
+				singleton("a2", "node-a").Label("app", "protected").Obj(),
+				singleton("b1", "node-b").Obj(),
+				singleton("b2", "node-b").Obj(),
+			},
+			pdbs: []*policy.PodDisruptionBudget{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "pdb", Namespace: "ns"},
+					Spec:       policy.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "protected"}}},
+					Status:     policy.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+				},
+			},
+			pg:            tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj(),
+			wantResult:    framework.NewPostFilterResultWithNominatedNode("node-b"),
+			wantStatus:    framework.NewStatus(framework.Success),
+			wantVictims:   []string{"b1", "b2"},
+			wantNominated: map[string]string{"p2": "node-b"},
+		},
+		{
+			name:     "never evict a part of another gang",
+			pod:      member("p1", 100).Obj(),
+			siblings: []*v1.Pod{member("p2", 100).Obj()},
+			existingPods: []*v1.Pod{
+				singleton("a1", "node-a").Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+				singleton("a2", "node-a").Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+				singleton("b1", "node-b").Label(v1alpha1.PodGroupLabel, "pg2").Obj(),
+				singleton("b2", "node-b").Obj(),
+			},
+			pg: tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj(),
+			wantStatus: framework.NewStatus(framework.Unschedulable,
+				"PodGroup ns/pg1 gets rejected due to Pod p1 is unschedulable even after PostFilter"),
+		},
+		{
+			name:         "no preemption when the victims do not have a lower priority",
+			pod:          member("p1", 10).Obj(),
+			siblings:     []*v1.Pod{member("p2", 10).Obj()},
+			existingPods: fullNodes,
+			pg:           tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj(),
+			wantStatus: framework.NewStatus(framework.Unschedulable,
+				"PodGroup ns/pg1 gets rejected due to Pod p1 is unschedulable even after PostFilter"),
+		},
+		{
+			name:         "no preemption when minResources cannot be satisfied",
+			pod:          member("p1", 100).Obj(),
+			siblings:     []*v1.Pod{member("p2", 100).Obj()},
+			existingPods: fullNodes,
+			pg: tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).
+				MinResources(map[v1.ResourceName]string{v1.ResourceCPU: "5"}).Obj(),
+			wantStatus: framework.NewStatus(framework.Unschedulable,
+				"PodGroup ns/pg1 gets rejected due to Pod p1 is unschedulable even after PostFilter"),
+		},
+		{
+			name:         "no preemption for a pod with preemptionPolicy Never",
+			pod:          member("p1", 100).PreemptionPolicy(neverPreempt).Obj(),
+			siblings:     []*v1.Pod{member("p2", 100).Obj()},
+			existingPods: fullNodes,
+			pg:           tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj(),
+			wantStatus: framework.NewStatus(framework.Unschedulable,
+				"PodGroup ns/pg1 gets rejected due to Pod p1 is unschedulable even after PostFilter"),
+		},
+		{
+			name: "wait for the victims of an earlier preemption to terminate",
+			pod: func() *v1.Pod {
+				p := member("p1", 100).Obj()
+				p.Status.NominatedNodeName = "node-a"
+				return p
+			}(),
+			siblings:     []*v1.Pod{member("p2", 100).Obj()},
+			existingPods: append([]*v1.Pod{singleton("a1", "node-a").Terminating().Obj()}, fullNodes[1:]...),
+			pg:           tu.MakePodGroup().Name("pg1").Namespace("ns").MinMember(2).Obj(),
+			wantResult:   &framework.PostFilterResult{},
+			wantStatus:   framework.NewStatus(framework.Unschedulable, "PodGroup ns/pg1 is waiting for preempted pods to terminate"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			pods := append(append([]*v1.Pod{tt.pod}, tt.siblings...), tt.existingPods...)
+			client, err := tu.NewFakeClient(tt.pg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var objs []runtime.Object
+			for _, p := range pods {
+				objs = append(objs, p)
+			}
+			cs := clientsetfake.NewSimpleClientset(objs...)
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			podInformer := informerFactory.Core().V1().Pods()
+			for _, p := range pods {
+				podInformer.Informer().GetStore().Add(p)
+			}
+			pdbInformer := informerFactory.Policy().V1().PodDisruptionBudgets()
+			for _, pdb := range tt.pdbs {
+				pdbInformer.Informer().GetStore().Add(pdb)
+			}
+
+			registeredPlugins := []tf.RegisterPluginFunc{
+				tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				tf.RegisterPluginAsExtensions(noderesources.Name, func(ctx context.Context, plArgs runtime.Object, fh framework.Handle) (framework.Plugin, error) {
+					return noderesources.NewFit(ctx, plArgs, fh, plfeature.Features{})
+				}, "Filter", "PreFilter"),
+			}
+			snapshot := tu.NewFakeSharedLister(tt.existingPods, nodes)
+			f, err := tf.NewFramework(ctx, registeredPlugins, "default-scheduler",
+				fwkruntime.WithClientSet(cs),
+				fwkruntime.WithEventRecorder(&events.FakeRecorder{}),
+				fwkruntime.WithInformerFactory(informerFactory),
+				fwkruntime.WithPodNominator(tu.NewPodNominator(podInformer.Lister())),
+				fwkruntime.WithSnapshotSharedLister(snapshot),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pl := &Coscheduling{
+				frameworkHandler: f,
+				pgMgr:            core.NewPodGroupManager(client, snapshot, &scheduleTimeout, podInformer),
+				scheduleTimeout:  &scheduleTimeout,
+				preemptionMode:   config.PreemptionModeGang,
+				pdbLister:        pdbInformer.Lister(),
+			}
+
+			state := framework.NewCycleState()
+			if _, s := f.RunPreFilterPlugins(ctx, state, tt.pod); !s.IsSuccess() {
+				t.Fatalf("Unexpected PreFilter status: %v", s)
+			}
+			gotResult, gotStatus := pl.PostFilter(ctx, state, tt.pod, nodeStatusMap)
+			if diff := cmp.Diff(tt.wantStatus, gotStatus); diff != "" {
+				t.Errorf("Unexpected status (-want,+got):\n%s", diff)
+			}
+			if tt.wantResult != nil {
+				if diff := cmp.Diff(tt.wantResult, gotResult); diff != "" {
+					t.Errorf("Unexpected result (-want,+got):\n%s", diff)
+				}
+			}
+
+			podList, err := cs.CoreV1().Pods("ns").List(ctx, metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			remaining := make(map[string]bool)
+			gotNominated := make(map[string]string)
+			for _, p := range podList.Items {
+				remaining[p.Name] = true
+				if p.Name != tt.pod.Name && p.Status.NominatedNodeName != "" {
+					gotNominated[p.Name] = p.Status.NominatedNodeName
+				}
+			}
+			var gotVictims []string
+			for _, p := range tt.existingPods {
+				if !remaining[p.Name] {
+					gotVictims = append(gotVictims, p.Name)
+				}
+			}
+			sort.Strings(gotVictims)
+			if diff := cmp.Diff(tt.wantVictims, gotVictims); diff != "" {
+				t.Errorf("Unexpected victims (-want,+got):\n%s", diff)
+			}
+			if len(tt.wantNominated) == 0 {
+				tt.wantNominated = map[string]string{}
+			}
+			if diff := cmp.Diff(tt.wantNominated, gotNominated); diff != "" {
+				t.Errorf("Unexpected nominated nodes (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,468 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskioaware
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	ctrlruntimecache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	pluginconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
+	diskiov1alpha1 "sigs.k8s.io/scheduler-plugins/apis/diskio/v1alpha1"
+)
+
+const (
+	// Name is the name of the plugin used in Registry and configurations.
+	Name = "DiskIOAware"
+
+	// preFilterStateKey is the key in CycleState to DiskIOAware pre-computed data.
+	preFilterStateKey = "PreFilter" + Name
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(diskiov1alpha1.AddToScheme(scheme))
+}
+
+// DiskIOAware filters and scores the nodes on the disk IO bandwidth requested by the pods,
+// against the allocatable bandwidth the node driver reports in the NodeDiskIOInfo objects.
+type DiskIOAware struct {
+	// Client writes the reserved pods of the NodeDiskIOInfo objects.
+	client.Client
+	// infoReader reads the NodeDiskIOInfo objects from the informer cache.
+	infoReader client.Reader
+
+	handle          framework.Handle
+	namespace       string
+	scoringStrategy pluginconfig.ScoringStrategyType
+	reservations    *reservations
+	// queue holds the nodes whose reserved pods are to be written, off the scheduling cycle.
+	queue workqueue.RateLimitingInterface
+}
+
+var _ framework.PreFilterPlugin = &DiskIOAware{}
+var _ framework.FilterPlugin = &DiskIOAware{}
+var _ framework.PreScorePlugin = &DiskIOAware{}
+var _ framework.ScorePlugin = &DiskIOAware{}
+var _ framework.ReservePlugin = &DiskIOAware{}
+var _ framework.PostBindPlugin = &DiskIOAware{}
+
+// device is a disk of a node with its allocatable bandwidth.
+type device struct {
+	id          string
+	model       string
+	allocatable Bandwidth
+}
+
+// nodeDevices are the disks of a node, sorted by id.
+type nodeDevices struct {
+	// infoName is the name of the NodeDiskIOInfo object of the node.
+	infoName string
+	devices  []device
+}
+
+// preFilterState computed at PreFilter and used at Filter and Score.
+type preFilterState struct {
+	request *IORequest
+	// nodes are the disks of the nodes with a NodeDiskIOInfo object, keyed by node name.
+	nodes map[string]*nodeDevices
+}
+
+// Clone the preFilter state. The state is not modified after PreFilter.
+func (s *preFilterState) Clone() framework.StateData {
+	return s
+}
+
+// Name returns name of the plugin.
+func (d *DiskIOAware) Name() string {
+	return Name
+}
+
+// New initializes a new plugin and returns it.
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+	args, ok := obj.(*pluginconfig.DiskIOAwareArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type DiskIOAwareArgs, got %T", obj)
+	}
+	if err := validation.ValidateDiskIOAwareArgs(nil, args); err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(handle.KubeConfig(), client.Options{
+		Scheme: scheme,
+	})
+	if err != nil {
+		return nil, err
+	}
+	infoCache, err := ctrlruntimecache.New(handle.KubeConfig(), ctrlruntimecache.Options{
+		Scheme:            scheme,
+		DefaultNamespaces: map[string]ctrlruntimecache.Config{args.NodeDiskIOInfoNamespace: {}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The informer must exist before the cache starts, for the cache to wait for its sync.
+	if _, err := infoCache.GetInformer(ctx, &diskiov1alpha1.NodeDiskIOInfo{}); err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := infoCache.Start(ctx); err != nil {
+			klog.ErrorS(err, "Failed to start the NodeDiskIOInfo informer")
+		}
+	}()
+	if !infoCache.WaitForCacheSync(ctx) {
+		return nil, fmt.Errorf("failed to sync the NodeDiskIOInfo informer")
+	}
+
+	d := &DiskIOAware{
+		Client:          c,
+		infoReader:      infoCache,
+		handle:          handle,
+		namespace:       args.NodeDiskIOInfoNamespace,
+		scoringStrategy: args.ScoringStrategy,
+		reservations:    newReservations(),
+		queue:           workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), Name),
+	}
+	handle.SharedInformerFactory().Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: d.podDeleted,
+	})
+	go func() {
+		<-ctx.Done()
+		d.queue.ShutDown()
+	}()
+	go wait.UntilWithContext(ctx, d.runWorker, time.Second)
+	return d, nil
+}
+
+// PreFilter reads the disk IO request of the pod and the disks of the nodes.
+func (d *DiskIOAware) PreFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod) (*framework.PreFilterResult, *framework.Status) {
+	req, err := GetIORequest(pod)
+	if err != nil {
+		return nil, framework.NewStatus(framework.UnschedulableAndUnresolvable, err.Error())
+	}
+	if req == nil {
+		return nil, framework.NewStatus(framework.Skip)
+	}
+
+	infos := &diskiov1alpha1.NodeDiskIOInfoList{}
+	if err := d.infoReader.List(ctx, infos, client.InNamespace(d.namespace)); err != nil {
+		return nil, framework.AsStatus(fmt.Errorf("listing NodeDiskIOInfo: %w", err))
+	}
+	nodes := make(map[string]*nodeDevices, len(infos.Items))
+	for i := range infos.Items {
+		info := &infos.Items[i]
+		nodes[info.Spec.NodeName] = d.nodeDevices(info)
+	}
+
+	state.Write(preFilterStateKey, &preFilterState{request: req, nodes: nodes})
+	return nil, nil
+}
+
+// PreFilterExtensions returns nil, the bandwidth of the pods is not accounted in the node infos.
+func (d *DiskIOAware) PreFilterExtensions() framework.PreFilterExtensions {
+	return nil
+}
+
+// Filter rejects the nodes with no disk having enough bandwidth for the pod.
+func (d *DiskIOAware) Filter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	s, err := getPreFilterState(state)
+	if err != nil {
+		return framework.AsStatus(err)
+	}
+	node, ok := s.nodes[nodeInfo.Node().Name]
+	if !ok {
+		return framework.NewStatus(framework.UnschedulableAndUnresolvable, "node has no disk IO information")
+	}
+	if _, _, ok := fit(node.devices, s.request); !ok {
+		return framework.NewStatus(framework.Unschedulable, "node has not enough disk IO bandwidth")
+	}
+	return nil
+}
+
+// PreScore skips the scoring of the pods with no disk IO request.
+func (d *DiskIOAware) PreScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodes []*v1.Node) *framework.Status {
+	if _, err := getPreFilterState(state); err != nil {
+		return framework.NewStatus(framework.Skip)
+	}
+	return nil
+}
+
+// Score scores the node from the bandwidth of the disk the pod fits on, with the scoring strategy.
+func (d *DiskIOAware) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	s, err := getPreFilterState(state)
+	if err != nil {
+		return 0, framework.AsStatus(err)
+	}
+	node, ok := s.nodes[nodeName]
+	if !ok {
+		return framework.MinNodeScore, nil
+	}
+	dev, bw, ok := fit(node.devices, s.request)
+	if !ok {
+		return framework.MinNodeScore, nil
+	}
+	return d.score(bw.Total, dev.allocatable.Total), nil
+}
+
+// ScoreExtensions returns nil, the scores are already in the node score range.
+func (d *DiskIOAware) ScoreExtensions() framework.ScoreExtensions {
+	return nil
+}
+
+// Reserve deducts the bandwidth of the pod from the disk, until the driver reports it.
+func (d *DiskIOAware) Reserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) *framework.Status {
+	s, err := getPreFilterState(state)
+	if err != nil {
+		// The pod requests no bandwidth.
+		return nil
+	}
+	node, ok := s.nodes[nodeName]
+	if !ok {
+		return framework.NewStatus(framework.Error, "node has no disk IO information")
+	}
+	dev, bw, ok := fit(node.devices, s.request)
+	if !ok {
+		return framework.NewStatus(framework.Unschedulable, "node has not enough disk IO bandwidth")
+	}
+
+	d.reservations.add(nodeName, pod.UID, reservation{device: dev.id, bandwidth: bw})
+	klog.V(5).InfoS("Reserved disk IO bandwidth", "pod", klog.KObj(pod), "node", nodeName, "device", dev.id, "bandwidth", bw)
+	return nil
+}
+
+// Unreserve releases the bandwidth reserved for the pod.
+func (d *DiskIOAware) Unreserve(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+	if _, err := getPreFilterState(state); err != nil {
+		return
+	}
+	// The pod is not bound, hence not listed in the reserved pods of the node yet.
+	d.reservations.remove(nodeName, pod.UID)
+}
+
+// PostBind adds the pod to the reserved pods of the node, for the driver to account its bandwidth.
+// The NodeDiskIOInfo object is updated asynchronously.
+func (d *DiskIOAware) PostBind(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) {
+	if _, err := getPreFilterState(state); err != nil {
+		return
+	}
+	d.reservations.bind(nodeName, pod.UID)
+	d.queue.Add(nodeName)
+}
+
+// podDeleted releases the bandwidth reserved for a deleted pod.
+func (d *DiskIOAware) podDeleted(obj interface{}) {
+	var pod *v1.Pod
+	switch t := obj.(type) {
+	case *v1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		pod, _ = t.Obj.(*v1.Pod)
+	}
+	if pod == nil || pod.Spec.NodeName == "" {
+		return
+	}
+	if _, ok := pod.Annotations[IORequestAnnotation]; !ok {
+		return
+	}
+	d.reservations.release(pod.Spec.NodeName, pod.UID)
+	d.queue.Add(pod.Spec.NodeName)
+}
+
+func (d *DiskIOAware) runWorker(ctx context.Context) {
+	for d.processNextNode(ctx) {
+	}
+}
+
+// processNextNode writes the reserved pods of the next node in the queue.
+// Returns false once the queue is shut down.
+func (d *DiskIOAware) processNextNode(ctx context.Context) bool {
+	item, shutdown := d.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer d.queue.Done(item)
+
+	nodeName := item.(string)
+	if err := d.syncReservedPods(ctx, nodeName); err != nil {
+		klog.ErrorS(err, "Failed to update the reserved pods", "node", nodeName)
+		d.queue.AddRateLimited(item)
+		return true
+	}
+	d.queue.Forget(item)
+	return true
+}
+
+// syncReservedPods adds the bound pods to, and removes the released pods from, the reserved pods
+// of the NodeDiskIOInfo object of the node.
+func (d *DiskIOAware) syncReservedPods(ctx context.Context, nodeName string) error {
+	listed, released := d.reservations.toSync(nodeName)
+	if len(listed) == 0 && released.Len() == 0 {
+		return nil
+	}
+	infoName, err := d.nodeDiskIOInfoName(ctx, nodeName)
+	if err != nil {
+		return err
+	}
+	if infoName == "" {
+		// Nothing to write to: the reservations of the node are dropped with it.
+		d.reservations.synced(nodeName, listed, released, 0)
+		return nil
+	}
+	generation, err := d.updateReservedPods(ctx, infoName, func(pods []string) []string {
+		pods = slices.DeleteFunc(pods, func(p string) bool {
+			return released.Has(types.UID(p))
+		})
+		for _, uid := range listed {
+			if !slices.Contains(pods, string(uid)) {
+				pods = append(pods, string(uid))
+			}
+		}
+		return pods
+	})
+	if err != nil {
+		return err
+	}
+	d.reservations.synced(nodeName, listed, released, generation)
+	klog.V(5).InfoS("Updated the reserved pods", "node", nodeName, "added", len(listed), "removed", released.Len(), "generation", generation)
+	return nil
+}
+
+// nodeDiskIOInfoName returns the name of the NodeDiskIOInfo object of the node, empty if none.
+func (d *DiskIOAware) nodeDiskIOInfoName(ctx context.Context, nodeName string) (string, error) {
+	infos := &diskiov1alpha1.NodeDiskIOInfoList{}
+	if err := d.infoReader.List(ctx, infos, client.InNamespace(d.namespace)); err != nil {
+		return "", err
+	}
+	for _, info := range infos.Items {
+		if info.Spec.NodeName == nodeName {
+			return info.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// updateReservedPods updates the reserved pods of a NodeDiskIOInfo object, and returns the
+// generation of the object holding them.
+func (d *DiskIOAware) updateReservedPods(ctx context.Context, name string, update func([]string) []string) (int64, error) {
+	var generation int64
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		info := &diskiov1alpha1.NodeDiskIOInfo{}
+		if err := d.Get(ctx, client.ObjectKey{Namespace: d.namespace, Name: name}, info); err != nil {
+			return err
+		}
+		pods := update(append([]string(nil), info.Spec.ReservedPods...))
+		if slices.Equal(pods, info.Spec.ReservedPods) {
+			generation = info.Generation
+			return nil
+		}
+		info.Spec.ReservedPods = pods
+		if err := d.Update(ctx, info); err != nil {
+			return err
+		}
+		generation = info.Generation
+		return nil
+	})
+	return generation, err
+}
+
+// nodeDevices returns the disks of the node, with the bandwidth of the reservations not yet
+// accounted by the driver deducted.
+func (d *DiskIOAware) nodeDevices(info *diskiov1alpha1.NodeDiskIOInfo) *nodeDevices {
+	pending := d.reservations.pending(info.Spec.NodeName, info.Status.ObservedGeneration)
+	node := &nodeDevices{infoName: info.Name}
+	for id, bw := range info.Status.AllocatableBandwidth {
+		allocatable := Bandwidth{
+			Read:  Unlimited,
+			Write: Unlimited,
+			Total: bw.Total.Value(),
+		}
+		if bw.Read != nil {
+			allocatable.Read = bw.Read.Value()
+		}
+		if bw.Write != nil {
+			allocatable.Write = bw.Write.Value()
+		}
+		node.devices = append(node.devices, device{
+			id:          id,
+			model:       bw.Model,
+			allocatable: allocatable.Sub(pending[id]),
+		})
+	}
+	sort.Slice(node.devices, func(i, j int) bool {
+		return node.devices[i].id < node.devices[j].id
+	})
+	return node
+}
+
+// score scores the request of a disk with the scoring strategy.
+func (d *DiskIOAware) score(requested, allocatable int64) int64 {
+	if allocatable <= 0 {
+		return framework.MinNodeScore
+	}
+	if d.scoringStrategy == pluginconfig.LeastAllocated {
+		return (allocatable - requested) * framework.MaxNodeScore / allocatable
+	}
+	return requested * framework.MaxNodeScore / allocatable
+}
+
+// fit returns the first disk with enough bandwidth for the request, and the normalized
+// bandwidth of the request on it.
+func fit(devices []device, req *IORequest) (device, Bandwidth, bool) {
+	for _, dev := range devices {
+		n := NormalizerFor(dev.model)
+		bw, err := n.EstimateRequest(req)
+		if err != nil {
+			klog.V(5).InfoS("Failed to normalize disk IO request", "normalizer", n.Name(), "device", dev.id, "err", err)
+			continue
+		}
+		if bw.FitsIn(dev.allocatable) {
+			return dev, bw, true
+		}
+	}
+	return device{}, Bandwidth{}, false
+}
+
+func getPreFilterState(state *framework.CycleState) (*preFilterState, error) {
+	c, err := state.Read(preFilterStateKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q from cycleState: %w", preFilterStateKey, err)
+	}
+	s, ok := c.(*preFilterState)
+	if !ok {
+		return nil, fmt.Errorf("%+v cannot be converted to diskioaware.preFilterState", c)
+	}
+	return s, nil
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskioaware
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	pluginconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	diskiov1alpha1 "sigs.k8s.io/scheduler-plugins/apis/diskio/v1alpha1"
+)
+
+const testNamespace = "kube-system"
+
+func makeNodeDiskIOInfo(nodeName string, generation int64, devices map[string]int64) *diskiov1alpha1.NodeDiskIOInfo {
+	info := &diskiov1alpha1.NodeDiskIOInfo{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: nodeName, Generation: generation},
+		Spec:       diskiov1alpha1.NodeDiskIOInfoSpec{NodeName: nodeName},
+		Status: diskiov1alpha1.NodeDiskIOInfoStatus{
+			ObservedGeneration:   generation,
+			AllocatableBandwidth: map[string]diskiov1alpha1.DeviceAllocatableBandwidth{},
+		},
+	}
+	for id, total := range devices {
+		info.Status.AllocatableBandwidth[id] = diskiov1alpha1.DeviceAllocatableBandwidth{
+			Name: "/dev/" + id,
+			BlockIOStatus: diskiov1alpha1.BlockIOStatus{
+				Total: *resource.NewQuantity(total, resource.DecimalSI),
+			},
+		}
+	}
+	return info
+}
+
+func makeIOPod(name, annotation string) *v1.Pod {
+	pod := st.MakePod().Name(name).Namespace("default").UID(name).Obj()
+	if annotation != "" {
+		pod.Annotations = map[string]string{IORequestAnnotation: annotation}
+	}
+	return pod
+}
+
+func newTestDiskIOAware(strategy pluginconfig.ScoringStrategyType, infos ...*diskiov1alpha1.NodeDiskIOInfo) *DiskIOAware {
+	builder := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&diskiov1alpha1.NodeDiskIOInfo{})
+	for _, info := range infos {
+		builder.WithObjects(info)
+	}
+	c := builder.Build()
+	return &DiskIOAware{
+		Client:          c,
+		infoReader:      c,
+		namespace:       testNamespace,
+		scoringStrategy: strategy,
+		reservations:    newReservations(),
+		queue:           workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+}
+
+func TestDiskIOAwareFilterAndScore(t *testing.T) {
+	infos := []*diskiov1alpha1.NodeDiskIOInfo{
+		makeNodeDiskIOInfo("n1", 1, map[string]int64{"d1": 50000000, "d2": 200000000}),
+		makeNodeDiskIOInfo("n2", 1, map[string]int64{"d1": 100000000}),
+		makeNodeDiskIOInfo("n3", 1, map[string]int64{"d1": 40000000}),
+	}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n1").Obj(),
+		st.MakeNode().Name("n2").Obj(),
+		st.MakeNode().Name("n3").Obj(),
+		st.MakeNode().Name("n4").Obj(),
+	}
+
+	tests := []struct {
+		name         string
+		pod          *v1.Pod
+		strategy     pluginconfig.ScoringStrategyType
+		wantSkip     bool
+		wantFilter   map[string]framework.Code
+		wantScores   map[string]int64
+		wantPreError bool
+	}{
+		{
+			name:     "pod without request is skipped",
+			pod:      makeIOPod("p1", ""),
+			strategy: pluginconfig.MostAllocated,
+			wantSkip: true,
+		},
+		{
+			name:         "pod with an invalid request",
+			pod:          makeIOPod("p1", `{"rbps": "fast"}`),
+			strategy:     pluginconfig.MostAllocated,
+			wantPreError: true,
+		},
+		{
+			name:     "most allocated",
+			pod:      makeIOPod("p1", `{"rbps": "30M", "wbps": "20M"}`),
+			strategy: pluginconfig.MostAllocated,
+			wantFilter: map[string]framework.Code{
+				"n1": framework.Success,
+				"n2": framework.Success,
+				"n3": framework.Unschedulable,
+				"n4": framework.UnschedulableAndUnresolvable,
+			},
+			// The pod fits on d1 of n1, using all of its bandwidth.
+			wantScores: map[string]int64{"n1": 100, "n2": 50},
+		},
+		{
+			name:     "least allocated",
+			pod:      makeIOPod("p1", `{"bps": "60M", "rwratio": "1:1"}`),
+			strategy: pluginconfig.LeastAllocated,
+			wantFilter: map[string]framework.Code{
+				"n1": framework.Success,
+				"n2": framework.Success,
+				"n3": framework.Unschedulable,
+				"n4": framework.UnschedulableAndUnresolvable,
+			},
+			// The pod does not fit on d1 of n1 and goes on d2.
+			wantScores: map[string]int64{"n1": 70, "n2": 40},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pl := newTestDiskIOAware(tt.strategy, infos...)
+			state := framework.NewCycleState()
+
+			_, status := pl.PreFilter(ctx, state, tt.pod)
+			if tt.wantSkip {
+				if !status.IsSkip() {
+					t.Fatalf("PreFilter() = %v, want skip", status)
+				}
+				if status := pl.PreScore(ctx, state, tt.pod, nodes); !status.IsSkip() {
+					t.Errorf("PreScore() = %v, want skip", status)
+				}
+				return
+			}
+			if tt.wantPreError {
+				if status.Code() != framework.UnschedulableAndUnresolvable {
+					t.Fatalf("PreFilter() = %v, want UnschedulableAndUnresolvable", status)
+				}
+				return
+			}
+			if !status.IsSuccess() {
+				t.Fatalf("PreFilter() = %v", status)
+			}
+
+			for _, node := range nodes {
+				nodeInfo := framework.NewNodeInfo()
+				nodeInfo.SetNode(node)
+				if got := pl.Filter(ctx, state, tt.pod, nodeInfo).Code(); got != tt.wantFilter[node.Name] {
+					t.Errorf("Filter(%s) = %v, want %v", node.Name, got, tt.wantFilter[node.Name])
+				}
+			}
+
+			if status := pl.PreScore(ctx, state, tt.pod, nodes); !status.IsSuccess() {
+				t.Fatalf("PreScore() = %v", status)
+			}
+			for nodeName, want := range tt.wantScores {
+				got, status := pl.Score(ctx, state, tt.pod, nodeName)
+				if !status.IsSuccess() {
+					t.Fatalf("Score(%s) = %v", nodeName, status)
+				}
+				if got != want {
+					t.Errorf("Score(%s) = %d, want %d", nodeName, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDiskIOAwareReserve(t *testing.T) {
+	ctx := context.Background()
+	pl := newTestDiskIOAware(pluginconfig.MostAllocated, makeNodeDiskIOInfo("n1", 1, map[string]int64{"d1": 100000000}))
+	pod := makeIOPod("p1", `{"rbps": "40M"}`)
+
+	state := framework.NewCycleState()
+	if _, status := pl.PreFilter(ctx, state, pod); !status.IsSuccess() {
+		t.Fatalf("PreFilter() = %v", status)
+	}
+	reservedPods := func() []string {
+		info := &diskiov1alpha1.NodeDiskIOInfo{}
+		if err := pl.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: "n1"}, info); err != nil {
+			t.Fatal(err)
+		}
+		return info.Spec.ReservedPods
+	}
+
+	// Reserve and Unreserve only update the reservations in memory.
+	if status := pl.Reserve(ctx, state, pod, "n1"); !status.IsSuccess() {
+		t.Fatalf("Reserve() = %v", status)
+	}
+	if _, ok := pl.reservations.nodes["n1"][pod.UID]; !ok {
+		t.Errorf("no reservation recorded for the pod")
+	}
+	if got := reservedPods(); len(got) != 0 {
+		t.Errorf("ReservedPods after Reserve() = %v, want none", got)
+	}
+	pl.Unreserve(ctx, state, pod, "n1")
+	if len(pl.reservations.nodes) != 0 {
+		t.Errorf("reservations = %v, want none", pl.reservations.nodes)
+	}
+	if pl.queue.Len() != 0 {
+		t.Errorf("queue length = %d, want 0", pl.queue.Len())
+	}
+
+	// PostBind queues the node, whose reserved pods are then written by the worker.
+	if status := pl.Reserve(ctx, state, pod, "n1"); !status.IsSuccess() {
+		t.Fatalf("Reserve() = %v", status)
+	}
+	pl.PostBind(ctx, state, pod, "n1")
+	if pl.queue.Len() != 1 {
+		t.Fatalf("queue length = %d, want 1", pl.queue.Len())
+	}
+	pl.processNextNode(ctx)
+	if want := []string{"p1"}; !reflect.DeepEqual(reservedPods(), want) {
+		t.Errorf("ReservedPods after PostBind() = %v, want %v", reservedPods(), want)
+	}
+	if res := pl.reservations.nodes["n1"][pod.UID]; res.generation == 0 {
+		t.Errorf("generation of the reservation not recorded")
+	}
+
+	// Deleting the pod releases its reservation and removes it from the reserved pods.
+	bound := pod.DeepCopy()
+	bound.Spec.NodeName = "n1"
+	pl.podDeleted(bound)
+	if len(pl.reservations.nodes) != 0 {
+		t.Errorf("reservations = %v, want none", pl.reservations.nodes)
+	}
+	pl.processNextNode(ctx)
+	if got := reservedPods(); len(got) != 0 {
+		t.Errorf("ReservedPods after deletion = %v, want none", got)
+	}
+	if len(pl.reservations.released) != 0 {
+		t.Errorf("released = %v, want none", pl.reservations.released)
+	}
+}
+
+func TestReservationsPending(t *testing.T) {
+	r := newReservations()
+	r.add("n1", types.UID("p1"), reservation{device: "d1", bandwidth: Bandwidth{Read: 10, Total: 10}, generation: 2})
+	r.add("n1", types.UID("p2"), reservation{device: "d1", bandwidth: Bandwidth{Write: 20, Total: 20}, generation: 3})
+	r.add("n1", types.UID("p3"), reservation{device: "d2", bandwidth: Bandwidth{Read: 5, Total: 5}, generation: 3})
+
+	// The driver accounted generation 1: all the reservations are pending.
+	want := map[string]Bandwidth{
+		"d1": {Read: 10, Write: 20, Total: 30},
+		"d2": {Read: 5, Total: 5},
+	}
+	if got := r.pending("n1", 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("pending(1) = %v, want %v", got, want)
+	}
+
+	// The driver accounted generation 2: the first reservation is dropped.
+	want = map[string]Bandwidth{
+		"d1": {Write: 20, Total: 20},
+		"d2": {Read: 5, Total: 5},
+	}
+	if got := r.pending("n1", 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("pending(2) = %v, want %v", got, want)
+	}
+	if _, ok := r.nodes["n1"]["p1"]; ok {
+		t.Errorf("reservation of p1 not dropped")
+	}
+
+	// The pending reservations are deducted from the bandwidth reported by the driver.
+	pl := newTestDiskIOAware(pluginconfig.MostAllocated)
+	pl.reservations = r
+	node := pl.nodeDevices(makeNodeDiskIOInfo("n1", 2, map[string]int64{"d1": 100, "d2": 100}))
+	wantDevices := []device{
+		{id: "d1", allocatable: Bandwidth{Read: Unlimited, Write: Unlimited, Total: 80}},
+		{id: "d2", allocatable: Bandwidth{Read: Unlimited, Write: Unlimited, Total: 95}},
+	}
+	if !reflect.DeepEqual(node.devices, wantDevices) {
+		t.Errorf("nodeDevices() = %v, want %v", node.devices, wantDevices)
+	}
+}
+
+func TestDiskIOAwareFilterUsedUpBandwidth(t *testing.T) {
+	usedUp := makeNodeDiskIOInfo("n1", 1, map[string]int64{"d1": 100000000})
+	readUsedUp := usedUp.Status.AllocatableBandwidth["d1"]
+	readUsedUp.Read = resource.NewQuantity(0, resource.DecimalSI)
+	readUsedUp.Write = resource.NewQuantity(50000000, resource.DecimalSI)
+	usedUp.Status.AllocatableBandwidth["d1"] = readUsedUp
+	overcommitted := makeNodeDiskIOInfo("n2", 1, map[string]int64{"d1": -10000000})
+
+	tests := []struct {
+		name string
+		pod  *v1.Pod
+		want map[string]framework.Code
+	}{
+		{
+			name: "read bandwidth used up",
+			pod:  makeIOPod("p1", `{"rbps": "10M"}`),
+			want: map[string]framework.Code{"n1": framework.Unschedulable, "n2": framework.Unschedulable},
+		},
+		{
+			name: "write bandwidth left",
+			pod:  makeIOPod("p1", `{"wbps": "10M"}`),
+			want: map[string]framework.Code{"n1": framework.Success, "n2": framework.Unschedulable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			pl := newTestDiskIOAware(pluginconfig.MostAllocated, usedUp, overcommitted)
+			state := framework.NewCycleState()
+			if _, status := pl.PreFilter(ctx, state, tt.pod); !status.IsSuccess() {
+				t.Fatalf("PreFilter() = %v", status)
+			}
+			for nodeName, want := range tt.want {
+				nodeInfo := framework.NewNodeInfo()
+				nodeInfo.SetNode(st.MakeNode().Name(nodeName).Obj())
+				if got := pl.Filter(ctx, state, tt.pod, nodeInfo).Code(); got != want {
+					t.Errorf("Filter(%s) = %v, want %v", nodeName, got, want)
+				}
+			}
+		})
+	}
+}
+
+type blockSizeNormalizer struct{}
+
+func (blockSizeNormalizer) Name() string {
+	return "BlockSize"
+}
+
+// EstimateRequest doubles the bandwidth of the requests with blocks smaller than 64k.
+func (blockSizeNormalizer) EstimateRequest(req *IORequest) (Bandwidth, error) {
+	factor := int64(1)
+	if req.BlockSize < 64000 {
+		factor = 2
+	}
+	return Bandwidth{Read: req.Read * factor, Write: req.Write * factor, Total: (req.Read + req.Write) * factor}, nil
+}
+
+func TestNormalizer(t *testing.T) {
+	if err := RegisterNormalizer("test-model", blockSizeNormalizer{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterNormalizer("test-model", blockSizeNormalizer{}); err == nil {
+		t.Errorf("registering a normalizer twice succeeded")
+	}
+
+	req := &IORequest{Read: 10, Write: 20, BlockSize: 4096}
+	got, _ := NormalizerFor("test-model").EstimateRequest(req)
+	if want := (Bandwidth{Read: 20, Write: 40, Total: 60}); got != want {
+		t.Errorf("EstimateRequest() = %v, want %v", got, want)
+	}
+	got, _ = NormalizerFor("unknown-model").EstimateRequest(req)
+	if want := (Bandwidth{Read: 10, Write: 20, Total: 30}); got != want {
+		t.Errorf("pass-through EstimateRequest() = %v, want %v", got, want)
+	}
+}
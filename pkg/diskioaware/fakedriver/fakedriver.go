@@ -0,0 +1,179 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakedriver implements a node disk IO driver for tests: it creates the NodeDiskIOInfo
+// objects of the nodes and reports the capacity of their disks minus the bandwidth of the pods
+// reserved on them.
+package fakedriver
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	diskiov1alpha1 "sigs.k8s.io/scheduler-plugins/apis/diskio/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/diskioaware"
+)
+
+// Device is a disk of a node.
+type Device struct {
+	// ID is the device id, the key of the disk in the NodeDiskIOInfo status.
+	ID string
+	// Name is the device name, e.g. /dev/sda.
+	Name string
+	// Model selects the IO calculation model of the disk.
+	Model string
+	// Capacity is the bandwidth of the disk. The read and write bandwidth are reported only when set.
+	Capacity diskioaware.Bandwidth
+}
+
+// Driver reports the allocatable bandwidth of the disks of the nodes it is given.
+type Driver struct {
+	client    client.Client
+	namespace string
+
+	lock  sync.Mutex
+	nodes map[string][]Device
+}
+
+// New returns a driver writing the NodeDiskIOInfo objects in the namespace.
+func New(c client.Client, namespace string) *Driver {
+	return &Driver{
+		client:    c,
+		namespace: namespace,
+		nodes:     make(map[string][]Device),
+	}
+}
+
+// AddNode creates the NodeDiskIOInfo object of the node, named after it, and reports its disks.
+func (d *Driver) AddNode(ctx context.Context, nodeName string, devices ...Device) error {
+	devices = append([]Device(nil), devices...)
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].ID < devices[j].ID
+	})
+	d.lock.Lock()
+	d.nodes[nodeName] = devices
+	d.lock.Unlock()
+
+	info := &diskiov1alpha1.NodeDiskIOInfo{
+		ObjectMeta: metav1.ObjectMeta{Namespace: d.namespace, Name: nodeName},
+		Spec:       diskiov1alpha1.NodeDiskIOInfoSpec{NodeName: nodeName},
+	}
+	if err := d.client.Create(ctx, info); err != nil {
+		return err
+	}
+	return d.syncNode(ctx, info, nil)
+}
+
+// Run syncs the NodeDiskIOInfo objects every period until the context is done.
+func (d *Driver) Run(ctx context.Context, period time.Duration) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := d.Sync(ctx); err != nil {
+			klog.ErrorS(err, "Failed to sync NodeDiskIOInfo")
+		}
+	}, period)
+}
+
+// Sync reports the allocatable bandwidth of the disks of the nodes, accounting the reserved pods.
+func (d *Driver) Sync(ctx context.Context) error {
+	pods := &v1.PodList{}
+	if err := d.client.List(ctx, pods); err != nil {
+		return err
+	}
+	podsByUID := make(map[string]*v1.Pod, len(pods.Items))
+	for i := range pods.Items {
+		podsByUID[string(pods.Items[i].UID)] = &pods.Items[i]
+	}
+
+	infos := &diskiov1alpha1.NodeDiskIOInfoList{}
+	if err := d.client.List(ctx, infos, client.InNamespace(d.namespace)); err != nil {
+		return err
+	}
+	for i := range infos.Items {
+		if err := d.syncNode(ctx, &infos.Items[i], podsByUID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncNode places the reserved pods first-fit on the disks, as the scheduler does, and reports
+// the bandwidth left. The pods not found are ignored.
+func (d *Driver) syncNode(ctx context.Context, info *diskiov1alpha1.NodeDiskIOInfo, pods map[string]*v1.Pod) error {
+	d.lock.Lock()
+	devices, ok := d.nodes[info.Spec.NodeName]
+	d.lock.Unlock()
+	if !ok {
+		return nil
+	}
+
+	allocatable := make([]diskioaware.Bandwidth, len(devices))
+	for i, dev := range devices {
+		allocatable[i] = dev.Capacity
+		if allocatable[i].Read == 0 {
+			allocatable[i].Read = diskioaware.Unlimited
+		}
+		if allocatable[i].Write == 0 {
+			allocatable[i].Write = diskioaware.Unlimited
+		}
+	}
+	for _, uid := range info.Spec.ReservedPods {
+		pod, ok := pods[uid]
+		if !ok {
+			continue
+		}
+		req, err := diskioaware.GetIORequest(pod)
+		if err != nil || req == nil {
+			continue
+		}
+		for i, dev := range devices {
+			bw, err := diskioaware.NormalizerFor(dev.Model).EstimateRequest(req)
+			if err != nil || !bw.FitsIn(allocatable[i]) {
+				continue
+			}
+			allocatable[i] = allocatable[i].Sub(bw)
+			break
+		}
+	}
+
+	info.Status.ObservedGeneration = info.Generation
+	info.Status.AllocatableBandwidth = make(map[string]diskiov1alpha1.DeviceAllocatableBandwidth, len(devices))
+	for i, dev := range devices {
+		status := diskiov1alpha1.BlockIOStatus{
+			Total: *resource.NewQuantity(allocatable[i].Total, resource.DecimalSI),
+		}
+		if allocatable[i].Read != diskioaware.Unlimited {
+			status.Read = resource.NewQuantity(allocatable[i].Read, resource.DecimalSI)
+		}
+		if allocatable[i].Write != diskioaware.Unlimited {
+			status.Write = resource.NewQuantity(allocatable[i].Write, resource.DecimalSI)
+		}
+		info.Status.AllocatableBandwidth[dev.ID] = diskiov1alpha1.DeviceAllocatableBandwidth{
+			Name:          dev.Name,
+			Model:         dev.Model,
+			BlockIOStatus: status,
+		}
+	}
+	return d.client.Status().Update(ctx, info)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskioaware
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Unlimited is the read or write bandwidth of a disk whose driver reports only the total bandwidth.
+const Unlimited int64 = math.MaxInt64
+
+// Bandwidth is a disk IO bandwidth in bytes per second.
+type Bandwidth struct {
+	Read  int64
+	Write int64
+	Total int64
+}
+
+// FitsIn checks the total, read and write bandwidth against the allocatable bandwidth of a disk.
+// The read and write bandwidth the disk reports no limit for are Unlimited; a used up or
+// overcommitted bandwidth is zero or negative.
+func (b Bandwidth) FitsIn(allocatable Bandwidth) bool {
+	return b.Total <= allocatable.Total && b.Read <= allocatable.Read && b.Write <= allocatable.Write
+}
+
+// Sub deducts the bandwidth from the allocatable bandwidth, leaving the Unlimited read and
+// write bandwidth as they are.
+func (b Bandwidth) Sub(other Bandwidth) Bandwidth {
+	b.Total -= other.Total
+	if b.Read != Unlimited {
+		b.Read -= other.Read
+	}
+	if b.Write != Unlimited {
+		b.Write -= other.Write
+	}
+	return b
+}
+
+// Normalizer is an IO calculation model: it converts the IO request of a pod into the
+// normalized bandwidth the pod consumes on a disk model, the unit the node driver reports
+// the allocatable bandwidth of the disks in.
+type Normalizer interface {
+	// Name returns the name of the IO calculation model.
+	Name() string
+	// EstimateRequest returns the normalized bandwidth of the IO request.
+	EstimateRequest(req *IORequest) (Bandwidth, error)
+}
+
+var (
+	normalizersLock sync.RWMutex
+	// normalizers holds the registered IO calculation models, keyed by disk model.
+	normalizers = map[string]Normalizer{}
+)
+
+// RegisterNormalizer registers the IO calculation model of a disk model. It is meant to be
+// called before the scheduler starts, by the builds shipping vendor specific models.
+func RegisterNormalizer(model string, n Normalizer) error {
+	normalizersLock.Lock()
+	defer normalizersLock.Unlock()
+	if _, ok := normalizers[model]; ok {
+		return fmt.Errorf("a normalizer is already registered for disk model %q", model)
+	}
+	normalizers[model] = n
+	return nil
+}
+
+// NormalizerFor returns the IO calculation model of the disk model, the pass-through model
+// when none is registered.
+func NormalizerFor(model string) Normalizer {
+	normalizersLock.RLock()
+	defer normalizersLock.RUnlock()
+	if n, ok := normalizers[model]; ok {
+		return n
+	}
+	return passThroughNormalizer{}
+}
+
+// passThroughNormalizer takes the requested bandwidth as is, whatever the block size.
+type passThroughNormalizer struct{}
+
+func (passThroughNormalizer) Name() string {
+	return "PassThrough"
+}
+
+func (passThroughNormalizer) EstimateRequest(req *IORequest) (Bandwidth, error) {
+	return Bandwidth{
+		Read:  req.Read,
+		Write: req.Write,
+		Total: req.Read + req.Write,
+	}, nil
+}
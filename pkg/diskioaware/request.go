@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskioaware
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// IORequestAnnotation holds the disk IO bandwidth request of a pod, either as read and
+	// write bandwidth, e.g. {"rbps": "20M", "wbps": "30M", "blocksize": "4k"}, or as a total
+	// bandwidth and a read:write ratio, e.g. {"bps": "50M", "rwratio": "2:3", "blocksize": "4k"}.
+	IORequestAnnotation = "blockio.kubernetes.io/throughput"
+
+	// DefaultBlockSize is the IO block size of the requests not setting one.
+	DefaultBlockSize int64 = 4096
+)
+
+// IORequest is the disk IO bandwidth requested by a pod.
+type IORequest struct {
+	// Read is the read bandwidth in bytes per second.
+	Read int64
+	// Write is the write bandwidth in bytes per second.
+	Write int64
+	// BlockSize is the IO block size in bytes.
+	BlockSize int64
+}
+
+type ioRequestAnnotation struct {
+	RBPS      string `json:"rbps,omitempty"`
+	WBPS      string `json:"wbps,omitempty"`
+	BPS       string `json:"bps,omitempty"`
+	RWRatio   string `json:"rwratio,omitempty"`
+	BlockSize string `json:"blocksize,omitempty"`
+}
+
+// GetIORequest returns the disk IO request of the pod, nil if it requests no bandwidth.
+func GetIORequest(pod *v1.Pod) (*IORequest, error) {
+	value, ok := pod.Annotations[IORequestAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var a ioRequestAnnotation
+	if err := json.Unmarshal([]byte(value), &a); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", IORequestAnnotation, err)
+	}
+
+	req := &IORequest{BlockSize: DefaultBlockSize}
+	var err error
+	if a.BlockSize != "" {
+		if req.BlockSize, err = parseBytes("blocksize", a.BlockSize); err != nil {
+			return nil, err
+		}
+		if req.BlockSize == 0 {
+			return nil, fmt.Errorf("invalid %s annotation: blocksize must be greater than 0", IORequestAnnotation)
+		}
+	}
+
+	switch {
+	case a.BPS != "" && (a.RBPS != "" || a.WBPS != ""):
+		return nil, fmt.Errorf("invalid %s annotation: bps cannot be set with rbps or wbps", IORequestAnnotation)
+	case a.BPS != "":
+		total, err := parseBytes("bps", a.BPS)
+		if err != nil {
+			return nil, err
+		}
+		read, write, err := parseRWRatio(a.RWRatio)
+		if err != nil {
+			return nil, err
+		}
+		req.Read = total * read / (read + write)
+		req.Write = total - req.Read
+	default:
+		if a.RWRatio != "" {
+			return nil, fmt.Errorf("invalid %s annotation: rwratio requires bps", IORequestAnnotation)
+		}
+		if a.RBPS != "" {
+			if req.Read, err = parseBytes("rbps", a.RBPS); err != nil {
+				return nil, err
+			}
+		}
+		if a.WBPS != "" {
+			if req.Write, err = parseBytes("wbps", a.WBPS); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if req.Read+req.Write == 0 {
+		return nil, nil
+	}
+	return req, nil
+}
+
+func parseBytes(field, value string) (int64, error) {
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation: %s: %w", IORequestAnnotation, field, err)
+	}
+	if q.Sign() < 0 {
+		return 0, fmt.Errorf("invalid %s annotation: %s must not be negative", IORequestAnnotation, field)
+	}
+	return q.Value(), nil
+}
+
+// parseRWRatio parses a read:write ratio, a pod not setting one only reads.
+func parseRWRatio(value string) (int64, int64, error) {
+	if value == "" {
+		return 1, 0, nil
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid %s annotation: rwratio %q is not of the form read:write", IORequestAnnotation, value)
+	}
+	read, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || read < 0 {
+		return 0, 0, fmt.Errorf("invalid %s annotation: rwratio %q is not of the form read:write", IORequestAnnotation, value)
+	}
+	write, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || write < 0 || read+write == 0 {
+		return 0, 0, fmt.Errorf("invalid %s annotation: rwratio %q is not of the form read:write", IORequestAnnotation, value)
+	}
+	return read, write, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskioaware
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestGetIORequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation *string
+		want       *IORequest
+		wantErr    bool
+	}{
+		{
+			name: "no annotation",
+		},
+		{
+			name:       "read and write bandwidth",
+			annotation: pointer.String(`{"rbps": "20M", "wbps": "30M", "blocksize": "8k"}`),
+			want:       &IORequest{Read: 20000000, Write: 30000000, BlockSize: 8000},
+		},
+		{
+			name:       "read bandwidth with the default block size",
+			annotation: pointer.String(`{"rbps": "1Mi"}`),
+			want:       &IORequest{Read: 1048576, BlockSize: DefaultBlockSize},
+		},
+		{
+			name:       "total bandwidth and read:write ratio",
+			annotation: pointer.String(`{"bps": "50M", "rwratio": "2:3"}`),
+			want:       &IORequest{Read: 20000000, Write: 30000000, BlockSize: DefaultBlockSize},
+		},
+		{
+			name:       "total bandwidth without ratio",
+			annotation: pointer.String(`{"bps": "50M"}`),
+			want:       &IORequest{Read: 50000000, BlockSize: DefaultBlockSize},
+		},
+		{
+			name:       "zero bandwidth",
+			annotation: pointer.String(`{"rbps": "0"}`),
+		},
+		{
+			name:       "invalid json",
+			annotation: pointer.String(`{"rbps": 20}`),
+			wantErr:    true,
+		},
+		{
+			name:       "invalid quantity",
+			annotation: pointer.String(`{"rbps": "fast"}`),
+			wantErr:    true,
+		},
+		{
+			name:       "negative bandwidth",
+			annotation: pointer.String(`{"wbps": "-10M"}`),
+			wantErr:    true,
+		},
+		{
+			name:       "total bandwidth with read bandwidth",
+			annotation: pointer.String(`{"bps": "50M", "rbps": "20M"}`),
+			wantErr:    true,
+		},
+		{
+			name:       "ratio without total bandwidth",
+			annotation: pointer.String(`{"rbps": "20M", "rwratio": "1:1"}`),
+			wantErr:    true,
+		},
+		{
+			name:       "invalid ratio",
+			annotation: pointer.String(`{"bps": "50M", "rwratio": "0:0"}`),
+			wantErr:    true,
+		},
+		{
+			name:       "zero block size",
+			annotation: pointer.String(`{"rbps": "20M", "blocksize": "0"}`),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "p"}}
+			if tt.annotation != nil {
+				pod.Annotations = map[string]string{IORequestAnnotation: *tt.annotation}
+			}
+			got, err := GetIORequest(pod)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetIORequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetIORequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskioaware
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// reservation is the bandwidth reserved for a pod on a device, deducted from the bandwidth
+// reported by the driver until the driver reports a generation accounting the pod.
+type reservation struct {
+	device    string
+	bandwidth Bandwidth
+	// bound tells the pod is bound to the node, and is to be listed in the reserved pods of the node.
+	bound bool
+	// generation is the generation of the NodeDiskIOInfo object listing the pod, 0 until it is listed.
+	generation int64
+}
+
+// reservations holds the reservations of the pods, per node, and the changes of the reserved
+// pods of the nodes not yet written to their NodeDiskIOInfo objects.
+type reservations struct {
+	sync.Mutex
+	nodes map[string]map[types.UID]reservation
+	// released are the pods to remove from the reserved pods of the nodes.
+	released map[string]sets.Set[types.UID]
+}
+
+func newReservations() *reservations {
+	return &reservations{
+		nodes:    make(map[string]map[types.UID]reservation),
+		released: make(map[string]sets.Set[types.UID]),
+	}
+}
+
+func (r *reservations) add(nodeName string, uid types.UID, res reservation) {
+	r.Lock()
+	defer r.Unlock()
+	pods, ok := r.nodes[nodeName]
+	if !ok {
+		pods = make(map[types.UID]reservation)
+		r.nodes[nodeName] = pods
+	}
+	pods[uid] = res
+}
+
+func (r *reservations) remove(nodeName string, uid types.UID) {
+	r.Lock()
+	defer r.Unlock()
+	r.removeLocked(nodeName, uid)
+}
+
+func (r *reservations) removeLocked(nodeName string, uid types.UID) {
+	delete(r.nodes[nodeName], uid)
+	if len(r.nodes[nodeName]) == 0 {
+		delete(r.nodes, nodeName)
+	}
+}
+
+// bind marks the pod bound to the node, to be listed in the reserved pods of the node.
+func (r *reservations) bind(nodeName string, uid types.UID) {
+	r.Lock()
+	defer r.Unlock()
+	if res, ok := r.nodes[nodeName][uid]; ok {
+		res.bound = true
+		r.nodes[nodeName][uid] = res
+	}
+}
+
+// release drops the reservation of the pod, to be removed from the reserved pods of the node.
+func (r *reservations) release(nodeName string, uid types.UID) {
+	r.Lock()
+	defer r.Unlock()
+	r.removeLocked(nodeName, uid)
+	if _, ok := r.released[nodeName]; !ok {
+		r.released[nodeName] = sets.New[types.UID]()
+	}
+	r.released[nodeName].Insert(uid)
+}
+
+// toSync returns the pods to add to and to remove from the reserved pods of the node.
+func (r *reservations) toSync(nodeName string) ([]types.UID, sets.Set[types.UID]) {
+	r.Lock()
+	defer r.Unlock()
+	var listed []types.UID
+	for uid, res := range r.nodes[nodeName] {
+		if res.bound && res.generation == 0 {
+			listed = append(listed, uid)
+		}
+	}
+	return listed, r.released[nodeName].Clone()
+}
+
+// synced records that the reserved pods of the node were updated at the generation.
+func (r *reservations) synced(nodeName string, listed []types.UID, released sets.Set[types.UID], generation int64) {
+	r.Lock()
+	defer r.Unlock()
+	for _, uid := range listed {
+		if res, ok := r.nodes[nodeName][uid]; ok {
+			res.generation = generation
+			r.nodes[nodeName][uid] = res
+		}
+	}
+	if pods, ok := r.released[nodeName]; ok {
+		pods.Delete(released.UnsortedList()...)
+		if pods.Len() == 0 {
+			delete(r.released, nodeName)
+		}
+	}
+}
+
+// pending returns the bandwidth reserved on each device of the node and not accounted by the
+// driver at observedGeneration. The reservations accounted by the driver are dropped, the ones
+// not yet listed in the NodeDiskIOInfo object are always pending.
+func (r *reservations) pending(nodeName string, observedGeneration int64) map[string]Bandwidth {
+	r.Lock()
+	defer r.Unlock()
+	pending := make(map[string]Bandwidth)
+	for uid, res := range r.nodes[nodeName] {
+		if res.generation > 0 && res.generation <= observedGeneration {
+			delete(r.nodes[nodeName], uid)
+			continue
+		}
+		bw := pending[res.device]
+		bw.Read += res.bandwidth.Read
+		bw.Write += res.bandwidth.Write
+		bw.Total += res.bandwidth.Total
+		pending[res.device] = bw
+	}
+	if len(r.nodes[nodeName]) == 0 {
+		delete(r.nodes, nodeName)
+	}
+	return pending
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkoverhead
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+)
+
+// bandwidthAllocations holds the bandwidth allocated to the pods on each link, from their Reserve, or from
+// their assignment seen by the pod informer, until they are unreserved, terminated or deleted.
+type bandwidthAllocations struct {
+	sync.Mutex
+	pods map[types.UID]map[networkawareutil.CostKey]int64
+}
+
+func newBandwidthAllocations() *bandwidthAllocations {
+	return &bandwidthAllocations{
+		pods: make(map[types.UID]map[networkawareutil.CostKey]int64),
+	}
+}
+
+func (b *bandwidthAllocations) add(uid types.UID, links map[networkawareutil.CostKey]int64) {
+	b.Lock()
+	defer b.Unlock()
+	b.pods[uid] = links
+}
+
+// addIfAbsent adds the links of a pod unless it has any already, e.g. reserved since they were computed.
+func (b *bandwidthAllocations) addIfAbsent(uid types.UID, links map[networkawareutil.CostKey]int64) {
+	b.Lock()
+	defer b.Unlock()
+	if _, ok := b.pods[uid]; !ok {
+		b.pods[uid] = links
+	}
+}
+
+func (b *bandwidthAllocations) has(uid types.UID) bool {
+	b.Lock()
+	defer b.Unlock()
+	_, ok := b.pods[uid]
+	return ok
+}
+
+func (b *bandwidthAllocations) remove(uid types.UID) {
+	b.Lock()
+	defer b.Unlock()
+	delete(b.pods, uid)
+}
+
+// allocated returns the bandwidth allocated to all the pods on each link.
+func (b *bandwidthAllocations) allocated() map[networkawareutil.CostKey]int64 {
+	b.Lock()
+	defer b.Unlock()
+	allocated := make(map[networkawareutil.CostKey]int64)
+	for _, links := range b.pods {
+		for link, bandwidth := range links {
+			allocated[link] += bandwidth
+		}
+	}
+	return allocated
+}
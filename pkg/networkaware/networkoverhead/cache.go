@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package networkoverhead
+
+import (
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
+
+	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
+)
+
+// domainGroup holds the nodes belonging to the same domains at all topology levels, which share their network costs.
+type domainGroup struct {
+	// domains of the nodes, from the finest to the coarsest topology level
+	domains []string
+
+	// map for cost / destinations of the domains. Search for requirements faster...
+	costMap map[networkawareutil.CostKey]int64
+}
+
+// topologyCosts holds the network costs of the domains of the nodes, computed from a NetworkTopology.
+// It is shared by all pods, and never modified once built.
+type topologyCosts struct {
+	// NetworkTopology CR the costs were computed from, with sorted costs
+	networkTopology *ntv1alpha1.NetworkTopology
+
+	// key of the domain group of each node
+	nodeGroups map[string]string
+
+	// domain groups by key
+	groups map[string]*domainGroup
+
+	// bandwidth capacity of the links, unbounded if missing
+	bandwidthCapacity map[networkawareutil.CostKey]int64
+
+	// bandwidth allocated on the links in the NetworkTopology
+	bandwidthAllocated map[networkawareutil.CostKey]int64
+}
+
+// domainsKey : return the key of the domain group of nodes with the given domains
+func domainsKey(domains []string) string {
+	return strings.Join(domains, "/")
+}
+
+// costCache holds the topology costs until the NetworkTopology or the nodes change.
+type costCache struct {
+	sync.Mutex
+
+	// generation of the nodes, increased when a node is added, removed or relabeled
+	generation uint64
+
+	// NetworkTopology the costs were computed from
+	ntUID             types.UID
+	ntResourceVersion string
+
+	// generation of the nodes the costs were computed for
+	costsGeneration uint64
+
+	costs *topologyCosts
+}
+
+func newCostCache() *costCache {
+	return &costCache{}
+}
+
+// invalidate : drop the costs, e.g. when a node is added, removed or relabeled. The costs being built wait for
+// the invalidation, and are never used after it.
+func (c *costCache) invalidate() {
+	c.Lock()
+	defer c.Unlock()
+	c.generation++
+	c.costs = nil
+}
+
+// get : return the costs computed for the NetworkTopology and the current generation of the nodes, built if
+// they changed. The nodes must be listed by build, after the invalidations of their previous changes.
+func (c *costCache) get(networkTopology *ntv1alpha1.NetworkTopology, build func() (*topologyCosts, error)) (*topologyCosts, error) {
+	c.Lock()
+	defer c.Unlock()
+	if c.costs != nil && c.ntUID == networkTopology.UID && c.ntResourceVersion == networkTopology.ResourceVersion && c.costsGeneration == c.generation {
+		return c.costs, nil
+	}
+	costs, err := build()
+	if err != nil {
+		return nil, err
+	}
+	c.costs = costs
+	c.ntUID = networkTopology.UID
+	c.ntResourceVersion = networkTopology.ResourceVersion
+	c.costsGeneration = c.generation
+	return c.costs, nil
+}
@@ -21,19 +21,25 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	pluginconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
 
 	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
@@ -43,6 +49,7 @@ import (
 var _ framework.PreFilterPlugin = &NetworkOverhead{}
 var _ framework.FilterPlugin = &NetworkOverhead{}
 var _ framework.ScorePlugin = &NetworkOverhead{}
+var _ framework.ReservePlugin = &NetworkOverhead{}
 
 const (
 	// Name : name of plugin used in the plugin registry and configurations.
@@ -54,7 +61,7 @@ const (
 	// SameHostname : If pods belong to the same host, then consider cost as 0
 	SameHostname = 0
 
-	// SameZone : If pods belong to hosts in the same zone, then consider cost as 1
+	// SameZone : If pods belong to hosts in the same zone, or the same domain at the finest topology level, then consider cost as 1
 	SameZone = 1
 
 	// preFilterStateKey is the key in CycleState to NetworkOverhead pre-computed data.
@@ -63,6 +70,8 @@ const (
 
 var scheme = runtime.NewScheme()
 
+var defaultTopologyKeys = []ntv1alpha1.TopologyKey{ntv1alpha1.NetworkTopologyZone, ntv1alpha1.NetworkTopologyRegion}
+
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
@@ -70,15 +79,28 @@ func init() {
 	utilruntime.Must(ntv1alpha1.AddToScheme(scheme))
 }
 
-// NetworkOverhead : Filter and Score nodes based on Pod's AppGroup requirements: MaxNetworkCosts requirements among Pods with dependencies
+// NetworkOverhead : Filter and Score nodes based on Pod's AppGroup requirements: MaxNetworkCosts and MinBandwidth requirements among Pods with dependencies
 type NetworkOverhead struct {
 	client.Client
 
 	podLister   corelisters.PodLister
+	nodeLister  corelisters.NodeLister
 	handle      framework.Handle
 	namespaces  []string
 	weightsName string
 	ntName      string
+
+	// topology levels of the network costs, from the finest to the coarsest
+	topologyKeys []ntv1alpha1.TopologyKey
+
+	// bandwidth allocated to the pods by the plugin on the links between domains
+	bandwidth *bandwidthAllocations
+
+	// assigned pods whose bandwidth is to be allocated, e.g. the ones bound before the plugin started
+	queue workqueue.RateLimitingInterface
+
+	// network costs of the domains of the nodes, shared by all pods
+	costCache *costCache
 }
 
 // PreFilterState computed at PreFilter and used at Filter and Score.
@@ -101,17 +123,28 @@ type PreFilterState struct {
 	// Pods already scheduled based on the dependency list
 	scheduledList networkawareutil.ScheduledList
 
-	// node map for cost / destinations. Search for requirements faster...
-	nodeCostMap map[string]map[networkawareutil.CostKey]int64
+	// Network costs of the domains of the nodes
+	topologyCosts *topologyCosts
+
+	// domain group map for the results of the nodes not hosting pods of the AppGroup
+	groupResults map[string]*nodeResult
 
-	// node map for satisfied dependencies
-	satisfiedMap map[string]int64
+	// node map for the results of the nodes hosting pods of the AppGroup
+	hostResults map[string]*nodeResult
 
-	// node map for violated dependencies
-	violatedMap map[string]int64
+	// bandwidth capacity of the links, unbounded if missing
+	bandwidthCapacityMap map[networkawareutil.CostKey]int64
 
-	// node map for costs
-	finalCostMap map[string]int64
+	// bandwidth already allocated on the links
+	bandwidthAllocatedMap map[networkawareutil.CostKey]int64
+}
+
+// nodeResult : dependencies of the pod met and unmet on a node, accumulated cost, and bandwidth requested on each link
+type nodeResult struct {
+	satisfied        int64
+	violated         int64
+	cost             int64
+	bandwidthRequest map[networkawareutil.CostKey]int64
 }
 
 // Clone the preFilter state.
@@ -139,13 +172,16 @@ func (no *NetworkOverhead) ScoreExtensions() framework.ScoreExtensions {
 }
 
 // New : create an instance of a NetworkOverhead plugin
-func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	klog.V(4).InfoS("Creating new instance of the NetworkOverhead plugin")
 
 	args, err := getArgs(obj)
 	if err != nil {
 		return nil, err
 	}
+	if err := validation.ValidateNetworkOverheadArgs(nil, args); err != nil {
+		return nil, err
+	}
 	client, err := client.New(handle.KubeConfig(), client.Options{
 		Scheme: scheme,
 	})
@@ -157,11 +193,50 @@ func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framew
 		Client: client,
 
 		podLister:   handle.SharedInformerFactory().Core().V1().Pods().Lister(),
+		nodeLister:  handle.SharedInformerFactory().Core().V1().Nodes().Lister(),
 		handle:      handle,
 		namespaces:  args.Namespaces,
 		weightsName: args.WeightsName,
 		ntName:      args.NetworkTopologyName,
+		bandwidth:   newBandwidthAllocations(),
+		queue:       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), Name),
+		costCache:   newCostCache(),
+	}
+	for _, key := range args.TopologyKeys {
+		no.topologyKeys = append(no.topologyKeys, ntv1alpha1.TopologyKey(key))
+	}
+	if len(no.topologyKeys) == 0 { // Costs between zones and regions by default
+		no.topologyKeys = defaultTopologyKeys
 	}
+	// The bandwidth of the assigned pods is allocated until they terminate or are deleted
+	handle.SharedInformerFactory().Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: no.podUpdated,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			no.podUpdated(newObj)
+		},
+		DeleteFunc: no.podDeleted,
+	})
+	go func() {
+		<-ctx.Done()
+		no.queue.ShutDown()
+	}()
+	go wait.UntilWithContext(ctx, no.runWorker, time.Second)
+	// The costs are computed again once the domains of the nodes change
+	handle.SharedInformerFactory().Core().V1().Nodes().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			no.costCache.invalidate()
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNode, oldOK := oldObj.(*corev1.Node)
+			newNode, newOK := newObj.(*corev1.Node)
+			if !oldOK || !newOK || domainsKey(networkawareutil.GetNodeDomains(oldNode, no.topologyKeys)) != domainsKey(networkawareutil.GetNodeDomains(newNode, no.topologyKeys)) {
+				no.costCache.invalidate()
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			no.costCache.invalidate()
+		},
+	})
 	return no, nil
 }
 
@@ -169,9 +244,11 @@ func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framew
 // 1. Get appGroup name and respective appGroup CR.
 // 2. Get networkTopology CR.
 // 3. Get dependency and scheduled list for the given pod
-// 4. Update cost map of all nodes
+// 4. Get cost map of all domains, cached until the networkTopology CR or the nodes change
 // 5. Get number of satisfied and violated dependencies
 // 6. Get final cost of the given node to be used in the score plugin
+// 7. Get the bandwidth requested by the given pod on the links of each node
+// Steps 5 to 7 are computed once per domain group, and once more for the nodes hosting pods of the AppGroup.
 func (no *NetworkOverhead) PreFilter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod) (*framework.PreFilterResult, *framework.Status) {
 	// Init PreFilter State
 	preFilterState := &PreFilterState{
@@ -193,9 +270,6 @@ func (no *NetworkOverhead) PreFilter(ctx context.Context, state *framework.Cycle
 	// Get NetworkTopology CR
 	networkTopology := no.findNetworkTopologyNetworkOverhead()
 
-	// Sort Costs if manual weights were selected
-	no.sortNetworkTopologyCosts(networkTopology)
-
 	// Get Dependencies of the given pod
 	dependencyList := networkawareutil.GetDependencyList(pod, appGroup)
 
@@ -224,59 +298,52 @@ func (no *NetworkOverhead) PreFilter(ctx context.Context, state *framework.Cycle
 		return nil, framework.NewStatus(framework.Success, "Scheduled list is empty, return")
 	}
 
-	// Get all nodes
-	nodeList, err := no.handle.SnapshotSharedLister().NodeInfos().List()
+	// Get cost map of all domains: only built when the NetworkTopology CR or the nodes change.
+	// The nodes are listed from the informer, whose changes invalidate the costs once they are listed.
+	topologyCosts, err := no.costCache.get(networkTopology, func() (*topologyCosts, error) {
+		nodeList, err := no.nodeLister.List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		return no.buildTopologyCosts(networkTopology, nodeList), nil
+	})
 	if err != nil {
 		return nil, framework.NewStatus(framework.Error, fmt.Sprintf("Error getting the nodelist: %v", err))
 	}
 
-	// Create variables to fill PreFilterState
-	nodeCostMap := make(map[string]map[networkawareutil.CostKey]int64)
-	satisfiedMap := make(map[string]int64)
-	violatedMap := make(map[string]int64)
-	finalCostMap := make(map[string]int64)
-
-	// For each node:
-	// 1 - Get region and zone labels
-	// 2 - Calculate satisfied and violated number of dependencies
-	// 3 - Calculate the final cost of the node to be used by the scoring plugin
-	for _, nodeInfo := range nodeList {
-		// retrieve region and zone labels
-		region := networkawareutil.GetNodeRegion(nodeInfo.Node())
-		zone := networkawareutil.GetNodeZone(nodeInfo.Node())
-		klog.V(6).InfoS("Node info",
-			"name", nodeInfo.Node().Name,
-			"region", region,
-			"zone", zone)
-
-		// Create map for cost / destinations. Search for requirements faster...
-		costMap := make(map[networkawareutil.CostKey]int64)
-
-		// Populate cost map for the given node
-		no.populateCostMap(costMap, networkTopology, region, zone)
-		klog.V(6).InfoS("Map", "costMap", costMap)
-
-		// Update nodeCostMap
-		nodeCostMap[nodeInfo.Node().Name] = costMap
-
-		// Get Satisfied and Violated number of dependencies
-		satisfied, violated, ok := checkMaxNetworkCostRequirements(scheduledList, dependencyList, nodeInfo, region, zone, costMap, no)
-		if ok != nil {
-			return nil, framework.NewStatus(framework.Error, fmt.Sprintf("pod hostname not found: %v", ok))
+	// For each domain group, calculate the results of its nodes, but the ones hosting pods of the AppGroup
+	groupResults := make(map[string]*nodeResult, len(topologyCosts.groups))
+	for key, group := range topologyCosts.groups {
+		result, err := no.getNodeResult(scheduledList, dependencyList, "", group)
+		if err != nil {
+			return nil, framework.NewStatus(framework.Error, fmt.Sprintf("pod hostname not found: %v", err))
 		}
+		klog.V(6).InfoS("Domain group result", "domains", group.domains, "result", result)
+		groupResults[key] = result
+	}
 
-		// Update Satisfied and Violated maps
-		satisfiedMap[nodeInfo.Node().Name] = satisfied
-		violatedMap[nodeInfo.Node().Name] = violated
-		klog.V(6).InfoS("Number of dependencies", "satisfied", satisfied, "violated", violated)
-
-		// Get accumulated cost based on pod dependencies
-		cost, ok := no.getAccumulatedCost(scheduledList, dependencyList, nodeInfo.Node().Name, region, zone, costMap)
-		if ok != nil {
-			return nil, framework.NewStatus(framework.Error, fmt.Sprintf("getting pod hostname from Snapshot: %v", ok))
+	// For each node hosting pods of the AppGroup, calculate its own results
+	hostResults := make(map[string]*nodeResult)
+	for _, podAllocated := range scheduledList {
+		if _, ok := hostResults[podAllocated.Hostname]; ok {
+			continue
 		}
-		klog.V(6).InfoS("Node final cost", "cost", cost)
-		finalCostMap[nodeInfo.Node().Name] = cost
+		group, ok := topologyCosts.groups[topologyCosts.nodeGroups[podAllocated.Hostname]]
+		if !ok {
+			continue
+		}
+		result, err := no.getNodeResult(scheduledList, dependencyList, podAllocated.Hostname, group)
+		if err != nil {
+			return nil, framework.NewStatus(framework.Error, fmt.Sprintf("pod hostname not found: %v", err))
+		}
+		klog.V(6).InfoS("Node result", "name", podAllocated.Hostname, "result", result)
+		hostResults[podAllocated.Hostname] = result
+	}
+
+	// Get bandwidth allocations of the links: the ones of the NetworkTopology CR and the ones of the plugin
+	bandwidthAllocatedMap := no.bandwidth.allocated()
+	for link, allocated := range topologyCosts.bandwidthAllocated {
+		bandwidthAllocatedMap[link] += allocated
 	}
 
 	// Update PreFilter State
@@ -287,16 +354,62 @@ func (no *NetworkOverhead) PreFilter(ctx context.Context, state *framework.Cycle
 		networkTopology: networkTopology,
 		dependencyList:  dependencyList,
 		scheduledList:   scheduledList,
-		nodeCostMap:     nodeCostMap,
-		satisfiedMap:    satisfiedMap,
-		violatedMap:     violatedMap,
-		finalCostMap:    finalCostMap,
+		topologyCosts:   topologyCosts,
+		groupResults:    groupResults,
+		hostResults:     hostResults,
+
+		bandwidthCapacityMap:  topologyCosts.bandwidthCapacity,
+		bandwidthAllocatedMap: bandwidthAllocatedMap,
 	}
 
 	state.Write(preFilterStateKey, preFilterState)
 	return nil, framework.NewStatus(framework.Success, "PreFilter State updated")
 }
 
+// getNodeResult : calculate the results of a node, given its domain group
+func (no *NetworkOverhead) getNodeResult(
+	scheduledList networkawareutil.ScheduledList,
+	dependencyList []agv1alpha1.DependenciesInfo,
+	nodeName string,
+	group *domainGroup) (*nodeResult, error) {
+	// Get Satisfied and Violated number of dependencies
+	satisfied, violated, err := checkMaxNetworkCostRequirements(scheduledList, dependencyList, nodeName, group.domains, group.costMap, no)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get accumulated cost based on pod dependencies
+	cost, err := no.getAccumulatedCost(scheduledList, dependencyList, nodeName, group.domains, group.costMap)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get bandwidth requested on the links to the pod dependencies
+	bandwidthRequest, err := no.getBandwidthRequest(scheduledList, dependencyList, nodeName, group.domains, no.getSnapshotNode)
+	if err != nil {
+		return nil, err
+	}
+	return &nodeResult{satisfied: satisfied, violated: violated, cost: cost, bandwidthRequest: bandwidthRequest}, nil
+}
+
+// getPreFilterNodeResult : return the results of a node computed at PreFilter. The results of a node unknown
+// to the cached costs, e.g. added since they were computed, are calculated on the spot.
+func (no *NetworkOverhead) getPreFilterNodeResult(preFilterState *PreFilterState, nodeName string) (*nodeResult, error) {
+	if result, ok := preFilterState.hostResults[nodeName]; ok {
+		return result, nil
+	}
+	if key, ok := preFilterState.topologyCosts.nodeGroups[nodeName]; ok {
+		return preFilterState.groupResults[key], nil
+	}
+
+	nodeInfo, err := no.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return nil, err
+	}
+	group := no.buildDomainGroup(preFilterState.topologyCosts.networkTopology, networkawareutil.GetNodeDomains(nodeInfo.Node(), no.topologyKeys))
+	return no.getNodeResult(preFilterState.scheduledList, preFilterState.dependencyList, nodeName, group)
+}
+
 // PreFilterExtensions returns prefilter extensions, pod add and remove.
 func (no *NetworkOverhead) PreFilterExtensions() framework.PreFilterExtensions {
 	return no
@@ -322,7 +435,7 @@ func (no *NetworkOverhead) RemovePod(ctx context.Context,
 	return framework.NewStatus(framework.Success, "")
 }
 
-// Filter : evaluate if node can respect maxNetworkCost requirements
+// Filter : evaluate if node can respect maxNetworkCost and minBandwidth requirements
 func (no *NetworkOverhead) Filter(ctx context.Context,
 	cycleState *framework.CycleState,
 	pod *corev1.Pod,
@@ -344,9 +457,14 @@ func (no *NetworkOverhead) Filter(ctx context.Context,
 		return nil
 	}
 
+	result, err := no.getPreFilterNodeResult(preFilterState, nodeInfo.Node().Name)
+	if err != nil {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("pod hostname not found: %v", err))
+	}
+
 	// Get satisfied and violated number of dependencies
-	satisfied := preFilterState.satisfiedMap[nodeInfo.Node().Name]
-	violated := preFilterState.violatedMap[nodeInfo.Node().Name]
+	satisfied := result.satisfied
+	violated := result.violated
 	klog.V(6).InfoS("Number of dependencies:", "satisfied", satisfied, "violated", violated)
 
 	// The pod is filtered out if the number of violated dependencies is higher than the satisfied ones
@@ -354,6 +472,18 @@ func (no *NetworkOverhead) Filter(ctx context.Context,
 		return framework.NewStatus(framework.Unschedulable,
 			fmt.Sprintf("Node %v does not meet several network requirements from Workload dependencies: Satisfied: %v Violated: %v", nodeInfo.Node().Name, satisfied, violated))
 	}
+
+	// The pod is filtered out if its bandwidth requests exceed the capacity of a link
+	for link, request := range result.bandwidthRequest {
+		capacity, ok := preFilterState.bandwidthCapacityMap[link]
+		if !ok {
+			continue
+		}
+		if allocated := preFilterState.bandwidthAllocatedMap[link]; allocated+request > capacity {
+			return framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("Node %v does not have enough bandwidth from %v %v to %v: Requested: %v Allocated: %v Capacity: %v", nodeInfo.Node().Name, link.TopologyKey, link.Origin, link.Destination, request, allocated, capacity))
+		}
+	}
 	return nil
 }
 
@@ -376,12 +506,150 @@ func (no *NetworkOverhead) Score(ctx context.Context,
 		return score, framework.NewStatus(framework.Success, "scoreEqually enabled: minimum score")
 	}
 
-	// Return Accumulated Cost as score
-	score = preFilterState.finalCostMap[nodeName]
+	result, err := no.getPreFilterNodeResult(preFilterState, nodeName)
+	if err != nil {
+		return score, framework.NewStatus(framework.Error, fmt.Sprintf("pod hostname not found: %v", err))
+	}
+
+	// Return Accumulated Cost as score, plus the bandwidth cost favoring links with more leftover bandwidth
+	score = result.cost + getBandwidthCost(preFilterState, result.bandwidthRequest)
 	klog.V(4).InfoS("Score:", "pod", pod.GetName(), "node", nodeName, "finalScore", score)
 	return score, framework.NewStatus(framework.Success, "Accumulated cost added as score, normalization ensures lower costs are favored")
 }
 
+// Reserve : allocate the bandwidth requested by the pod on the links of the node
+func (no *NetworkOverhead) Reserve(ctx context.Context,
+	cycleState *framework.CycleState,
+	pod *corev1.Pod,
+	nodeName string) *framework.Status {
+	preFilterState, err := getPreFilterState(cycleState)
+	if err != nil {
+		klog.ErrorS(err, "Failed to read preFilterState from cycleState", "preFilterStateKey", preFilterStateKey)
+		return framework.NewStatus(framework.Error, "not eligible due to failed to read from cycleState")
+	}
+
+	if preFilterState.scoreEqually {
+		return nil
+	}
+	result, err := no.getPreFilterNodeResult(preFilterState, nodeName)
+	if err != nil {
+		return framework.NewStatus(framework.Error, fmt.Sprintf("pod hostname not found: %v", err))
+	}
+	bandwidthRequest := result.bandwidthRequest
+	no.bandwidth.add(pod.UID, bandwidthRequest)
+	klog.V(5).InfoS("Reserved bandwidth", "pod", klog.KObj(pod), "node", nodeName, "bandwidthRequest", bandwidthRequest)
+	return nil
+}
+
+// Unreserve : release the bandwidth allocated to the pod
+func (no *NetworkOverhead) Unreserve(ctx context.Context,
+	cycleState *framework.CycleState,
+	pod *corev1.Pod,
+	nodeName string) {
+	no.bandwidth.remove(pod.UID)
+}
+
+// podDeleted : release the bandwidth allocated to a deleted pod
+func (no *NetworkOverhead) podDeleted(obj interface{}) {
+	var pod *corev1.Pod
+	switch t := obj.(type) {
+	case *corev1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		pod, _ = t.Obj.(*corev1.Pod)
+	}
+	if pod == nil {
+		return
+	}
+	no.bandwidth.remove(pod.UID)
+}
+
+// podUpdated : release the bandwidth allocated to a terminated pod, and queue the assigned pods of an AppGroup
+// without any bandwidth allocated, e.g. the ones bound before the plugin started.
+func (no *NetworkOverhead) podUpdated(obj interface{}) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		no.bandwidth.remove(pod.UID)
+		return
+	}
+	if len(pod.Spec.NodeName) == 0 || len(networkawareutil.GetPodAppGroupLabel(pod)) == 0 || no.bandwidth.has(pod.UID) {
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(pod)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get the key of the pod", "pod", klog.KObj(pod))
+		return
+	}
+	no.queue.Add(key)
+}
+
+func (no *NetworkOverhead) runWorker(ctx context.Context) {
+	for no.processNextPod() {
+	}
+}
+
+// processNextPod : allocate the bandwidth of the next pod in the queue. Returns false once the queue is shut down.
+func (no *NetworkOverhead) processNextPod() bool {
+	item, shutdown := no.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer no.queue.Done(item)
+
+	key := item.(string)
+	if err := no.allocatePodBandwidth(key); err != nil {
+		klog.ErrorS(err, "Failed to allocate the bandwidth of the pod", "pod", key)
+		no.queue.AddRateLimited(item)
+		return true
+	}
+	no.queue.Forget(item)
+	return true
+}
+
+// allocatePodBandwidth : allocate the bandwidth requested by an assigned pod on the links to its dependencies,
+// as they are placed now.
+func (no *NetworkOverhead) allocatePodBandwidth(key string) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	pod, err := no.podLister.Pods(namespace).Get(name)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || no.bandwidth.has(pod.UID) {
+		return nil
+	}
+
+	agName := networkawareutil.GetPodAppGroupLabel(pod)
+	appGroup := no.findAppGroupNetworkOverhead(agName)
+	if appGroup == nil {
+		return fmt.Errorf("AppGroup %s not found", agName)
+	}
+	node, err := no.nodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		return err
+	}
+	pods, err := no.podLister.List(labels.Set(map[string]string{agv1alpha1.AppGroupLabel: agName}).AsSelector())
+	if err != nil {
+		return err
+	}
+	bandwidthRequest, err := no.getBandwidthRequest(networkawareutil.GetScheduledList(pods), networkawareutil.GetDependencyList(pod, appGroup),
+		pod.Spec.NodeName, networkawareutil.GetNodeDomains(node, no.topologyKeys), no.nodeLister.Get)
+	if err != nil {
+		return err
+	}
+	no.bandwidth.addIfAbsent(pod.UID, bandwidthRequest)
+	klog.V(5).InfoS("Allocated bandwidth", "pod", klog.KObj(pod), "node", pod.Spec.NodeName, "bandwidthRequest", bandwidthRequest)
+	return nil
+}
+
 // NormalizeScore : normalize scores since lower scores correspond to lower latency
 func (no *NetworkOverhead) NormalizeScore(ctx context.Context,
 	state *framework.CycleState,
@@ -430,75 +698,173 @@ func getMinMaxScores(scores framework.NodeScoreList) (int64, int64) {
 	return min, max
 }
 
-// sortNetworkTopologyCosts : sort costs if manual weights were selected
-func (no *NetworkOverhead) sortNetworkTopologyCosts(networkTopology *ntv1alpha1.NetworkTopology) {
+// buildTopologyCosts : compute the costs of the domains of the nodes from a sorted copy of the NetworkTopology CR,
+// which is left as it is
+func (no *NetworkOverhead) buildTopologyCosts(networkTopology *ntv1alpha1.NetworkTopology, nodeList []*corev1.Node) *topologyCosts {
+	networkTopology = networkTopology.DeepCopy()
 	if no.weightsName != ntv1alpha1.NetworkTopologyNetperfCosts { // Manual weights were selected
 		for _, w := range networkTopology.Spec.Weights {
-			// Sort Costs by TopologyKey, might not be sorted since were manually defined
+			// Sort Costs by TopologyKey and origin, might not be sorted since were manually defined
 			sort.Sort(networkawareutil.ByTopologyKey(w.TopologyList))
+			for _, t := range w.TopologyList {
+				sort.Sort(networkawareutil.ByOrigin(t.OriginList))
+			}
 		}
 	}
+
+	costs := &topologyCosts{
+		networkTopology: networkTopology,
+		nodeGroups:      make(map[string]string, len(nodeList)),
+		groups:          make(map[string]*domainGroup),
+	}
+	for _, node := range nodeList {
+		domains := networkawareutil.GetNodeDomains(node, no.topologyKeys)
+		key := domainsKey(domains)
+		if _, ok := costs.groups[key]; !ok {
+			costs.groups[key] = no.buildDomainGroup(networkTopology, domains)
+		}
+		costs.nodeGroups[node.Name] = key
+	}
+	costs.bandwidthCapacity, costs.bandwidthAllocated = no.populateBandwidthMaps(networkTopology)
+	klog.V(5).InfoS("Network costs computed", "nodes", len(nodeList), "domainGroups", len(costs.groups))
+	return costs
+}
+
+// buildDomainGroup : compute the cost map of the nodes with the given domains
+func (no *NetworkOverhead) buildDomainGroup(networkTopology *ntv1alpha1.NetworkTopology, domains []string) *domainGroup {
+	// Create map for cost / destinations. Search for requirements faster...
+	costMap := make(map[networkawareutil.CostKey]int64)
+
+	// Populate cost map for the given domains
+	no.populateCostMap(costMap, networkTopology, domains)
+	klog.V(6).InfoS("Map", "domains", domains, "costMap", costMap)
+	return &domainGroup{domains: domains, costMap: costMap}
 }
 
-// populateCostMap : Populates costMap based on the node being filtered/scored
+// populateCostMap : Populates costMap based on the domains of the node being filtered/scored, from a sorted NetworkTopology
 func (no *NetworkOverhead) populateCostMap(
 	costMap map[networkawareutil.CostKey]int64,
 	networkTopology *ntv1alpha1.NetworkTopology,
-	region string,
-	zone string) {
+	domains []string) {
 	for _, w := range networkTopology.Spec.Weights { // Check the weights List
 		if w.Name != no.weightsName { // If it is not the Preferred algorithm, continue
 			continue
 		}
 
-		if region != "" { // Add Region Costs
-			// Binary search through CostList: find the Topology Key for region
-			topologyList := networkawareutil.FindTopologyKey(w.TopologyList, ntv1alpha1.NetworkTopologyRegion)
-
-			if no.weightsName != ntv1alpha1.NetworkTopologyNetperfCosts {
-				// Sort Costs by origin, might not be sorted since were manually defined
-				sort.Sort(networkawareutil.ByOrigin(topologyList))
+		for i, key := range no.topologyKeys { // Add Costs of each topology level
+			if domains[i] == "" {
+				continue
 			}
+			// Binary search through CostList: find the Topology Key
+			topologyList := networkawareutil.FindTopologyKey(w.TopologyList, key)
 
-			// Binary search through TopologyList: find the costs for the given Region
-			costs := networkawareutil.FindOriginCosts(topologyList, region)
+			// Binary search through TopologyList: find the costs for the given domain
+			costs := networkawareutil.FindOriginCosts(topologyList, domains[i])
 
-			// Add Region Costs
+			// Add Costs
 			for _, c := range costs {
 				costMap[networkawareutil.CostKey{ // Add the cost to the map
-					Origin:      region,
+					TopologyKey: key,
+					Origin:      domains[i],
 					Destination: c.Destination}] = c.NetworkCost
 			}
 		}
-		if zone != "" { // Add Zone Costs
-			// Binary search through CostList: find the Topology Key for zone
-			topologyList := networkawareutil.FindTopologyKey(w.TopologyList, ntv1alpha1.NetworkTopologyZone)
+	}
+}
+
+// populateBandwidthMaps : get the bandwidth capacity and allocations of the links between domains in the NetworkTopology.
+func (no *NetworkOverhead) populateBandwidthMaps(networkTopology *ntv1alpha1.NetworkTopology) (map[networkawareutil.CostKey]int64, map[networkawareutil.CostKey]int64) {
+	capacityMap := make(map[networkawareutil.CostKey]int64)
+	allocatedMap := make(map[networkawareutil.CostKey]int64)
+	for _, w := range networkTopology.Spec.Weights { // Check the weights List
+		if w.Name != no.weightsName { // If it is not the Preferred algorithm, continue
+			continue
+		}
+		for _, t := range w.TopologyList {
+			for _, o := range t.OriginList {
+				for _, c := range o.CostList {
+					link := networkawareutil.CostKey{TopologyKey: t.TopologyKey, Origin: o.Origin, Destination: c.Destination}
+					if !c.BandwidthCapacity.IsZero() { // Links without capacity are unbounded
+						capacityMap[link] = c.BandwidthCapacity.Value()
+					}
+					allocatedMap[link] += c.BandwidthAllocated.Value()
+				}
+			}
+		}
+	}
+	return capacityMap, allocatedMap
+}
 
-			if no.weightsName != ntv1alpha1.NetworkTopologyNetperfCosts {
-				// Sort Costs by origin, might not be sorted since were manually defined
-				sort.Sort(networkawareutil.ByOrigin(topologyList))
+// getBandwidthRequest : calculate the bandwidth requested on each link by the Pod's dependencies,
+// if the pod is placed on the given node. Dependencies in the same domain do not use any link.
+func (no *NetworkOverhead) getBandwidthRequest(
+	scheduledList networkawareutil.ScheduledList,
+	dependencyList []agv1alpha1.DependenciesInfo,
+	nodeName string,
+	domains []string,
+	getNode func(nodeName string) (*corev1.Node, error)) (map[networkawareutil.CostKey]int64, error) {
+	request := make(map[networkawareutil.CostKey]int64)
+	for _, podAllocated := range scheduledList { // For each pod already allocated
+		if podAllocated.Hostname == "" || podAllocated.Hostname == nodeName {
+			continue
+		}
+		for _, d := range dependencyList { // For each pod dependency
+			// If the pod allocated is not an established dependency, or has no bandwidth requirement, continue.
+			if podAllocated.Selector != d.Workload.Selector || d.MinBandwidth.IsZero() {
+				continue
 			}
 
-			// Binary search through TopologyList: find the costs for the given Region
-			costs := networkawareutil.FindOriginCosts(topologyList, zone)
+			// Get Node from pod Hostname
+			podNode, err := getNode(podAllocated.Hostname)
+			if err != nil {
+				klog.ErrorS(err, "Failed to get the node of the pod", "node", podAllocated.Hostname)
+				return request, err
+			}
+			// Get domains from Pod Hostname
+			podDomains := networkawareutil.GetNodeDomains(podNode, no.topologyKeys)
 
-			// Add Zone Costs
-			for _, c := range costs {
-				costMap[networkawareutil.CostKey{ // Add the cost to the map
-					Origin:      zone,
-					Destination: c.Destination}] = c.NetworkCost
+			// Nodes in the same domain do not use any link, and the link between nodes without a common level is unknown
+			level, same, ok := networkawareutil.FindLink(domains, podDomains)
+			if same || !ok {
+				continue
 			}
+			link := networkawareutil.CostKey{TopologyKey: no.topologyKeys[level], Origin: domains[level], Destination: podDomains[level]}
+			request[link] += d.MinBandwidth.Value()
 		}
 	}
+	return request, nil
+}
+
+// getSnapshotNode : get a node from the snapshot of the scheduling cycle
+func (no *NetworkOverhead) getSnapshotNode(nodeName string) (*corev1.Node, error) {
+	nodeInfo, err := no.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return nil, err
+	}
+	return nodeInfo.Node(), nil
+}
+
+// getBandwidthCost : calculate the cost of the bandwidth requested by the pod on the given node.
+// Each link with a capacity adds up to MaxCost, in proportion to the part of its capacity that would be allocated.
+func getBandwidthCost(preFilterState *PreFilterState, bandwidthRequest map[networkawareutil.CostKey]int64) int64 {
+	var cost int64 = 0
+	for link, request := range bandwidthRequest {
+		capacity, ok := preFilterState.bandwidthCapacityMap[link]
+		if !ok || capacity == 0 {
+			continue
+		}
+		allocated := preFilterState.bandwidthAllocatedMap[link] + request
+		cost += int64(float64(MaxCost) * math.Min(float64(allocated)/float64(capacity), 1))
+	}
+	return cost
 }
 
 // checkMaxNetworkCostRequirements : verifies the number of met and unmet dependencies based on the pod being filtered
 func checkMaxNetworkCostRequirements(
 	scheduledList networkawareutil.ScheduledList,
 	dependencyList []agv1alpha1.DependenciesInfo,
-	nodeInfo *framework.NodeInfo,
-	region string,
-	zone string,
+	nodeName string,
+	domains []string,
 	costMap map[networkawareutil.CostKey]int64,
 	no *NetworkOverhead) (int64, int64, error) {
 	var satisfied int64 = 0
@@ -514,7 +880,7 @@ func checkMaxNetworkCostRequirements(
 				}
 
 				// If the Pod hostname is the node being filtered, requirements are checked via extended resources
-				if podAllocated.Hostname == nodeInfo.Node().Name {
+				if podAllocated.Hostname == nodeName {
 					satisfied += 1
 					continue
 				}
@@ -526,32 +892,19 @@ func checkMaxNetworkCostRequirements(
 					return satisfied, violated, err
 				}
 
-				// Get zone and region from Pod Hostname
-				regionPodNodeInfo := networkawareutil.GetNodeRegion(podNodeInfo.Node())
-				zonePodNodeInfo := networkawareutil.GetNodeZone(podNodeInfo.Node())
+				// Get domains from Pod Hostname
+				podDomains := networkawareutil.GetNodeDomains(podNodeInfo.Node(), no.topologyKeys)
 
-				if regionPodNodeInfo == "" && zonePodNodeInfo == "" { // Node has no zone and region defined
+				level, same, ok := networkawareutil.FindLink(domains, podDomains)
+				if !hasDomain(podDomains) { // Node has no domain defined
 					violated += 1
-				} else if region == regionPodNodeInfo { // If Nodes belong to the same region
-					if zone == zonePodNodeInfo { // If Nodes belong to the same zone
-						satisfied += 1
-					} else { // belong to a different zone, check maxNetworkCost
-						cost, costOK := costMap[networkawareutil.CostKey{ // Retrieve the cost from the map (origin: zone, destination: pod zoneHostname)
-							Origin:      zone, // Time Complexity: O(1)
-							Destination: zonePodNodeInfo,
-						}]
-						if costOK {
-							if cost <= d.MaxNetworkCost {
-								satisfied += 1
-							} else {
-								violated += 1
-							}
-						}
-					}
-				} else { // belong to a different region
-					cost, costOK := costMap[networkawareutil.CostKey{ // Retrieve the cost from the map (origin: zone, destination: pod zoneHostname)
-						Origin:      region, // Time Complexity: O(1)
-						Destination: regionPodNodeInfo,
+				} else if same { // If Nodes belong to the same domain
+					satisfied += 1
+				} else if ok { // belong to a different domain, check maxNetworkCost
+					cost, costOK := costMap[networkawareutil.CostKey{ // Retrieve the cost from the map (level, origin: domain, destination: pod domain)
+						TopologyKey: no.topologyKeys[level],
+						Origin:      domains[level], // Time Complexity: O(1)
+						Destination: podDomains[level],
 					}]
 					if costOK {
 						if cost <= d.MaxNetworkCost {
@@ -572,8 +925,7 @@ func (no *NetworkOverhead) getAccumulatedCost(
 	scheduledList networkawareutil.ScheduledList,
 	dependencyList []agv1alpha1.DependenciesInfo,
 	nodeName string,
-	region string,
-	zone string,
+	domains []string,
 	costMap map[networkawareutil.CostKey]int64) (int64, error) {
 	// keep track of the accumulated cost
 	var cost int64 = 0
@@ -595,30 +947,21 @@ func (no *NetworkOverhead) getAccumulatedCost(
 					klog.ErrorS(nil, "getting pod hostname %q from Snapshot: %v", podNodeInfo, err)
 					return cost, err
 				}
-				// Get zone and region from Pod Hostname
-				regionPodNodeInfo := networkawareutil.GetNodeRegion(podNodeInfo.Node())
-				zonePodNodeInfo := networkawareutil.GetNodeZone(podNodeInfo.Node())
+				// Get domains from Pod Hostname
+				podDomains := networkawareutil.GetNodeDomains(podNodeInfo.Node(), no.topologyKeys)
 
-				if regionPodNodeInfo == "" && zonePodNodeInfo == "" { // Node has no zone and region defined
+				level, same, ok := networkawareutil.FindLink(domains, podDomains)
+				if !hasDomain(podDomains) { // Node has no domain defined
 					cost += MaxCost
-				} else if region == regionPodNodeInfo { // If Nodes belong to the same region
-					if zone == zonePodNodeInfo { // If Nodes belong to the same zone
-						cost += SameZone
-					} else { // belong to a different zone
-						value, ok := costMap[networkawareutil.CostKey{ // Retrieve the cost from the map (origin: zone, destination: pod zoneHostname)
-							Origin:      zone, // Time Complexity: O(1)
-							Destination: zonePodNodeInfo,
-						}]
-						if ok {
-							cost += value // Add the cost to the sum
-						} else {
-							cost += MaxCost
-						}
-					}
-				} else { // belong to a different region
-					value, ok := costMap[networkawareutil.CostKey{ // Retrieve the cost from the map (origin: region, destination: pod regionHostname)
-						Origin:      region, // Time Complexity: O(1)
-						Destination: regionPodNodeInfo,
+				} else if same { // If Nodes belong to the same domain
+					cost += SameZone
+				} else if !ok { // Nodes have no level in common
+					cost += MaxCost
+				} else { // belong to a different domain
+					value, ok := costMap[networkawareutil.CostKey{ // Retrieve the cost from the map (level, origin: domain, destination: pod domain)
+						TopologyKey: no.topologyKeys[level],
+						Origin:      domains[level], // Time Complexity: O(1)
+						Destination: podDomains[level],
 					}]
 					if ok {
 						cost += value // Add the cost to the sum
@@ -632,6 +975,16 @@ func (no *NetworkOverhead) getAccumulatedCost(
 	return cost, nil
 }
 
+// hasDomain : return true if the node has a domain at some topology level
+func hasDomain(domains []string) bool {
+	for _, domain := range domains {
+		if domain != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func getPreFilterState(cycleState *framework.CycleState) (*PreFilterState, error) {
 	no, err := cycleState.Read(preFilterStateKey)
 	if err != nil {
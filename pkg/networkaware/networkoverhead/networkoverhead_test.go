@@ -26,6 +26,7 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -33,6 +34,8 @@ import (
 	"k8s.io/client-go/informers"
 	testClientSet "k8s.io/client-go/kubernetes/fake"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
@@ -538,12 +541,16 @@ func BenchmarkNetworkOverheadPreFilter(b *testing.B) {
 				schedruntime.WithInformerFactory(informerFactory), schedruntime.WithSnapshotSharedLister(snapshot))
 
 			pl := &NetworkOverhead{
-				Client:      client,
-				podLister:   podLister,
-				handle:      fh,
-				namespaces:  []string{"default"},
-				weightsName: "UserDefined",
-				ntName:      "nt-test",
+				Client:       client,
+				podLister:    podLister,
+				nodeLister:   newTestNodeLister(nodes),
+				handle:       fh,
+				namespaces:   []string{"default"},
+				weightsName:  "UserDefined",
+				ntName:       "nt-test",
+				topologyKeys: defaultTopologyKeys,
+				bandwidth:    newBandwidthAllocations(),
+				costCache:    newCostCache(),
 			}
 
 			state := framework.NewCycleState()
@@ -758,12 +765,16 @@ func TestNetworkOverheadScore(t *testing.T) {
 				schedruntime.WithInformerFactory(informerFactory), schedruntime.WithSnapshotSharedLister(snapshot))
 
 			pl := &NetworkOverhead{
-				Client:      client,
-				podLister:   podLister,
-				handle:      fh,
-				namespaces:  []string{"default"},
-				weightsName: "UserDefined",
-				ntName:      "nt-test",
+				Client:       client,
+				podLister:    podLister,
+				nodeLister:   newTestNodeLister(tt.nodes),
+				handle:       fh,
+				namespaces:   []string{"default"},
+				weightsName:  "UserDefined",
+				ntName:       "nt-test",
+				topologyKeys: defaultTopologyKeys,
+				bandwidth:    newBandwidthAllocations(),
+				costCache:    newCostCache(),
 			}
 
 			// Wait for the pods to be scheduled.
@@ -1005,12 +1016,16 @@ func BenchmarkNetworkOverheadScore(b *testing.B) {
 				schedruntime.WithInformerFactory(informerFactory), schedruntime.WithSnapshotSharedLister(snapshot))
 
 			pl := &NetworkOverhead{
-				Client:      client,
-				podLister:   podLister,
-				handle:      fh,
-				namespaces:  []string{"default"},
-				weightsName: "UserDefined",
-				ntName:      "nt-test",
+				Client:       client,
+				podLister:    podLister,
+				nodeLister:   newTestNodeLister(nodes),
+				handle:       fh,
+				namespaces:   []string{"default"},
+				weightsName:  "UserDefined",
+				ntName:       "nt-test",
+				topologyKeys: defaultTopologyKeys,
+				bandwidth:    newBandwidthAllocations(),
+				costCache:    newCostCache(),
 			}
 
 			state := framework.NewCycleState()
@@ -1236,12 +1251,16 @@ func TestNetworkOverheadFilter(t *testing.T) {
 				schedruntime.WithSnapshotSharedLister(snapshot))
 
 			pl := &NetworkOverhead{
-				Client:      client,
-				podLister:   podLister,
-				handle:      fh,
-				namespaces:  []string{"default"},
-				weightsName: "UserDefined",
-				ntName:      "nt-test",
+				Client:       client,
+				podLister:    podLister,
+				nodeLister:   newTestNodeLister(nodes),
+				handle:       fh,
+				namespaces:   []string{"default"},
+				weightsName:  "UserDefined",
+				ntName:       "nt-test",
+				topologyKeys: defaultTopologyKeys,
+				bandwidth:    newBandwidthAllocations(),
+				costCache:    newCostCache(),
 			}
 
 			// Wait for the pods to be scheduled.
@@ -1269,6 +1288,445 @@ func TestNetworkOverheadFilter(t *testing.T) {
 	}
 }
 
+func TestNetworkOverheadTopologyKeys(t *testing.T) {
+	const rackLabel = "example.com/rack"
+
+	// AppGroup: p1 depends on p2 with a maximum network cost of 5
+	appGroup := GetAppGroupCRBasic()
+	appGroup.Spec.Workloads[0].Dependencies[0].MaxNetworkCost = 5
+
+	// NetworkTopology: costs between racks of zone Z1, and between zones. Rack Z1 is named like zone Z1, and
+	// zone R2 like rack R2, so that their costs only differ by topology level.
+	networkTopology := &ntv1alpha1.NetworkTopology{
+		ObjectMeta: metav1.ObjectMeta{Name: "nt-test", Namespace: "default", UID: types.UID("fake-uid")},
+		Spec: ntv1alpha1.NetworkTopologySpec{
+			Weights: ntv1alpha1.WeightList{
+				ntv1alpha1.WeightInfo{Name: "UserDefined",
+					TopologyList: ntv1alpha1.TopologyList{
+						ntv1alpha1.TopologyInfo{
+							TopologyKey: rackLabel,
+							OriginList: ntv1alpha1.OriginList{
+								ntv1alpha1.OriginInfo{Origin: "R1", CostList: []ntv1alpha1.CostInfo{{Destination: "R2", NetworkCost: 3}}},
+								ntv1alpha1.OriginInfo{Origin: "R3", CostList: []ntv1alpha1.CostInfo{{Destination: "R2", NetworkCost: 8}}},
+								ntv1alpha1.OriginInfo{Origin: "Z1", CostList: []ntv1alpha1.CostInfo{{Destination: "R2", NetworkCost: 4}}},
+							}},
+						ntv1alpha1.TopologyInfo{
+							TopologyKey: "topology.kubernetes.io/zone",
+							OriginList: ntv1alpha1.OriginList{
+								ntv1alpha1.OriginInfo{Origin: "Z1", CostList: []ntv1alpha1.CostInfo{{Destination: "R2", NetworkCost: 50}}},
+								ntv1alpha1.OriginInfo{Origin: "Z2", CostList: []ntv1alpha1.CostInfo{{Destination: "Z1", NetworkCost: 20}}},
+							}},
+					},
+				},
+			},
+		},
+	}
+
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n-1").Label(v1.LabelTopologyZone, "Z1").Label(rackLabel, "R1").Obj(),
+		st.MakeNode().Name("n-2").Label(v1.LabelTopologyZone, "Z1").Label(rackLabel, "R2").Obj(),
+		st.MakeNode().Name("n-3").Label(v1.LabelTopologyZone, "Z1").Label(rackLabel, "R3").Obj(),
+		st.MakeNode().Name("n-4").Label(v1.LabelTopologyZone, "Z2").Label(rackLabel, "R4").Obj(),
+		st.MakeNode().Name("n-5").Label(v1.LabelTopologyZone, "Z1").Label(rackLabel, "Z1").Obj(),
+	}
+
+	pods := []*v1.Pod{
+		makePodAllocated("p2", "p2-deployment", "n-2", 0, "basic", nil, nil),
+	}
+
+	s := clientgoscheme.Scheme
+	utilruntime.Must(agv1alpha1.AddToScheme(s))
+	utilruntime.Must(ntv1alpha1.AddToScheme(s))
+
+	ctx := context.Background()
+	cs := testClientSet.NewSimpleClientset()
+	client := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(appGroup, networkTopology).
+		Build()
+
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	podLister := informerFactory.Core().V1().Pods().Lister()
+	for _, p := range pods {
+		if err := informerFactory.Core().V1().Pods().Informer().GetStore().Add(p); err != nil {
+			t.Fatalf("Failed to add Pod %q: %v", p.Name, err)
+		}
+	}
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	fh, _ := tf.NewFramework(ctx, registeredPlugins, "default-scheduler",
+		schedruntime.WithClientSet(cs),
+		schedruntime.WithInformerFactory(informerFactory),
+		schedruntime.WithSnapshotSharedLister(newTestSharedLister(nil, nodes)))
+
+	pl := &NetworkOverhead{
+		Client:       client,
+		podLister:    podLister,
+		nodeLister:   newTestNodeLister(nodes),
+		handle:       fh,
+		namespaces:   []string{"default"},
+		weightsName:  "UserDefined",
+		ntName:       "nt-test",
+		topologyKeys: []ntv1alpha1.TopologyKey{rackLabel, ntv1alpha1.NetworkTopologyZone, ntv1alpha1.NetworkTopologyRegion},
+		bandwidth:    newBandwidthAllocations(),
+		costCache:    newCostCache(),
+	}
+
+	tests := []struct {
+		name       string
+		node       *v1.Node
+		wantStatus *framework.Status
+		wantScore  int64
+	}{
+		{
+			name:       "same node as the dependency",
+			node:       nodes[1],
+			wantStatus: nil,
+			wantScore:  SameHostname,
+		},
+		{
+			name:       "rack with a low cost to the rack of the dependency",
+			node:       nodes[0],
+			wantStatus: nil,
+			wantScore:  3,
+		},
+		{
+			name:       "rack with a high cost to the rack of the dependency",
+			node:       nodes[2],
+			wantStatus: framework.NewStatus(framework.Unschedulable, "Node n-3 does not meet several network requirements from Workload dependencies: Satisfied: 0 Violated: 1"),
+			wantScore:  8,
+		},
+		{
+			name:       "different zone: the zone cost applies",
+			node:       nodes[3],
+			wantStatus: framework.NewStatus(framework.Unschedulable, "Node n-4 does not meet several network requirements from Workload dependencies: Satisfied: 0 Violated: 1"),
+			wantScore:  20,
+		},
+		{
+			name:       "rack named like a zone: the rack cost applies",
+			node:       nodes[4],
+			wantStatus: nil,
+			wantScore:  4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := makePod("p1", "p1-deployment", 0, "basic", nil, nil)
+			state := framework.NewCycleState()
+			if _, status := pl.PreFilter(ctx, state, pod); !status.IsSuccess() {
+				t.Fatalf("PreFilter failed: %v", status.Message())
+			}
+
+			nodeInfo := framework.NewNodeInfo()
+			nodeInfo.SetNode(tt.node)
+			if gotStatus := pl.Filter(ctx, state, pod, nodeInfo); !reflect.DeepEqual(gotStatus, tt.wantStatus) {
+				t.Errorf("status does not match: %v, want: %v", gotStatus, tt.wantStatus)
+			}
+			if score, _ := pl.Score(ctx, state, pod, tt.node.Name); score != tt.wantScore {
+				t.Errorf("score does not match: %v, want: %v", score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestNetworkOverheadCostCache(t *testing.T) {
+	appGroup := GetAppGroupCRBasic()
+
+	// NetworkTopology with costs not sorted by origin
+	networkTopology := GetNetworkTopologyCRBasic()
+	origins := networkTopology.Spec.Weights[0].TopologyList[1].OriginList
+	origins[0], origins[3] = origins[3], origins[0]
+
+	pods := []*v1.Pod{
+		makePodAllocated("p2", "p2-deployment", "n-3", 0, "basic", nil, nil),
+	}
+
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n-1").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z1").Obj(),
+		st.MakeNode().Name("n-2").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z1").Obj(),
+		st.MakeNode().Name("n-3").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z2").Obj(),
+		st.MakeNode().Name("n-4").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z2").Obj(),
+		st.MakeNode().Name("n-5").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z3").Obj(),
+	}
+
+	s := clientgoscheme.Scheme
+	utilruntime.Must(agv1alpha1.AddToScheme(s))
+	utilruntime.Must(ntv1alpha1.AddToScheme(s))
+
+	ctx := context.Background()
+	cs := testClientSet.NewSimpleClientset()
+	client := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(appGroup, networkTopology.DeepCopy()).
+		Build()
+
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	podLister := informerFactory.Core().V1().Pods().Lister()
+	for _, p := range pods {
+		if err := informerFactory.Core().V1().Pods().Informer().GetStore().Add(p); err != nil {
+			t.Fatalf("Failed to add Pod %q: %v", p.Name, err)
+		}
+	}
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	fh, _ := tf.NewFramework(ctx, registeredPlugins, "default-scheduler",
+		schedruntime.WithClientSet(cs),
+		schedruntime.WithInformerFactory(informerFactory),
+		schedruntime.WithSnapshotSharedLister(newTestSharedLister(nil, nodes)))
+
+	pl := &NetworkOverhead{
+		Client:       client,
+		podLister:    podLister,
+		nodeLister:   newTestNodeLister(nodes),
+		handle:       fh,
+		namespaces:   []string{"default"},
+		weightsName:  "UserDefined",
+		ntName:       "nt-test",
+		topologyKeys: defaultTopologyKeys,
+		bandwidth:    newBandwidthAllocations(),
+		costCache:    newCostCache(),
+	}
+
+	preFilter := func() *PreFilterState {
+		state := framework.NewCycleState()
+		pod := makePod("p1", "p1-deployment", 0, "basic", nil, nil)
+		if _, status := pl.PreFilter(ctx, state, pod); !status.IsSuccess() {
+			t.Fatalf("PreFilter failed: %v", status.Message())
+		}
+		preFilterState, err := getPreFilterState(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return preFilterState
+	}
+
+	// One domain group per zone, one result per group and per node hosting a pod
+	first := preFilter()
+	if len(first.topologyCosts.groups) != 3 {
+		t.Errorf("expected 3 domain groups, got %v", len(first.topologyCosts.groups))
+	}
+	if len(first.groupResults) != 3 || len(first.hostResults) != 1 {
+		t.Errorf("expected 3 group results and 1 host result, got %v and %v", len(first.groupResults), len(first.hostResults))
+	}
+	if result, _ := pl.getPreFilterNodeResult(first, "n-1"); result.cost != 5 {
+		t.Errorf("expected cost 5 from Z1 to Z2, got %v", result.cost)
+	}
+
+	// The costs are shared until the NetworkTopology or the nodes change
+	if second := preFilter(); second.topologyCosts != first.topologyCosts {
+		t.Errorf("expected the costs to be cached")
+	}
+
+	// The NetworkTopology CR is not sorted in place
+	unsorted := networkTopology.DeepCopy()
+	pl.buildTopologyCosts(networkTopology, nil)
+	if !reflect.DeepEqual(networkTopology, unsorted) {
+		t.Errorf("expected the NetworkTopology not to be modified, got %v", networkTopology.Spec)
+	}
+
+	// The costs are computed again once the NetworkTopology changes
+	updated := pl.findNetworkTopologyNetworkOverhead()
+	for i, origin := range updated.Spec.Weights[0].TopologyList[1].OriginList {
+		if origin.Origin == "Z1" {
+			updated.Spec.Weights[0].TopologyList[1].OriginList[i].CostList[0].NetworkCost = 7
+		}
+	}
+	if err := client.Update(ctx, updated); err != nil {
+		t.Fatal(err)
+	}
+	third := preFilter()
+	if third.topologyCosts == first.topologyCosts {
+		t.Errorf("expected the costs to be computed again")
+	}
+	if result, _ := pl.getPreFilterNodeResult(third, "n-1"); result.cost != 7 {
+		t.Errorf("expected cost 7 from Z1 to Z2, got %v", result.cost)
+	}
+
+	// ... or once the nodes change
+	pl.costCache.invalidate()
+	fourth := preFilter()
+	if fourth.topologyCosts == third.topologyCosts {
+		t.Errorf("expected the costs to be computed again")
+	}
+
+	// The costs are computed from the nodes listed after the invalidation, and never reused once invalidated again
+	moved := nodes[1].DeepCopy()
+	moved.Labels[v1.LabelTopologyZone] = "Z2"
+	pl.nodeLister = newTestNodeLister(append([]*v1.Node{moved}, nodes[2:]...))
+	pl.costCache.invalidate()
+	fifth := preFilter()
+	if got := fifth.topologyCosts.nodeGroups["n-2"]; got != "Z2/us-west-1" {
+		t.Errorf("expected n-2 in the Z2 domain group, got %q", got)
+	}
+	if _, ok := fifth.topologyCosts.nodeGroups["n-1"]; ok {
+		t.Errorf("expected the removed node n-1 not to be in the costs")
+	}
+	pl.costCache.invalidate()
+	if sixth := preFilter(); sixth.topologyCosts == fifth.topologyCosts {
+		t.Errorf("expected the costs built before the invalidation not to be reused")
+	}
+}
+
+func TestNetworkOverheadBandwidth(t *testing.T) {
+	// AppGroup: p1 depends on p2 with a minimum bandwidth of 100Mi
+	appGroup := GetAppGroupCRBasic()
+	appGroup.Spec.Workloads[0].Dependencies[0].MinBandwidth = resource.MustParse("100Mi")
+	appGroup.Spec.Workloads[0].Dependencies[0].MaxNetworkCost = 30
+
+	// NetworkTopology: Z1 -> Z2 has a capacity of 150Mi, 20Mi of which allocated; us-west-1 -> us-east-1 is unbounded
+	networkTopology := GetNetworkTopologyCRBasic()
+	zoneCosts := networkTopology.Spec.Weights[0].TopologyList[1].OriginList[0].CostList
+	zoneCosts[0].BandwidthCapacity = resource.MustParse("150Mi")
+	zoneCosts[0].BandwidthAllocated = resource.MustParse("20Mi")
+
+	pods := []*v1.Pod{
+		makePodAllocated("p2", "p2-deployment", "n-3", 0, "basic", nil, nil),
+	}
+
+	nodes := []*v1.Node{
+		st.MakeNode().Name("n-1").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z1").Obj(),
+		st.MakeNode().Name("n-3").Label(v1.LabelTopologyRegion, "us-west-1").Label(v1.LabelTopologyZone, "Z2").Obj(),
+		st.MakeNode().Name("n-5").Label(v1.LabelTopologyRegion, "us-east-1").Label(v1.LabelTopologyZone, "Z3").Obj(),
+	}
+
+	s := clientgoscheme.Scheme
+	utilruntime.Must(agv1alpha1.AddToScheme(s))
+	utilruntime.Must(ntv1alpha1.AddToScheme(s))
+
+	ctx := context.Background()
+	cs := testClientSet.NewSimpleClientset()
+	client := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(appGroup, networkTopology).
+		Build()
+
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	podLister := informerFactory.Core().V1().Pods().Lister()
+	for _, p := range pods {
+		if err := informerFactory.Core().V1().Pods().Informer().GetStore().Add(p); err != nil {
+			t.Fatalf("Failed to add Pod %q: %v", p.Name, err)
+		}
+	}
+	for _, n := range nodes {
+		if err := informerFactory.Core().V1().Nodes().Informer().GetStore().Add(n); err != nil {
+			t.Fatalf("Failed to add Node %q: %v", n.Name, err)
+		}
+	}
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	fh, _ := tf.NewFramework(ctx, registeredPlugins, "default-scheduler",
+		schedruntime.WithClientSet(cs),
+		schedruntime.WithInformerFactory(informerFactory),
+		schedruntime.WithSnapshotSharedLister(newTestSharedLister(nil, nodes)))
+
+	pl := &NetworkOverhead{
+		Client:       client,
+		podLister:    podLister,
+		handle:       fh,
+		namespaces:   []string{"default"},
+		weightsName:  "UserDefined",
+		ntName:       "nt-test",
+		nodeLister:   informerFactory.Core().V1().Nodes().Lister(),
+		topologyKeys: defaultTopologyKeys,
+		bandwidth:    newBandwidthAllocations(),
+		queue:        workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		costCache:    newCostCache(),
+	}
+	defer pl.queue.ShutDown()
+
+	filter := func(pod *v1.Pod, node *v1.Node) (*framework.CycleState, *framework.Status) {
+		state := framework.NewCycleState()
+		if _, status := pl.PreFilter(ctx, state, pod); !status.IsSuccess() {
+			t.Fatalf("PreFilter failed: %v", status.Message())
+		}
+		nodeInfo := framework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		return state, pl.Filter(ctx, state, pod, nodeInfo)
+	}
+
+	first := makePod("p1", "p1-deployment-1", 0, "basic", nil, nil)
+	first.UID = types.UID("p1-1")
+	second := makePod("p1", "p1-deployment-2", 0, "basic", nil, nil)
+	second.UID = types.UID("p1-2")
+
+	// Z1 -> Z2: 20Mi + 100Mi fits in 150Mi
+	state, status := filter(first, nodes[0])
+	if !status.IsSuccess() {
+		t.Fatalf("expected n-1 to fit the first pod, got %v", status.Message())
+	}
+	// Score: network cost of Z1 -> Z2, plus the allocated part of the link
+	if score, _ := pl.Score(ctx, state, first, "n-1"); score != 5+MaxCost*120/150 {
+		t.Errorf("expected score %v, got %v", 5+MaxCost*120/150, score)
+	}
+	if status := pl.Reserve(ctx, state, first, "n-1"); !status.IsSuccess() {
+		t.Fatalf("Reserve failed: %v", status.Message())
+	}
+
+	// Z1 -> Z2: 20Mi + 100Mi + 100Mi exceeds 150Mi
+	if _, status := filter(second, nodes[0]); status.Code() != framework.Unschedulable {
+		t.Errorf("expected n-1 not to fit the second pod, got %v", status)
+	}
+	// Same zone as the dependency: no link used
+	if _, status := filter(second, nodes[1]); !status.IsSuccess() {
+		t.Errorf("expected n-3 to fit the second pod, got %v", status.Message())
+	}
+
+	// Releasing the first pod frees the link
+	pl.Unreserve(ctx, state, first, "n-1")
+	if _, status := filter(second, nodes[0]); !status.IsSuccess() {
+		t.Errorf("expected n-1 to fit the second pod once the first is unreserved, got %v", status.Message())
+	}
+
+	// Deleting a reserved pod frees the link too
+	state, _ = filter(first, nodes[0])
+	pl.Reserve(ctx, state, first, "n-1")
+	pl.podDeleted(first)
+	if allocated := pl.bandwidth.allocated(); len(allocated) != 0 {
+		t.Errorf("expected no bandwidth allocated, got %v", allocated)
+	}
+
+	// ... and so does terminating it
+	state, _ = filter(first, nodes[0])
+	pl.Reserve(ctx, state, first, "n-1")
+	terminated := first.DeepCopy()
+	terminated.Spec.NodeName = "n-1"
+	terminated.Status.Phase = v1.PodSucceeded
+	pl.podUpdated(terminated)
+	if allocated := pl.bandwidth.allocated(); len(allocated) != 0 {
+		t.Errorf("expected no bandwidth allocated, got %v", allocated)
+	}
+
+	// The bandwidth of a pod bound without a Reserve, e.g. before the plugin started, is allocated from the informer
+	bound := first.DeepCopy()
+	bound.Namespace = "default"
+	bound.Spec.NodeName = "n-1"
+	if err := informerFactory.Core().V1().Pods().Informer().GetStore().Add(bound); err != nil {
+		t.Fatalf("Failed to add Pod %q: %v", bound.Name, err)
+	}
+	pl.podUpdated(bound)
+	if pl.queue.Len() != 1 {
+		t.Fatalf("expected the bound pod to be queued, got %v pods", pl.queue.Len())
+	}
+	pl.processNextPod()
+	if _, status := filter(second, nodes[0]); status.Code() != framework.Unschedulable {
+		t.Errorf("expected n-1 not to fit the second pod once the bound pod is allocated, got %v", status)
+	}
+	// ... only once
+	pl.podUpdated(bound)
+	if pl.queue.Len() != 0 {
+		t.Errorf("expected the allocated pod not to be queued, got %v pods", pl.queue.Len())
+	}
+}
+
 func BenchmarkNetworkOverheadFilter(b *testing.B) {
 	// Get AppGroup CRD: onlineboutique
 	onlineBoutiqueAppGroup := GetAppGroupCROnlineBoutique()
@@ -1462,12 +1920,16 @@ func BenchmarkNetworkOverheadFilter(b *testing.B) {
 				schedruntime.WithSnapshotSharedLister(snapshot))
 
 			pl := &NetworkOverhead{
-				Client:      client,
-				podLister:   podLister,
-				handle:      fh,
-				namespaces:  []string{"default"},
-				weightsName: "UserDefined",
-				ntName:      "nt-test",
+				Client:       client,
+				podLister:    podLister,
+				nodeLister:   newTestNodeLister(nodes),
+				handle:       fh,
+				namespaces:   []string{"default"},
+				weightsName:  "UserDefined",
+				ntName:       "nt-test",
+				topologyKeys: defaultTopologyKeys,
+				bandwidth:    newBandwidthAllocations(),
+				costCache:    newCostCache(),
 			}
 
 			// Wait for the pods to be scheduled.
@@ -1518,6 +1980,14 @@ func Until(ctx context.Context, pieces int, doWorkPiece workqueue.DoWorkPieceFun
 	workqueue.ParallelizeUntil(ctx, parallelism, pieces, doWorkPiece, chunkSizeFor(pieces))
 }
 
+func newTestNodeLister(nodes []*v1.Node) corelisters.NodeLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, n := range nodes {
+		_ = indexer.Add(n)
+	}
+	return corelisters.NewNodeLister(indexer)
+}
+
 func newTestSharedLister(pods []*v1.Pod, nodes []*v1.Node) *testSharedLister {
 	nodeInfoMap := make(map[string]*framework.NodeInfo)
 	nodeInfos := make([]*framework.NodeInfo, 0)
@@ -19,17 +19,25 @@ package topologicalsort
 import (
 	"context"
 	"fmt"
+	"math"
+	"sync"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
-	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
 
+	ctrlruntimecache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	pluginconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 	networkawareutil "sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
 
 	agv1alpha "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
@@ -49,9 +57,21 @@ func init() {
 
 // TopologicalSort : Sort pods based on their AppGroup and corresponding microservice dependencies
 type TopologicalSort struct {
-	client.Client
+	// Reader reads the AppGroups from an informer
+	client.Reader
 	handle     framework.Handle
 	namespaces []string
+	fairness   pluginconfig.FairnessType
+
+	// sort keys of the queued pods, computed once each time a pod is queued
+	keysLock sync.Mutex
+	keys     map[types.UID]queuedSortKey
+}
+
+// queuedSortKey is the sort key of a pod, computed when it was queued at timestamp.
+type queuedSortKey struct {
+	timestamp time.Time
+	key       podSortKey
 }
 
 var _ framework.QueueSortPlugin = &TopologicalSort{}
@@ -67,65 +87,198 @@ func getArgs(obj runtime.Object) (*pluginconfig.TopologicalSortArgs, error) {
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type TopologicalSortArgs, got %T", obj)
 	}
+	if TopologicalSortArgs.Fairness == "" {
+		// args built in code, not decoded from the versioned config, miss the default
+		defaulted := *TopologicalSortArgs
+		defaulted.Fairness = pluginconfig.FairnessRoundRobin
+		TopologicalSortArgs = &defaulted
+	}
 
 	return TopologicalSortArgs, nil
 }
 
 // New : create an instance of a TopologicalSort plugin
-func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	klog.V(4).InfoS("Creating new instance of the TopologicalSort plugin")
 
 	args, err := getArgs(obj)
 	if err != nil {
 		return nil, err
 	}
+	if err := validation.ValidateTopologicalSortArgs(nil, args); err != nil {
+		return nil, err
+	}
 
-	client, err := client.New(handle.KubeConfig(), client.Options{
-		Scheme: scheme,
+	namespaces := make(map[string]ctrlruntimecache.Config, len(args.Namespaces))
+	for _, namespace := range args.Namespaces {
+		namespaces[namespace] = ctrlruntimecache.Config{}
+	}
+	agCache, err := ctrlruntimecache.New(handle.KubeConfig(), ctrlruntimecache.Options{
+		Scheme:            scheme,
+		DefaultNamespaces: namespaces,
 	})
 	if err != nil {
 		return nil, err
 	}
+	// The informer must exist before the cache starts, for the cache to wait for its sync.
+	if _, err := agCache.GetInformer(ctx, &agv1alpha.AppGroup{}); err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := agCache.Start(ctx); err != nil {
+			klog.ErrorS(err, "Failed to start the AppGroup informer")
+		}
+	}()
+	if !agCache.WaitForCacheSync(ctx) {
+		return nil, fmt.Errorf("failed to sync the AppGroup informer")
+	}
 
 	pl := &TopologicalSort{
-		Client:     client,
+		Reader:     agCache,
 		handle:     handle,
 		namespaces: args.Namespaces,
+		fairness:   args.Fairness,
 	}
+	// The sort keys are dropped once the pods leave the queue for good
+	handle.SharedInformerFactory().Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if pod, ok := newObj.(*corev1.Pod); ok && len(pod.Spec.NodeName) != 0 {
+				pl.forgetSortKey(pod.UID)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			switch t := obj.(type) {
+			case *corev1.Pod:
+				pl.forgetSortKey(t.UID)
+			case cache.DeletedFinalStateUnknown:
+				if pod, ok := t.Obj.(*corev1.Pod); ok {
+					pl.forgetSortKey(pod.UID)
+				}
+			}
+		},
+	})
 	return pl, nil
 }
 
 // Less is the function used by the activeQ heap algorithm to sort pods.
-// 1) Sort Pods based on their AppGroup and corresponding service topology graph.
-// 2) Otherwise, follow the strategy of the in-tree QueueSort Plugin (PrioritySort Plugin)
+// Each pod gets a sort key, and the keys are compared field by field, so that Less is a strict weak
+// ordering (in fact a total order, since the last field is the UID of the pod):
+// 1) Higher priority first.
+// 2) With the RoundRobin fairness, lower topology index first, so that the AppGroups take turns.
+// 3) Older AppGroup first. A pod without an AppGroup, or whose AppGroup is not found, is a group
+// of its own, as old as the pod has been in the queue.
+// 4) AppGroup namespace and name, so that the pods of an AppGroup stay together.
+// 5) Lower topology index first, inside the AppGroup.
+// 6) Earlier queue timestamp, then pod namespace, name and UID.
+// The key of a pod is computed once each time it is queued, so that it does not change while the pod is in
+// the heap, e.g. when its AppGroup is sorted again.
 func (ts *TopologicalSort) Less(pInfo1, pInfo2 *framework.QueuedPodInfo) bool {
-	p1AppGroup := networkawareutil.GetPodAppGroupLabel(pInfo1.Pod)
-	p2AppGroup := networkawareutil.GetPodAppGroupLabel(pInfo2.Pod)
+	k1 := ts.queuedSortKey(pInfo1)
+	k2 := ts.queuedSortKey(pInfo2)
+
+	klog.V(6).InfoS("Pod sort keys", "p1 name", pInfo1.Pod.Name, "p1 key", k1, "p2 name", pInfo2.Pod.Name, "p2 key", k2)
+	return k1.less(k2)
+}
 
-	// If pods do not belong to an AppGroup, or being to different AppGroups, follow vanilla QoS Sort
-	if p1AppGroup != p2AppGroup || len(p1AppGroup) == 0 {
-		klog.V(4).InfoS("Pods do not belong to the same AppGroup CR", "p1AppGroup", p1AppGroup, "p2AppGroup", p2AppGroup)
-		s := &queuesort.PrioritySort{}
-		return s.Less(pInfo1, pInfo2)
+// podSortKey holds the fields Less compares, in order.
+type podSortKey struct {
+	priority  int32
+	round     int32
+	groupTime time.Time
+	group     string
+	order     int32
+	timestamp time.Time
+	namespace string
+	name      string
+	uid       string
+}
+
+// queuedSortKey returns the sort key of the pod computed when it was queued, computed now if it was queued since.
+func (ts *TopologicalSort) queuedSortKey(pInfo *framework.QueuedPodInfo) podSortKey {
+	ts.keysLock.Lock()
+	defer ts.keysLock.Unlock()
+	if queued, ok := ts.keys[pInfo.Pod.UID]; ok && queued.timestamp.Equal(pInfo.Timestamp) {
+		return queued.key
 	}
+	if ts.keys == nil {
+		ts.keys = make(map[types.UID]queuedSortKey)
+	}
+	key := ts.sortKey(pInfo)
+	ts.keys[pInfo.Pod.UID] = queuedSortKey{timestamp: pInfo.Timestamp, key: key}
+	return key
+}
 
-	// Pods belong to the same appGroup, get the CR
-	klog.V(6).InfoS("Pods belong to the same AppGroup CR", "p1 name", pInfo1.Pod.Name, "p2 name", pInfo2.Pod.Name, "appGroup", p1AppGroup)
-	agName := p1AppGroup
-	appGroup := ts.findAppGroupTopologicalSort(agName)
+// forgetSortKey drops the sort key of a pod, once it is assigned or deleted.
+func (ts *TopologicalSort) forgetSortKey(uid types.UID) {
+	ts.keysLock.Lock()
+	defer ts.keysLock.Unlock()
+	delete(ts.keys, uid)
+}
 
-	// Get labels from both pods
-	labelsP1 := pInfo1.Pod.GetLabels()
-	labelsP2 := pInfo2.Pod.GetLabels()
+// sortKey returns the sort key of the pod.
+func (ts *TopologicalSort) sortKey(pInfo *framework.QueuedPodInfo) podSortKey {
+	pod := pInfo.Pod
+	key := podSortKey{
+		priority:  corev1helpers.PodPriority(pod),
+		round:     1,
+		groupTime: pInfo.Timestamp,
+		timestamp: pInfo.Timestamp,
+		namespace: pod.Namespace,
+		name:      pod.Name,
+		uid:       string(pod.UID),
+	}
 
-	// Binary search to find both order index since topology list is ordered by Workload Name
-	orderP1 := networkawareutil.FindPodOrder(appGroup.Status.TopologyOrder, labelsP1[agv1alpha.AppGroupSelectorLabel])
-	orderP2 := networkawareutil.FindPodOrder(appGroup.Status.TopologyOrder, labelsP2[agv1alpha.AppGroupSelectorLabel])
+	agName := networkawareutil.GetPodAppGroupLabel(pod)
+	if len(agName) == 0 {
+		return key
+	}
+	appGroup := ts.findAppGroupTopologicalSort(agName)
+	if appGroup == nil {
+		klog.V(4).InfoS("AppGroup of the pod not found, sorting it on its own", "pod", klog.KObj(pod), "appGroup", agName)
+		return key
+	}
 
-	klog.V(6).InfoS("Pod order values", "p1 order", orderP1, "p2 order", orderP2)
+	// Binary search to find the order index since topology list is ordered by Workload Name.
+	// A workload missing from the topology order goes last.
+	order := networkawareutil.FindPodOrder(appGroup.Status.TopologyOrder, pod.GetLabels()[agv1alpha.AppGroupSelectorLabel])
+	if order < 0 {
+		order = math.MaxInt32
+	}
+	key.groupTime = appGroup.CreationTimestamp.Time
+	key.group = appGroup.Namespace + "/" + appGroup.Name
+	key.order = order
+	if ts.fairness == pluginconfig.FairnessRoundRobin {
+		key.round = order
+	}
+	return key
+}
 
-	// Lower is better
-	return orderP1 <= orderP2
+func (k podSortKey) less(other podSortKey) bool {
+	if k.priority != other.priority {
+		return k.priority > other.priority
+	}
+	if k.round != other.round {
+		return k.round < other.round
+	}
+	if !k.groupTime.Equal(other.groupTime) {
+		return k.groupTime.Before(other.groupTime)
+	}
+	if k.group != other.group {
+		return k.group < other.group
+	}
+	if k.order != other.order {
+		return k.order < other.order
+	}
+	if !k.timestamp.Equal(other.timestamp) {
+		return k.timestamp.Before(other.timestamp)
+	}
+	if k.namespace != other.namespace {
+		return k.namespace < other.namespace
+	}
+	if k.name != other.name {
+		return k.name < other.name
+	}
+	return k.uid < other.uid
 }
 
 func (ts *TopologicalSort) findAppGroupTopologicalSort(agName string) *agv1alpha.AppGroup {
@@ -18,7 +18,9 @@ package topologicalsort
 
 import (
 	"context"
+	"fmt"
 	"math"
+	mathrand "math/rand"
 	"sort"
 	"testing"
 	"time"
@@ -26,6 +28,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -33,10 +36,13 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	st "k8s.io/kubernetes/pkg/scheduler/testing"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	pluginconfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	testutil "sigs.k8s.io/scheduler-plugins/test/util"
 
 	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+	"github.com/google/go-cmp/cmp"
 
 	"sigs.k8s.io/scheduler-plugins/pkg/networkaware/util"
 )
@@ -264,7 +270,7 @@ func TestTopologicalSortLess(t *testing.T) {
 			}
 
 			ts := &TopologicalSort{
-				Client:     client,
+				Reader:     client,
 				namespaces: []string{metav1.NamespaceDefault},
 			}
 
@@ -275,6 +281,234 @@ func TestTopologicalSortLess(t *testing.T) {
 	}
 }
 
+func TestGetArgsDefaultsFairness(t *testing.T) {
+	obj := &pluginconfig.TopologicalSortArgs{Namespaces: []string{"default"}}
+	args, err := getArgs(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Fairness != pluginconfig.FairnessRoundRobin {
+		t.Errorf("expected fairness %q, got %q", pluginconfig.FairnessRoundRobin, args.Fairness)
+	}
+	if obj.Fairness != "" {
+		t.Errorf("expected the given args to be left unchanged, got fairness %q", obj.Fairness)
+	}
+}
+
+func TestTopologicalSortFairness(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	client := newFairnessClient(t, now)
+
+	pInfos := []*framework.QueuedPodInfo{
+		newQueuedPodInfo(t, makePod("p3", "old-p3", 0, "basic", nil, nil), now),
+		newQueuedPodInfo(t, makePod("p2", "new-p2", 0, "basic-new", nil, nil), now),
+		newQueuedPodInfo(t, makePod("p1", "new-p1", 0, "basic-new", nil, nil), now),
+		newQueuedPodInfo(t, makePod("", "ungrouped", 0, "", nil, nil), now.Add(30*time.Second)),
+		newQueuedPodInfo(t, makePod("p2", "old-p2", 0, "basic", nil, nil), now),
+		newQueuedPodInfo(t, makePod("p3", "new-p3", 0, "basic-new", nil, nil), now),
+		newQueuedPodInfo(t, makePod("p1", "old-p1", 0, "basic", nil, nil), now),
+		newQueuedPodInfo(t, makePod("p1", "missing", 0, "other", nil, nil), now.Add(2*time.Minute)),
+		newQueuedPodInfo(t, makePod("p3", "high", 10, "basic-new", nil, nil), now),
+	}
+
+	tests := []struct {
+		name     string
+		fairness pluginconfig.FairnessType
+		want     []string
+	}{
+		{
+			name:     "age, the pods of the oldest AppGroup go first",
+			fairness: pluginconfig.FairnessAge,
+			want:     []string{"high", "old-p1", "old-p2", "old-p3", "ungrouped", "new-p1", "new-p2", "new-p3", "missing"},
+		},
+		{
+			name:     "round robin, the AppGroups take turns",
+			fairness: pluginconfig.FairnessRoundRobin,
+			want:     []string{"high", "old-p1", "ungrouped", "new-p1", "missing", "old-p2", "new-p2", "old-p3", "new-p3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := &TopologicalSort{
+				Reader:     client,
+				namespaces: []string{metav1.NamespaceDefault},
+				fairness:   tt.fairness,
+			}
+
+			sorted := append([]*framework.QueuedPodInfo(nil), pInfos...)
+			sort.Slice(sorted, func(i, j int) bool {
+				return ts.Less(sorted[i], sorted[j])
+			})
+			var got []string
+			for _, pInfo := range sorted {
+				got = append(got, pInfo.Pod.Name)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected order (-want,+got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTopologicalSortQueuedSortKey(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	c := newFairnessClient(t, now)
+	ts := &TopologicalSort{
+		Reader:     c,
+		namespaces: []string{metav1.NamespaceDefault},
+		fairness:   pluginconfig.FairnessRoundRobin,
+	}
+
+	p1 := newQueuedPodInfo(t, makePod("p1", "p1", 0, "basic", nil, nil), now)
+	p2 := newQueuedPodInfo(t, makePod("p2", "p2", 0, "basic", nil, nil), now)
+	if !ts.Less(p1, p2) {
+		t.Fatalf("expected p1 before p2")
+	}
+
+	// The AppGroup is sorted again: p2 now goes first
+	appGroup := &agv1alpha1.AppGroup{}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(GetAppGroupCRBasic()), appGroup); err != nil {
+		t.Fatal(err)
+	}
+	for i, info := range appGroup.Status.TopologyOrder {
+		switch info.Workload.Selector {
+		case "p1":
+			appGroup.Status.TopologyOrder[i].Index = 2
+		case "p2":
+			appGroup.Status.TopologyOrder[i].Index = 1
+		}
+	}
+	if err := c.Update(context.TODO(), appGroup); err != nil {
+		t.Fatal(err)
+	}
+
+	// The keys of the queued pods do not change...
+	if !ts.Less(p1, p2) {
+		t.Errorf("expected p1 to stay before p2 while queued")
+	}
+	// ... until they are queued again
+	p1.Timestamp = now.Add(time.Second)
+	p2.Timestamp = now.Add(time.Second)
+	if !ts.Less(p2, p1) {
+		t.Errorf("expected p2 before p1 once queued again")
+	}
+
+	// The keys are dropped once the pods are assigned or deleted
+	ts.forgetSortKey(p1.Pod.UID)
+	ts.forgetSortKey(p2.Pod.UID)
+	if len(ts.keys) != 0 {
+		t.Errorf("expected no sort keys, got %v", len(ts.keys))
+	}
+}
+
+// TestTopologicalSortStrictWeakOrdering checks the properties of a strict weak ordering on random pods:
+// irreflexivity, asymmetry, transitivity and transitivity of incomparability.
+func TestTopologicalSortStrictWeakOrdering(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	client := newFairnessClient(t, now)
+
+	appGroups := []string{"basic", "basic-new", "other", ""}
+	selectors := []string{"p1", "p2", "p3", "p4"}
+	names := []string{"a", "b", "c"}
+	r := mathrand.New(mathrand.NewSource(1))
+
+	for _, fairness := range []pluginconfig.FairnessType{pluginconfig.FairnessAge, pluginconfig.FairnessRoundRobin} {
+		t.Run(string(fairness), func(t *testing.T) {
+			ts := &TopologicalSort{
+				Reader:     client,
+				namespaces: []string{metav1.NamespaceDefault},
+				fairness:   fairness,
+			}
+
+			const n = 40
+			pInfos := make([]*framework.QueuedPodInfo, n)
+			for i := range pInfos {
+				pod := makePod(selectors[r.Intn(len(selectors))], names[r.Intn(len(names))], int32(r.Intn(3)), appGroups[r.Intn(len(appGroups))], nil, nil)
+				pod.UID = types.UID(fmt.Sprintf("uid-%d", r.Intn(n)))
+				pInfos[i] = newQueuedPodInfo(t, pod, now.Add(time.Duration(r.Intn(4))*30*time.Second))
+			}
+			// Pods with the same UID are the same pod, hence the same key.
+			for i, pInfo := range pInfos {
+				for j := 0; j < i; j++ {
+					if pInfos[j].Pod.UID == pInfo.Pod.UID {
+						pInfos[i] = pInfos[j]
+						break
+					}
+				}
+			}
+
+			less := make([][]bool, n)
+			for i := range pInfos {
+				less[i] = make([]bool, n)
+				for j := range pInfos {
+					less[i][j] = ts.Less(pInfos[i], pInfos[j])
+				}
+			}
+			incomparable := func(i, j int) bool {
+				return !less[i][j] && !less[j][i]
+			}
+
+			for i := 0; i < n; i++ {
+				if less[i][i] {
+					t.Fatalf("Less(%d, %d) is not irreflexive", i, i)
+				}
+				for j := 0; j < n; j++ {
+					if less[i][j] && less[j][i] {
+						t.Fatalf("Less(%d, %d) is not asymmetric", i, j)
+					}
+					for k := 0; k < n; k++ {
+						if less[i][j] && less[j][k] && !less[i][k] {
+							t.Fatalf("Less(%d, %d, %d) is not transitive", i, j, k)
+						}
+						if incomparable(i, j) && incomparable(j, k) && !incomparable(i, k) {
+							t.Fatalf("incomparability of (%d, %d, %d) is not transitive", i, j, k)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+// newFairnessClient returns a client with two copies of the basic AppGroup: "basic", created at
+// now, and "basic-new", created a minute later.
+func newFairnessClient(t *testing.T, now time.Time) client.Client {
+	oldAppGroup := GetAppGroupCRBasic()
+	oldAppGroup.CreationTimestamp = metav1.NewTime(now)
+	newAppGroup := GetAppGroupCRBasic()
+	newAppGroup.Name = "basic-new"
+	newAppGroup.CreationTimestamp = metav1.NewTime(now.Add(time.Minute))
+	for _, ag := range []*agv1alpha1.AppGroup{oldAppGroup, newAppGroup} {
+		sort.Sort(util.ByWorkloadSelector(ag.Status.TopologyOrder))
+	}
+
+	s := clientgoscheme.Scheme
+	utilruntime.Must(agv1alpha1.AddToScheme(s))
+	c := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(oldAppGroup, newAppGroup).
+		Build()
+
+	ag := &agv1alpha1.AppGroup{}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(newAppGroup), ag); err != nil {
+		t.Fatalf("failed to get AppGroup CR: %v", err)
+	}
+	if !ag.CreationTimestamp.Equal(&newAppGroup.CreationTimestamp) {
+		t.Fatalf("AppGroup CR creation timestamp = %v, want %v", ag.CreationTimestamp, newAppGroup.CreationTimestamp)
+	}
+	return c
+}
+
+func newQueuedPodInfo(t *testing.T, pod *v1.Pod, timestamp time.Time) *framework.QueuedPodInfo {
+	if pod.Labels[agv1alpha1.AppGroupLabel] == "" {
+		pod.Labels = nil
+	}
+	return &framework.QueuedPodInfo{
+		PodInfo:   testutil.MustNewPodInfo(t, pod),
+		Timestamp: timestamp,
+	}
+}
+
 func BenchmarkTopologicalSortPlugin(b *testing.B) {
 	ctx := context.TODO()
 	agName := "onlineboutique"
@@ -439,7 +673,7 @@ func BenchmarkTopologicalSortPlugin(b *testing.B) {
 				Build()
 
 			ts := &TopologicalSort{
-				Client:     client,
+				Reader:     client,
 				namespaces: []string{metav1.NamespaceDefault},
 			}
 
@@ -511,6 +745,7 @@ func makePod(selector string, podName string, priority int32, appGroup string, r
 	return &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   podName,
+			UID:    types.UID(podName),
 			Labels: label,
 		},
 		Spec: v1.PodSpec{
@@ -0,0 +1,214 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+
+	agv1alpha1 "github.com/diktyo-io/appgroup-api/pkg/apis/appgroup/v1alpha1"
+)
+
+// DependencyGraph : the workload selectors of an AppGroup and the selectors each of them depends on
+type DependencyGraph map[string][]string
+
+// GetDependencyGraph : return the dependency graph of the workloads of the AppGroup
+func GetDependencyGraph(ag *agv1alpha1.AppGroup) DependencyGraph {
+	graph := DependencyGraph{}
+	for _, w := range ag.Spec.Workloads {
+		if _, ok := graph[w.Workload.Selector]; !ok {
+			graph[w.Workload.Selector] = []string{}
+		}
+		for _, dependency := range w.Dependencies {
+			graph[w.Workload.Selector] = append(graph[w.Workload.Selector], dependency.Workload.Selector)
+			if _, ok := graph[dependency.Workload.Selector]; !ok {
+				graph[dependency.Workload.Selector] = []string{}
+			}
+		}
+	}
+	return graph
+}
+
+// selectors : return the selectors of the graph in alphabetical order
+func (g DependencyGraph) selectors() []string {
+	selectors := make([]string, 0, len(g))
+	for selector := range g {
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(selectors)
+	return selectors
+}
+
+// TopologicalSort : sort the graph with the given AppGroup topology sorting algorithm
+func TopologicalSort(algorithm string, graph DependencyGraph) ([]string, error) {
+	switch algorithm {
+	case agv1alpha1.AppGroupKahnSort:
+		return KahnSort(graph)
+	case agv1alpha1.AppGroupTarjanSort:
+		return TarjanSort(graph), nil
+	case agv1alpha1.AppGroupReverseKahn:
+		return ReverseKahn(graph)
+	case agv1alpha1.AppGroupReverseTarjan:
+		return ReverseTarjan(graph), nil
+	case agv1alpha1.AppGroupAlternateKahn:
+		return AlternateKahn(graph)
+	case agv1alpha1.AppGroupAlternateTarjan:
+		return AlternateTarjan(graph), nil
+	default:
+		return nil, fmt.Errorf("unknown topology sorting algorithm %q", algorithm)
+	}
+}
+
+// KahnSort : sort the graph with Kahn's algorithm, a workload comes before the workloads it depends on.
+// The workloads ready to be sorted are kept in a stack, so the last dependency of a workload is sorted first.
+// Returns an error if the graph has a cycle.
+func KahnSort(graph DependencyGraph) ([]string, error) {
+	inDegree := make(map[string]int, len(graph))
+	for _, dependencies := range graph {
+		for _, dependency := range dependencies {
+			inDegree[dependency]++
+		}
+	}
+
+	var stack []string
+	for _, selector := range graph.selectors() {
+		if inDegree[selector] == 0 {
+			stack = append(stack, selector)
+		}
+	}
+
+	order := make([]string, 0, len(graph))
+	for len(stack) > 0 {
+		selector := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		order = append(order, selector)
+		for _, dependency := range graph[selector] {
+			inDegree[dependency]--
+			if inDegree[dependency] == 0 {
+				stack = append(stack, dependency)
+			}
+		}
+	}
+
+	if len(order) != len(graph) {
+		return nil, fmt.Errorf("the dependencies of the workloads have a cycle")
+	}
+	return order, nil
+}
+
+// TarjanSort : sort the graph with Tarjan's strongly connected components algorithm, a workload comes
+// before the workloads it depends on. The workloads of a cycle are sorted next to each other.
+func TarjanSort(graph DependencyGraph) []string {
+	index := 0
+	indexes := make(map[string]int, len(graph))
+	lowLinks := make(map[string]int, len(graph))
+	onStack := make(map[string]bool, len(graph))
+	var stack []string
+	// The components are found in the reverse topological order.
+	var components [][]string
+
+	var connect func(selector string)
+	connect = func(selector string) {
+		indexes[selector] = index
+		lowLinks[selector] = index
+		index++
+		stack = append(stack, selector)
+		onStack[selector] = true
+
+		for _, dependency := range graph[selector] {
+			if _, visited := indexes[dependency]; !visited {
+				connect(dependency)
+				lowLinks[selector] = min(lowLinks[selector], lowLinks[dependency])
+			} else if onStack[dependency] {
+				lowLinks[selector] = min(lowLinks[selector], indexes[dependency])
+			}
+		}
+
+		if lowLinks[selector] == indexes[selector] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == selector {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for _, selector := range graph.selectors() {
+		if _, visited := indexes[selector]; !visited {
+			connect(selector)
+		}
+	}
+
+	order := make([]string, 0, len(graph))
+	for i := len(components) - 1; i >= 0; i-- {
+		order = append(order, components[i]...)
+	}
+	return order
+}
+
+// ReverseKahn : the order of KahnSort reversed, a workload comes after the workloads it depends on
+func ReverseKahn(graph DependencyGraph) ([]string, error) {
+	order, err := KahnSort(graph)
+	if err != nil {
+		return nil, err
+	}
+	return reverse(order), nil
+}
+
+// ReverseTarjan : the order of TarjanSort reversed, a workload comes after the workloads it depends on
+func ReverseTarjan(graph DependencyGraph) []string {
+	return reverse(TarjanSort(graph))
+}
+
+// AlternateKahn : the order of KahnSort alternating between its first and its last workloads
+func AlternateKahn(graph DependencyGraph) ([]string, error) {
+	order, err := KahnSort(graph)
+	if err != nil {
+		return nil, err
+	}
+	return alternate(order), nil
+}
+
+// AlternateTarjan : the order of TarjanSort alternating between its first and its last workloads
+func AlternateTarjan(graph DependencyGraph) []string {
+	return alternate(TarjanSort(graph))
+}
+
+func reverse(order []string) []string {
+	reversed := make([]string, 0, len(order))
+	for i := len(order) - 1; i >= 0; i-- {
+		reversed = append(reversed, order[i])
+	}
+	return reversed
+}
+
+func alternate(order []string) []string {
+	alternated := make([]string, 0, len(order))
+	for first, last := 0, len(order)-1; first <= last; first, last = first+1, last-1 {
+		alternated = append(alternated, order[first])
+		if first != last {
+			alternated = append(alternated, order[last])
+		}
+	}
+	return alternated
+}
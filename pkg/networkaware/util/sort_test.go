@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopologicalSort(t *testing.T) {
+	// The dependencies of the online boutique, p1 being the frontend.
+	onlineBoutique := DependencyGraph{
+		"p1":  {"p2", "p3", "p4", "p6", "p8", "p9", "p10"},
+		"p2":  {"p11"},
+		"p3":  {},
+		"p4":  {},
+		"p5":  {},
+		"p6":  {},
+		"p7":  {},
+		"p8":  {"p2", "p3", "p4", "p5", "p6", "p7"},
+		"p9":  {"p3"},
+		"p10": {},
+		"p11": {},
+	}
+	chain := DependencyGraph{
+		"p1": {"p2"},
+		"p2": {"p3"},
+		"p3": {},
+	}
+	cycle := DependencyGraph{
+		"p1": {"p2"},
+		"p2": {"p3"},
+		"p3": {"p2"},
+	}
+
+	tests := []struct {
+		name      string
+		algorithm string
+		graph     DependencyGraph
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "KahnSort",
+			algorithm: "KahnSort",
+			graph:     onlineBoutique,
+			want:      []string{"p1", "p10", "p9", "p8", "p7", "p6", "p5", "p4", "p3", "p2", "p11"},
+		},
+		{
+			name:      "KahnSort with a cycle",
+			algorithm: "KahnSort",
+			graph:     cycle,
+			wantErr:   true,
+		},
+		{
+			name:      "TarjanSort",
+			algorithm: "TarjanSort",
+			graph:     chain,
+			want:      []string{"p1", "p2", "p3"},
+		},
+		{
+			name:      "TarjanSort keeps the dependencies after their workload",
+			algorithm: "TarjanSort",
+			graph:     onlineBoutique,
+			want:      []string{"p1", "p10", "p9", "p8", "p7", "p5", "p6", "p4", "p3", "p2", "p11"},
+		},
+		{
+			name:      "TarjanSort with a cycle",
+			algorithm: "TarjanSort",
+			graph:     cycle,
+			want:      []string{"p1", "p3", "p2"},
+		},
+		{
+			name:      "ReverseKahn",
+			algorithm: "ReverseKahn",
+			graph:     chain,
+			want:      []string{"p3", "p2", "p1"},
+		},
+		{
+			name:      "ReverseTarjan",
+			algorithm: "ReverseTarjan",
+			graph:     chain,
+			want:      []string{"p3", "p2", "p1"},
+		},
+		{
+			name:      "AlternateKahn",
+			algorithm: "AlternateKahn",
+			graph:     onlineBoutique,
+			want:      []string{"p1", "p11", "p10", "p2", "p9", "p3", "p8", "p4", "p7", "p5", "p6"},
+		},
+		{
+			name:      "AlternateTarjan",
+			algorithm: "AlternateTarjan",
+			graph:     chain,
+			want:      []string{"p1", "p3", "p2"},
+		},
+		{
+			name:      "unknown algorithm",
+			algorithm: "Unknown",
+			graph:     chain,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TopologicalSort(tt.algorithm, tt.graph)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TopologicalSort() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TopologicalSort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
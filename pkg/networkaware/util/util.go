@@ -23,8 +23,9 @@ import (
 	ntv1alpha1 "github.com/diktyo-io/networktopology-api/pkg/apis/networktopology/v1alpha1"
 )
 
-// CostKey : key for map concerning network costs (origin / destinations)
+// CostKey : key for map concerning network costs (topology level / origin / destinations)
 type CostKey struct {
+	TopologyKey ntv1alpha1.TopologyKey
 	Origin      string
 	Destination string
 }
@@ -64,6 +65,32 @@ func GetNodeZone(node *v1.Node) string {
 	return labels[v1.LabelTopologyZone]
 }
 
+// GetNodeDomains : return the domain of the node at each topology level, empty if the node has no label for it
+func GetNodeDomains(node *v1.Node, topologyKeys []ntv1alpha1.TopologyKey) []string {
+	domains := make([]string, len(topologyKeys))
+	for i, key := range topologyKeys {
+		domains[i] = node.Labels[string(key)]
+	}
+	return domains
+}
+
+// FindLink : return the topology level of the link between two nodes, given their domains from the finest to the
+// coarsest level: the coarsest level they belong to different domains at, i.e. right below the levels they share.
+// Only the levels both nodes have a domain for are considered, so that the link is found at the finest level available.
+// Returns same if the nodes belong to the same domains, and false if they have no level in common.
+func FindLink(domains []string, otherDomains []string) (level int, same bool, ok bool) {
+	for i := len(domains) - 1; i >= 0; i-- {
+		if domains[i] == "" || otherDomains[i] == "" {
+			continue
+		}
+		if domains[i] != otherDomains[i] {
+			return i, false, true
+		}
+		ok = true
+	}
+	return -1, ok, ok
+}
+
 // GetPodAppGroupLabel : get AppGroup from pod annotations
 func GetPodAppGroupLabel(pod *v1.Pod) string {
 	return pod.Labels[agv1alpha1.AppGroupLabel]
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func TestFindLink(t *testing.T) {
+	// Domains by hostname, rack, zone and region.
+	tests := []struct {
+		name         string
+		domains      []string
+		otherDomains []string
+		wantLevel    int
+		wantSame     bool
+		wantOK       bool
+	}{
+		{
+			name:         "same domains",
+			domains:      []string{"", "r1", "z1", "us-west-1"},
+			otherDomains: []string{"", "r1", "z1", "us-west-1"},
+			wantLevel:    -1,
+			wantSame:     true,
+			wantOK:       true,
+		},
+		{
+			name:         "different racks",
+			domains:      []string{"n1", "r1", "z1", "us-west-1"},
+			otherDomains: []string{"n2", "r2", "z1", "us-west-1"},
+			wantLevel:    1,
+			wantOK:       true,
+		},
+		{
+			name:         "different regions",
+			domains:      []string{"n1", "r1", "z1", "us-west-1"},
+			otherDomains: []string{"n2", "r2", "z2", "us-east-1"},
+			wantLevel:    3,
+			wantOK:       true,
+		},
+		{
+			name:         "rack missing: the zone is the finest level available",
+			domains:      []string{"", "r1", "z1", "us-west-1"},
+			otherDomains: []string{"", "", "z2", "us-west-1"},
+			wantLevel:    2,
+			wantOK:       true,
+		},
+		{
+			name:         "rack missing in the same zone",
+			domains:      []string{"", "r1", "z1", "us-west-1"},
+			otherDomains: []string{"", "", "z1", "us-west-1"},
+			wantLevel:    -1,
+			wantSame:     true,
+			wantOK:       true,
+		},
+		{
+			name:         "no level in common",
+			domains:      []string{"", "r1", "", ""},
+			otherDomains: []string{"", "", "z1", "us-west-1"},
+			wantLevel:    -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, same, ok := FindLink(tt.domains, tt.otherDomains)
+			if level != tt.wantLevel || same != tt.wantSame || ok != tt.wantOK {
+				t.Errorf("FindLink() = (%v, %v, %v), want (%v, %v, %v)", level, same, ok, tt.wantLevel, tt.wantSame, tt.wantOK)
+			}
+		})
+	}
+}
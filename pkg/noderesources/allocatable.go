@@ -144,6 +144,12 @@ func score(capacity int64, mode config.ModeType) int64 {
 
 // NormalizeScore invoked after scoring all nodes.
 func (alloc *Allocatable) NormalizeScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
+	normalizeScores(scores)
+	return nil
+}
+
+// normalizeScores transforms the highest to lowest score range to fit the framework's min to max node score range.
+func normalizeScores(scores framework.NodeScoreList) {
 	// Find highest and lowest scores.
 	var highest int64 = -math.MaxInt64
 	var lowest int64 = math.MaxInt64
@@ -156,7 +162,6 @@ func (alloc *Allocatable) NormalizeScore(ctx context.Context, state *framework.C
 		}
 	}
 
-	// Transform the highest to lowest score range to fit the framework's min to max node score range.
 	oldRange := highest - lowest
 	newRange := framework.MaxNodeScore - framework.MinNodeScore
 	for i, nodeScore := range scores {
@@ -166,6 +171,4 @@ func (alloc *Allocatable) NormalizeScore(ctx context.Context, state *framework.C
 			scores[i].Score = ((nodeScore.Score - lowest) * newRange / oldRange) + framework.MinNodeScore
 		}
 	}
-
-	return nil
 }
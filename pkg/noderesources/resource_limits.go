@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesources
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	schedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
+	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
+)
+
+// ResourceLimits is a score plugin that favors nodes with the least share of their
+// allocatable resources committed as limits, to spread the limits of burstable pods
+// and reduce the contention on overcommitted nodes.
+type ResourceLimits struct {
+	handle    framework.Handle
+	resources []schedulerconfig.ResourceSpec
+}
+
+var _ = framework.ScorePlugin(&ResourceLimits{})
+
+// ResourceLimitsName is the name of the plugin used in the Registry and configurations.
+const ResourceLimitsName = "NodeResourceLimits"
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (rl *ResourceLimits) Name() string {
+	return ResourceLimitsName
+}
+
+// NewResourceLimits initializes a new plugin and returns it.
+func NewResourceLimits(_ context.Context, obj runtime.Object, h framework.Handle) (framework.Plugin, error) {
+	args, ok := obj.(*config.NodeResourceLimitsArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type NodeResourceLimitsArgs, got %T", obj)
+	}
+	if err := validation.ValidateNodeResourceLimitsArgs(nil, args); err != nil {
+		return nil, err
+	}
+
+	return &ResourceLimits{
+		handle:    h,
+		resources: args.Resources,
+	}, nil
+}
+
+// Score invoked at the score extension point.
+// The raw score of a resource is (allocatable - limits) * MaxNodeScore / allocatable, where
+// limits are the limits of the pods on the node and of the pod. It is negative when the
+// limits exceed the allocatable. The node score is the weighted average of the raw scores
+// of the resources the node has.
+func (rl *ResourceLimits) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	nodeInfo, err := rl.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
+	if err != nil {
+		return 0, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
+	}
+
+	limits := podLimits(pod, nodeInfo)
+	for _, p := range nodeInfo.Pods {
+		addResource(limits, podLimits(p.Pod, nodeInfo))
+	}
+
+	var nodeScore, weightSum int64
+	for _, resource := range rl.resources {
+		name := v1.ResourceName(resource.Name)
+		allocatable := resourceValue(nodeInfo.Allocatable, name)
+		if allocatable == 0 {
+			continue
+		}
+		nodeScore += (allocatable - resourceValue(limits, name)) * framework.MaxNodeScore / allocatable * resource.Weight
+		weightSum += resource.Weight
+	}
+	if weightSum == 0 {
+		return 0, nil
+	}
+
+	klog.V(10).InfoS("Resource limits and score", "pod", klog.KObj(pod), "node", nodeName,
+		"allocatable", nodeInfo.Allocatable, "limits", limits, "score", nodeScore/weightSum)
+	return nodeScore / weightSum, nil
+}
+
+// ScoreExtensions of the Score plugin.
+func (rl *ResourceLimits) ScoreExtensions() framework.ScoreExtensions {
+	return rl
+}
+
+// NormalizeScore invoked after scoring all nodes.
+func (rl *ResourceLimits) NormalizeScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, scores framework.NodeScoreList) *framework.Status {
+	normalizeScores(scores)
+	return nil
+}
+
+// podLimits returns the limits of the pod, a resource limit being at least the resource
+// request, and the request when the limit is not set. As in the KEP, the limits of a
+// best-effort pod, which sets no request nor limit and may use up the node, default to
+// the allocatable of the node.
+func podLimits(pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Resource {
+	requests := trimaran.GetResourceRequested(pod)
+	limits := trimaran.GetResourceLimits(pod)
+	trimaran.SetMaxLimits(requests, limits)
+	if isZero(limits) {
+		return nodeInfo.Allocatable.Clone()
+	}
+	return limits
+}
+
+func isZero(r *framework.Resource) bool {
+	if r.MilliCPU != 0 || r.Memory != 0 || r.EphemeralStorage != 0 {
+		return false
+	}
+	for _, value := range r.ScalarResources {
+		if value != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func addResource(dst, src *framework.Resource) {
+	dst.MilliCPU += src.MilliCPU
+	dst.Memory += src.Memory
+	dst.EphemeralStorage += src.EphemeralStorage
+	for name, value := range src.ScalarResources {
+		dst.AddScalar(name, value)
+	}
+}
+
+func resourceValue(r *framework.Resource, name v1.ResourceName) int64 {
+	switch name {
+	case v1.ResourceCPU:
+		return r.MilliCPU
+	case v1.ResourceMemory:
+		return r.Memory
+	case v1.ResourceEphemeralStorage:
+		return r.EphemeralStorage
+	default:
+		return r.ScalarResources[name]
+	}
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesources
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	schedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+func TestNodeResourceLimits(t *testing.T) {
+	makeLimitsPod := func(name string, requests, limits map[v1.ResourceName]string) *v1.Pod {
+		pod := st.MakePod().Name(name).Obj()
+		container := v1.Container{Name: name, Resources: v1.ResourceRequirements{Requests: v1.ResourceList{}, Limits: v1.ResourceList{}}}
+		for r, q := range requests {
+			container.Resources.Requests[r] = resource.MustParse(q)
+		}
+		for r, q := range limits {
+			container.Resources.Limits[r] = resource.MustParse(q)
+		}
+		pod.Spec.Containers = append(pod.Spec.Containers, container)
+		return pod
+	}
+	makeNodeInfoWithPods := func(name string, allocatable map[v1.ResourceName]string, pods ...*v1.Pod) *framework.NodeInfo {
+		nodeInfo := framework.NewNodeInfo(pods...)
+		nodeInfo.SetNode(st.MakeNode().Name(name).Capacity(allocatable).Obj())
+		return nodeInfo
+	}
+	cpuOnly := []schedulerconfig.ResourceSpec{{Name: string(v1.ResourceCPU), Weight: 1}}
+
+	tests := []struct {
+		name         string
+		pod          *v1.Pod
+		nodeInfos    []*framework.NodeInfo
+		resources    []schedulerconfig.ResourceSpec
+		wantErr      string
+		expectedList framework.NodeScoreList
+	}{
+		{
+			// The use case of the KEP: node1 has a smaller requests ratio, but its limits are already oversubscribed.
+			name: "limits oversubscribed on the least requested node",
+			pod:  makeLimitsPod("pod5", map[v1.ResourceName]string{v1.ResourceCPU: "1"}, map[v1.ResourceName]string{v1.ResourceCPU: "4"}),
+			nodeInfos: []*framework.NodeInfo{
+				makeNodeInfoWithPods("node1", map[v1.ResourceName]string{v1.ResourceCPU: "8"},
+					makeLimitsPod("pod1", map[v1.ResourceName]string{v1.ResourceCPU: "2"}, map[v1.ResourceName]string{v1.ResourceCPU: "6"}),
+					makeLimitsPod("pod2", map[v1.ResourceName]string{v1.ResourceCPU: "2"}, map[v1.ResourceName]string{v1.ResourceCPU: "4"})),
+				makeNodeInfoWithPods("node2", map[v1.ResourceName]string{v1.ResourceCPU: "8"},
+					makeLimitsPod("pod3", map[v1.ResourceName]string{v1.ResourceCPU: "3"}, map[v1.ResourceName]string{v1.ResourceCPU: "3"}),
+					makeLimitsPod("pod4", map[v1.ResourceName]string{v1.ResourceCPU: "2"}, map[v1.ResourceName]string{v1.ResourceCPU: "2"})),
+			},
+			resources:    cpuOnly,
+			expectedList: []framework.NodeScore{{Name: "node1", Score: framework.MinNodeScore}, {Name: "node2", Score: framework.MaxNodeScore}},
+		},
+		{
+			name: "requests count as limits when the limits are not set",
+			pod:  makeLimitsPod("pod", nil, nil),
+			nodeInfos: []*framework.NodeInfo{
+				makeNodeInfoWithPods("node1", map[v1.ResourceName]string{v1.ResourceCPU: "8"},
+					makeLimitsPod("pod1", map[v1.ResourceName]string{v1.ResourceCPU: "6"}, nil)),
+				makeNodeInfoWithPods("node2", map[v1.ResourceName]string{v1.ResourceCPU: "8"},
+					makeLimitsPod("pod2", map[v1.ResourceName]string{v1.ResourceCPU: "1"}, map[v1.ResourceName]string{v1.ResourceCPU: "4"})),
+				makeNodeInfoWithPods("node3", map[v1.ResourceName]string{v1.ResourceCPU: "8"}),
+			},
+			resources: cpuOnly,
+			// Raw scores: 25, 50 and 100.
+			expectedList: []framework.NodeScore{{Name: "node1", Score: 0}, {Name: "node2", Score: 33}, {Name: "node3", Score: 100}},
+		},
+		{
+			name: "best-effort pods default to the allocatable",
+			pod:  makeLimitsPod("pod", map[v1.ResourceName]string{v1.ResourceCPU: "1"}, map[v1.ResourceName]string{v1.ResourceCPU: "1"}),
+			nodeInfos: []*framework.NodeInfo{
+				makeNodeInfoWithPods("node1", map[v1.ResourceName]string{v1.ResourceCPU: "8"},
+					makeLimitsPod("pod1", nil, nil)),
+				makeNodeInfoWithPods("node2", map[v1.ResourceName]string{v1.ResourceCPU: "8"},
+					makeLimitsPod("pod2", map[v1.ResourceName]string{v1.ResourceCPU: "1"}, map[v1.ResourceName]string{v1.ResourceCPU: "4"})),
+			},
+			resources: cpuOnly,
+			// Raw scores: -12 and 37.
+			expectedList: []framework.NodeScore{{Name: "node1", Score: framework.MinNodeScore}, {Name: "node2", Score: framework.MaxNodeScore}},
+		},
+		{
+			name: "equally weighted resources",
+			pod:  makeLimitsPod("pod", nil, nil),
+			nodeInfos: []*framework.NodeInfo{
+				makeNodeInfoWithPods("node1", map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourceMemory: "8Gi"},
+					makeLimitsPod("pod1", nil, map[v1.ResourceName]string{v1.ResourceCPU: "4"})),
+				makeNodeInfoWithPods("node2", map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourceMemory: "8Gi"},
+					makeLimitsPod("pod2", nil, map[v1.ResourceName]string{v1.ResourceMemory: "8Gi"})),
+			},
+			resources:    []schedulerconfig.ResourceSpec{{Name: string(v1.ResourceCPU), Weight: 1}, {Name: string(v1.ResourceMemory), Weight: 1}},
+			expectedList: []framework.NodeScore{{Name: "node1", Score: framework.MinNodeScore}, {Name: "node2", Score: framework.MinNodeScore}},
+		},
+		{
+			name: "memory weighted more than cpu",
+			pod:  makeLimitsPod("pod", nil, nil),
+			nodeInfos: []*framework.NodeInfo{
+				makeNodeInfoWithPods("node1", map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourceMemory: "8Gi"},
+					makeLimitsPod("pod1", nil, map[v1.ResourceName]string{v1.ResourceCPU: "4"})),
+				makeNodeInfoWithPods("node2", map[v1.ResourceName]string{v1.ResourceCPU: "4", v1.ResourceMemory: "8Gi"},
+					makeLimitsPod("pod2", nil, map[v1.ResourceName]string{v1.ResourceMemory: "8Gi"})),
+			},
+			resources:    []schedulerconfig.ResourceSpec{{Name: string(v1.ResourceCPU), Weight: 1}, {Name: string(v1.ResourceMemory), Weight: 3}},
+			expectedList: []framework.NodeScore{{Name: "node1", Score: framework.MaxNodeScore}, {Name: "node2", Score: framework.MinNodeScore}},
+		},
+		{
+			name: "scalar resource",
+			pod:  makeLimitsPod("pod", nil, map[v1.ResourceName]string{"nvidia.com/gpu": "1"}),
+			nodeInfos: []*framework.NodeInfo{
+				makeNodeInfoWithPods("node1", map[v1.ResourceName]string{"nvidia.com/gpu": "4"},
+					makeLimitsPod("pod1", nil, map[v1.ResourceName]string{"nvidia.com/gpu": "3"})),
+				makeNodeInfoWithPods("node2", map[v1.ResourceName]string{"nvidia.com/gpu": "4"},
+					makeLimitsPod("pod2", nil, map[v1.ResourceName]string{"nvidia.com/gpu": "1"})),
+			},
+			resources:    []schedulerconfig.ResourceSpec{{Name: "nvidia.com/gpu", Weight: 1}},
+			expectedList: []framework.NodeScore{{Name: "node1", Score: framework.MinNodeScore}, {Name: "node2", Score: framework.MaxNodeScore}},
+		},
+		{
+			name:      "invalid args",
+			pod:       makeLimitsPod("pod", nil, nil),
+			nodeInfos: []*framework.NodeInfo{makeNodeInfoWithPods("node1", map[v1.ResourceName]string{v1.ResourceCPU: "8"})},
+			resources: []schedulerconfig.ResourceSpec{{Name: string(v1.ResourceCPU), Weight: 0}},
+			wantErr:   "resources[0].weight: Invalid value",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cs := clientsetfake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			registeredPlugins := []tf.RegisterPluginFunc{
+				tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+			}
+			fh, err := tf.NewFramework(
+				ctx,
+				registeredPlugins,
+				"default-scheduler",
+				frameworkruntime.WithClientSet(cs),
+				frameworkruntime.WithInformerFactory(informerFactory),
+				frameworkruntime.WithSnapshotSharedLister(&fakeSharedLister{nodes: test.nodeInfos}),
+			)
+			if err != nil {
+				t.Fatalf("fail to create framework: %s", err)
+			}
+
+			pl, err := NewResourceLimits(ctx, &config.NodeResourceLimitsArgs{Resources: test.resources}, fh)
+			if len(test.wantErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("got err %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to initialize plugin NodeResourceLimits, got error: %v", err)
+			}
+
+			var gotList framework.NodeScoreList
+			plugin := pl.(framework.ScorePlugin)
+			for i := range test.nodeInfos {
+				score, status := plugin.Score(ctx, nil, test.pod, test.nodeInfos[i].Node().Name)
+				if !status.IsSuccess() {
+					t.Errorf("unexpected error: %v", status)
+				}
+				gotList = append(gotList, framework.NodeScore{Name: test.nodeInfos[i].Node().Name, Score: score})
+			}
+
+			if status := plugin.ScoreExtensions().NormalizeScore(ctx, nil, test.pod, gotList); !status.IsSuccess() {
+				t.Errorf("unexpected error: %v", status)
+			}
+			for i := range gotList {
+				if test.expectedList[i] != gotList[i] {
+					t.Errorf("expected %#v, got %#v", test.expectedList[i], gotList[i])
+				}
+			}
+		})
+	}
+}
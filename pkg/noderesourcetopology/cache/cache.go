@@ -28,7 +28,8 @@ type Interface interface {
 	// GetCachedNRTCopy retrieves a NRT copy from cache, and then deducts over-reserved resources if necessary.
 	// It will be used as the source of truth across the Pod's scheduling cycle.
 	// Over-reserved resources are the resources consumed by pods scheduled to that node after the last update
-	// of NRT pertaining to the same node, pessimistically overallocated on ALL the NUMA zones of the node.
+	// of NRT pertaining to the same node, pessimistically overallocated on ALL the NUMA zones of the node,
+	// or, if the cache is configured so, only on the NUMA zones the pods are expected to take them from.
 	// The pod argument is used only for logging purposes.
 	// Returns nil if there is no NRT data available for the node named `nodeName`.
 	// Returns a boolean to signal the caller if the NRT data is fresh.
@@ -53,10 +54,12 @@ type Interface interface {
 
 	// ReserveNodeResources add the resources requested by a pod to the assumed resources for the node on which the pod
 	// is scheduled on. This is a prerequesite for the pessimistic overallocation tracking.
+	// The numaResources argument are the resources the pod is expected to take from each NUMA zone, as simulated
+	// by the filter, or nil if unknown.
 	// Additionally, this function resets the discarded counter for the same node. Being able to handle a pod means
 	// that this node has still available resources. If a node was previously discarded and then cleared, we interpret
 	// this sequence of events as the previous pod required too much - a possible and benign condition.
-	ReserveNodeResources(nodeName string, pod *corev1.Pod)
+	ReserveNodeResources(nodeName string, pod *corev1.Pod, numaResources NUMAResources)
 
 	// UnreserveNodeResources decrement from the node assumed resources the resources required by the given pod.
 	UnreserveNodeResources(nodeName string, pod *corev1.Pod)
@@ -67,3 +70,6 @@ type Interface interface {
 	// bound, PostBind is the extension point that it should register.
 	PostBind(nodeName string, pod *corev1.Pod)
 }
+
+// NUMAResources are the resources a pod is expected to take from each NUMA zone, keyed by NUMA ID.
+type NUMAResources map[int]corev1.ResourceList
@@ -77,7 +77,7 @@ func (pt *DiscardReserved) GetCachedNRTCopy(ctx context.Context, nodeName string
 func (pt *DiscardReserved) NodeMaybeOverReserved(nodeName string, pod *corev1.Pod) {}
 func (pt *DiscardReserved) NodeHasForeignPods(nodeName string, pod *corev1.Pod)    {}
 
-func (pt *DiscardReserved) ReserveNodeResources(nodeName string, pod *corev1.Pod) {
+func (pt *DiscardReserved) ReserveNodeResources(nodeName string, pod *corev1.Pod, numaResources NUMAResources) {
 	pt.lh.V(5).Info("NRT Reserve", "logID", logging.PodLogID(pod), "podUID", pod.GetUID(), "node", nodeName)
 	pt.rMutex.Lock()
 	defer pt.rMutex.Unlock()
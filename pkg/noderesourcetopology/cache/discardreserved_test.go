@@ -86,7 +86,7 @@ func TestDiscardReservedNodesReserveNodeResources(t *testing.T) {
 			Namespace: "test",
 			UID:       "some-uid",
 		},
-	})
+	}, nil)
 	nodePods, ok := nrtCache.reservationMap["node1"]
 	if !ok {
 		t.Fatal("expected reservationMap to have entry for node1")
@@ -119,7 +119,7 @@ func TestDiscardReservedNodesRemoveReservationForNode(t *testing.T) {
 		},
 	}
 
-	nrtCache.ReserveNodeResources("node1", pod)
+	nrtCache.ReserveNodeResources("node1", pod, nil)
 	nodePods, ok := nrtCache.reservationMap["node1"]
 	if !ok {
 		t.Fatal("expected reservationMap to have entry for node1")
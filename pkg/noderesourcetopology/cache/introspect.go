@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/component-base/metrics"
+)
+
+const (
+	resyncResultFlushed             = "flushed"
+	resyncResultGetFailed           = "get_failed"
+	resyncResultMissingPods         = "missing_pods"
+	resyncResultMissingFingerprint  = "missing_fingerprint"
+	resyncResultFingerprintMismatch = "fingerprint_mismatch"
+	resyncResultError               = "error"
+)
+
+// ResyncResult describes the last attempt to resync the cached NRT data of a node.
+type ResyncResult struct {
+	Time time.Time `json:"time"`
+	// Result is one of "flushed", "get_failed", "missing_pods", "missing_fingerprint", "fingerprint_mismatch" or "error".
+	Result string `json:"result"`
+	// Reason is the error which prevented the resync, including the expected and computed podset fingerprints on mismatch.
+	Reason string `json:"reason,omitempty"`
+}
+
+// NodeDump is the cache state of a node.
+type NodeDump struct {
+	Generation      int64  `json:"generation"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// AssumedResources are the resources deducted from the cached NRT data, by pod namespace/name.
+	AssumedResources map[string]corev1.ResourceList `json:"assumedResources,omitempty"`
+	// AssumedNUMAResources are the resources the pods are expected to take from each NUMA zone, by pod namespace/name.
+	AssumedNUMAResources map[string]NUMAResources `json:"assumedNUMAResources,omitempty"`
+	DiscardCount         int                      `json:"discardCount,omitempty"`
+	ForeignPods          bool                     `json:"foreignPods,omitempty"`
+	Mispredicted         bool                     `json:"mispredicted,omitempty"`
+	LastResync           *ResyncResult            `json:"lastResync,omitempty"`
+}
+
+// Dump is a point in time copy of the cache state, meant for troubleshooting.
+type Dump struct {
+	ResyncMethod   string              `json:"resyncMethod"`
+	AccountingMode string              `json:"accountingMode"`
+	Nodes          map[string]NodeDump `json:"nodes"`
+}
+
+// Dump returns a copy of the state of all the nodes known to the cache.
+func (ov *OverReserve) Dump() Dump {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+
+	nodeNames := make(map[string]struct{})
+	for nodeName := range ov.nrts.data {
+		nodeNames[nodeName] = struct{}{}
+	}
+	for nodeName := range ov.assumedResources {
+		nodeNames[nodeName] = struct{}{}
+	}
+	for _, cnt := range []counter{ov.nodesMaybeOverreserved, ov.nodesWithForeignPods, ov.nodesMispredicted} {
+		for nodeName := range cnt {
+			nodeNames[nodeName] = struct{}{}
+		}
+	}
+	for nodeName := range ov.resyncResults {
+		nodeNames[nodeName] = struct{}{}
+	}
+
+	dump := Dump{
+		ResyncMethod:   string(ov.resyncMethod),
+		AccountingMode: string(ov.accountingMode),
+		Nodes:          make(map[string]NodeDump, len(nodeNames)),
+	}
+	for nodeName := range nodeNames {
+		nodeDump := NodeDump{
+			DiscardCount: ov.nodesMaybeOverreserved[nodeName],
+			ForeignPods:  ov.nodesWithForeignPods.IsSet(nodeName),
+			Mispredicted: ov.nodesMispredicted.IsSet(nodeName),
+		}
+		if nrt, ok := ov.nrts.data[nodeName]; ok {
+			nodeDump.Generation = nrt.Generation
+			nodeDump.ResourceVersion = nrt.ResourceVersion
+		}
+		if rs, ok := ov.assumedResources[nodeName]; ok {
+			nodeDump.AssumedResources, nodeDump.AssumedNUMAResources = rs.Copy()
+		}
+		if res, ok := ov.resyncResults[nodeName]; ok {
+			res := res // local copy, not sharing the stored one
+			nodeDump.LastResync = &res
+		}
+		dump.Nodes[nodeName] = nodeDump
+	}
+	return dump
+}
+
+// setResyncResult records the result of the latest resync attempt of a node.
+func (ov *OverReserve) setResyncResult(nodeName, result string, err error) {
+	res := ResyncResult{
+		Time:   time.Now(),
+		Result: result,
+	}
+	if err != nil {
+		res.Reason = err.Error()
+	}
+	resyncs.WithLabelValues(ov.profileName, result).Inc()
+
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	ov.resyncResults[nodeName] = res
+}
+
+// updateNodeMetrics refreshes the metrics of a node from the cache state.
+// Needs to be called with the lock held.
+func (ov *OverReserve) updateNodeMetrics(nodeName string) {
+	assumedPods := 0
+	if rs, ok := ov.assumedResources[nodeName]; ok {
+		assumedPods = len(rs.data)
+	}
+	nodeAssumedPods.WithLabelValues(ov.profileName, nodeName).Set(float64(assumedPods))
+	nodeDiscardCount.WithLabelValues(ov.profileName, nodeName).Set(float64(ov.nodesMaybeOverreserved[nodeName]))
+	nodeForeignPods.WithLabelValues(ov.profileName, nodeName).Set(boolToFloat64(ov.nodesWithForeignPods.IsSet(nodeName)))
+	nodeMispredicted.WithLabelValues(ov.profileName, nodeName).Set(boolToFloat64(ov.nodesMispredicted.IsSet(nodeName)))
+	if nrt, ok := ov.nrts.data[nodeName]; ok {
+		nodeGeneration.WithLabelValues(ov.profileName, nodeName).Set(float64(nrt.Generation))
+	}
+}
+
+// deleteNodeMetrics drops the metrics of a node which is gone.
+func (ov *OverReserve) deleteNodeMetrics(nodeName string) {
+	for _, gauge := range []*metrics.GaugeVec{nodeAssumedPods, nodeDiscardCount, nodeForeignPods, nodeMispredicted, nodeGeneration} {
+		gauge.DeleteLabelValues(ov.profileName, nodeName)
+	}
+}
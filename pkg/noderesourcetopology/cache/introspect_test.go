@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/testutil"
+	"k8s.io/klog/v2"
+
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestOverReserveDump(t *testing.T) {
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakePodLister := &fakePodLister{}
+
+	nrtCache := mustOverReserve(t, fakeClient, fakePodLister)
+	for _, obj := range makeDefaultTestTopology() {
+		nrtCache.Store().Update(obj)
+	}
+
+	testPod := makeTestPod("namespace1", "pod1", "8", "16Gi")
+	testPod.Spec.NodeName = "node1"
+	nrtCache.ReserveNodeResources("node1", testPod, nil)
+	nrtCache.NodeMaybeOverReserved("node1", testPod)
+	nrtCache.NodeMaybeOverReserved("node1", testPod)
+
+	reportedNodeTopology := makeDefaultTestTopology()[0]
+	reportedNodeTopology.Attributes = topologyv1alpha2.AttributeList{
+		{
+			Name:  podfingerprint.Attribute,
+			Value: "pfp0v001badbadbadbadbad0",
+		},
+	}
+	if err := fakeClient.Create(context.Background(), reportedNodeTopology); err != nil {
+		t.Fatal(err)
+	}
+	runningPod := testPod.DeepCopy()
+	runningPod.Status.Phase = corev1.PodRunning
+	fakePodLister.AddPod(runningPod)
+
+	nrtCache.Resync()
+
+	dump := nrtCache.Dump()
+	nodeDump, ok := dump.Nodes["node1"]
+	if !ok {
+		t.Fatalf("missing node1 from dump: %v", dump)
+	}
+	if nodeDump.DiscardCount != 2 {
+		t.Errorf("discard count: got %d expected %d", nodeDump.DiscardCount, 2)
+	}
+	if nodeDump.ForeignPods {
+		t.Errorf("unexpected foreign pods")
+	}
+	podRes, ok := nodeDump.AssumedResources["namespace1/pod1"]
+	if !ok {
+		t.Fatalf("missing assumed resources for the reserved pod: %v", nodeDump.AssumedResources)
+	}
+	if qty := podRes[corev1.ResourceCPU]; qty.Cmp(resource.MustParse("8")) != 0 {
+		t.Errorf("assumed cpu: got %v expected %v", qty.String(), "8")
+	}
+	if nodeDump.LastResync == nil {
+		t.Fatalf("missing last resync result")
+	}
+	if nodeDump.LastResync.Result != resyncResultFingerprintMismatch {
+		t.Errorf("last resync result: got %q expected %q", nodeDump.LastResync.Result, resyncResultFingerprintMismatch)
+	}
+	if !strings.Contains(nodeDump.LastResync.Reason, podfingerprint.ErrSignatureMismatch.Error()) {
+		t.Errorf("last resync reason: got %q expected to contain %q", nodeDump.LastResync.Reason, podfingerprint.ErrSignatureMismatch.Error())
+	}
+
+	data, err := json.Marshal(dump)
+	if err != nil {
+		t.Fatalf("cannot marshal the dump: %v", err)
+	}
+	if !strings.Contains(string(data), `"result":"fingerprint_mismatch"`) {
+		t.Errorf("missing resync result from the JSON dump: %s", string(data))
+	}
+
+	expectGaugeValue(t, "assumed pods", nodeAssumedPods.WithLabelValues("", "node1"), 1)
+	expectGaugeValue(t, "discard count", nodeDiscardCount.WithLabelValues("", "node1"), 2)
+	expectGaugeValue(t, "foreign pods", nodeForeignPods.WithLabelValues("", "node1"), 0)
+
+	nrtCache.NodeHasForeignPods("node1", testPod)
+	if !nrtCache.Dump().Nodes["node1"].ForeignPods {
+		t.Errorf("missing foreign pods")
+	}
+	expectGaugeValue(t, "foreign pods", nodeForeignPods.WithLabelValues("", "node1"), 1)
+
+	nrtCache.FlushNodes(klog.Background(), makeDefaultTestTopology()...)
+	nodeDump = nrtCache.Dump().Nodes["node1"]
+	if nodeDump.DiscardCount != 0 || nodeDump.ForeignPods || len(nodeDump.AssumedResources) > 0 {
+		t.Errorf("unexpected state after flush: %+v", nodeDump)
+	}
+	if nodeDump.LastResync == nil {
+		t.Errorf("lost last resync result after flush")
+	}
+	expectGaugeValue(t, "assumed pods", nodeAssumedPods.WithLabelValues("", "node1"), 0)
+	expectGaugeValue(t, "discard count", nodeDiscardCount.WithLabelValues("", "node1"), 0)
+	expectGaugeValue(t, "foreign pods", nodeForeignPods.WithLabelValues("", "node1"), 0)
+
+	nrtCache.DeleteNode("node1")
+	if _, ok := nrtCache.Dump().Nodes["node1"]; ok {
+		t.Errorf("unexpected node1 in the dump after deletion")
+	}
+	if nrtCache.Store().Contains("node1") {
+		t.Errorf("unexpected node1 in the store after deletion")
+	}
+	expectGaugeMissing(t, "assumed pods", nodeAssumedPods, "node1")
+	expectGaugeMissing(t, "generation", nodeGeneration, "node1")
+}
+
+func expectGaugeMissing(t *testing.T, desc string, gauge *metrics.GaugeVec, nodeName string) {
+	t.Helper()
+	// DeleteLabelValues returns false if the series is already gone
+	if gauge.DeleteLabelValues("", nodeName) {
+		t.Errorf("unexpected %s metric for node %q", desc, nodeName)
+	}
+}
+
+func expectGaugeValue(t *testing.T, desc string, gauge metrics.GaugeMetric, expected float64) {
+	t.Helper()
+	got, err := testutil.GetGaugeMetricValue(gauge)
+	if err != nil {
+		t.Fatalf("cannot get %s metric: %v", desc, err)
+	}
+	if got != expected {
+		t.Errorf("%s metric: got %v expected %v", desc, got, expected)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const metricsSubsystem = "scheduler_nrt_cache"
+
+var (
+	nodeAssumedPods = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "node_assumed_pods",
+			Help:           "Number of pods whose resources are deducted from the cached NRT data of the node, by scheduler profile.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile", "node"})
+
+	nodeDiscardCount = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "node_discard_count",
+			Help:           "Number of times the node was filtered out since it was last resynced, by scheduler profile.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile", "node"})
+
+	nodeForeignPods = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "node_foreign_pods",
+			Help:           "Whether pods not scheduled by this scheduler were detected on the node since it was last resynced, by scheduler profile: 1 detected, 0 otherwise.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile", "node"})
+
+	nodeMispredicted = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "node_mispredicted",
+			Help:           "Whether the NRT data of the node disagreed with the per-NUMA accounting since it was last resynced, by scheduler profile: 1 disagreed, 0 otherwise.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile", "node"})
+
+	nodeGeneration = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "node_generation",
+			Help:           "Generation of the cached NRT object of the node, by scheduler profile.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile", "node"})
+
+	resyncs = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "node_resyncs_total",
+			Help:           "Number of attempts to resync the cached NRT data of a node, by scheduler profile and result.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile", "result"})
+
+	registerMetrics sync.Once
+)
+
+// RegisterMetrics registers the NRT cache metrics to the legacy registry served by the scheduler.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(nodeAssumedPods, nodeDiscardCount, nodeForeignPods, nodeMispredicted, nodeGeneration, resyncs)
+	})
+}
+
+func boolToFloat64(val bool) float64 {
+	if val {
+		return 1
+	}
+	return 0
+}
@@ -29,6 +29,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	podlisterv1 "k8s.io/client-go/listers/core/v1"
 
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -50,17 +51,26 @@ type OverReserve struct {
 	// to resync nodes. See The documentation of Resync() below for more details.
 	nodesMaybeOverreserved counter
 	nodesWithForeignPods   counter
-	podLister              podlisterv1.PodLister
-	resyncMethod           apiconfig.CacheResyncMethod
-	isPodRelevant          podprovider.PodFilterFunc
+	// nodesMispredicted counts the nodes whose NRT updates disagree with the NUMA zones we expected the pods to take
+	// their resources from. The resources of the pods on these nodes are deducted pessimistically until the next resync.
+	nodesMispredicted counter
+	// resyncResults holds the result of the latest resync attempt of each node, for troubleshooting purposes.
+	resyncResults  map[string]ResyncResult
+	podLister      podlisterv1.PodLister
+	resyncMethod   apiconfig.CacheResyncMethod
+	accountingMode apiconfig.CacheAccountingMode
+	isPodRelevant  podprovider.PodFilterFunc
+	// profileName labels the metrics, as each scheduler profile has its own cache
+	profileName string
 }
 
-func NewOverReserve(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache, client ctrlclient.Client, podLister podlisterv1.PodLister, isPodRelevant podprovider.PodFilterFunc) (*OverReserve, error) {
+func NewOverReserve(lh logr.Logger, profileName string, cfg *apiconfig.NodeResourceTopologyCache, client ctrlclient.Client, podLister podlisterv1.PodLister, isPodRelevant podprovider.PodFilterFunc) (*OverReserve, error) {
 	if client == nil || podLister == nil {
 		return nil, fmt.Errorf("received nil references")
 	}
 
 	resyncMethod := getCacheResyncMethod(lh, cfg)
+	accountingMode := getCacheAccountingMode(lh, cfg)
 
 	nrtObjs := &topologyv1alpha2.NodeResourceTopologyList{}
 	// TODO: we should pass-in a context in the future
@@ -68,18 +78,27 @@ func NewOverReserve(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache, cl
 		return nil, err
 	}
 
-	lh.V(3).Info("initializing", "noderesourcetopologies", len(nrtObjs.Items), "method", resyncMethod)
+	lh.V(3).Info("initializing", "noderesourcetopologies", len(nrtObjs.Items), "method", resyncMethod, "accounting", accountingMode)
 	obj := &OverReserve{
 		lh:                     lh,
+		profileName:            profileName,
 		client:                 client,
 		nrts:                   newNrtStore(lh, nrtObjs.Items),
 		assumedResources:       make(map[string]*resourceStore),
 		nodesMaybeOverreserved: newCounter(),
 		nodesWithForeignPods:   newCounter(),
+		nodesMispredicted:      newCounter(),
+		resyncResults:          make(map[string]ResyncResult),
 		podLister:              podLister,
 		resyncMethod:           resyncMethod,
+		accountingMode:         accountingMode,
 		isPodRelevant:          isPodRelevant,
 	}
+
+	RegisterMetrics()
+	for nodeName := range obj.nrts.data {
+		obj.updateNodeMetrics(nodeName)
+	}
 	return obj, nil
 }
 
@@ -103,7 +122,7 @@ func (ov *OverReserve) GetCachedNRTCopy(ctx context.Context, nodeName string, po
 	lh := ov.lh.WithValues("logID", logID, "podUID", pod.GetUID(), "node", nodeName)
 
 	lh.V(6).Info("NRT", "fromcache", stringify.NodeResourceTopologyResources(nrt))
-	nodeAssumedResources.UpdateNRT(logID, nrt)
+	nodeAssumedResources.UpdateNRT(logID, nrt, ov.isAccountedPerNUMA(nodeName))
 
 	lh.V(5).Info("NRT", "withassumed", stringify.NodeResourceTopologyResources(nrt))
 	return nrt, true
@@ -113,6 +132,7 @@ func (ov *OverReserve) NodeMaybeOverReserved(nodeName string, pod *corev1.Pod) {
 	ov.lock.Lock()
 	defer ov.lock.Unlock()
 	val := ov.nodesMaybeOverreserved.Incr(nodeName)
+	ov.updateNodeMetrics(nodeName)
 	ov.lh.V(4).Info("mark discarded", "node", nodeName, "count", val)
 }
 
@@ -125,10 +145,11 @@ func (ov *OverReserve) NodeHasForeignPods(nodeName string, pod *corev1.Pod) {
 		return
 	}
 	val := ov.nodesWithForeignPods.Incr(nodeName)
+	ov.updateNodeMetrics(nodeName)
 	lh.V(4).Info("marked with foreign pods", "count", val)
 }
 
-func (ov *OverReserve) ReserveNodeResources(nodeName string, pod *corev1.Pod) {
+func (ov *OverReserve) ReserveNodeResources(nodeName string, pod *corev1.Pod, numaResources NUMAResources) {
 	lh := ov.lh.WithValues("logID", logging.PodLogID(pod), "podUID", pod.GetUID(), "node", nodeName)
 	ov.lock.Lock()
 	defer ov.lock.Unlock()
@@ -138,10 +159,15 @@ func (ov *OverReserve) ReserveNodeResources(nodeName string, pod *corev1.Pod) {
 		ov.assumedResources[nodeName] = nodeAssumedResources
 	}
 
-	nodeAssumedResources.AddPod(pod)
+	if ov.accountingMode != apiconfig.CacheAccountingPerNUMA {
+		// don't keep what we won't use
+		numaResources = nil
+	}
+	nodeAssumedResources.AddPod(pod, numaResources)
 	lh.V(5).Info("post reserve", "assumedResources", nodeAssumedResources.String())
 
 	ov.nodesMaybeOverreserved.Delete(nodeName)
+	ov.updateNodeMetrics(nodeName)
 	lh.V(6).Info("reset discard counter")
 }
 
@@ -158,6 +184,7 @@ func (ov *OverReserve) UnreserveNodeResources(nodeName string, pod *corev1.Pod)
 	}
 
 	nodeAssumedResources.DeletePod(pod)
+	ov.updateNodeMetrics(nodeName)
 	lh.V(5).Info("post release", "assumedResources", nodeAssumedResources.String())
 }
 
@@ -205,6 +232,11 @@ func (ov *OverReserve) Resync() {
 	defer lh.V(4).Info(logging.FlowEnd)
 
 	nodeNames := ov.NodesMaybeOverReserved(lh)
+	if ov.accountingMode == apiconfig.CacheAccountingPerNUMA {
+		// the NRT updates of the nodes with resources deducted per NUMA zone are checked even if
+		// the nodes are not dirty, to catch as soon as we can the pods placed on other NUMA zones.
+		nodeNames = ov.appendNodesAccountedPerNUMA(lh, nodeNames)
+	}
 	// avoid as much as we can unnecessary work and logs.
 	if len(nodeNames) == 0 {
 		lh.V(6).Info("no dirty nodes detected")
@@ -228,6 +260,7 @@ func (ov *OverReserve) Resync() {
 		nrtCandidate := &topologyv1alpha2.NodeResourceTopology{}
 		if err := ov.client.Get(context.Background(), types.NamespacedName{Name: nodeName}, nrtCandidate); err != nil {
 			lh.V(3).Info("failed to get NodeTopology", "error", err)
+			ov.setResyncResult(nodeName, resyncResultGetFailed, err)
 			continue
 		}
 		if nrtCandidate == nil {
@@ -235,30 +268,7 @@ func (ov *OverReserve) Resync() {
 			continue
 		}
 
-		objs, ok := nodeToObjsMap[nodeName]
-		if !ok {
-			// this really should never happen
-			lh.V(3).Info("cannot find any pod for node")
-			continue
-		}
-
-		pfpExpected, onlyExclRes := podFingerprintForNodeTopology(nrtCandidate, ov.resyncMethod)
-		if pfpExpected == "" {
-			lh.V(3).Info("missing NodeTopology podset fingerprint data")
-			continue
-		}
-
-		lh.V(6).Info("trying to sync NodeTopology", "fingerprint", pfpExpected, "onlyExclusiveResources", onlyExclRes)
-
-		err = checkPodFingerprintForNode(lh, objs, nodeName, pfpExpected, onlyExclRes)
-		if errors.Is(err, podfingerprint.ErrSignatureMismatch) {
-			// can happen, not critical
-			lh.V(5).Info("NodeTopology podset fingerprint mismatch")
-			continue
-		}
-		if err != nil {
-			// should never happen, let's be vocal
-			lh.V(3).Error(err, "checking NodeTopology podset fingerprint")
+		if !ov.isNodeTopologyInSync(lh, nrtCandidate, nodeToObjsMap) {
 			continue
 		}
 
@@ -269,6 +279,74 @@ func (ov *OverReserve) Resync() {
 	ov.FlushNodes(lh, nrtUpdates...)
 }
 
+// ResyncNode tries to resync a node using its latest NRT object, without waiting for the periodic resync.
+// Nodes which are not dirty are left untouched, like the periodic resync does.
+func (ov *OverReserve) ResyncNode(nrtCandidate *topologyv1alpha2.NodeResourceTopology) {
+	lh := ov.lh.WithValues("logID", logging.TimeLogID(), "flow", logging.FlowCacheSync, "node", nrtCandidate.Name)
+	lh.V(4).Info(logging.FlowBegin)
+	defer lh.V(4).Info(logging.FlowEnd)
+
+	if !ov.nodeNeedsResync(nrtCandidate.Name) {
+		lh.V(6).Info("node not dirty")
+		return
+	}
+
+	pods, err := listPodsOnNode(ov.podLister, nrtCandidate.Name)
+	if err != nil {
+		lh.Error(err, "cannot list the running pods of the node")
+		return
+	}
+
+	if !ov.isNodeTopologyInSync(lh, nrtCandidate, podDataByNode(lh, pods, ov.isPodRelevant)) {
+		return
+	}
+
+	lh.V(4).Info("overriding cached info")
+	ov.FlushNodes(lh, nrtCandidate)
+}
+
+// isNodeTopologyInSync returns true if the pods accounted in the given NRT object, which is expected to be the latest
+// update of a node, are the pods we know are running on the node, so the NRT object can replace the cached info.
+func (ov *OverReserve) isNodeTopologyInSync(lh logr.Logger, nrtCandidate *topologyv1alpha2.NodeResourceTopology, nodeToObjsMap map[string][]podData) bool {
+	nodeName := nrtCandidate.Name
+
+	ov.checkNUMAAccounting(lh, nrtCandidate)
+
+	objs, ok := nodeToObjsMap[nodeName]
+	if !ok {
+		// this really should never happen
+		lh.V(3).Info("cannot find any pod for node")
+		ov.setResyncResult(nodeName, resyncResultMissingPods, nil)
+		return false
+	}
+
+	pfpExpected, onlyExclRes := podFingerprintForNodeTopology(nrtCandidate, ov.resyncMethod)
+	if pfpExpected == "" {
+		lh.V(3).Info("missing NodeTopology podset fingerprint data")
+		ov.setResyncResult(nodeName, resyncResultMissingFingerprint, nil)
+		return false
+	}
+
+	lh.V(6).Info("trying to sync NodeTopology", "fingerprint", pfpExpected, "onlyExclusiveResources", onlyExclRes)
+
+	err := checkPodFingerprintForNode(lh, objs, nodeName, pfpExpected, onlyExclRes)
+	if errors.Is(err, podfingerprint.ErrSignatureMismatch) {
+		// can happen, not critical
+		lh.V(5).Info("NodeTopology podset fingerprint mismatch")
+		ov.setResyncResult(nodeName, resyncResultFingerprintMismatch, err)
+		return false
+	}
+	if err != nil {
+		// should never happen, let's be vocal
+		lh.V(3).Error(err, "checking NodeTopology podset fingerprint")
+		ov.setResyncResult(nodeName, resyncResultError, err)
+		return false
+	}
+
+	ov.setResyncResult(nodeName, resyncResultFlushed, nil)
+	return true
+}
+
 // FlushNodes drops all the cached information about a given node, resetting its state clean.
 func (ov *OverReserve) FlushNodes(lh logr.Logger, nrts ...*topologyv1alpha2.NodeResourceTopology) {
 	ov.lock.Lock()
@@ -279,7 +357,90 @@ func (ov *OverReserve) FlushNodes(lh logr.Logger, nrts ...*topologyv1alpha2.Node
 		delete(ov.assumedResources, nrt.Name)
 		ov.nodesMaybeOverreserved.Delete(nrt.Name)
 		ov.nodesWithForeignPods.Delete(nrt.Name)
+		ov.nodesMispredicted.Delete(nrt.Name)
+		ov.updateNodeMetrics(nrt.Name)
+	}
+}
+
+// DeleteNode drops all the cached information about a node which is gone, including its metrics.
+func (ov *OverReserve) DeleteNode(nodeName string) {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	ov.lh.V(4).Info("deleting", "node", nodeName)
+	ov.nrts.Delete(nodeName)
+	delete(ov.assumedResources, nodeName)
+	ov.nodesMaybeOverreserved.Delete(nodeName)
+	ov.nodesWithForeignPods.Delete(nodeName)
+	ov.nodesMispredicted.Delete(nodeName)
+	delete(ov.resyncResults, nodeName)
+	ov.deleteNodeMetrics(nodeName)
+}
+
+// nodeNeedsResync returns true if the node is dirty, or if the resources of the pods on the node are deducted
+// per NUMA zone, which are the nodes the periodic resync considers.
+func (ov *OverReserve) nodeNeedsResync(nodeName string) bool {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	if ov.nodesWithForeignPods.IsSet(nodeName) || ov.nodesMaybeOverreserved.IsSet(nodeName) {
+		return true
+	}
+	nodeAssumedResources, ok := ov.assumedResources[nodeName]
+	return ok && ov.isAccountedPerNUMA(nodeName) && nodeAssumedResources.HasNUMAData()
+}
+
+// isAccountedPerNUMA returns true if the resources of the pods on the node are deducted per NUMA zone.
+// Needs to be called with the lock held.
+func (ov *OverReserve) isAccountedPerNUMA(nodeName string) bool {
+	return ov.accountingMode == apiconfig.CacheAccountingPerNUMA && !ov.nodesMispredicted.IsSet(nodeName)
+}
+
+// appendNodesAccountedPerNUMA appends to nodeNames the nodes which have resources deducted per NUMA zone
+// and are not in nodeNames yet.
+func (ov *OverReserve) appendNodesAccountedPerNUMA(lh logr.Logger, nodeNames []string) []string {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	known := sets.New(nodeNames...)
+	count := 0
+	for nodeName, nodeAssumedResources := range ov.assumedResources {
+		if known.Has(nodeName) || !ov.isAccountedPerNUMA(nodeName) || !nodeAssumedResources.HasNUMAData() {
+			continue
+		}
+		nodeNames = append(nodeNames, nodeName)
+		count++
 	}
+	if count > 0 {
+		lh.V(4).Info("found nodes accounted per NUMA zone", "count", count)
+	}
+	return nodeNames
+}
+
+// checkNUMAAccounting compares the latest NRT update of a node with the cached NRT data, with the resources
+// of the pods deducted per NUMA zone. If any NUMA zone has less resources available than we expected, the
+// kubelet did not place the pods where we predicted, or something else is running on the node. Either way,
+// we fall back to the pessimistic deduction on all the NUMA zones of the node until it is flushed.
+func (ov *OverReserve) checkNUMAAccounting(lh logr.Logger, nrtCandidate *topologyv1alpha2.NodeResourceTopology) {
+	ov.lock.Lock()
+	defer ov.lock.Unlock()
+	if !ov.isAccountedPerNUMA(nrtCandidate.Name) {
+		return
+	}
+	nodeAssumedResources, ok := ov.assumedResources[nrtCandidate.Name]
+	if !ok || !nodeAssumedResources.HasNUMAData() {
+		return
+	}
+	nrtExpected := ov.nrts.GetNRTCopyByNodeName(nrtCandidate.Name)
+	if nrtExpected == nil {
+		return
+	}
+	nodeAssumedResources.UpdateNRT(logging.TimeLogID(), nrtExpected, true)
+
+	zoneName, resourceName, found := findZoneResourceBelow(nrtCandidate, nrtExpected)
+	if !found {
+		return
+	}
+	val := ov.nodesMispredicted.Incr(nrtCandidate.Name)
+	ov.updateNodeMetrics(nrtCandidate.Name)
+	lh.V(4).Info("NUMA accounting mismatch, falling back to pessimistic accounting", "zone", zoneName, "resource", resourceName, "count", val)
 }
 
 // to be used only in tests
@@ -288,11 +449,33 @@ func (ov *OverReserve) Store() *nrtStore {
 }
 
 func makeNodeToPodDataMap(lh logr.Logger, podLister podlisterv1.PodLister, isPodRelevant podprovider.PodFilterFunc) (map[string][]podData, error) {
-	nodeToObjsMap := make(map[string][]podData)
 	pods, err := podLister.List(labels.Everything())
 	if err != nil {
-		return nodeToObjsMap, err
+		return make(map[string][]podData), err
 	}
+	return podDataByNode(lh, pods, isPodRelevant), nil
+}
+
+// listPodsOnNode lists the pods bound to a node, through the node index of the lister if it has one.
+func listPodsOnNode(podLister podlisterv1.PodLister, nodeName string) ([]*corev1.Pod, error) {
+	if npl, ok := podLister.(podprovider.NodePodLister); ok {
+		return npl.ListOnNode(nodeName)
+	}
+	pods, err := podLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var nodePods []*corev1.Pod
+	for _, pod := range pods {
+		if pod.Spec.NodeName == nodeName {
+			nodePods = append(nodePods, pod)
+		}
+	}
+	return nodePods, nil
+}
+
+func podDataByNode(lh logr.Logger, pods []*corev1.Pod, isPodRelevant podprovider.PodFilterFunc) map[string][]podData {
+	nodeToObjsMap := make(map[string][]podData)
 	for _, pod := range pods {
 		if !isPodRelevant(lh, pod) {
 			continue
@@ -305,7 +488,7 @@ func makeNodeToPodDataMap(lh logr.Logger, podLister podlisterv1.PodLister, isPod
 		})
 		nodeToObjsMap[pod.Spec.NodeName] = nodeObjs
 	}
-	return nodeToObjsMap, nil
+	return nodeToObjsMap
 }
 
 func getCacheResyncMethod(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache) apiconfig.CacheResyncMethod {
@@ -319,4 +502,15 @@ func getCacheResyncMethod(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCac
 	return resyncMethod
 }
 
+func getCacheAccountingMode(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache) apiconfig.CacheAccountingMode {
+	var accountingMode apiconfig.CacheAccountingMode
+	if cfg != nil && cfg.AccountingMode != nil {
+		accountingMode = *cfg.AccountingMode
+	} else { // explicitly set to nil?
+		accountingMode = apiconfig.CacheAccountingPessimistic
+		lh.Info("cache accounting mode missing", "fallback", accountingMode)
+	}
+	return accountingMode
+}
+
 func (ov *OverReserve) PostBind(nodeName string, pod *corev1.Pod) {}
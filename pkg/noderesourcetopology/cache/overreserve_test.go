@@ -97,6 +97,50 @@ func TestGetCacheResyncMethod(t *testing.T) {
 		})
 	}
 }
+func TestGetCacheAccountingMode(t *testing.T) {
+	accountingPessimistic := apiconfig.CacheAccountingPessimistic
+	accountingPerNUMA := apiconfig.CacheAccountingPerNUMA
+
+	testCases := []struct {
+		description string
+		cfg         *apiconfig.NodeResourceTopologyCache
+		expected    apiconfig.CacheAccountingMode
+	}{
+		{
+			description: "nil config",
+			expected:    apiconfig.CacheAccountingPessimistic,
+		},
+		{
+			description: "empty config",
+			cfg:         &apiconfig.NodeResourceTopologyCache{},
+			expected:    apiconfig.CacheAccountingPessimistic,
+		},
+		{
+			description: "explicit pessimistic",
+			cfg: &apiconfig.NodeResourceTopologyCache{
+				AccountingMode: &accountingPessimistic,
+			},
+			expected: apiconfig.CacheAccountingPessimistic,
+		},
+		{
+			description: "explicit per NUMA",
+			cfg: &apiconfig.NodeResourceTopologyCache{
+				AccountingMode: &accountingPerNUMA,
+			},
+			expected: apiconfig.CacheAccountingPerNUMA,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			got := getCacheAccountingMode(klog.Background(), testCase.cfg)
+			if got != testCase.expected {
+				t.Errorf("cache accounting mode got %v expected %v", got, testCase.expected)
+			}
+		})
+	}
+}
+
 func TestInitEmptyLister(t *testing.T) {
 	fakeClient, err := tu.NewFakeClient()
 	if err != nil {
@@ -105,12 +149,12 @@ func TestInitEmptyLister(t *testing.T) {
 
 	fakePodLister := &fakePodLister{}
 
-	_, err = NewOverReserve(klog.Background(), nil, nil, fakePodLister, podprovider.IsPodRelevantAlways)
+	_, err = NewOverReserve(klog.Background(), "", nil, nil, fakePodLister, podprovider.IsPodRelevantAlways)
 	if err == nil {
 		t.Fatalf("accepted nil lister")
 	}
 
-	_, err = NewOverReserve(klog.Background(), nil, fakeClient, nil, podprovider.IsPodRelevantAlways)
+	_, err = NewOverReserve(klog.Background(), "", nil, fakeClient, nil, podprovider.IsPodRelevantAlways)
 	if err == nil {
 		t.Fatalf("accepted nil indexer")
 	}
@@ -147,7 +191,7 @@ func TestDirtyNodesMarkDiscarded(t *testing.T) {
 	}
 
 	for _, nodeName := range expectedNodes {
-		nrtCache.ReserveNodeResources(nodeName, &corev1.Pod{})
+		nrtCache.ReserveNodeResources(nodeName, &corev1.Pod{}, nil)
 	}
 
 	dirtyNodes := nrtCache.NodesMaybeOverReserved(klog.Background())
@@ -183,7 +227,7 @@ func TestDirtyNodesUnmarkedOnReserve(t *testing.T) {
 	}
 
 	for _, nodeName := range availNodes {
-		nrtCache.ReserveNodeResources(nodeName, &corev1.Pod{})
+		nrtCache.ReserveNodeResources(nodeName, &corev1.Pod{}, nil)
 	}
 
 	dirtyNodes := nrtCache.NodesMaybeOverReserved(klog.Background())
@@ -196,7 +240,7 @@ func TestDirtyNodesUnmarkedOnReserve(t *testing.T) {
 	}
 
 	// assume noe update which unblocks node-4
-	nrtCache.ReserveNodeResources("node-4", &corev1.Pod{})
+	nrtCache.ReserveNodeResources("node-4", &corev1.Pod{}, nil)
 
 	expectedNodes := []string{
 		"node-1",
@@ -229,7 +273,7 @@ func TestOverreserveGetCachedNRTCopy(t *testing.T) {
 	checkGetCachedNRTCopy(
 		t,
 		func(client ctrlclient.Client, podLister podlisterv1.PodLister) (Interface, error) {
-			return NewOverReserve(klog.Background(), nil, client, podLister, podprovider.IsPodRelevantAlways)
+			return NewOverReserve(klog.Background(), "", nil, client, podLister, podprovider.IsPodRelevantAlways)
 		},
 		testCases...,
 	)
@@ -268,7 +312,7 @@ func TestGetCachedNRTCopyReserve(t *testing.T) {
 			},
 		},
 	}
-	nrtCache.ReserveNodeResources("node1", testPod)
+	nrtCache.ReserveNodeResources("node1", testPod, nil)
 
 	nrtObj, _ := nrtCache.GetCachedNRTCopy(context.Background(), "node1", testPod)
 	for _, zone := range nrtObj.Zones {
@@ -287,6 +331,173 @@ func TestGetCachedNRTCopyReserve(t *testing.T) {
 	}
 }
 
+func TestGetCachedNRTCopyReservePerNUMA(t *testing.T) {
+	testCases := []struct {
+		description    string
+		accountingMode apiconfig.CacheAccountingMode
+		numaResources  NUMAResources
+		expectedZone0  []string
+		expectedZone1  []string
+	}{
+		{
+			description:    "pessimistic",
+			accountingMode: apiconfig.CacheAccountingPessimistic,
+			numaResources: NUMAResources{
+				1: {corev1.ResourceCPU: resource.MustParse("8"), corev1.ResourceMemory: resource.MustParse("16Gi")},
+			},
+			expectedZone0: []string{"22", "44Gi"},
+			expectedZone1: []string{"22", "44Gi"},
+		},
+		{
+			description:    "per NUMA",
+			accountingMode: apiconfig.CacheAccountingPerNUMA,
+			numaResources: NUMAResources{
+				1: {corev1.ResourceCPU: resource.MustParse("8"), corev1.ResourceMemory: resource.MustParse("16Gi")},
+			},
+			expectedZone0: []string{"30", "60Gi"},
+			expectedZone1: []string{"22", "44Gi"},
+		},
+		{
+			description:    "per NUMA, but unknown NUMA zones",
+			accountingMode: apiconfig.CacheAccountingPerNUMA,
+			expectedZone0:  []string{"22", "44Gi"},
+			expectedZone1:  []string{"22", "44Gi"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			fakeClient, err := tu.NewFakeClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			nrtCache := mustOverReserveWithAccountingMode(t, fakeClient, &fakePodLister{}, testCase.accountingMode)
+			for _, obj := range makeDefaultTestTopology() {
+				nrtCache.Store().Update(obj)
+			}
+
+			testPod := makeTestPod("namespace1", "pod1", "8", "16Gi")
+			nrtCache.ReserveNodeResources("node1", testPod, testCase.numaResources)
+
+			nrtObj, _ := nrtCache.GetCachedNRTCopy(context.Background(), "node1", testPod)
+			expectZoneAvailable(t, nrtObj, "node-0", testCase.expectedZone0[0], testCase.expectedZone0[1])
+			expectZoneAvailable(t, nrtObj, "node-1", testCase.expectedZone1[0], testCase.expectedZone1[1])
+		})
+	}
+}
+
+func TestResyncPerNUMAMismatch(t *testing.T) {
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakePodLister := &fakePodLister{}
+
+	nrtCache := mustOverReserveWithAccountingMode(t, fakeClient, fakePodLister, apiconfig.CacheAccountingPerNUMA)
+	for _, obj := range makeDefaultTestTopology() {
+		nrtCache.Store().Update(obj)
+	}
+
+	testPod := makeTestPod("namespace1", "pod1", "8", "16Gi")
+	testPod.Spec.NodeName = "node1"
+	nrtCache.ReserveNodeResources("node1", testPod, NUMAResources{
+		1: {corev1.ResourceCPU: resource.MustParse("8"), corev1.ResourceMemory: resource.MustParse("16Gi")},
+	})
+
+	nrtObj, _ := nrtCache.GetCachedNRTCopy(context.Background(), "node1", testPod)
+	expectZoneAvailable(t, nrtObj, "node-0", "30", "60Gi")
+	expectZoneAvailable(t, nrtObj, "node-1", "22", "44Gi")
+
+	// the kubelet placed the pod on the other NUMA zone, and reported it before it reported all the pods
+	reportedNodeTopology := makeDefaultTestTopology()[0]
+	reportedNodeTopology.Attributes = topologyv1alpha2.AttributeList{
+		{
+			Name:  podfingerprint.Attribute,
+			Value: "pfp0v001badbadbadbadbad0",
+		},
+	}
+	reportedNodeTopology.Zones[0].Resources = topologyv1alpha2.ResourceInfoList{
+		MakeTopologyResInfo(cpu, "32", "22"),
+		MakeTopologyResInfo(memory, "64Gi", "44Gi"),
+		MakeTopologyResInfo(nicResourceName, "16", "16"),
+	}
+	if err := fakeClient.Create(context.Background(), reportedNodeTopology); err != nil {
+		t.Fatal(err)
+	}
+	runningPod := testPod.DeepCopy()
+	runningPod.Status.Phase = corev1.PodRunning
+	fakePodLister.AddPod(runningPod)
+
+	nrtCache.Resync()
+
+	// the fingerprint does not match, so the node is not flushed, but the pod is now deducted from all the zones
+	nrtObj, _ = nrtCache.GetCachedNRTCopy(context.Background(), "node1", testPod)
+	expectZoneAvailable(t, nrtObj, "node-0", "22", "44Gi")
+	expectZoneAvailable(t, nrtObj, "node-1", "22", "44Gi")
+
+	// until the node is flushed
+	nrtCache.FlushNodes(klog.Background(), makeDefaultTestTopology()...)
+	nrtCache.ReserveNodeResources("node1", testPod, NUMAResources{
+		1: {corev1.ResourceCPU: resource.MustParse("8"), corev1.ResourceMemory: resource.MustParse("16Gi")},
+	})
+	nrtObj, _ = nrtCache.GetCachedNRTCopy(context.Background(), "node1", testPod)
+	expectZoneAvailable(t, nrtObj, "node-0", "30", "60Gi")
+	expectZoneAvailable(t, nrtObj, "node-1", "22", "44Gi")
+}
+
+func mustOverReserveWithAccountingMode(t *testing.T, client ctrlclient.Client, podLister podlisterv1.PodLister, accountingMode apiconfig.CacheAccountingMode) *OverReserve {
+	cfg := &apiconfig.NodeResourceTopologyCache{
+		AccountingMode: &accountingMode,
+	}
+	obj, err := NewOverReserve(klog.Background(), "", cfg, client, podLister, podprovider.IsPodRelevantAlways)
+	if err != nil {
+		t.Fatalf("unexpected error creating cache: %v", err)
+	}
+	return obj
+}
+
+func makeTestPod(namespace, name, cpuQty, memoryQty string) *corev1.Pod {
+	res := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpuQty),
+		corev1.ResourceMemory: resource.MustParse(memoryQty),
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Resources: corev1.ResourceRequirements{
+						Limits:   res,
+						Requests: res.DeepCopy(),
+					},
+				},
+			},
+		},
+	}
+}
+
+func expectZoneAvailable(t *testing.T, nrt *topologyv1alpha2.NodeResourceTopology, zoneName, cpuQty, memoryQty string) {
+	t.Helper()
+	for _, zone := range nrt.Zones {
+		if zone.Name != zoneName {
+			continue
+		}
+		if got := findResourceInfo(zone.Resources, cpu).Available; got.Cmp(resource.MustParse(cpuQty)) != 0 {
+			t.Errorf("bad availability for resource %q on zone %q: expected %v got %v", cpu, zoneName, cpuQty, got.String())
+		}
+		if got := findResourceInfo(zone.Resources, memory).Available; got.Cmp(resource.MustParse(memoryQty)) != 0 {
+			t.Errorf("bad availability for resource %q on zone %q: expected %v got %v", memory, zoneName, memoryQty, got.String())
+		}
+		return
+	}
+	t.Errorf("missing zone %q", zoneName)
+}
+
 func TestGetCachedNRTCopyReleaseNone(t *testing.T) {
 	fakeClient, err := tu.NewFakeClient()
 	if err != nil {
@@ -361,7 +572,7 @@ func TestGetCachedNRTCopyReserveRelease(t *testing.T) {
 			},
 		},
 	}
-	nrtCache.ReserveNodeResources("node1", testPod)
+	nrtCache.ReserveNodeResources("node1", testPod, nil)
 	nrtCache.UnreserveNodeResources("node1", testPod)
 
 	nrtObj, _ := nrtCache.GetCachedNRTCopy(context.Background(), "node1", testPod)
@@ -404,7 +615,7 @@ func TestFlush(t *testing.T) {
 		},
 	}
 
-	nrtCache.ReserveNodeResources("node1", testPod)
+	nrtCache.ReserveNodeResources("node1", testPod, nil)
 	nrtCache.NodeMaybeOverReserved("node1", testPod)
 
 	expectedNodeTopology := &topologyv1alpha2.NodeResourceTopology{
@@ -484,7 +695,7 @@ func TestResyncNoPodFingerprint(t *testing.T) {
 			},
 		},
 	}
-	nrtCache.ReserveNodeResources("node1", testPod)
+	nrtCache.ReserveNodeResources("node1", testPod, nil)
 	nrtCache.NodeMaybeOverReserved("node1", testPod)
 
 	expectedNodeTopology := &topologyv1alpha2.NodeResourceTopology{
@@ -563,7 +774,7 @@ func TestResyncMatchFingerprint(t *testing.T) {
 			},
 		},
 	}
-	nrtCache.ReserveNodeResources("node1", testPod)
+	nrtCache.ReserveNodeResources("node1", testPod, nil)
 	nrtCache.NodeMaybeOverReserved("node1", testPod)
 
 	expectedNodeTopology := &topologyv1alpha2.NodeResourceTopology{
@@ -727,7 +938,7 @@ func TestNodeWithForeignPods(t *testing.T) {
 }
 
 func mustOverReserve(t *testing.T, client ctrlclient.Client, podLister podlisterv1.PodLister) *OverReserve {
-	obj, err := NewOverReserve(klog.Background(), nil, client, podLister, podprovider.IsPodRelevantAlways)
+	obj, err := NewOverReserve(klog.Background(), "", nil, client, podLister, podprovider.IsPodRelevantAlways)
 	if err != nil {
 		t.Fatalf("unexpected error creating cache: %v", err)
 	}
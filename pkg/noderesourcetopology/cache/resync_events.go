@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8scache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewNodeTopologyInformer creates an informer watching the NRT objects through the given client.
+func NewNodeTopologyInformer(client ctrlclient.WithWatch) k8scache.SharedIndexInformer {
+	lw := &k8scache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			nrtObjs := &topologyv1alpha2.NodeResourceTopologyList{}
+			err := client.List(context.Background(), nrtObjs, &ctrlclient.ListOptions{Raw: &options})
+			return nrtObjs, err
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.Watch(context.Background(), &topologyv1alpha2.NodeResourceTopologyList{}, &ctrlclient.ListOptions{Raw: &options})
+		},
+	}
+	return k8scache.NewSharedIndexInformer(lw, &topologyv1alpha2.NodeResourceTopology{}, 0, k8scache.Indexers{})
+}
+
+var (
+	sharedNRTInformerLock sync.Mutex
+	sharedNRTInformer     k8scache.SharedIndexInformer
+)
+
+// SharedNodeTopologyInformer returns the NRT informer shared by the caches of all the scheduler profiles.
+// The informer is created and run on first use, until ctx is done.
+func SharedNodeTopologyInformer(ctx context.Context, client ctrlclient.WithWatch) k8scache.SharedIndexInformer {
+	sharedNRTInformerLock.Lock()
+	defer sharedNRTInformerLock.Unlock()
+	if sharedNRTInformer != nil {
+		return sharedNRTInformer
+	}
+
+	nrtInformer := NewNodeTopologyInformer(client)
+	go func() {
+		nrtInformer.Run(ctx.Done())
+
+		sharedNRTInformerLock.Lock()
+		defer sharedNRTInformerLock.Unlock()
+		if sharedNRTInformer == nrtInformer {
+			sharedNRTInformer = nil
+		}
+	}()
+	sharedNRTInformer = nrtInformer
+	return nrtInformer
+}
+
+// SetupNodeTopologyResyncer makes the cache try to resync the dirty nodes as soon as their NRT objects change,
+// without waiting for the periodic resync, which is still needed to recover from missed or failed attempts.
+// The changes of the NRT object of a node happening within the debounce period are coalesced in one attempt.
+func SetupNodeTopologyResyncer(ctx context.Context, lh logr.Logger, nrtInformer k8scache.SharedInformer, ov *OverReserve, debounce time.Duration) {
+	queue := workqueue.NewDelayingQueueWithConfig(workqueue.DelayingQueueConfig{Name: "nrtcache-resync"})
+
+	resyncLater := func(obj interface{}) {
+		nrt, ok := obj.(*topologyv1alpha2.NodeResourceTopology)
+		if !ok {
+			lh.V(3).Info("unsupported object", "kind", fmt.Sprintf("%T", obj))
+			return
+		}
+		if !ov.nodeNeedsResync(nrt.Name) {
+			return
+		}
+		lh.V(6).Info("NodeTopology changed, resync requested", "node", nrt.Name, "debounce", debounce)
+		queue.AddAfter(nrt.Name, debounce)
+	}
+
+	nrtInformer.AddEventHandler(k8scache.ResourceEventHandlerFuncs{
+		AddFunc: resyncLater,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			resyncLater(newObj)
+		},
+	})
+
+	go func() {
+		<-ctx.Done()
+		queue.ShutDown()
+	}()
+
+	go func() {
+		for processNextNodeResync(lh, queue, nrtInformer.GetStore(), ov) {
+		}
+	}()
+}
+
+// processNextNodeResync resyncs the next node in the queue with its latest NRT object.
+// Returns false once the queue is shut down.
+func processNextNodeResync(lh logr.Logger, queue workqueue.DelayingInterface, store k8scache.Store, ov *OverReserve) bool {
+	item, shutdown := queue.Get()
+	if shutdown {
+		return false
+	}
+	defer queue.Done(item)
+
+	nodeName := item.(string)
+	obj, exists, err := store.GetByKey(nodeName)
+	if err != nil || !exists {
+		lh.V(3).Info("missing NodeTopology", "node", nodeName, "error", err)
+		return true
+	}
+	ov.ResyncNode(obj.(*topologyv1alpha2.NodeResourceTopology))
+	return true
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"github.com/k8stopologyawareschedwg/podfingerprint"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestNodeTopologyResyncer(t *testing.T) {
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fakePodLister := &fakePodLister{}
+
+	nrtCache := mustOverReserve(t, fakeClient, fakePodLister)
+	for _, obj := range makeDefaultTestTopology() {
+		nrtCache.Store().Update(obj)
+	}
+
+	reportedNodeTopology := makeDefaultTestTopology()[0]
+	reportedNodeTopology.Attributes = topologyv1alpha2.AttributeList{
+		{
+			Name:  podfingerprint.Attribute,
+			Value: "pfp0v001badbadbadbadbad0",
+		},
+	}
+	if err := fakeClient.Create(context.Background(), reportedNodeTopology); err != nil {
+		t.Fatal(err)
+	}
+
+	foreignPod := makeTestPod("namespace1", "pod1", "8", "16Gi")
+	foreignPod.Spec.NodeName = "node1"
+	foreignPod.Status.Phase = corev1.PodRunning
+	fakePodLister.AddPod(foreignPod)
+	nrtCache.NodeHasForeignPods("node1", foreignPod)
+
+	if nrtObj, ok := nrtCache.GetCachedNRTCopy(context.Background(), "node1", foreignPod); nrtObj != nil || ok {
+		t.Fatalf("node with foreign pods should not be usable until resynced")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nrtInformer := NewNodeTopologyInformer(fakeClient.(ctrlclient.WithWatch))
+	SetupNodeTopologyResyncer(ctx, nrtCache.lh, nrtInformer, nrtCache, 10*time.Millisecond)
+	go nrtInformer.Run(ctx.Done())
+
+	// the podset fingerprint reported in the initial NRT object does not match, so the node stays dirty
+	err = wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		res := nrtCache.Dump().Nodes["node1"].LastResync
+		return res != nil && res.Result == resyncResultFingerprintMismatch, nil
+	})
+	if err != nil {
+		t.Fatalf("node not resynced after the NRT object was added: %v", err)
+	}
+	if !nrtCache.Dump().Nodes["node1"].ForeignPods {
+		t.Fatalf("node flushed despite the podset fingerprint mismatch")
+	}
+
+	pfp := podfingerprint.NewFingerprint(1)
+	pfp.Add(foreignPod.Namespace, foreignPod.Name)
+
+	updatedNodeTopology := &topologyv1alpha2.NodeResourceTopology{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: "node1"}, updatedNodeTopology); err != nil {
+		t.Fatal(err)
+	}
+	updatedNodeTopology.Attributes[0].Value = pfp.Sign()
+	if err := fakeClient.Update(context.Background(), updatedNodeTopology); err != nil {
+		t.Fatal(err)
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(ctx context.Context) (bool, error) {
+		nrtObj, ok := nrtCache.GetCachedNRTCopy(ctx, "node1", foreignPod)
+		return nrtObj != nil && ok, nil
+	})
+	if err != nil {
+		t.Fatalf("node not resynced after the NRT object was updated: %v", err)
+	}
+	if res := nrtCache.Dump().Nodes["node1"].LastResync; res == nil || res.Result != resyncResultFlushed {
+		t.Errorf("unexpected last resync result: %+v", res)
+	}
+}
+
+func TestResyncNodeNotDirty(t *testing.T) {
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nrtCache := mustOverReserve(t, fakeClient, &fakePodLister{})
+	for _, obj := range makeDefaultTestTopology() {
+		nrtCache.Store().Update(obj)
+	}
+
+	testPod := makeTestPod("namespace1", "pod1", "8", "16Gi")
+	nrtCache.ReserveNodeResources("node1", testPod, nil)
+
+	nrtCache.ResyncNode(makeDefaultTestTopology()[0])
+
+	nodeDump := nrtCache.Dump().Nodes["node1"]
+	if nodeDump.LastResync != nil {
+		t.Errorf("unexpected resync attempt of a node not dirty: %+v", nodeDump.LastResync)
+	}
+	if len(nodeDump.AssumedResources) != 1 {
+		t.Errorf("unexpected assumed resources: %v", nodeDump.AssumedResources)
+	}
+}
+
+// nodeIndexedPodLister fails to list all the pods, so only the pods of a node can be listed.
+type nodeIndexedPodLister struct {
+	*fakePodLister
+}
+
+func (nipl nodeIndexedPodLister) List(selector labels.Selector) ([]*corev1.Pod, error) {
+	return nil, fmt.Errorf("listing all the pods")
+}
+
+func (nipl nodeIndexedPodLister) ListOnNode(nodeName string) ([]*corev1.Pod, error) {
+	var pods []*corev1.Pod
+	for _, pod := range nipl.pods {
+		if pod.Spec.NodeName == nodeName {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+func TestResyncNodeListsOnlyNodePods(t *testing.T) {
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	podLister := nodeIndexedPodLister{fakePodLister: &fakePodLister{}}
+	nrtCache := mustOverReserve(t, fakeClient, podLister)
+	for _, obj := range makeDefaultTestTopology() {
+		nrtCache.Store().Update(obj)
+	}
+
+	for _, nodeName := range []string{"node1", "node2"} {
+		pod := makeTestPod("namespace1", "pod-"+nodeName, "8", "16Gi")
+		pod.Spec.NodeName = nodeName
+		pod.Status.Phase = corev1.PodRunning
+		podLister.AddPod(pod)
+	}
+	nrtCache.NodeHasForeignPods("node1", podLister.pods[0])
+
+	pfp := podfingerprint.NewFingerprint(1)
+	pfp.Add("namespace1", "pod-node1")
+	reportedNodeTopology := makeDefaultTestTopology()[0]
+	reportedNodeTopology.Attributes = topologyv1alpha2.AttributeList{
+		{
+			Name:  podfingerprint.Attribute,
+			Value: pfp.Sign(),
+		},
+	}
+
+	nrtCache.ResyncNode(reportedNodeTopology)
+
+	if res := nrtCache.Dump().Nodes["node1"].LastResync; res == nil || res.Result != resyncResultFlushed {
+		t.Errorf("unexpected last resync result: %+v", res)
+	}
+}
+
+func TestSharedNodeTopologyInformer(t *testing.T) {
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := fakeClient.(ctrlclient.WithWatch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	nrtInformer := SharedNodeTopologyInformer(ctx, client)
+	if got := SharedNodeTopologyInformer(ctx, client); got != nrtInformer {
+		t.Errorf("the informer is not shared")
+	}
+
+	cancel()
+	err = wait.PollUntilContextTimeout(context.Background(), 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		sharedNRTInformerLock.Lock()
+		defer sharedNRTInformerLock.Unlock()
+		return sharedNRTInformer == nil, nil
+	})
+	if err != nil {
+		t.Fatalf("the stopped informer is still shared: %v", err)
+	}
+}
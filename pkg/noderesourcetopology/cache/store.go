@@ -25,6 +25,7 @@ import (
 	"github.com/go-logr/logr"
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
 	topologyv1alpha2attr "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper/attribute"
+	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper/numanode"
 	"github.com/k8stopologyawareschedwg/podfingerprint"
 
 	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
@@ -74,17 +75,26 @@ func (nrs *nrtStore) Update(nrt *topologyv1alpha2.NodeResourceTopology) {
 	nrs.lh.V(5).Info("updated cached NodeTopology", "node", nrt.Name)
 }
 
+// Delete forgets the Node Resource Topology associated to a node.
+func (nrs *nrtStore) Delete(nodeName string) {
+	delete(nrs.data, nodeName)
+	nrs.lh.V(5).Info("deleted cached NodeTopology", "node", nodeName)
+}
+
 // resourceStore maps the resource requested by pod by pod namespaed name. It is not thread safe and needs to be protected by a lock.
 type resourceStore struct {
 	// key: namespace + "/" name
 	data map[string]corev1.ResourceList
-	lh   logr.Logger
+	// numaData holds the resources each pod is expected to take from each NUMA zone, if known. Same keys as data.
+	numaData map[string]NUMAResources
+	lh       logr.Logger
 }
 
 func newResourceStore(lh logr.Logger) *resourceStore {
 	return &resourceStore{
-		data: make(map[string]corev1.ResourceList),
-		lh:   lh,
+		data:     make(map[string]corev1.ResourceList),
+		numaData: make(map[string]NUMAResources),
+		lh:       lh,
 	}
 }
 
@@ -96,8 +106,9 @@ func (rs *resourceStore) String() string {
 	return sb.String()
 }
 
-// AddPod returns true if updating existing pod, false if adding for the first time
-func (rs *resourceStore) AddPod(pod *corev1.Pod) bool {
+// AddPod returns true if updating existing pod, false if adding for the first time.
+// numaResources are the resources the pod is expected to take from each NUMA zone, or nil if unknown.
+func (rs *resourceStore) AddPod(pod *corev1.Pod, numaResources NUMAResources) bool {
 	key := pod.Namespace + "/" + pod.Name
 	_, ok := rs.data[key]
 	if ok {
@@ -107,6 +118,11 @@ func (rs *resourceStore) AddPod(pod *corev1.Pod) bool {
 	resData := util.GetPodEffectiveRequest(pod)
 	rs.lh.V(5).Info("resourcestore ADD", stringify.ResourceListToLoggable(resData)...)
 	rs.data[key] = resData
+	if numaResources != nil {
+		rs.numaData[key] = numaResources
+	} else {
+		delete(rs.numaData, key)
+	}
 	return ok
 }
 
@@ -120,41 +136,103 @@ func (rs *resourceStore) DeletePod(pod *corev1.Pod) bool {
 	}
 	rs.lh.V(5).Info("resourcestore DEL", stringify.ResourceListToLoggable(rs.data[key])...)
 	delete(rs.data, key)
+	delete(rs.numaData, key)
 	return ok
 }
 
-// UpdateNRT updates the provided Node Resource Topology object with the resources tracked in this store,
-// performing pessimistic overallocation across all the NUMA zones.
-func (rs *resourceStore) UpdateNRT(logID string, nrt *topologyv1alpha2.NodeResourceTopology) {
+// Copy returns copies of the resources tracked in this store, by pod namespace/name.
+func (rs *resourceStore) Copy() (map[string]corev1.ResourceList, map[string]NUMAResources) {
+	data := make(map[string]corev1.ResourceList, len(rs.data))
+	for key, res := range rs.data {
+		data[key] = res.DeepCopy()
+	}
+	numaData := make(map[string]NUMAResources, len(rs.numaData))
+	for key, numaRes := range rs.numaData {
+		numaResCopy := make(NUMAResources, len(numaRes))
+		for numaID, res := range numaRes {
+			numaResCopy[numaID] = res.DeepCopy()
+		}
+		numaData[key] = numaResCopy
+	}
+	return data, numaData
+}
+
+// HasNUMAData returns true if the resources of at least one pod are tracked per NUMA zone.
+func (rs *resourceStore) HasNUMAData() bool {
+	return len(rs.numaData) > 0
+}
+
+// UpdateNRT updates the provided Node Resource Topology object with the resources tracked in this store.
+// If perNUMA is false, or for the pods whose NUMA zones are unknown, it performs pessimistic overallocation
+// across all the NUMA zones. Otherwise, it deducts the resources of the pods only from the NUMA zones they
+// are expected to take them from.
+func (rs *resourceStore) UpdateNRT(logID string, nrt *topologyv1alpha2.NodeResourceTopology, perNUMA bool) {
 	for key, res := range rs.data {
+		numaRes, ok := rs.numaData[key]
+		if perNUMA && ok {
+			for zi := 0; zi < len(nrt.Zones); zi++ {
+				zone := &nrt.Zones[zi] // shortcut
+				numaID, err := numanode.NameToID(zone.Name)
+				if err != nil {
+					// not a NUMA zone, nothing was predicted for it
+					continue
+				}
+				rs.deductFromZone(logID, nrt.Name, zone, key, numaRes[numaID])
+			}
+			continue
+		}
+
 		// We cannot predict on which Zone the workload will be placed.
 		// And we should totally not guess. So the only safe (and conservative)
 		// choice is to decrement the available resources from *all* the zones.
 		// This can cause false negatives, but will never cause false positives,
 		// which are much worse.
 		for zi := 0; zi < len(nrt.Zones); zi++ {
-			zone := &nrt.Zones[zi] // shortcut
-			for ri := 0; ri < len(zone.Resources); ri++ {
-				zr := &zone.Resources[ri] // shortcut
-				qty, ok := res[corev1.ResourceName(zr.Name)]
-				if !ok {
-					// this is benign; it is totally possible some resources are not
-					// available on some zones (think PCI devices), hence we don't
-					// even report this error, being an expected condition
-					continue
-				}
-				if zr.Available.Cmp(qty) < 0 {
-					// this should happen rarely, and it is likely caused by
-					// a bug elsewhere.
-					rs.lh.V(3).Info("cannot decrement resource", "logID", logID, "zone", zr.Name, "node", nrt.Name, "available", zr.Available, "requestor", key, "quantity", qty.String())
-					zr.Available = resource.Quantity{}
-					continue
-				}
+			rs.deductFromZone(logID, nrt.Name, &nrt.Zones[zi], key, res)
+		}
+	}
+}
+
+func (rs *resourceStore) deductFromZone(logID, nodeName string, zone *topologyv1alpha2.Zone, key string, res corev1.ResourceList) {
+	for ri := 0; ri < len(zone.Resources); ri++ {
+		zr := &zone.Resources[ri] // shortcut
+		qty, ok := res[corev1.ResourceName(zr.Name)]
+		if !ok {
+			// this is benign; it is totally possible some resources are not
+			// available on some zones (think PCI devices), hence we don't
+			// even report this error, being an expected condition
+			continue
+		}
+		if zr.Available.Cmp(qty) < 0 {
+			// this should happen rarely, and it is likely caused by
+			// a bug elsewhere.
+			rs.lh.V(3).Info("cannot decrement resource", "logID", logID, "zone", zr.Name, "node", nodeName, "available", zr.Available, "requestor", key, "quantity", qty.String())
+			zr.Available = resource.Quantity{}
+			continue
+		}
 
-				zr.Available.Sub(qty)
+		zr.Available.Sub(qty)
+	}
+}
+
+// findZoneResourceBelow returns the name of a zone and of a resource which are available in nrt
+// in a lesser quantity than in expected, if any.
+func findZoneResourceBelow(nrt, expected *topologyv1alpha2.NodeResourceTopology) (string, string, bool) {
+	for _, zone := range nrt.Zones {
+		for _, expectedZone := range expected.Zones {
+			if expectedZone.Name != zone.Name {
+				continue
+			}
+			for _, zr := range zone.Resources {
+				for _, expectedZr := range expectedZone.Resources {
+					if expectedZr.Name == zr.Name && zr.Available.Cmp(expectedZr.Available) < 0 {
+						return zone.Name, zr.Name, true
+					}
+				}
 			}
 		}
 	}
+	return "", "", false
 }
 
 type counter map[string]int
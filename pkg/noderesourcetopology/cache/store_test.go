@@ -411,11 +411,11 @@ func TestResourceStoreAddPod(t *testing.T) {
 	}
 
 	rs := newResourceStore(klog.Background())
-	existed := rs.AddPod(&pod)
+	existed := rs.AddPod(&pod, nil)
 	if existed {
 		t.Fatalf("replaced a pod into a empty resourceStore")
 	}
-	existed = rs.AddPod(&pod)
+	existed = rs.AddPod(&pod, nil)
 	if !existed {
 		t.Fatalf("added pod twice")
 	}
@@ -447,7 +447,7 @@ func TestResourceStoreDeletePod(t *testing.T) {
 	if existed {
 		t.Fatalf("deleted a pod into a empty resourceStore")
 	}
-	rs.AddPod(&pod)
+	rs.AddPod(&pod, nil)
 	existed = rs.DeletePod(&pod)
 	if !existed {
 		t.Fatalf("deleted a pod which was not supposed to be present")
@@ -510,13 +510,13 @@ func TestResourceStoreUpdate(t *testing.T) {
 	}
 
 	rs := newResourceStore(klog.Background())
-	existed := rs.AddPod(&pod)
+	existed := rs.AddPod(&pod, nil)
 	if existed {
 		t.Fatalf("replacing a pod into a empty resourceStore")
 	}
 
 	logID := "testResourceStoreUpdate"
-	rs.UpdateNRT(logID, nrt)
+	rs.UpdateNRT(logID, nrt, false)
 
 	cpuInfo0 := findResourceInfo(nrt.Zones[0].Resources, cpu)
 	if cpuInfo0.Capacity.Cmp(resource.MustParse("20")) != 0 {
@@ -565,6 +565,93 @@ func TestResourceStoreUpdate(t *testing.T) {
 	}
 }
 
+func TestResourceStoreUpdatePerNUMA(t *testing.T) {
+	nrt := &topologyv1alpha2.NodeResourceTopology{
+		ObjectMeta:       metav1.ObjectMeta{Name: "node"},
+		TopologyPolicies: []string{string(topologyv1alpha2.SingleNUMANodePodLevel)},
+		Zones: topologyv1alpha2.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "20", "20"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+					MakeTopologyResInfo(nicName, "8", "8"),
+				},
+			},
+		},
+	}
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns-0",
+			Name:      "pod-0",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "cnt-0",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:           resource.MustParse("16"),
+							corev1.ResourceMemory:        resource.MustParse("4Gi"),
+							corev1.ResourceName(nicName): resource.MustParse("2"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rs := newResourceStore(klog.Background())
+	rs.AddPod(&pod, NUMAResources{
+		1: {
+			corev1.ResourceCPU:           resource.MustParse("16"),
+			corev1.ResourceMemory:        resource.MustParse("4Gi"),
+			corev1.ResourceName(nicName): resource.MustParse("2"),
+		},
+	})
+	if !rs.HasNUMAData() {
+		t.Fatalf("missing NUMA data after adding a pod with NUMA resources")
+	}
+
+	expected := map[bool][]string{
+		// perNUMA: cpu and memory available on zone 0, then on zone 1
+		false: {"4", "28Gi", "4", "28Gi"},
+		true:  {"20", "32Gi", "4", "28Gi"},
+	}
+	for _, perNUMA := range []bool{false, true} {
+		nrtUpd := nrt.DeepCopy()
+		rs.UpdateNRT("testResourceStoreUpdatePerNUMA", nrtUpd, perNUMA)
+
+		want := expected[perNUMA]
+		for zi, zone := range nrtUpd.Zones {
+			if got := findResourceInfo(zone.Resources, cpu).Available; got.Cmp(resource.MustParse(want[2*zi])) != 0 {
+				t.Errorf("perNUMA=%v: bad availability for resource %q on zone %d: expected %v got %v", perNUMA, cpu, zi, want[2*zi], got.String())
+			}
+			if got := findResourceInfo(zone.Resources, memory).Available; got.Cmp(resource.MustParse(want[2*zi+1])) != 0 {
+				t.Errorf("perNUMA=%v: bad availability for resource %q on zone %d: expected %v got %v", perNUMA, memory, zi, want[2*zi+1], got.String())
+			}
+		}
+		if got := findResourceInfo(nrtUpd.Zones[1].Resources, nicName).Available; got.Cmp(resource.MustParse("6")) != 0 {
+			t.Errorf("perNUMA=%v: bad availability for resource %q on zone %d: expected %v got %v", perNUMA, nicName, 1, "6", got.String())
+		}
+	}
+
+	rs.DeletePod(&pod)
+	if rs.HasNUMAData() {
+		t.Errorf("unexpected NUMA data after deleting the pod")
+	}
+}
+
 func TestCheckPodFingerprintForNode(t *testing.T) {
 	tcases := []struct {
 		description string
@@ -29,6 +29,8 @@ import (
 
 	"github.com/go-logr/logr"
 	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	"github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2/helper/numanode"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/resourcerequests"
 	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/stringify"
@@ -41,12 +43,13 @@ const highestNUMAID = 8
 
 type PolicyHandler func(pod *v1.Pod, zoneMap topologyv1alpha2.ZoneList) *framework.Status
 
-func singleNUMAContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+func singleNUMAContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) (nrtcache.NUMAResources, *framework.Status) {
 	lh.V(5).Info("container level single NUMA node handler")
 
 	// prepare NUMANodes list from zoneMap
 	nodes := createNUMANodeList(lh, zones)
 	qos := v1qos.GetPodQOS(pod)
+	numaResources := nrtcache.NUMAResources{}
 
 	// Node() != nil already verified in Filter(), which is the only public entry point
 	logNumaNodes(lh, "container handler NUMA resources", nodeInfo.Node().Name, nodes)
@@ -61,7 +64,7 @@ func singleNUMAContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topology
 		if !match {
 			// we can't align init container, so definitely we can't align a pod
 			lh.V(2).Info("cannot align container", "name", initContainer.Name, "kind", "init")
-			return framework.NewStatus(framework.Unschedulable, "cannot align init container")
+			return nil, framework.NewStatus(framework.Unschedulable, "cannot align init container")
 		}
 	}
 
@@ -73,14 +76,15 @@ func singleNUMAContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topology
 		if !match {
 			// we can't align container, so definitely we can't align a pod
 			lh.V(2).Info("cannot align container", "name", container.Name, "kind", "app")
-			return framework.NewStatus(framework.Unschedulable, "cannot align container")
+			return nil, framework.NewStatus(framework.Unschedulable, "cannot align container")
 		}
 
 		// subtract the resources requested by the container from the given NUMA.
 		// this is necessary, so we won't allocate the same resources for the upcoming containers
 		subtractFromNUMA(lh, nodes, numaID, container)
+		addNUMAResources(numaResources, container.Resources.Requests, numaID)
 	}
-	return nil
+	return numaResources, nil
 }
 
 // resourcesAvailableInAnyNUMANodes checks for sufficient resource and return the NUMAID that would be selected by Kubelet.
@@ -172,7 +176,7 @@ func isResourceSetSuitable(qos v1.PodQOSClass, resource v1.ResourceName, quantit
 	return numaQuantity.Cmp(quantity) >= 0
 }
 
-func singleNUMAPodLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status {
+func singleNUMAPodLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) (nrtcache.NUMAResources, *framework.Status) {
 	lh.V(5).Info("pod level single NUMA node handler")
 
 	resources := util.GetPodEffectiveRequest(pod)
@@ -183,14 +187,191 @@ func singleNUMAPodLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alph
 	logNumaNodes(lh, "pod handler NUMA resources", nodeInfo.Node().Name, nodes)
 	lh.V(6).Info("pod desired resources", stringify.ResourceListToLoggable(resources)...)
 
-	if _, match := resourcesAvailableInAnyNUMANodes(lh, createNUMANodeList(lh, zones), resources, v1qos.GetPodQOS(pod), nodeInfo); !match {
+	numaID, match := resourcesAvailableInAnyNUMANodes(lh, createNUMANodeList(lh, zones), resources, v1qos.GetPodQOS(pod), nodeInfo)
+	if !match {
 		lh.V(2).Info("cannot align pod", "name", pod.Name)
-		return framework.NewStatus(framework.Unschedulable, "cannot align pod")
+		return nil, framework.NewStatus(framework.Unschedulable, "cannot align pod")
 	}
-	return nil
+	numaResources := nrtcache.NUMAResources{}
+	addNUMAResources(numaResources, resources, numaID)
+	return numaResources, nil
+}
+
+func restrictedContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) (nrtcache.NUMAResources, *framework.Status) {
+	lh.V(5).Info("container level restricted handler")
+	return numaAffinityContainerLevelHandler(lh, pod, zones, nodeInfo)
+}
+
+func restrictedPodLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) (nrtcache.NUMAResources, *framework.Status) {
+	lh.V(5).Info("pod level restricted handler")
+	return numaAffinityPodLevelHandler(lh, pod, zones, nodeInfo)
+}
+
+// bestEffortHandler only checks the pod fits on the NUMA nodes altogether: the best-effort policy of the kubelet
+// admits the pods it cannot align, so neither scope rejects a pod for its alignment. The resources are accounted
+// to the NUMA nodes the kubelet would likely pick, if the pod can be aligned at all.
+func bestEffortHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) (nrtcache.NUMAResources, *framework.Status) {
+	lh.V(5).Info("best-effort handler")
+	resources := util.GetPodEffectiveRequest(pod)
+
+	nodes := createNUMANodeList(lh, zones)
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes(lh, "best-effort handler NUMA resources", nodeInfo.Node().Name, nodes)
+	lh.V(6).Info("pod desired resources", stringify.ResourceListToLoggable(resources)...)
+
+	for name, quantity := range resources {
+		if quantity.IsZero() {
+			continue
+		}
+		available := resource.Quantity{}
+		reported := false
+		for _, numaNode := range nodes {
+			if numaQuantity, ok := numaNode.Resources[name]; ok {
+				available.Add(numaQuantity)
+				reported = true
+			}
+		}
+		// the resources not reported by the NUMA nodes are left to the node level fit check
+		if reported && available.Cmp(quantity) < 0 {
+			lh.V(2).Info("cannot fit pod", "name", pod.Name, "resource", name)
+			return nil, framework.NewStatus(framework.Unschedulable, "cannot fit pod")
+		}
+	}
+
+	numaResources := nrtcache.NUMAResources{}
+	affinity, _, match := numaAffinity(lh, nodes, createNUMANodeCapacityList(lh, zones), resources, v1qos.GetPodQOS(pod), nodeInfo)
+	if match {
+		addNUMAResources(numaResources, resources, affinity.GetBits()...)
+	} else {
+		lh.V(4).Info("cannot align pod, admitting it unaligned", "name", pod.Name)
+	}
+	return numaResources, nil
+}
+
+// numaAffinityContainerLevelHandler checks the containers like singleNUMAContainerLevelHandler, but lets them
+// span several NUMA nodes. A container must also get the NUMA affinity the kubelet prefers, i.e. it must fit
+// on as few NUMA nodes as it would on the node with all its resources available.
+func numaAffinityContainerLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) (nrtcache.NUMAResources, *framework.Status) {
+	nodes := createNUMANodeList(lh, zones)
+	capacity := createNUMANodeCapacityList(lh, zones)
+	qos := v1qos.GetPodQOS(pod)
+	numaResources := nrtcache.NUMAResources{}
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes(lh, "container handler NUMA resources", nodeInfo.Node().Name, nodes)
+
+	// the init containers are running SERIALLY and BEFORE the normal containers,
+	// therefore, we don't need to accumulate their resources together
+	for _, initContainer := range pod.Spec.InitContainers {
+		lh.V(6).Info("init container desired resources", stringify.ResourceListToLoggable(initContainer.Resources.Requests)...)
+
+		_, preferred, match := numaAffinity(lh, nodes, capacity, initContainer.Resources.Requests, qos, nodeInfo)
+		if !match {
+			lh.V(2).Info("cannot align container", "name", initContainer.Name, "kind", "init")
+			return nil, framework.NewStatus(framework.Unschedulable, "cannot align init container")
+		}
+		if !preferred {
+			lh.V(2).Info("cannot align container on the fewest NUMA nodes", "name", initContainer.Name, "kind", "init")
+			return nil, framework.NewStatus(framework.Unschedulable, "cannot align init container on the fewest NUMA nodes")
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		lh.V(6).Info("app container resources", stringify.ResourceListToLoggable(container.Resources.Requests)...)
+
+		affinity, preferred, match := numaAffinity(lh, nodes, capacity, container.Resources.Requests, qos, nodeInfo)
+		if !match {
+			lh.V(2).Info("cannot align container", "name", container.Name, "kind", "app")
+			return nil, framework.NewStatus(framework.Unschedulable, "cannot align container")
+		}
+		if !preferred {
+			lh.V(2).Info("cannot align container on the fewest NUMA nodes", "name", container.Name, "kind", "app")
+			return nil, framework.NewStatus(framework.Unschedulable, "cannot align container on the fewest NUMA nodes")
+		}
+
+		// subtract the resources requested by the container from the given NUMA nodes.
+		// this is necessary, so we won't allocate the same resources for the upcoming containers
+		subtractFromNUMAs(container.Resources.Requests, nodes, affinity.GetBits()...)
+		addNUMAResources(numaResources, container.Resources.Requests, affinity.GetBits()...)
+	}
+	return numaResources, nil
+}
+
+// numaAffinityPodLevelHandler is the pod scope counterpart of numaAffinityContainerLevelHandler.
+func numaAffinityPodLevelHandler(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) (nrtcache.NUMAResources, *framework.Status) {
+	resources := util.GetPodEffectiveRequest(pod)
+
+	nodes := createNUMANodeList(lh, zones)
+
+	// Node() != nil already verified in Filter(), which is the only public entry point
+	logNumaNodes(lh, "pod handler NUMA resources", nodeInfo.Node().Name, nodes)
+	lh.V(6).Info("pod desired resources", stringify.ResourceListToLoggable(resources)...)
+
+	affinity, preferred, match := numaAffinity(lh, nodes, createNUMANodeCapacityList(lh, zones), resources, v1qos.GetPodQOS(pod), nodeInfo)
+	if !match {
+		lh.V(2).Info("cannot align pod", "name", pod.Name)
+		return nil, framework.NewStatus(framework.Unschedulable, "cannot align pod")
+	}
+	if !preferred {
+		lh.V(2).Info("cannot align pod on the fewest NUMA nodes", "name", pod.Name)
+		return nil, framework.NewStatus(framework.Unschedulable, "cannot align pod on the fewest NUMA nodes")
+	}
+	numaResources := nrtcache.NUMAResources{}
+	addNUMAResources(numaResources, resources, affinity.GetBits()...)
+	return numaResources, nil
+}
+
+// numaAffinity returns the narrowest NUMA nodes that can satisfy the resources, picked like the merged hint
+// of the kubelet Topology Manager, and whether they can satisfy them at all. The affinity is preferred when no
+// narrower one could satisfy the resources out of the capacity of the NUMA nodes: the restricted policy of the
+// kubelet rejects the pods that get an affinity which is not preferred.
+func numaAffinity(lh logr.Logger, numaNodes, capacity NUMANodeList, resources v1.ResourceList, qos v1.PodQOSClass, nodeInfo *framework.NodeInfo) (bm.BitMask, bool, bool) {
+	nodeResources := util.ResourceList(nodeInfo.Allocatable)
+
+	numaResources := v1.ResourceList{}
+	for resource, quantity := range resources {
+		if quantity.IsZero() {
+			lh.V(4).Info("ignoring zero-qty resource request", "resource", resource)
+			continue
+		}
+
+		if _, ok := nodeResources[resource]; !ok {
+			// all resources must be reported at node level, see resourcesAvailableInAnyNUMANodes
+			lh.V(5).Info("early verdict: cannot meet request", "resource", resource, "suitable", "false")
+			return nil, false, false
+		}
+
+		if onlyNonNUMAResources(numaNodes, v1.ResourceList{resource: quantity}) {
+			// non-native resources or ephemeral-storage may not expose NUMA affinity,
+			// but since they are available at node level, this is fine
+			if !v1helper.IsNativeResource(resource) || resource == v1.ResourceEphemeralStorage {
+				lh.V(6).Info("resource available at node level (no NUMA affinity)", "resource", resource)
+				continue
+			}
+			lh.V(5).Info("early verdict: no NUMA affinity", "resource", resource, "suitable", "false")
+			return nil, false, false
+		}
+		numaResources[resource] = quantity
+	}
+
+	if len(numaResources) == 0 {
+		return bm.NewEmptyBitMask(), true, true
+	}
+
+	affinity, _ := numaNodesRequired(lh, qos, numaNodes, numaResources)
+	if affinity == nil {
+		lh.V(5).Info("final verdict", "suitable", false)
+		return nil, false, false
+	}
+
+	narrowest, _ := numaNodesRequired(lh, qos, capacity, numaResources)
+	preferred := narrowest == nil || affinity.Count() <= narrowest.Count()
+	lh.V(5).Info("final verdict", "suitable", true, "numaNodes", affinity.GetBits(), "preferred", preferred)
+	return affinity, preferred, true
 }
 
-// Filter Now only single-numa-node supported
+// Filter checks the pod can be aligned on the NUMA nodes according to the Topology Manager policy of the node.
 func (tm *TopologyMatch) Filter(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
 	if nodeInfo.Node() == nil {
 		return framework.NewStatus(framework.Error, "node not found")
@@ -214,17 +395,106 @@ func (tm *TopologyMatch) Filter(ctx context.Context, cycleState *framework.Cycle
 		return nil
 	}
 
+	if preempted := getPreemptedNUMAResources(cycleState, nodeName); preempted != nil {
+		// preemption dry run: the resources of the victims are available again
+		addToNUMAZones(nodeTopology, preempted)
+	}
+
 	lh.V(5).Info("found nrt data", "object", stringify.NodeResourceTopologyResources(nodeTopology))
 
 	handler := filterHandlerFromTopologyManagerConfig(topologyManagerConfigFromNodeResourceTopology(lh, nodeTopology))
 	if handler == nil {
 		return nil
 	}
-	status := handler(lh, pod, nodeTopology.Zones, nodeInfo)
+	numaResources, status := handler(lh, pod, nodeTopology.Zones, nodeInfo)
 	if status != nil {
 		tm.nrtCache.NodeMaybeOverReserved(nodeName, pod)
+		return status
+	}
+	if tm.accountedPerNUMA {
+		// the cache deducts the resources of the pod only from these NUMA nodes, should the pod land on this node
+		cycleState.Write(numaResourcesStateKey(nodeName), &numaResourcesState{numaResources: numaResources})
+	}
+	return nil
+}
+
+// numaResourcesState holds the resources the Filter expects a pod to take from each NUMA node of a node.
+type numaResourcesState struct {
+	numaResources nrtcache.NUMAResources
+}
+
+// Clone returns the state itself, as it is never modified after being written.
+func (s *numaResourcesState) Clone() framework.StateData {
+	return s
+}
+
+func numaResourcesStateKey(nodeName string) framework.StateKey {
+	return framework.StateKey(Name + "/numaResources/" + nodeName)
+}
+
+func preemptedNUMAResourcesStateKey(nodeName string) framework.StateKey {
+	return framework.StateKey(Name + "/preemptedNUMAResources/" + nodeName)
+}
+
+// getNUMAResources returns the resources the Filter expects the pod to take from each NUMA node of the node,
+// or nil if unknown.
+func getNUMAResources(cycleState *framework.CycleState, nodeName string) nrtcache.NUMAResources {
+	return readNUMAResourcesState(cycleState, numaResourcesStateKey(nodeName))
+}
+
+// getPreemptedNUMAResources returns the resources the PostFilter simulates are freed on each NUMA node
+// of the node by preempting the victims, or nil if none.
+func getPreemptedNUMAResources(cycleState *framework.CycleState, nodeName string) nrtcache.NUMAResources {
+	return readNUMAResourcesState(cycleState, preemptedNUMAResourcesStateKey(nodeName))
+}
+
+func readNUMAResourcesState(cycleState *framework.CycleState, key framework.StateKey) nrtcache.NUMAResources {
+	data, err := cycleState.Read(key)
+	if err != nil {
+		return nil
+	}
+	state, ok := data.(*numaResourcesState)
+	if !ok {
+		return nil
+	}
+	return state.numaResources
+}
+
+// addNUMAResources adds the resources to the ones expected to be taken from each of the NUMA nodes.
+func addNUMAResources(numaResources nrtcache.NUMAResources, resources v1.ResourceList, numaIDs ...int) {
+	for _, numaID := range numaIDs {
+		numaRes, ok := numaResources[numaID]
+		if !ok {
+			numaRes = v1.ResourceList{}
+			numaResources[numaID] = numaRes
+		}
+		for name, quantity := range resources {
+			total := numaRes[name]
+			total.Add(quantity)
+			numaRes[name] = total
+		}
+	}
+}
+
+// addToNUMAZones adds the resources to the available resources of the NUMA zones of the node topology.
+func addToNUMAZones(nodeTopology *topologyv1alpha2.NodeResourceTopology, numaResources nrtcache.NUMAResources) {
+	for zi := range nodeTopology.Zones {
+		zone := &nodeTopology.Zones[zi] // shortcut
+		numaID, err := numanode.NameToID(zone.Name)
+		if err != nil {
+			continue
+		}
+		res, ok := numaResources[numaID]
+		if !ok {
+			continue
+		}
+		for ri := range zone.Resources {
+			zr := &zone.Resources[ri] // shortcut
+			if qty, ok := res[v1.ResourceName(zr.Name)]; ok {
+				zr.Available.Add(qty)
+			}
+		}
 	}
-	return status
 }
 
 // subtractFromNUMA finds the correct NUMA ID's resources and subtract them from `nodes`.
@@ -250,14 +520,25 @@ func subtractFromNUMA(lh logr.Logger, nodes NUMANodeList, numaID int, container
 }
 
 func filterHandlerFromTopologyManagerConfig(conf TopologyManagerConfig) filterFn {
-	if conf.Policy != kubeletconfig.SingleNumaNodeTopologyManagerPolicy {
-		return nil
-	}
-	if conf.Scope == kubeletconfig.PodTopologyManagerScope {
-		return singleNUMAPodLevelHandler
-	}
-	if conf.Scope == kubeletconfig.ContainerTopologyManagerScope {
-		return singleNUMAContainerLevelHandler
+	switch conf.Policy {
+	case kubeletconfig.SingleNumaNodeTopologyManagerPolicy:
+		if conf.Scope == kubeletconfig.PodTopologyManagerScope {
+			return singleNUMAPodLevelHandler
+		}
+		if conf.Scope == kubeletconfig.ContainerTopologyManagerScope {
+			return singleNUMAContainerLevelHandler
+		}
+	case kubeletconfig.RestrictedTopologyManagerPolicy:
+		if conf.Scope == kubeletconfig.PodTopologyManagerScope {
+			return restrictedPodLevelHandler
+		}
+		if conf.Scope == kubeletconfig.ContainerTopologyManagerScope {
+			return restrictedContainerLevelHandler
+		}
+	case kubeletconfig.BestEffortTopologyManagerPolicy:
+		if conf.Scope == kubeletconfig.PodTopologyManagerScope || conf.Scope == kubeletconfig.ContainerTopologyManagerScope {
+			return bestEffortHandler
+		}
 	}
-	return nil // cannot happen
+	return nil
 }
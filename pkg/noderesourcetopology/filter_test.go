@@ -1179,6 +1179,293 @@ func TestNodeResourceTopologyMultiContainerContainerScope(t *testing.T) {
 	}
 }
 
+func TestNodeResourceTopologyRestrictedAndBestEffort(t *testing.T) {
+	makeNRT := func(policy, scope string) *topologyv1alpha2.NodeResourceTopology {
+		return &topologyv1alpha2.NodeResourceTopology{
+			ObjectMeta: metav1.ObjectMeta{Name: "host0"},
+			Attributes: topologyv1alpha2.AttributeList{
+				{Name: AttributePolicy, Value: policy},
+				{Name: AttributeScope, Value: scope},
+			},
+			Zones: topologyv1alpha2.ZoneList{
+				{
+					Name: "node-0",
+					Type: "Node",
+					Resources: topologyv1alpha2.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "16", "4"),
+						MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+						MakeTopologyResInfo(nicResourceName, "8", "8"),
+					},
+				},
+				{
+					Name: "node-1",
+					Type: "Node",
+					Resources: topologyv1alpha2.ResourceInfoList{
+						MakeTopologyResInfo(cpu, "16", "16"),
+						MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+						MakeTopologyResInfo(nicResourceName, "8", "2"),
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		description string
+		scope       string
+		cntReq      []map[string]string
+		// the expected errors with the restricted and the best-effort policies
+		restrictedErr string
+		bestEffortErr string
+	}{
+		{
+			description: "container fits on a single NUMA node",
+			scope:       "container",
+			cntReq: []map[string]string{
+				{cpu: "8", memory: "4Gi"},
+			},
+		},
+		{
+			description: "container needs two NUMA nodes, as on an empty node",
+			scope:       "container",
+			cntReq: []map[string]string{
+				{cpu: "18", memory: "4Gi"},
+			},
+		},
+		{
+			description: "container needs two NUMA nodes, but would fit on one on an empty node",
+			scope:       "container",
+			cntReq: []map[string]string{
+				{cpu: "12", memory: "4Gi", nicResourceName: "4"},
+			},
+			restrictedErr: "cannot align container on the fewest NUMA nodes",
+		},
+		{
+			description: "container does not fit on the NUMA nodes",
+			scope:       "container",
+			cntReq: []map[string]string{
+				{cpu: "24", memory: "4Gi"},
+			},
+			restrictedErr: "cannot align container",
+			bestEffortErr: "cannot fit pod",
+		},
+		{
+			description: "second container needs two NUMA nodes, but would fit on one on an empty node",
+			scope:       "container",
+			cntReq: []map[string]string{
+				{cpu: "10", memory: "4Gi"},
+				{cpu: "10", memory: "4Gi"},
+			},
+			restrictedErr: "cannot align container on the fewest NUMA nodes",
+		},
+		{
+			description: "pod needs two NUMA nodes, as on an empty node",
+			scope:       "pod",
+			cntReq: []map[string]string{
+				{cpu: "10", memory: "4Gi"},
+				{cpu: "10", memory: "4Gi"},
+			},
+		},
+		{
+			description: "pod needs two NUMA nodes, but would fit on one on an empty node",
+			scope:       "pod",
+			cntReq: []map[string]string{
+				{cpu: "6", memory: "4Gi", nicResourceName: "2"},
+				{cpu: "6", memory: "4Gi", nicResourceName: "2"},
+			},
+			restrictedErr: "cannot align pod on the fewest NUMA nodes",
+		},
+		{
+			description: "pod does not fit on the NUMA nodes",
+			scope:       "pod",
+			cntReq: []map[string]string{
+				{cpu: "12", memory: "4Gi"},
+				{cpu: "12", memory: "4Gi"},
+			},
+			restrictedErr: "cannot align pod",
+			bestEffortErr: "cannot fit pod",
+		},
+	}
+
+	for _, tt := range tests {
+		for policy, statusErr := range map[string]string{"restricted": tt.restrictedErr, "best-effort": tt.bestEffortErr} {
+			t.Run(policy+": "+tt.description, func(t *testing.T) {
+				nrt := makeNRT(policy, tt.scope)
+
+				fakeClient, err := tu.NewFakeClient()
+				if err != nil {
+					t.Fatalf("failed to create fake client: %v", err)
+				}
+				if err := fakeClient.Create(context.Background(), nrt.DeepCopy()); err != nil {
+					t.Fatal(err)
+				}
+
+				tm := TopologyMatch{
+					nrtCache: nrtcache.NewPassthrough(klog.Background(), fakeClient),
+				}
+
+				nodeInfo := framework.NewNodeInfo()
+				nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+				pod := makePod("testpod", withMultiContainers(parseContainerRes(tt.cntReq)))
+				gotStatus := tm.Filter(context.Background(), framework.NewCycleState(), pod, nodeInfo)
+
+				if wantStatus := parseState(statusErr); !reflect.DeepEqual(gotStatus, wantStatus) {
+					t.Errorf("status does not match: %v, want: %v", gotStatus, wantStatus)
+				}
+			})
+		}
+	}
+}
+
+func TestNodeResourceTopologyFilterNUMAResources(t *testing.T) {
+	nrt := &topologyv1alpha2.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{Name: "host0"},
+		Attributes: topologyv1alpha2.AttributeList{
+			{Name: AttributePolicy, Value: "single-numa-node"},
+			{Name: AttributeScope, Value: "container"},
+		},
+		Zones: topologyv1alpha2.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "16", "4"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "16", "16"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			},
+		},
+	}
+
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatalf("failed to create fake client: %v", err)
+	}
+	if err := fakeClient.Create(context.Background(), nrt.DeepCopy()); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := TopologyMatch{
+		nrtCache:         nrtcache.NewPassthrough(klog.Background(), fakeClient),
+		accountedPerNUMA: true,
+	}
+
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+	pod := makePod("testpod", withMultiContainers(parseContainerRes([]map[string]string{
+		{cpu: "8", memory: "4Gi"},
+	})))
+	cycleState := framework.NewCycleState()
+	if gotStatus := tm.Filter(context.Background(), cycleState, pod, nodeInfo); gotStatus != nil {
+		t.Fatalf("unexpected filter status: %v", gotStatus)
+	}
+
+	expected := nrtcache.NUMAResources{
+		1: {
+			v1.ResourceCPU:    resource.MustParse("8"),
+			v1.ResourceMemory: resource.MustParse("4Gi"),
+		},
+	}
+	got := getNUMAResources(cycleState, nrt.Name)
+	if len(got) != len(expected) {
+		t.Fatalf("NUMA resources mismatch: got %v expected %v", got, expected)
+	}
+	for numaID, res := range expected {
+		for resName, qty := range res {
+			gotQty := got[numaID][resName]
+			if gotQty.Cmp(qty) != 0 {
+				t.Errorf("NUMA %d resource %q: got %v expected %v", numaID, resName, gotQty.String(), qty.String())
+			}
+		}
+	}
+
+	if got := getNUMAResources(cycleState, "host1"); got != nil {
+		t.Errorf("unexpected NUMA resources for unfiltered node: %v", got)
+	}
+
+	// the cache does not account the resources per NUMA node
+	tm.accountedPerNUMA = false
+	cycleState = framework.NewCycleState()
+	if gotStatus := tm.Filter(context.Background(), cycleState, pod, nodeInfo); gotStatus != nil {
+		t.Fatalf("unexpected filter status: %v", gotStatus)
+	}
+	if got := getNUMAResources(cycleState, nrt.Name); got != nil {
+		t.Errorf("unexpected NUMA resources without the per NUMA accounting: %v", got)
+	}
+}
+
+func TestNodeResourceTopologyFilterPreemptedNUMAResources(t *testing.T) {
+	nrt := &topologyv1alpha2.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{Name: "host0"},
+		Attributes: topologyv1alpha2.AttributeList{
+			{Name: AttributePolicy, Value: "single-numa-node"},
+			{Name: AttributeScope, Value: "container"},
+		},
+		Zones: topologyv1alpha2.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "16", "4"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					MakeTopologyResInfo(cpu, "16", "2"),
+					MakeTopologyResInfo(memory, "32Gi", "32Gi"),
+				},
+			},
+		},
+	}
+
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatalf("failed to create fake client: %v", err)
+	}
+	if err := fakeClient.Create(context.Background(), nrt.DeepCopy()); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := TopologyMatch{
+		nrtCache:         nrtcache.NewPassthrough(klog.Background(), fakeClient),
+		accountedPerNUMA: true,
+	}
+
+	nodeInfo := framework.NewNodeInfo()
+	nodeInfo.SetNode(makeNodeFromNodeResourceTopology(nrt))
+	pod := makePod("testpod", withMultiContainers(parseContainerRes([]map[string]string{
+		{cpu: "8", memory: "4Gi"},
+	})))
+
+	cycleState := framework.NewCycleState()
+	if gotStatus := tm.Filter(context.Background(), cycleState, pod, nodeInfo); gotStatus.Code() != framework.Unschedulable {
+		t.Fatalf("unexpected filter status: %v", gotStatus)
+	}
+
+	cycleState.Write(preemptedNUMAResourcesStateKey(nrt.Name), &numaResourcesState{
+		numaResources: nrtcache.NUMAResources{
+			1: {v1.ResourceCPU: resource.MustParse("6")},
+		},
+	})
+	if gotStatus := tm.Filter(context.Background(), cycleState, pod, nodeInfo); gotStatus != nil {
+		t.Fatalf("unexpected filter status: %v", gotStatus)
+	}
+	got := getNUMAResources(cycleState, nrt.Name)
+	if _, ok := got[1]; !ok || len(got) != 1 {
+		t.Errorf("expected the pod on the NUMA node freed by the preemption, got %v", got)
+	}
+}
+
 func makeNodeFromNodeResourceTopology(nrt *topologyv1alpha2.NodeResourceTopology) *v1.Node {
 	res := makeResourceListFromZones(nrt.Zones)
 	return &v1.Node{
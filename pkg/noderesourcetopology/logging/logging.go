@@ -44,12 +44,13 @@ const (
 )
 
 const (
-	FlowCacheSync string = "cachesync"
-	FlowFilter    string = "filter"
-	FlowPostBind  string = "postbind"
-	FlowReserve   string = "reserve"
-	FlowUnreserve string = "unreserve"
-	FlowScore     string = "score"
+	FlowCacheSync  string = "cachesync"
+	FlowFilter     string = "filter"
+	FlowPostBind   string = "postbind"
+	FlowPostFilter string = "postfilter"
+	FlowReserve    string = "reserve"
+	FlowUnreserve  string = "unreserve"
+	FlowScore      string = "score"
 )
 
 var logh logr.Logger
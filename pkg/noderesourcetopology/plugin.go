@@ -24,9 +24,17 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	schedulinglisters "k8s.io/client-go/listers/scheduling/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/features"
+	schedconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
+	plfeature "k8s.io/kubernetes/pkg/scheduler/framework/plugins/feature"
 
 	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
@@ -96,7 +104,7 @@ func subtractFromNUMAs(resources v1.ResourceList, numaNodes NUMANodeList, nodes
 	}
 }
 
-type filterFn func(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) *framework.Status
+type filterFn func(lh logr.Logger, pod *v1.Pod, zones topologyv1alpha2.ZoneList, nodeInfo *framework.NodeInfo) (nrtcache.NUMAResources, *framework.Status)
 type scoringFn func(logr.Logger, *v1.Pod, topologyv1alpha2.ZoneList) (int64, *framework.Status)
 
 // TopologyMatch plugin which run simplified version of TopologyManager's admit handler
@@ -105,6 +113,14 @@ type TopologyMatch struct {
 	nrtCache            nrtcache.Interface
 	scoreStrategyFunc   scoreStrategyFn
 	scoreStrategyType   apiconfig.ScoringStrategyType
+	// the cache deducts the resources of the reserved pods per NUMA node, as predicted by the Filter
+	accountedPerNUMA bool
+	// needed only by the PostFilter
+	defaultPreemption   *defaultpreemption.DefaultPreemption
+	fh                  framework.Handle
+	podLister           corelisters.PodLister
+	pdbLister           policylisters.PodDisruptionBudgetLister
+	priorityClassLister schedulinglisters.PriorityClassLister
 }
 
 var _ framework.FilterPlugin = &TopologyMatch{}
@@ -112,6 +128,7 @@ var _ framework.ReservePlugin = &TopologyMatch{}
 var _ framework.ScorePlugin = &TopologyMatch{}
 var _ framework.EnqueueExtensions = &TopologyMatch{}
 var _ framework.PostBindPlugin = &TopologyMatch{}
+var _ framework.PostFilterPlugin = &TopologyMatch{}
 
 // Name returns name of the plugin. It is used in logs, etc.
 func (tm *TopologyMatch) Name() string {
@@ -119,7 +136,7 @@ func (tm *TopologyMatch) Name() string {
 }
 
 // New initializes a new plugin and returns it.
-func New(_ context.Context, args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+func New(ctx context.Context, args runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	// we do this later to make sure klog is initialized. We don't need this anyway before this point
 	lh := klog.Background()
 	logging.SetLogger(lh)
@@ -134,7 +151,7 @@ func New(_ context.Context, args runtime.Object, handle framework.Handle) (frame
 		return nil, err
 	}
 
-	nrtCache, err := initNodeTopologyInformer(lh, tcfg, handle)
+	nrtCache, err := initNodeTopologyInformer(ctx, lh, tcfg, handle)
 	if err != nil {
 		lh.Error(err, "cannot create clientset for NodeTopologyResource", "kubeConfig", handle.KubeConfig())
 		return nil, err
@@ -155,11 +172,28 @@ func New(_ context.Context, args runtime.Object, handle framework.Handle) (frame
 		return nil, err
 	}
 
+	// the PostFilter checks the preemptors and bounds the candidate nodes like the DefaultPreemption plugin with its default args
+	defaultPreemption, err := defaultpreemption.New(ctx, &schedconfig.DefaultPreemptionArgs{
+		MinCandidateNodesPercentage: 10,
+		MinCandidateNodesAbsolute:   100,
+	}, handle, plfeature.Features{
+		EnablePodDisruptionConditions: utilfeature.DefaultFeatureGate.Enabled(features.PodDisruptionConditions),
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	topologyMatch := &TopologyMatch{
 		resourceToWeightMap: resToWeightMap,
 		nrtCache:            nrtCache,
 		scoreStrategyFunc:   strategy,
 		scoreStrategyType:   tcfg.ScoringStrategy.Type,
+		accountedPerNUMA:    isCacheAccountedPerNUMA(tcfg),
+		defaultPreemption:   defaultPreemption.(*defaultpreemption.DefaultPreemption),
+		fh:                  handle,
+		podLister:           handle.SharedInformerFactory().Core().V1().Pods().Lister(),
+		pdbLister:           handle.SharedInformerFactory().Policy().V1().PodDisruptionBudgets().Lister(),
+		priorityClassLister: handle.SharedInformerFactory().Scheduling().V1().PriorityClasses().Lister(),
 	}
 
 	return topologyMatch, nil
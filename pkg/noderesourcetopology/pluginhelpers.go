@@ -17,11 +17,14 @@ limitations under the License.
 package noderesourcetopology
 
 import (
+	"context"
+	"encoding/json"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	k8scache "k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/configz"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"github.com/go-logr/logr"
@@ -38,10 +41,14 @@ import (
 
 const (
 	maxNUMAId = 64
+
+	// nodeTopologyResyncDebounce is how long the changes of the NRT object of a node are
+	// coalesced before trying to resync the node.
+	nodeTopologyResyncDebounce = 1 * time.Second
 )
 
-func initNodeTopologyInformer(lh logr.Logger, tcfg *apiconfig.NodeResourceTopologyMatchArgs, handle framework.Handle) (nrtcache.Interface, error) {
-	client, err := ctrlclient.New(handle.KubeConfig(), ctrlclient.Options{Scheme: scheme})
+func initNodeTopologyInformer(ctx context.Context, lh logr.Logger, tcfg *apiconfig.NodeResourceTopologyMatchArgs, handle framework.Handle) (nrtcache.Interface, error) {
+	client, err := ctrlclient.NewWithWatch(handle.KubeConfig(), ctrlclient.Options{Scheme: scheme})
 	if err != nil {
 		lh.Error(err, "cannot create client for NodeTopologyResource", "kubeConfig", handle.KubeConfig())
 		return nil, err
@@ -57,21 +64,33 @@ func initNodeTopologyInformer(lh logr.Logger, tcfg *apiconfig.NodeResourceTopolo
 
 	podSharedInformer, podLister, isPodRelevant := podprovider.NewFromHandle(lh, handle, tcfg.Cache)
 
-	nrtCache, err := nrtcache.NewOverReserve(lh.WithName("nrtcache"), tcfg.Cache, client, podLister, isPodRelevant)
+	nrtCache, err := nrtcache.NewOverReserve(lh.WithName("nrtcache"), profileName(handle), tcfg.Cache, client, podLister, isPodRelevant)
 	if err != nil {
 		return nil, err
 	}
 
 	initNodeTopologyForeignPodsDetection(lh, tcfg.Cache, handle, podSharedInformer, nrtCache)
+	initNodeTopologyNodeRemoval(lh, handle, nrtCache)
+	initNodeTopologyCacheDump(lh, profileName(handle), nrtCache)
+	initNodeTopologyResyncer(ctx, lh, tcfg.Cache, client, nrtCache)
 
 	resyncPeriod := time.Duration(tcfg.CacheResyncPeriodSeconds) * time.Second
-	go wait.Forever(nrtCache.Resync, resyncPeriod)
+	go wait.Until(nrtCache.Resync, resyncPeriod, ctx.Done())
 
 	lh.V(3).Info("enable NodeTopology cache (needs the Reserve plugin)", "resyncPeriod", resyncPeriod)
 
 	return nrtCache, nil
 }
 
+// isCacheAccountedPerNUMA returns true if the plugin uses a cache which deducts the resources of the reserved
+// pods only from the NUMA nodes predicted by the Filter.
+func isCacheAccountedPerNUMA(tcfg *apiconfig.NodeResourceTopologyMatchArgs) bool {
+	if tcfg.CacheResyncPeriodSeconds <= 0 || tcfg.Cache == nil || tcfg.Cache.AccountingMode == nil {
+		return false
+	}
+	return *tcfg.Cache.AccountingMode == apiconfig.CacheAccountingPerNUMA
+}
+
 func initNodeTopologyForeignPodsDetection(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache, handle framework.Handle, podSharedInformer k8scache.SharedInformer, nrtCache *nrtcache.OverReserve) {
 	foreignPodsDetect := getForeignPodsDetectMode(lh, cfg)
 
@@ -97,7 +116,83 @@ func initNodeTopologyForeignPodsDetection(lh logr.Logger, cfg *apiconfig.NodeRes
 	nrtcache.SetupForeignPodsDetector(lh.WithName("foreignpods"), profileName, podSharedInformer, nrtCache)
 }
 
+func initNodeTopologyResyncer(ctx context.Context, lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache, client ctrlclient.WithWatch, nrtCache *nrtcache.OverReserve) {
+	resyncTrigger := getCacheResyncTrigger(lh, cfg)
+	if resyncTrigger != apiconfig.CacheResyncTriggerEvents {
+		lh.Info("event-driven cache resync disabled by configuration")
+		return
+	}
+
+	lh.Info("setting up event-driven cache resync", "debounce", nodeTopologyResyncDebounce)
+	nrtInformer := nrtcache.SharedNodeTopologyInformer(ctx, client)
+	nrtcache.SetupNodeTopologyResyncer(ctx, lh.WithName("nrtresync"), nrtInformer, nrtCache, nodeTopologyResyncDebounce)
+}
+
+// initNodeTopologyNodeRemoval makes the cache forget the nodes which are deleted, along with their metrics.
+func initNodeTopologyNodeRemoval(lh logr.Logger, handle framework.Handle, nrtCache *nrtcache.OverReserve) {
+	handle.SharedInformerFactory().Core().V1().Nodes().Informer().AddEventHandler(k8scache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			switch t := obj.(type) {
+			case *corev1.Node:
+				nrtCache.DeleteNode(t.Name)
+			case k8scache.DeletedFinalStateUnknown:
+				if node, ok := t.Obj.(*corev1.Node); ok {
+					nrtCache.DeleteNode(node.Name)
+				}
+			}
+		},
+	})
+	lh.V(3).Info("setting up node removal")
+}
+
+// cacheDump marshals a fresh dump of the cache state every time the scheduler serves it.
+type cacheDump struct {
+	nrtCache *nrtcache.OverReserve
+}
+
+func (cd cacheDump) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cd.nrtCache.Dump())
+}
+
+// initNodeTopologyCacheDump makes the scheduler serve the cache state as JSON on the /configz endpoint of its
+// secure port, the only handler of the secure port plugins can extend. The dump locks and copies the whole
+// cache, so it is built only when the endpoint is requested.
+func initNodeTopologyCacheDump(lh logr.Logger, profile string, nrtCache *nrtcache.OverReserve) {
+	name := cacheDumpName(profile)
+	// the latest cache created for a profile is the one in use
+	configz.Delete(name)
+	cz, err := configz.New(name)
+	if err != nil {
+		lh.Error(err, "cannot register the cache dump", "name", name)
+		return
+	}
+	cz.Set(cacheDump{nrtCache: nrtCache})
+	lh.V(3).Info("serving the cache dump", "endpoint", "/configz", "name", name)
+}
+
+func cacheDumpName(profile string) string {
+	return Name + "Cache/" + profile
+}
+
+// profileName returns the name of the scheduler profile of the plugin, if known.
+func profileName(handle framework.Handle) string {
+	if fwk, ok := handle.(framework.Framework); ok {
+		return fwk.ProfileName()
+	}
+	return ""
+}
+
 func createNUMANodeList(lh logr.Logger, zones topologyv1alpha2.ZoneList) NUMANodeList {
+	return createNUMANodeListFrom(lh, zones, extractResources)
+}
+
+// createNUMANodeCapacityList is like createNUMANodeList, but with the capacity of the NUMA nodes
+// instead of their available resources.
+func createNUMANodeCapacityList(lh logr.Logger, zones topologyv1alpha2.ZoneList) NUMANodeList {
+	return createNUMANodeListFrom(lh, zones, extractCapacity)
+}
+
+func createNUMANodeListFrom(lh logr.Logger, zones topologyv1alpha2.ZoneList, extract func(topologyv1alpha2.Zone) corev1.ResourceList) NUMANodeList {
 	numaIDToZoneIDx := make([]int, maxNUMAId)
 	nodes := NUMANodeList{}
 	// filter non Node zones and create idToIdx lookup array
@@ -114,7 +209,7 @@ func createNUMANodeList(lh logr.Logger, zones topologyv1alpha2.ZoneList) NUMANod
 
 		numaIDToZoneIDx[numaID] = i
 
-		resources := extractResources(zone)
+		resources := extract(zone)
 		numaItems := []interface{}{"numaCell", numaID}
 		lh.V(6).Info("extracted NUMA resources", stringify.ResourceListToLoggableWithValues(numaItems, resources)...)
 		nodes = append(nodes, NUMANode{NUMAID: numaID, Resources: resources})
@@ -155,6 +250,32 @@ func extractResources(zone topologyv1alpha2.Zone) corev1.ResourceList {
 	return res
 }
 
+// extractCapacity returns the capacity of the resources of the zone, or their available quantity
+// if the capacity is not reported.
+func extractCapacity(zone topologyv1alpha2.Zone) corev1.ResourceList {
+	res := make(corev1.ResourceList)
+	for _, resInfo := range zone.Resources {
+		if resInfo.Capacity.IsZero() {
+			res[corev1.ResourceName(resInfo.Name)] = resInfo.Available.DeepCopy()
+			continue
+		}
+		res[corev1.ResourceName(resInfo.Name)] = resInfo.Capacity.DeepCopy()
+	}
+	return res
+}
+
+// extractAllocatable returns the allocatable quantity of the resources of the zone, or their capacity
+// if the allocatable quantity is not reported.
+func extractAllocatable(zone topologyv1alpha2.Zone) corev1.ResourceList {
+	res := extractCapacity(zone)
+	for _, resInfo := range zone.Resources {
+		if !resInfo.Allocatable.IsZero() {
+			res[corev1.ResourceName(resInfo.Name)] = resInfo.Allocatable.DeepCopy()
+		}
+	}
+	return res
+}
+
 func onlyNonNUMAResources(numaNodes NUMANodeList, resources corev1.ResourceList) bool {
 	for resourceName := range resources {
 		for _, node := range numaNodes {
@@ -178,6 +299,17 @@ func getForeignPodsDetectMode(lh logr.Logger, cfg *apiconfig.NodeResourceTopolog
 	return foreignPodsDetect
 }
 
+func getCacheResyncTrigger(lh logr.Logger, cfg *apiconfig.NodeResourceTopologyCache) apiconfig.CacheResyncTrigger {
+	var resyncTrigger apiconfig.CacheResyncTrigger
+	if cfg != nil && cfg.ResyncTrigger != nil {
+		resyncTrigger = *cfg.ResyncTrigger
+	} else { // explicitly set to nil?
+		resyncTrigger = apiconfig.CacheResyncTriggerPeriodic
+		lh.Info("cache resync trigger missing", "fallback", resyncTrigger)
+	}
+	return resyncTrigger
+}
+
 func logNumaNodes(lh logr.Logger, desc, nodeName string, nodes NUMANodeList) {
 	for _, numaNode := range nodes {
 		numaItems := []interface{}{"numaCell", numaNode.NUMAID}
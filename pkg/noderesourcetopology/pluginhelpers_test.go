@@ -17,12 +17,21 @@ limitations under the License.
 package noderesourcetopology
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/component-base/configz"
 	"k8s.io/klog/v2"
 	apiconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/podprovider"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
 )
 
 func TestOnlyNonNUMAResources(t *testing.T) {
@@ -156,3 +165,83 @@ func TestGetForeignPodsDetectMode(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCacheResyncTrigger(t *testing.T) {
+	triggerPeriodic := apiconfig.CacheResyncTriggerPeriodic
+	triggerEvents := apiconfig.CacheResyncTriggerEvents
+
+	testCases := []struct {
+		description string
+		cfg         *apiconfig.NodeResourceTopologyCache
+		expected    apiconfig.CacheResyncTrigger
+	}{
+		{
+			description: "nil config",
+			expected:    apiconfig.CacheResyncTriggerPeriodic,
+		},
+		{
+			description: "empty config",
+			cfg:         &apiconfig.NodeResourceTopologyCache{},
+			expected:    apiconfig.CacheResyncTriggerPeriodic,
+		},
+		{
+			description: "explicit periodic",
+			cfg: &apiconfig.NodeResourceTopologyCache{
+				ResyncTrigger: &triggerPeriodic,
+			},
+			expected: apiconfig.CacheResyncTriggerPeriodic,
+		},
+		{
+			description: "explicit events",
+			cfg: &apiconfig.NodeResourceTopologyCache{
+				ResyncTrigger: &triggerEvents,
+			},
+			expected: apiconfig.CacheResyncTriggerEvents,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			got := getCacheResyncTrigger(klog.Background(), testCase.cfg)
+			if got != testCase.expected {
+				t.Errorf("cache resync trigger got %v expected %v", got, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestInitNodeTopologyCacheDump(t *testing.T) {
+	fakeClient, err := tu.NewFakeClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	podLister := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).Core().V1().Pods().Lister()
+	nrtCache, err := nrtcache.NewOverReserve(klog.Background(), "", nil, fakeClient, podLister, podprovider.IsPodRelevantAlways)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nrtCache.NodeMaybeOverReserved("node1", &corev1.Pod{})
+
+	initNodeTopologyCacheDump(klog.Background(), "test-profile", nrtCache)
+	defer configz.Delete(cacheDumpName("test-profile"))
+
+	mux := http.NewServeMux()
+	configz.InstallHandler(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/configz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var got map[string]nrtcache.Dump
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("cannot unmarshal %s: %v", rec.Body.String(), err)
+	}
+	dump, ok := got[cacheDumpName("test-profile")]
+	if !ok {
+		t.Fatalf("missing cache dump: %s", rec.Body.String())
+	}
+	if dump.Nodes["node1"].DiscardCount != 1 {
+		t.Errorf("unexpected cache dump: %+v", dump)
+	}
+}
@@ -33,15 +33,30 @@ import (
 
 type PodFilterFunc func(lh logr.Logger, pod *corev1.Pod) bool
 
+// NodeNameIndex indexes the pods by the name of the node they are bound to.
+const NodeNameIndex = "nodeName"
+
+// NodePodLister can list the pods bound to a node without going through all the pods.
+type NodePodLister interface {
+	podlisterv1.PodLister
+	ListOnNode(nodeName string) ([]*corev1.Pod, error)
+}
+
 func NewFromHandle(lh logr.Logger, handle framework.Handle, cacheConf *apiconfig.NodeResourceTopologyCache) (k8scache.SharedIndexInformer, podlisterv1.PodLister, PodFilterFunc) {
 	dedicated := wantsDedicatedInformer(cacheConf)
 	if !dedicated {
 		podHandle := handle.SharedInformerFactory().Core().V1().Pods() // shortcut
-		return podHandle.Informer(), podHandle.Lister(), IsPodRelevantShared
+		podInformer := podHandle.Informer()
+		if err := addNodeNameIndex(podInformer); err != nil {
+			// the informer already started: the pods of a node are found listing all the pods
+			lh.Info("cannot index the pods by node", "error", err)
+			return podInformer, podHandle.Lister(), IsPodRelevantShared
+		}
+		return podInformer, newNodePodLister(podInformer.GetIndexer()), IsPodRelevantShared
 	}
 
-	podInformer := coreinformers.NewFilteredPodInformer(handle.ClientSet(), metav1.NamespaceAll, 0, cache.Indexers{}, nil)
-	podLister := podlisterv1.NewPodLister(podInformer.GetIndexer())
+	podInformer := coreinformers.NewFilteredPodInformer(handle.ClientSet(), metav1.NamespaceAll, 0, cache.Indexers{NodeNameIndex: nodeNameIndexFunc}, nil)
+	podLister := newNodePodLister(podInformer.GetIndexer())
 
 	lh.V(5).Info("start custom pod informer")
 	ctx := context.Background()
@@ -54,6 +69,49 @@ func NewFromHandle(lh logr.Logger, handle framework.Handle, cacheConf *apiconfig
 	return podInformer, podLister, IsPodRelevantDedicated
 }
 
+// addNodeNameIndex adds the NodeNameIndex to the informer, unless another profile already did.
+func addNodeNameIndex(podInformer k8scache.SharedIndexInformer) error {
+	if _, ok := podInformer.GetIndexer().GetIndexers()[NodeNameIndex]; ok {
+		return nil
+	}
+	return podInformer.AddIndexers(cache.Indexers{NodeNameIndex: nodeNameIndexFunc})
+}
+
+func nodeNameIndexFunc(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return []string{}, nil
+	}
+	return []string{pod.Spec.NodeName}, nil
+}
+
+type nodePodLister struct {
+	podlisterv1.PodLister
+	indexer k8scache.Indexer
+}
+
+func newNodePodLister(indexer k8scache.Indexer) NodePodLister {
+	return &nodePodLister{
+		PodLister: podlisterv1.NewPodLister(indexer),
+		indexer:   indexer,
+	}
+}
+
+// ListOnNode lists the pods bound to the given node.
+func (npl *nodePodLister) ListOnNode(nodeName string) ([]*corev1.Pod, error) {
+	objs, err := npl.indexer.ByIndex(NodeNameIndex, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*corev1.Pod, 0, len(objs))
+	for _, obj := range objs {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
 // IsPodRelevantAlways is meant to be used in test only
 func IsPodRelevantAlways(lh logr.Logger, pod *corev1.Pod) bool {
 	return true
@@ -0,0 +1,466 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/klog/v2"
+	v1qos "k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultpreemption"
+	"k8s.io/kubernetes/pkg/scheduler/framework/preemption"
+	schedutil "k8s.io/kubernetes/pkg/scheduler/util"
+
+	"github.com/go-logr/logr"
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/logging"
+	"sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/resourcerequests"
+	"sigs.k8s.io/scheduler-plugins/pkg/preemptiontoleration"
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// PostFilter tries to make room for the pod on a single NUMA node of the nodes the Filter rejected,
+// by preempting lower priority pods running on that NUMA node.
+func (tm *TopologyMatch) PostFilter(ctx context.Context, state *framework.CycleState, pod *v1.Pod, m framework.NodeToStatusMap) (*framework.PostFilterResult, *framework.Status) {
+	lh := logging.Log().WithValues(logging.KeyLogID, logging.PodLogID(pod), logging.KeyPodUID, pod.GetUID(), logging.KeyFlow, logging.FlowPostFilter)
+	lh.V(4).Info(logging.FlowBegin)
+	defer lh.V(4).Info(logging.FlowEnd)
+
+	nodeNames := sets.New[string]()
+	for nodeName, status := range m {
+		if status.Plugin() == Name {
+			nodeNames.Insert(nodeName)
+		}
+	}
+	if nodeNames.Len() == 0 {
+		// nothing we can help with: the other PostFilter plugins, if any, handle the other failures
+		return nil, framework.NewStatus(framework.Unschedulable, "no node rejected because of NUMA alignment")
+	}
+	lh.V(4).Info("trying NUMA-aware preemption", "nodes", nodeNames.Len())
+
+	pe := preemption.Evaluator{
+		PluginName: tm.Name(),
+		Handler:    tm.fh,
+		PodLister:  tm.podLister,
+		PdbLister:  tm.pdbLister,
+		State:      state,
+		Interface: &numaPreemptor{
+			DefaultPreemption: tm.defaultPreemption,
+			tm:                tm,
+			lh:                lh,
+			nodeNames:         nodeNames,
+			curTime:           time.Now(),
+		},
+	}
+
+	return pe.Preempt(ctx, pod, m)
+}
+
+// numaPreemptor selects the victims on a single NUMA node, and otherwise preempts like the DefaultPreemption plugin.
+type numaPreemptor struct {
+	*defaultpreemption.DefaultPreemption
+	tm *TopologyMatch
+	lh logr.Logger
+	// nodeNames are the nodes rejected by the Filter of this plugin
+	nodeNames sets.Set[string]
+	curTime   time.Time
+}
+
+var _ preemption.Interface = &numaPreemptor{}
+
+// numaVictim is a potential victim, along with the resources it takes from its NUMA node.
+type numaVictim struct {
+	podInfo   *framework.PodInfo
+	resources v1.ResourceList
+}
+
+// numaCandidate is the outcome of the preemption dry run on a NUMA node.
+type numaCandidate struct {
+	numaID       int
+	victims      []numaVictim
+	numViolating int
+}
+
+// SelectVictimsOnNode evaluates each NUMA node of the node in turn and returns the smallest set
+// of victims which, once preempted, lets the pod fit on one of them.
+func (p *numaPreemptor) SelectVictimsOnNode(
+	ctx context.Context,
+	state *framework.CycleState,
+	pod *v1.Pod,
+	nodeInfo *framework.NodeInfo,
+	pdbs []*policy.PodDisruptionBudget) ([]*v1.Pod, int, *framework.Status) {
+	nodeName := nodeInfo.Node().Name
+	lh := p.lh.WithValues(logging.KeyNode, nodeName)
+
+	if !p.nodeNames.Has(nodeName) {
+		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, "node not rejected because of NUMA alignment")
+	}
+
+	nodeTopology, ok := p.tm.nrtCache.GetCachedNRTCopy(ctx, nodeName, pod)
+	if !ok || nodeTopology == nil {
+		lh.V(2).Info("invalid topology data")
+		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, "invalid node topology data")
+	}
+	if filterHandlerFromTopologyManagerConfig(topologyManagerConfigFromNodeResourceTopology(lh, nodeTopology)) == nil {
+		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, "no NUMA alignment required on node")
+	}
+
+	victimsByNUMA, err := p.potentialVictimsByNUMA(lh, pod, nodeInfo, nodeTopology)
+	if err != nil {
+		lh.Error(err, "cannot select the potential victims")
+		return nil, 0, framework.AsStatus(err)
+	}
+	// No potential victims are found, and so we don't need to evaluate the node again since its state didn't change.
+	if len(victimsByNUMA) == 0 {
+		message := fmt.Sprintf("No victims found on node %v for preemptor pod %v", nodeName, pod.Name)
+		return nil, 0, framework.NewStatus(framework.UnschedulableAndUnresolvable, message)
+	}
+
+	numaIDs := make([]int, 0, len(victimsByNUMA))
+	for numaID := range victimsByNUMA {
+		numaIDs = append(numaIDs, numaID)
+	}
+	sort.Ints(numaIDs)
+
+	var best *numaCandidate
+	for _, numaID := range numaIDs {
+		cand, err := p.selectVictimsOnNUMANode(ctx, state, pod, nodeInfo, pdbs, numaID, victimsByNUMA[numaID])
+		if err != nil {
+			lh.Error(err, "cannot select the victims", "numaCell", numaID)
+			return nil, 0, framework.AsStatus(err)
+		}
+		if cand == nil {
+			lh.V(5).Info("preempting the pods is not enough", "numaCell", numaID)
+			continue
+		}
+		if best == nil || cand.betterThan(best) {
+			best = cand
+		}
+	}
+	if best == nil {
+		return nil, 0, framework.NewStatus(framework.Unschedulable, "cannot free the resources of a NUMA node")
+	}
+
+	victims := make([]*v1.Pod, 0, len(best.victims))
+	for _, v := range best.victims {
+		lh.V(5).Info("found a potential preemption victim", "pod", klog.KObj(v.podInfo.Pod), "numaCell", best.numaID)
+		victims = append(victims, v.podInfo.Pod)
+	}
+	return victims, best.numViolating, framework.NewStatus(framework.Success)
+}
+
+// potentialVictimsByNUMA returns the lower priority pods on the node which can be preempted, grouped
+// by the NUMA node they take their resources from. The pods whose NUMA node is ambiguous, or which
+// take no resources from a NUMA node, are never victims.
+func (p *numaPreemptor) potentialVictimsByNUMA(lh logr.Logger, pod *v1.Pod, nodeInfo *framework.NodeInfo, nodeTopology *topologyv1alpha2.NodeResourceTopology) (map[int][]numaVictim, error) {
+	podPriority := corev1helpers.PodPriority(pod)
+	victimsByNUMA := make(map[int][]numaVictim)
+
+	for numaID, attributed := range attributeToNUMANodes(lh, allocatedNUMAResources(lh, nodeTopology.Zones), nodeInfo.Pods) {
+		for _, v := range attributed {
+			if corev1helpers.PodPriority(v.podInfo.Pod) >= podPriority {
+				continue
+			}
+			exempted, err := preemptiontoleration.ExemptedFromPreemption(v.podInfo.Pod, pod, p.tm.priorityClassLister, p.curTime)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, err
+			}
+			if exempted {
+				lh.V(5).Info("pod exempted from preemption", "pod", klog.KObj(v.podInfo.Pod))
+				continue
+			}
+			victimsByNUMA[numaID] = append(victimsByNUMA[numaID], v)
+		}
+	}
+	return victimsByNUMA, nil
+}
+
+// attributeToNUMANodes groups the pods by the NUMA node they take their resources from. The NRT data does not
+// tell which pods run on which NUMA node, so we infer it: a pod runs on a NUMA node if it is the only one with
+// enough resources allocated to run it. The resources of an attributed pod are no longer allocated to the others,
+// which may leave a single NUMA node to a pod found ambiguous before it, so the attribution is repeated until
+// nothing changes. The pods of any priority are attributed, as each of them narrows down the others.
+func attributeToNUMANodes(lh logr.Logger, allocated nrtcache.NUMAResources, podInfos []*framework.PodInfo) map[int][]numaVictim {
+	pending := make([]numaVictim, 0, len(podInfos))
+	for _, pi := range podInfos {
+		resources := numaAffineResources(pi.Pod, allocated)
+		if len(resources) == 0 {
+			continue
+		}
+		pending = append(pending, numaVictim{podInfo: pi, resources: resources})
+	}
+
+	attributed := make(map[int][]numaVictim)
+	for changed := true; changed; {
+		changed = false
+		ambiguous := pending[:0]
+		for _, v := range pending {
+			numaID, ok := findAllocatingNUMANode(allocated, v.resources)
+			if !ok {
+				ambiguous = append(ambiguous, v)
+				continue
+			}
+			// the same resources cannot be accounted to more than one pod
+			subtractResources(allocated[numaID], v.resources)
+			attributed[numaID] = append(attributed[numaID], v)
+			changed = true
+		}
+		pending = ambiguous
+	}
+	for _, v := range pending {
+		lh.V(5).Info("cannot tell the NUMA node of the pod", "pod", klog.KObj(v.podInfo.Pod))
+	}
+	return attributed
+}
+
+// selectVictimsOnNUMANode simulates the preemption of the victims from the NUMA node and returns the smallest
+// subset of them which lets the pod fit, or nil if the pod does not fit even after preempting all of them.
+// The node info and the cycle state are restored before returning.
+func (p *numaPreemptor) selectVictimsOnNUMANode(
+	ctx context.Context,
+	state *framework.CycleState,
+	pod *v1.Pod,
+	nodeInfo *framework.NodeInfo,
+	pdbs []*policy.PodDisruptionBudget,
+	numaID int,
+	potentialVictims []numaVictim) (*numaCandidate, error) {
+	logger := klog.FromContext(ctx)
+	stateKey := preemptedNUMAResourcesStateKey(nodeInfo.Node().Name)
+	defer state.Delete(stateKey)
+
+	freed := v1.ResourceList{}
+	removeVictim := func(v numaVictim) error {
+		if err := nodeInfo.RemovePod(logger, v.podInfo.Pod); err != nil {
+			return err
+		}
+		status := p.tm.fh.RunPreFilterExtensionRemovePod(ctx, state, pod, v.podInfo, nodeInfo)
+		if !status.IsSuccess() {
+			return status.AsError()
+		}
+		addResources(freed, v.resources)
+		return nil
+	}
+	addVictim := func(v numaVictim) error {
+		nodeInfo.AddPodInfo(v.podInfo)
+		status := p.tm.fh.RunPreFilterExtensionAddPod(ctx, state, pod, v.podInfo, nodeInfo)
+		if !status.IsSuccess() {
+			return status.AsError()
+		}
+		subtractResources(freed, v.resources)
+		return nil
+	}
+	fits := func() bool {
+		// the state is never modified after being written, so each dry run needs its own copy
+		state.Write(stateKey, &numaResourcesState{numaResources: nrtcache.NUMAResources{numaID: freed.DeepCopy()}})
+		return p.tm.fh.RunFilterPluginsWithNominatedPods(ctx, state, pod, nodeInfo).IsSuccess()
+	}
+
+	for _, v := range potentialVictims {
+		if err := removeVictim(v); err != nil {
+			return nil, err
+		}
+	}
+	if !fits() {
+		for _, v := range potentialVictims {
+			if err := addVictim(v); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	victimByPodInfo := make(map[*framework.PodInfo]numaVictim, len(potentialVictims))
+	podInfos := make([]*framework.PodInfo, 0, len(potentialVictims))
+	for _, v := range potentialVictims {
+		victimByPodInfo[v.podInfo] = v
+		podInfos = append(podInfos, v.podInfo)
+	}
+	sort.Slice(podInfos, func(i, j int) bool {
+		return schedutil.MoreImportantPod(podInfos[i].Pod, podInfos[j].Pod)
+	})
+
+	cand := &numaCandidate{numaID: numaID}
+	reprieve := func(pi *framework.PodInfo) (bool, error) {
+		v := victimByPodInfo[pi]
+		if err := addVictim(v); err != nil {
+			return false, err
+		}
+		if fits() {
+			return true, nil
+		}
+		if err := removeVictim(v); err != nil {
+			return false, err
+		}
+		cand.victims = append(cand.victims, v)
+		return false, nil
+	}
+	// Try to reprieve as many pods as possible. We first try to reprieve the PDB
+	// violating victims and then other non-violating ones. In both cases, we start
+	// from the highest priority victims.
+	violatingVictims, nonViolatingVictims := util.FilterPodsWithPDBViolation(podInfos, pdbs)
+	for _, pi := range violatingVictims {
+		fit, err := reprieve(pi)
+		if err != nil {
+			return nil, err
+		}
+		if !fit {
+			cand.numViolating++
+		}
+	}
+	for _, pi := range nonViolatingVictims {
+		if _, err := reprieve(pi); err != nil {
+			return nil, err
+		}
+	}
+
+	// leave the node info as we found it, for the evaluation of the next NUMA node
+	for _, v := range cand.victims {
+		if err := addVictim(v); err != nil {
+			return nil, err
+		}
+	}
+	if len(cand.victims) == 0 {
+		// the pod fits without preempting anything: not our business
+		return nil, nil
+	}
+	return cand, nil
+}
+
+// betterThan tells if preempting the victims of the candidate is less disruptive than preempting
+// the victims of the other: fewer PDB violations first, then fewer victims, then victims with lower priority.
+func (c *numaCandidate) betterThan(other *numaCandidate) bool {
+	if c.numViolating != other.numViolating {
+		return c.numViolating < other.numViolating
+	}
+	if len(c.victims) != len(other.victims) {
+		return len(c.victims) < len(other.victims)
+	}
+	if hc, ho := c.highestPriority(), other.highestPriority(); hc != ho {
+		return hc < ho
+	}
+	return c.numaID < other.numaID
+}
+
+func (c *numaCandidate) highestPriority() int32 {
+	var highest int32
+	for i, v := range c.victims {
+		if prio := corev1helpers.PodPriority(v.podInfo.Pod); i == 0 || prio > highest {
+			highest = prio
+		}
+	}
+	return highest
+}
+
+// allocatedNUMAResources returns the resources allocated to the pods on each NUMA node, keyed by NUMA ID.
+// The resources reserved for the system are part of the capacity, but are never allocated to the pods.
+func allocatedNUMAResources(lh logr.Logger, zones topologyv1alpha2.ZoneList) nrtcache.NUMAResources {
+	available := make(map[int]v1.ResourceList)
+	for _, numaNode := range createNUMANodeList(lh, zones) {
+		available[numaNode.NUMAID] = numaNode.Resources
+	}
+	allocated := nrtcache.NUMAResources{}
+	for _, numaNode := range createNUMANodeListFrom(lh, zones, extractAllocatable) {
+		res := v1.ResourceList{}
+		for name, allocatable := range numaNode.Resources {
+			qty := allocatable.DeepCopy()
+			if avail, ok := available[numaNode.NUMAID][name]; ok {
+				qty.Sub(avail)
+			}
+			res[name] = qty
+		}
+		allocated[numaNode.NUMAID] = res
+	}
+	return allocated
+}
+
+// numaAffineResources returns the resources the running pod takes from its NUMA node, restricted to the
+// resources the NUMA nodes report. The init containers are done, so only the app containers count.
+func numaAffineResources(pod *v1.Pod, numaResources nrtcache.NUMAResources) v1.ResourceList {
+	qos := v1qos.GetPodQOS(pod)
+	resources := v1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		for name, qty := range container.Resources.Requests {
+			if !resourcerequests.IsExclusive(qos, name, qty) || !reportedByAnyNUMANode(numaResources, name) {
+				continue
+			}
+			total := resources[name]
+			total.Add(qty)
+			resources[name] = total
+		}
+	}
+	return resources
+}
+
+func reportedByAnyNUMANode(numaResources nrtcache.NUMAResources, name v1.ResourceName) bool {
+	for _, res := range numaResources {
+		if _, ok := res[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// findAllocatingNUMANode returns the only NUMA node which has enough resources allocated to account for the resources.
+func findAllocatingNUMANode(allocated nrtcache.NUMAResources, resources v1.ResourceList) (int, bool) {
+	found := -1
+	for numaID, res := range allocated {
+		if !containsResources(res, resources) {
+			continue
+		}
+		if found != -1 {
+			return -1, false
+		}
+		found = numaID
+	}
+	return found, found != -1
+}
+
+func containsResources(res, resources v1.ResourceList) bool {
+	for name, qty := range resources {
+		avail, ok := res[name]
+		if !ok || avail.Cmp(qty) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func addResources(res, resources v1.ResourceList) {
+	for name, qty := range resources {
+		total := res[name]
+		total.Add(qty)
+		res[name] = total
+	}
+}
+
+func subtractResources(res, resources v1.ResourceList) {
+	for name, qty := range resources {
+		total := res[name]
+		total.Sub(qty)
+		res[name] = total
+	}
+}
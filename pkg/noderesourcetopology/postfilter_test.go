@@ -0,0 +1,374 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package noderesourcetopology
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	topologyv1alpha2 "github.com/k8stopologyawareschedwg/noderesourcetopology-api/pkg/apis/topology/v1alpha2"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
+
+	nrtcache "sigs.k8s.io/scheduler-plugins/pkg/noderesourcetopology/cache"
+	"sigs.k8s.io/scheduler-plugins/pkg/preemptiontoleration"
+	tu "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestSelectVictimsOnNode(t *testing.T) {
+	// node-0 runs pod-a (10 CPUs) and pod-b (2 CPUs), node-1 runs pod-c (6 CPUs).
+	// Both NUMA nodes reserve 2 CPUs for the system.
+	nrt := &topologyv1alpha2.NodeResourceTopology{
+		ObjectMeta: metav1.ObjectMeta{Name: "host0"},
+		Attributes: topologyv1alpha2.AttributeList{
+			{Name: AttributePolicy, Value: "single-numa-node"},
+			{Name: AttributeScope, Value: "container"},
+		},
+		Zones: topologyv1alpha2.ZoneList{
+			{
+				Name: "node-0",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					makeTopologyResInfoWithAllocatable(cpu, "18", "16", "4"),
+					MakeTopologyResInfo(memory, "32Gi", "30Gi"),
+				},
+			},
+			{
+				Name: "node-1",
+				Type: "Node",
+				Resources: topologyv1alpha2.ResourceInfoList{
+					makeTopologyResInfoWithAllocatable(cpu, "18", "16", "10"),
+					MakeTopologyResInfo(memory, "32Gi", "31Gi"),
+				},
+			},
+		},
+	}
+
+	tolerantPriorityClass := &schedulingv1.PriorityClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "tolerant",
+			Annotations: map[string]string{
+				preemptiontoleration.AnnotationKeyMinimumPreemptablePriority: "1000",
+				preemptiontoleration.AnnotationKeyTolerationSeconds:          "-1",
+			},
+		},
+		Value: 5,
+	}
+
+	tests := []struct {
+		name            string
+		preemptor       *v1.Pod
+		pods            []*v1.Pod
+		pdbs            []*policy.PodDisruptionBudget
+		nodeNames       []string
+		expectedVictims []string
+		expectedCode    framework.Code
+		expectedNumPDB  int
+	}{
+		{
+			name:      "victims on the NUMA node with the lowest priority pods",
+			preemptor: makePreemptionTestPod("preemptor", 100, "12"),
+			pods: []*v1.Pod{
+				makePreemptionTestPod("pod-a", 10, "10"),
+				makePreemptionTestPod("pod-c", 5, "6"),
+				makePreemptionTestPod("pod-b", 10, "2"),
+			},
+			nodeNames:       []string{"host0"},
+			expectedVictims: []string{"pod-c"},
+			expectedCode:    framework.Success,
+		},
+		{
+			name:      "victims on the first NUMA node on ties",
+			preemptor: makePreemptionTestPod("preemptor", 100, "12"),
+			pods: []*v1.Pod{
+				makePreemptionTestPod("pod-a", 10, "10"),
+				makePreemptionTestPod("pod-c", 10, "6"),
+				makePreemptionTestPod("pod-b", 10, "2"),
+			},
+			nodeNames:       []string{"host0"},
+			expectedVictims: []string{"pod-a"},
+			expectedCode:    framework.Success,
+		},
+		{
+			// pod-b is ambiguous until pod-a and pod-c are attributed
+			name:      "victims attributed regardless of the pod order",
+			preemptor: makePreemptionTestPod("preemptor", 100, "16"),
+			pods: []*v1.Pod{
+				makePreemptionTestPod("pod-b", 10, "2"),
+				makePreemptionTestPod("pod-a", 10, "10"),
+				makePreemptionTestPod("pod-c", 200, "6"),
+			},
+			nodeNames:       []string{"host0"},
+			expectedVictims: []string{"pod-a", "pod-b"},
+			expectedCode:    framework.Success,
+		},
+		{
+			name:      "victims avoiding PDB violations",
+			preemptor: makePreemptionTestPod("preemptor", 100, "12"),
+			pods: []*v1.Pod{
+				withLabels(makePreemptionTestPod("pod-a", 10, "10"), map[string]string{"app": "protected"}),
+				makePreemptionTestPod("pod-c", 10, "6"),
+				makePreemptionTestPod("pod-b", 10, "2"),
+			},
+			pdbs: []*policy.PodDisruptionBudget{
+				makePDB("protected", map[string]string{"app": "protected"}, 0),
+			},
+			nodeNames:       []string{"host0"},
+			expectedVictims: []string{"pod-c"},
+			expectedCode:    framework.Success,
+		},
+		{
+			name:      "PDB violated when unavoidable",
+			preemptor: makePreemptionTestPod("preemptor", 100, "12"),
+			pods: []*v1.Pod{
+				withLabels(makePreemptionTestPod("pod-a", 10, "10"), map[string]string{"app": "protected"}),
+				makePreemptionTestPod("pod-c", 200, "6"),
+				makePreemptionTestPod("pod-b", 10, "2"),
+			},
+			pdbs: []*policy.PodDisruptionBudget{
+				makePDB("protected", map[string]string{"app": "protected"}, 0),
+			},
+			nodeNames:       []string{"host0"},
+			expectedVictims: []string{"pod-a"},
+			expectedCode:    framework.Success,
+			expectedNumPDB:  1,
+		},
+		{
+			name:      "victims honoring the preemption toleration",
+			preemptor: makePreemptionTestPod("preemptor", 100, "12"),
+			pods: []*v1.Pod{
+				makePreemptionTestPod("pod-a", 10, "10"),
+				withPriorityClassName(makePreemptionTestPod("pod-c", 5, "6"), tolerantPriorityClass.Name),
+				makePreemptionTestPod("pod-b", 10, "2"),
+			},
+			nodeNames:       []string{"host0"},
+			expectedVictims: []string{"pod-a"},
+			expectedCode:    framework.Success,
+		},
+		{
+			name:      "no lower priority pods",
+			preemptor: makePreemptionTestPod("preemptor", 1, "12"),
+			pods: []*v1.Pod{
+				makePreemptionTestPod("pod-a", 10, "10"),
+				makePreemptionTestPod("pod-c", 10, "6"),
+				makePreemptionTestPod("pod-b", 10, "2"),
+			},
+			nodeNames:    []string{"host0"},
+			expectedCode: framework.UnschedulableAndUnresolvable,
+		},
+		{
+			name:      "no NUMA node large enough",
+			preemptor: makePreemptionTestPod("preemptor", 100, "20"),
+			pods: []*v1.Pod{
+				makePreemptionTestPod("pod-a", 10, "10"),
+				makePreemptionTestPod("pod-c", 10, "6"),
+				makePreemptionTestPod("pod-b", 10, "2"),
+			},
+			nodeNames:    []string{"host0"},
+			expectedCode: framework.Unschedulable,
+		},
+		{
+			name:      "node not rejected by the filter",
+			preemptor: makePreemptionTestPod("preemptor", 100, "12"),
+			pods: []*v1.Pod{
+				makePreemptionTestPod("pod-a", 10, "10"),
+				makePreemptionTestPod("pod-c", 5, "6"),
+				makePreemptionTestPod("pod-b", 10, "2"),
+			},
+			expectedCode: framework.UnschedulableAndUnresolvable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fakeClient, err := tu.NewFakeClient()
+			if err != nil {
+				t.Fatalf("failed to create fake client: %v", err)
+			}
+			if err := fakeClient.Create(ctx, nrt.DeepCopy()); err != nil {
+				t.Fatal(err)
+			}
+
+			cs := clientsetfake.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			pcInformer := informerFactory.Scheduling().V1().PriorityClasses()
+			if err := pcInformer.Informer().GetStore().Add(tolerantPriorityClass); err != nil {
+				t.Fatal(err)
+			}
+
+			tm := &TopologyMatch{
+				nrtCache:            nrtcache.NewPassthrough(klog.Background(), fakeClient),
+				priorityClassLister: pcInformer.Lister(),
+			}
+			registeredPlugins := []tf.RegisterPluginFunc{
+				tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+				tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+				tf.RegisterFilterPlugin(Name, func(_ context.Context, _ apiruntime.Object, _ framework.Handle) (framework.Plugin, error) {
+					return tm, nil
+				}),
+			}
+			node := makeNodeFromNodeResourceTopology(nrt)
+			fwk, err := tf.NewFramework(
+				ctx,
+				registeredPlugins,
+				"default-scheduler",
+				frameworkruntime.WithClientSet(cs),
+				frameworkruntime.WithEventRecorder(&events.FakeRecorder{}),
+				frameworkruntime.WithInformerFactory(informerFactory),
+				frameworkruntime.WithPodNominator(tu.NewPodNominator(nil)),
+				frameworkruntime.WithSnapshotSharedLister(tu.NewFakeSharedLister(tt.pods, []*v1.Node{node})),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tm.fh = fwk
+
+			nodeInfo, err := fwk.SnapshotSharedLister().NodeInfos().Get(node.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pp := &numaPreemptor{
+				tm:        tm,
+				lh:        klog.Background(),
+				nodeNames: sets.New[string](tt.nodeNames...),
+			}
+			state := framework.NewCycleState()
+			victims, numPDB, status := pp.SelectVictimsOnNode(ctx, state, tt.preemptor, nodeInfo.Snapshot(), tt.pdbs)
+			if status.Code() != tt.expectedCode {
+				t.Fatalf("unexpected status: got %v expected %v", status, tt.expectedCode)
+			}
+			var gotVictims []string
+			for _, victim := range victims {
+				gotVictims = append(gotVictims, victim.Name)
+			}
+			sort.Strings(gotVictims)
+			if !reflect.DeepEqual(gotVictims, tt.expectedVictims) {
+				t.Errorf("unexpected victims: got %v expected %v", gotVictims, tt.expectedVictims)
+			}
+			if numPDB != tt.expectedNumPDB {
+				t.Errorf("unexpected PDB violations: got %d expected %d", numPDB, tt.expectedNumPDB)
+			}
+			if got := getPreemptedNUMAResources(state, node.Name); got != nil {
+				t.Errorf("unexpected preempted NUMA resources left in the cycle state: %v", got)
+			}
+		})
+	}
+}
+
+func TestFindAllocatingNUMANode(t *testing.T) {
+	allocated := nrtcache.NUMAResources{
+		0: {v1.ResourceCPU: resource.MustParse("12")},
+		1: {v1.ResourceCPU: resource.MustParse("6")},
+	}
+
+	tests := []struct {
+		name           string
+		resources      v1.ResourceList
+		expectedNUMAID int
+		expectedFound  bool
+	}{
+		{
+			name:           "only one NUMA node large enough",
+			resources:      v1.ResourceList{v1.ResourceCPU: resource.MustParse("8")},
+			expectedNUMAID: 0,
+			expectedFound:  true,
+		},
+		{
+			name:      "ambiguous",
+			resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("4")},
+		},
+		{
+			name:      "no NUMA node large enough",
+			resources: v1.ResourceList{v1.ResourceCPU: resource.MustParse("16")},
+		},
+		{
+			name:      "resource not reported",
+			resources: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			numaID, found := findAllocatingNUMANode(allocated, tt.resources)
+			if found != tt.expectedFound || (found && numaID != tt.expectedNUMAID) {
+				t.Errorf("got NUMA %d found=%v expected NUMA %d found=%v", numaID, found, tt.expectedNUMAID, tt.expectedFound)
+			}
+		})
+	}
+}
+
+func makeTopologyResInfoWithAllocatable(name, capacity, allocatable, available string) topologyv1alpha2.ResourceInfo {
+	resInfo := MakeTopologyResInfo(name, capacity, available)
+	resInfo.Allocatable = resource.MustParse(allocatable)
+	return resInfo
+}
+
+func makePreemptionTestPod(name string, priority int32, cpuReq string) *v1.Pod {
+	pod := makePod(name, withMultiContainers(parseContainerRes([]map[string]string{
+		{cpu: cpuReq, memory: "1Gi"},
+	})))
+	pod.Namespace = "default"
+	pod.UID = types.UID("uid-" + name)
+	pod.Spec.NodeName = "host0"
+	pod.Spec.Priority = &priority
+	return pod
+}
+
+func withLabels(pod *v1.Pod, labels map[string]string) *v1.Pod {
+	pod.Labels = labels
+	return pod
+}
+
+func withPriorityClassName(pod *v1.Pod, name string) *v1.Pod {
+	pod.Spec.PriorityClassName = name
+	return pod
+}
+
+func makePDB(name string, selector map[string]string, disruptionsAllowed int32) *policy.PodDisruptionBudget {
+	minAvailable := intstr.FromInt32(1)
+	return &policy.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: policy.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector:     &metav1.LabelSelector{MatchLabels: selector},
+		},
+		Status: policy.PodDisruptionBudgetStatus{
+			DisruptionsAllowed: disruptionsAllowed,
+		},
+	}
+}
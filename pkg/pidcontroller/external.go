@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pidcontroller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+// ScoreRequest is the body of the batched request sent to the endpoint once per scheduling cycle.
+type ScoreRequest struct {
+	Pod   PodRequest `json:"pod"`
+	Nodes []string   `json:"nodes"`
+}
+
+// PodRequest describes the pod being scheduled.
+type PodRequest struct {
+	Namespace string          `json:"namespace"`
+	Name      string          `json:"name"`
+	UID       types.UID       `json:"uid"`
+	Requests  v1.ResourceList `json:"requests,omitempty"`
+}
+
+// ScoreResponse is the answer of the endpoint to a ScoreRequest.
+type ScoreResponse struct {
+	// Scores maps node names to their score.
+	Scores map[string]int64 `json:"scores,omitempty"`
+	// Score is the single score returned by endpoints which are not node aware;
+	// when set, it is applied to every node missing from Scores.
+	Score *int64 `json:"score,omitempty"`
+}
+
+// fetchScores sends one request carrying the pod requests and the candidate node
+// names to the endpoint, and returns the per-node scores. Scores above MaxNodeScore are
+// left to NormalizeScore, negative ones are raised to MinNodeScore.
+func (p *PIDController) fetchScores(ctx context.Context, pod *v1.Pod, nodes []*v1.Node) (map[string]int64, error) {
+	req := ScoreRequest{
+		Pod: PodRequest{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			UID:       pod.UID,
+			Requests:  util.GetPodEffectiveRequest(pod),
+		},
+		Nodes: make([]string, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		req.Nodes = append(req.Nodes, node.Name)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding request: %v", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpointURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching scores: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching scores: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %v", err)
+	}
+
+	var result ScoreResponse
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
+	}
+	if result.Scores == nil && result.Score == nil {
+		return nil, fmt.Errorf("scores not found in the response")
+	}
+
+	scores := make(map[string]int64, len(req.Nodes))
+	for _, name := range req.Nodes {
+		score, ok := result.Scores[name]
+		if !ok {
+			if result.Score == nil {
+				continue
+			}
+			score = *result.Score
+		}
+		if score < framework.MinNodeScore {
+			score = framework.MinNodeScore
+		}
+		scores[name] = score
+	}
+	return scores, nil
+}
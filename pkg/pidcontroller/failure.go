@@ -0,0 +1,233 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pidcontroller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/clock"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+)
+
+var errBreakerOpen = errors.New("circuit breaker is open, not calling the endpoint")
+
+// breakerStateType is the state of a circuitBreaker.
+type breakerStateType int
+
+const (
+	breakerClosed breakerStateType = iota
+	breakerHalfOpen
+	breakerOpen
+)
+
+func (s breakerStateType) String() string {
+	switch s {
+	case breakerHalfOpen:
+		return "HalfOpen"
+	case breakerOpen:
+		return "Open"
+	default:
+		return "Closed"
+	}
+}
+
+// circuitBreaker stops calling an endpoint after threshold consecutive failures.
+// Once openDuration has elapsed a single trial request is let through: the breaker
+// closes again if it succeeds, and opens for another openDuration if it fails.
+type circuitBreaker struct {
+	endpoint     string
+	threshold    int64
+	openDuration time.Duration
+	clock        clock.PassiveClock
+	gauge        metrics.GaugeMetric
+
+	sync.Mutex
+	state    breakerStateType
+	failures int64
+	openedAt time.Time
+}
+
+func newCircuitBreaker(profile, endpoint string, threshold int64, openDuration time.Duration, clock clock.PassiveClock) *circuitBreaker {
+	b := &circuitBreaker{
+		endpoint:     endpoint,
+		threshold:    threshold,
+		openDuration: openDuration,
+		clock:        clock,
+		gauge:        breakerState.WithLabelValues(profile, endpoint),
+	}
+	b.gauge.Set(float64(breakerClosed))
+	return b
+}
+
+// allow tells whether the endpoint can be called.
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.Lock()
+	defer b.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.clock.Since(b.openedAt) < b.openDuration {
+			return false
+		}
+		b.setState(breakerHalfOpen)
+		return true
+	case breakerHalfOpen:
+		// the trial request is in flight
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) success() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+
+	b.failures = 0
+	b.setState(breakerClosed)
+}
+
+func (b *circuitBreaker) failure() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.Lock()
+	defer b.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.clock.Now()
+		b.setState(breakerOpen)
+	}
+}
+
+func (b *circuitBreaker) setState(state breakerStateType) {
+	if b.state != state {
+		klog.V(3).InfoS("PIDController circuit breaker changed state", "endpoint", b.endpoint, "from", b.state, "to", state)
+	}
+	b.state = state
+	b.gauge.Set(float64(state))
+}
+
+// cachedScore is the last score the endpoint returned for a node.
+type cachedScore struct {
+	score   int64
+	updated time.Time
+}
+
+// scoreCache keeps the last known good score of every node. The scores depend on the pod
+// they were computed for, so they are only an approximation for the pods that come after.
+type scoreCache struct {
+	maxAge time.Duration
+	clock  clock.PassiveClock
+
+	sync.Mutex
+	scores map[string]cachedScore
+}
+
+func newScoreCache(maxAge time.Duration, clock clock.PassiveClock) *scoreCache {
+	return &scoreCache{
+		maxAge: maxAge,
+		clock:  clock,
+		scores: make(map[string]cachedScore),
+	}
+}
+
+func (c *scoreCache) update(scores map[string]int64) {
+	c.Lock()
+	defer c.Unlock()
+
+	now := c.clock.Now()
+	for name, score := range scores {
+		c.scores[name] = cachedScore{score: score, updated: now}
+	}
+	for name, s := range c.scores {
+		if now.Sub(s.updated) > c.maxAge {
+			delete(c.scores, name)
+		}
+	}
+}
+
+// get returns the cached scores of the nodes which are not older than maxAge.
+func (c *scoreCache) get(nodes []*v1.Node) map[string]int64 {
+	c.Lock()
+	defer c.Unlock()
+
+	now := c.clock.Now()
+	scores := make(map[string]int64)
+	for _, node := range nodes {
+		s, ok := c.scores[node.Name]
+		if ok && now.Sub(s.updated) <= c.maxAge {
+			scores[node.Name] = s.score
+		}
+	}
+	return scores
+}
+
+// externalScores fetches the node scores from the endpoint, going through the circuit
+// breaker, and applies the failure policy when no usable answer is available.
+func (p *PIDController) externalScores(ctx context.Context, pod *v1.Pod, nodes []*v1.Node) (map[string]int64, *framework.Status) {
+	var err error
+	if !p.breaker.allow() {
+		err = errBreakerOpen
+		requestErrors.WithLabelValues(p.endpointURL, reasonBreakerOpen).Inc()
+	} else {
+		start := time.Now()
+		var scores map[string]int64
+		scores, err = p.fetchScores(ctx, pod, nodes)
+		if err == nil {
+			requestDuration.WithLabelValues(p.endpointURL, resultSuccess).Observe(time.Since(start).Seconds())
+			p.breaker.success()
+			if p.failurePolicy == config.PIDControllerFailurePolicyLastKnownGood {
+				p.lastKnownGood.update(scores)
+			}
+			return scores, nil
+		}
+		requestDuration.WithLabelValues(p.endpointURL, resultError).Observe(time.Since(start).Seconds())
+		requestErrors.WithLabelValues(p.endpointURL, reasonRequest).Inc()
+		p.breaker.failure()
+	}
+
+	switch p.failurePolicy {
+	case config.PIDControllerFailurePolicyFail:
+		fallbacks.WithLabelValues(p.endpointURL, string(p.failurePolicy), "fail").Inc()
+		return nil, framework.AsStatus(err)
+	case config.PIDControllerFailurePolicyLastKnownGood:
+		if scores := p.lastKnownGood.get(nodes); len(scores) > 0 {
+			klog.V(4).InfoS("Using last known good scores", "pod", klog.KObj(pod), "nodes", len(scores), "err", err)
+			fallbacks.WithLabelValues(p.endpointURL, string(p.failurePolicy), "cached").Inc()
+			return scores, nil
+		}
+	}
+	klog.V(4).InfoS("Skipping scoring", "pod", klog.KObj(pod), "failurePolicy", p.failurePolicy, "err", err)
+	fallbacks.WithLabelValues(p.endpointURL, string(p.failurePolicy), "skip").Inc()
+	return nil, framework.NewStatus(framework.Skip)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pidcontroller
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const metricsSubsystem = "scheduler_pid_controller"
+
+var (
+	requestDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "request_duration_seconds",
+			Help:           "Latency of the batched score requests sent to the endpoint, by result.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"endpoint", "result"})
+
+	requestErrors = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "request_errors_total",
+			Help:           "Number of score requests which failed, including the ones rejected by the circuit breaker.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"endpoint", "reason"})
+
+	fallbacks = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "fallbacks_total",
+			Help:           "Number of scheduling cycles scored according to the failure policy, by policy and outcome.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"endpoint", "policy", "outcome"})
+
+	breakerState = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      metricsSubsystem,
+			Name:           "circuit_breaker_state",
+			Help:           "State of the circuit breaker of the endpoint by scheduler profile: 0 closed, 1 half-open, 2 open.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile", "endpoint"})
+
+	registerMetrics sync.Once
+)
+
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+
+	reasonRequest     = "request"
+	reasonBreakerOpen = "breaker_open"
+)
+
+// RegisterMetrics registers the PIDController metrics to the legacy registry served by the scheduler.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(requestDuration, requestErrors, fallbacks, breakerState)
+	})
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pidcontroller
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"sigs.k8s.io/scheduler-plugins/pkg/util"
+)
+
+const (
+	// integralLimit bounds the accumulated error of a node, so a node which stayed
+	// below or above target for a long time doesn't dominate the score (anti-windup).
+	integralLimit = 10 * float64(framework.MaxNodeScore)
+	// nodeStateTTL is how long the state of a node is kept after it was last scored.
+	nodeStateTTL = 10 * time.Minute
+)
+
+// pidState is the controller state of a single node, carried over between scheduling cycles.
+type pidState struct {
+	integral  float64
+	lastError float64
+	lastSeen  time.Time
+}
+
+// pidLoop is the built-in PID controller. The error term of a node is the distance
+// between the target utilization and the utilization the node would have once the
+// pod is placed there; every scheduling cycle the node takes part in is one step.
+type pidLoop struct {
+	target     float64
+	kp, ki, kd float64
+
+	sync.Mutex
+	nodes map[string]*pidState
+}
+
+func newPIDLoop(target int64, kp, ki, kd float64) *pidLoop {
+	return &pidLoop{
+		target: float64(target),
+		kp:     kp,
+		ki:     ki,
+		kd:     kd,
+		nodes:  make(map[string]*pidState),
+	}
+}
+
+// update advances the state of the node by one step and returns the controller output.
+func (l *pidLoop) update(nodeName string, utilization float64, now time.Time) float64 {
+	e := l.target - utilization
+	s, ok := l.nodes[nodeName]
+	if !ok {
+		s = &pidState{lastError: e}
+		l.nodes[nodeName] = s
+	}
+	s.integral = math.Max(-integralLimit, math.Min(integralLimit, s.integral+e))
+	derivative := e - s.lastError
+	s.lastError = e
+	s.lastSeen = now
+	return l.kp*e + l.ki*s.integral + l.kd*derivative
+}
+
+// prune drops the state of nodes which weren't scored for nodeStateTTL, e.g. deleted nodes.
+func (l *pidLoop) prune(now time.Time) {
+	for name, s := range l.nodes {
+		if now.Sub(s.lastSeen) > nodeStateTTL {
+			delete(l.nodes, name)
+		}
+	}
+}
+
+// builtInScores runs one step of the PID loop for every candidate node.
+func (p *PIDController) builtInScores(pod *v1.Pod, nodes []*v1.Node) (map[string]int64, error) {
+	podRequests := util.GetPodEffectiveRequest(pod)
+	now := time.Now()
+
+	p.loop.Lock()
+	defer p.loop.Unlock()
+	p.loop.prune(now)
+
+	scores := make(map[string]int64, len(nodes))
+	for _, node := range nodes {
+		nodeInfo, err := p.handle.SnapshotSharedLister().NodeInfos().Get(node.Name)
+		if err != nil {
+			return nil, fmt.Errorf("getting node %q from Snapshot: %w", node.Name, err)
+		}
+		utilization := nodeUtilization(nodeInfo, podRequests)
+		output := p.loop.update(node.Name, utilization, now)
+		scores[node.Name] = int64(math.Round(math.Max(float64(framework.MinNodeScore), math.Min(float64(framework.MaxNodeScore), output))))
+		klog.V(6).InfoS("PID step", "pod", klog.KObj(pod), "nodeName", node.Name,
+			"utilization", utilization, "output", output, "score", scores[node.Name])
+	}
+	return scores, nil
+}
+
+// nodeUtilization returns the utilization percentage of the most utilized of CPU and
+// memory on the node, counting the requests of the pod being scheduled.
+func nodeUtilization(nodeInfo *framework.NodeInfo, podRequests v1.ResourceList) float64 {
+	cpu := percentage(nodeInfo.Requested.MilliCPU+podRequests.Cpu().MilliValue(), nodeInfo.Allocatable.MilliCPU)
+	memory := percentage(nodeInfo.Requested.Memory+podRequests.Memory().Value(), nodeInfo.Allocatable.Memory)
+	return math.Max(cpu, memory)
+}
+
+func percentage(requested, allocatable int64) float64 {
+	if allocatable == 0 {
+		return 100
+	}
+	return float64(requested) * 100 / float64(allocatable)
+}
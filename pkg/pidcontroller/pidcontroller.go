@@ -2,55 +2,97 @@ package pidcontroller
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/clock"
+
 	"sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 )
 
 const Name = "PIDController"
 const MaxNodeScore = framework.MaxNodeScore
 
+const (
+	// preScoreStateKey is the key in CycleState to the per-node scores computed at PreScore.
+	preScoreStateKey = "PreScore" + Name
+)
+
+var _ framework.PreScorePlugin = &PIDController{}
+var _ framework.ScorePlugin = &PIDController{}
+
 type PIDController struct {
 	handle      framework.Handle
 	client      *http.Client
 	endpointURL string
+	mode        config.PIDControllerMode
+	loop        *pidLoop
+
+	failurePolicy config.PIDControllerFailurePolicy
+	breaker       *circuitBreaker
+	lastKnownGood *scoreCache
+}
+
+// preScoreState holds the node scores of the current scheduling cycle.
+type preScoreState struct {
+	scores map[string]int64
+}
+
+// Clone the preScore state.
+func (s *preScoreState) Clone() framework.StateData {
+	return s
 }
 
 func (p *PIDController) Name() string {
 	return "PIDController"
 }
 
-func (p *PIDController) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
-	// Send GET request to the configured endpoint URL
-	resp, err := p.client.Get(p.endpointURL)
-	if err != nil {
-		return 0, framework.AsStatus(fmt.Errorf("error fetching score: %v", err))
+// PreScore computes the scores of all candidate nodes at once, either with a single
+// batched request to the external endpoint or by running the built-in PID loop, and
+// stores them in CycleState for Score to pick up. When the endpoint fails, the outcome
+// depends on the failure policy: the cycle fails, Score is skipped, or the last known
+// good scores are used.
+func (p *PIDController) PreScore(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodes []*v1.Node) *framework.Status {
+	if len(nodes) == 0 {
+		return nil
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, framework.AsStatus(fmt.Errorf("error reading response: %v", err))
+	var scores map[string]int64
+	switch p.mode {
+	case config.PIDControllerModeBuiltIn:
+		var err error
+		if scores, err = p.builtInScores(pod, nodes); err != nil {
+			return framework.AsStatus(err)
+		}
+	default:
+		var status *framework.Status
+		if scores, status = p.externalScores(ctx, pod, nodes); !status.IsSuccess() {
+			return status
+		}
 	}
 
-	var result map[string]int
-	if err = json.Unmarshal(body, &result); err != nil {
-		return 0, framework.AsStatus(fmt.Errorf("error parsing JSON: %v", err))
+	state.Write(preScoreStateKey, &preScoreState{scores: scores})
+	return nil
+}
+
+func (p *PIDController) Score(ctx context.Context, state *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
+	s, err := getPreScoreState(state)
+	if err != nil {
+		return 0, framework.AsStatus(err)
 	}
 
-	score, ok := result["score"]
+	score, ok := s.scores[nodeName]
 	if !ok {
-		return 0, framework.AsStatus(fmt.Errorf("score not found in the response"))
+		klog.V(5).InfoS("No score computed for node, using minimum score", "pod", klog.KObj(pod), "nodeName", nodeName)
+		return framework.MinNodeScore, nil
 	}
-
-	return int64(score), nil
+	return score, nil
 }
 
 func (p *PIDController) ScoreExtensions() framework.ScoreExtensions {
@@ -76,11 +118,27 @@ func (p *PIDController) NormalizeScore(ctx context.Context, state *framework.Cyc
 	return nil
 }
 
+func getPreScoreState(state *framework.CycleState) (*preScoreState, error) {
+	c, err := state.Read(preScoreStateKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading %q from cycleState: %w", preScoreStateKey, err)
+	}
+
+	s, ok := c.(*preScoreState)
+	if !ok {
+		return nil, fmt.Errorf("invalid PreScore state, got type %T", c)
+	}
+	return s, nil
+}
+
 func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	args, ok := obj.(*config.PIDControllerArgs)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type PIDControllerArgs, got %T", obj)
 	}
+	if err := validation.ValidatePIDControllerArgs(nil, args); err != nil {
+		return nil, err
+	}
 
 	// Dereference pointer fields with proper nil checks to prevent panics
 	var maxIdleConns, idleTimeoutSec, requestTimeoutSec int
@@ -107,9 +165,29 @@ func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (fram
 		Timeout: time.Duration(requestTimeoutSec) * time.Second,
 	}
 
+	klog.V(4).InfoS("Using PIDControllerArgs", "mode", args.Mode, "endpointURL", endpointURL,
+		"targetUtilization", args.TargetUtilization, "kp", args.Kp, "ki", args.Ki, "kd", args.Kd,
+		"failurePolicy", args.FailurePolicy, "circuitBreakerFailureThreshold", args.CircuitBreakerFailureThreshold)
+
+	RegisterMetrics()
+	realClock := clock.RealClock{}
 	return &PIDController{
-		handle:      handle,
-		client:      client,
-		endpointURL: endpointURL,
+		handle:        handle,
+		client:        client,
+		endpointURL:   endpointURL,
+		mode:          args.Mode,
+		loop:          newPIDLoop(args.TargetUtilization, args.Kp, args.Ki, args.Kd),
+		failurePolicy: args.FailurePolicy,
+		breaker: newCircuitBreaker(profileName(handle), endpointURL, args.CircuitBreakerFailureThreshold,
+			time.Duration(args.CircuitBreakerOpenSeconds)*time.Second, realClock),
+		lastKnownGood: newScoreCache(time.Duration(args.LastKnownGoodMaxAgeSeconds)*time.Second, realClock),
 	}, nil
 }
+
+// profileName returns the name of the scheduler profile of the plugin, if known.
+func profileName(handle framework.Handle) string {
+	if fwk, ok := handle.(framework.Framework); ok {
+		return fwk.ProfileName()
+	}
+	return ""
+}
@@ -0,0 +1,339 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pidcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/queuesort"
+	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
+	testingclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/scheduler-plugins/apis/config"
+	testutil "sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func newTestPlugin(t *testing.T, args *config.PIDControllerArgs, pods []*v1.Pod, nodes []*v1.Node) *PIDController {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	fh, err := testutil.NewFramework(ctx, registeredPlugins, nil, "default-scheduler",
+		runtime.WithSnapshotSharedLister(testutil.NewFakeSharedLister(pods, nodes)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := New(ctx, args, fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*PIDController)
+}
+
+func runScoring(t *testing.T, p *PIDController, pod *v1.Pod, nodes []*v1.Node) map[string]int64 {
+	state := framework.NewCycleState()
+	if status := p.PreScore(context.Background(), state, pod, nodes); !status.IsSuccess() {
+		t.Fatalf("PreScore failed: %v", status)
+	}
+	scores := make(map[string]int64)
+	for _, node := range nodes {
+		score, status := p.Score(context.Background(), state, pod, node.Name)
+		if !status.IsSuccess() {
+			t.Fatalf("Score failed: %v", status)
+		}
+		scores[node.Name] = score
+	}
+	return scores
+}
+
+func TestExternalScores(t *testing.T) {
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-a").Obj(),
+		st.MakeNode().Name("node-b").Obj(),
+		st.MakeNode().Name("node-c").Obj(),
+	}
+	pod := st.MakePod().Namespace("ns").Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "500m"}).Obj()
+
+	tests := []struct {
+		name     string
+		response string
+		want     map[string]int64
+	}{
+		{
+			name:     "per-node scores",
+			response: `{"scores": {"node-a": 10, "node-b": 80, "node-c": -5}}`,
+			want:     map[string]int64{"node-a": 10, "node-b": 80, "node-c": 0},
+		},
+		{
+			name:     "single score applied to every node",
+			response: `{"score": 42}`,
+			want:     map[string]int64{"node-a": 42, "node-b": 42, "node-c": 42},
+		},
+		{
+			name:     "missing node gets the minimum score",
+			response: `{"scores": {"node-a": 30}}`,
+			want:     map[string]int64{"node-a": 30, "node-b": 0, "node-c": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				var req ScoreRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("decoding request: %v", err)
+				}
+				if diff := cmp.Diff([]string{"node-a", "node-b", "node-c"}, req.Nodes); diff != "" {
+					t.Errorf("unexpected nodes (-want, +got):\n%s", diff)
+				}
+				if req.Pod.Name != "p" || req.Pod.Requests.Cpu().MilliValue() != 500 {
+					t.Errorf("unexpected pod in request: %+v", req.Pod)
+				}
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			p := newTestPlugin(t, &config.PIDControllerArgs{
+				EndpointURL:   pointer.String(server.URL),
+				Mode:          config.PIDControllerModeExternal,
+				FailurePolicy: config.PIDControllerFailurePolicyFail,
+			}, nil, nodes)
+			got := runScoring(t, p, pod, nodes)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("unexpected scores (-want, +got):\n%s", diff)
+			}
+			if requests != 1 {
+				t.Errorf("expected a single batched request, got %d", requests)
+			}
+		})
+	}
+}
+
+func TestExternalScoresFailurePolicy(t *testing.T) {
+	nodes := []*v1.Node{
+		st.MakeNode().Name("node-a").Obj(),
+		st.MakeNode().Name("node-b").Obj(),
+	}
+	pod := st.MakePod().Name("p").Obj()
+
+	tests := []struct {
+		name          string
+		failurePolicy config.PIDControllerFailurePolicy
+		elapsed       time.Duration
+		wantCode      framework.Code
+		wantScores    map[string]int64
+	}{
+		{
+			name:          "fail",
+			failurePolicy: config.PIDControllerFailurePolicyFail,
+			wantCode:      framework.Error,
+		},
+		{
+			name:          "skip",
+			failurePolicy: config.PIDControllerFailurePolicySkip,
+			wantCode:      framework.Skip,
+		},
+		{
+			name:          "last known good",
+			failurePolicy: config.PIDControllerFailurePolicyLastKnownGood,
+			elapsed:       30 * time.Second,
+			wantCode:      framework.Success,
+			wantScores:    map[string]int64{"node-a": 20, "node-b": 70},
+		},
+		{
+			name:          "last known good too old",
+			failurePolicy: config.PIDControllerFailurePolicyLastKnownGood,
+			elapsed:       2 * time.Minute,
+			wantCode:      framework.Skip,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthy := true
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if !healthy {
+					w.Write([]byte(`not json`))
+					return
+				}
+				w.Write([]byte(`{"scores": {"node-a": 20, "node-b": 70}}`))
+			}))
+			defer server.Close()
+
+			fakeClock := testingclock.NewFakeClock(time.Now())
+			p := newTestPlugin(t, &config.PIDControllerArgs{
+				EndpointURL:                &server.URL,
+				Mode:                       config.PIDControllerModeExternal,
+				FailurePolicy:              tt.failurePolicy,
+				LastKnownGoodMaxAgeSeconds: 60,
+			}, nil, nodes)
+			p.lastKnownGood = newScoreCache(time.Minute, fakeClock)
+
+			runScoring(t, p, pod, nodes)
+			healthy = false
+			fakeClock.Step(tt.elapsed)
+
+			state := framework.NewCycleState()
+			status := p.PreScore(context.Background(), state, pod, nodes)
+			if status.Code() != tt.wantCode {
+				t.Fatalf("expected status code %v, got %v", tt.wantCode, status)
+			}
+			if tt.wantScores == nil {
+				return
+			}
+			s, err := getPreScoreState(state)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantScores, s.scores); diff != "" {
+				t.Errorf("unexpected scores (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	requests := 0
+	healthy := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if !healthy {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"score": 50}`))
+	}))
+	defer server.Close()
+
+	nodes := []*v1.Node{st.MakeNode().Name("node-a").Obj()}
+	pod := st.MakePod().Name("p").Obj()
+	fakeClock := testingclock.NewFakeClock(time.Now())
+	p := newTestPlugin(t, &config.PIDControllerArgs{
+		EndpointURL:   &server.URL,
+		Mode:          config.PIDControllerModeExternal,
+		FailurePolicy: config.PIDControllerFailurePolicySkip,
+	}, nil, nodes)
+	p.breaker = newCircuitBreaker("", server.URL, 3, 30*time.Second, fakeClock)
+
+	preScore := func() *framework.Status {
+		return p.PreScore(context.Background(), framework.NewCycleState(), pod, nodes)
+	}
+
+	for i := 0; i < 5; i++ {
+		if status := preScore(); status.Code() != framework.Skip {
+			t.Fatalf("expected scoring to be skipped, got %v", status)
+		}
+	}
+	if requests != 3 {
+		t.Errorf("expected the breaker to open after 3 failed requests, got %d requests", requests)
+	}
+
+	// the trial request fails, the breaker opens again
+	fakeClock.Step(31 * time.Second)
+	preScore()
+	preScore()
+	if requests != 4 {
+		t.Errorf("expected a single trial request, got %d requests", requests)
+	}
+
+	// the trial request succeeds, the breaker closes
+	healthy = true
+	fakeClock.Step(31 * time.Second)
+	for i := 0; i < 2; i++ {
+		if status := preScore(); !status.IsSuccess() {
+			t.Fatalf("expected scoring to succeed, got %v", status)
+		}
+	}
+	if requests != 6 {
+		t.Errorf("expected the endpoint to be called once the breaker closed, got %d requests", requests)
+	}
+}
+
+func TestBuiltInScores(t *testing.T) {
+	capacity := map[v1.ResourceName]string{v1.ResourceCPU: "10", v1.ResourceMemory: "10Gi"}
+	nodes := []*v1.Node{
+		st.MakeNode().Name("idle").Capacity(capacity).Obj(),
+		st.MakeNode().Name("busy").Capacity(capacity).Obj(),
+		st.MakeNode().Name("full").Capacity(capacity).Obj(),
+	}
+	pods := []*v1.Pod{
+		st.MakePod().Name("p1").Node("busy").Req(map[v1.ResourceName]string{v1.ResourceCPU: "4"}).Obj(),
+		st.MakePod().Name("p2").Node("full").Req(map[v1.ResourceName]string{v1.ResourceMemory: "8Gi"}).Obj(),
+	}
+	pod := st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "1"}).Obj()
+
+	p := newTestPlugin(t, &config.PIDControllerArgs{
+		Mode:              config.PIDControllerModeBuiltIn,
+		TargetUtilization: 60,
+		Kp:                1,
+		Ki:                0.1,
+		FailurePolicy:     config.PIDControllerFailurePolicyFail,
+	}, pods, nodes)
+
+	// errors: idle 60-10=50, busy 60-50=10, full 60-80=-20
+	want := map[string]int64{"idle": 55, "busy": 11, "full": 0}
+	if diff := cmp.Diff(want, runScoring(t, p, pod, nodes)); diff != "" {
+		t.Errorf("unexpected scores on first cycle (-want, +got):\n%s", diff)
+	}
+
+	// the integral term carries over to the next cycle
+	want = map[string]int64{"idle": 60, "busy": 12, "full": 0}
+	if diff := cmp.Diff(want, runScoring(t, p, pod, nodes)); diff != "" {
+		t.Errorf("unexpected scores on second cycle (-want, +got):\n%s", diff)
+	}
+}
+
+func TestPIDLoop(t *testing.T) {
+	now := time.Now()
+	l := newPIDLoop(50, 1, 0.5, 2)
+
+	if got := l.update("n", 40, now); got != 15 {
+		t.Errorf("first step: expected 15, got %v", got)
+	}
+	// error went from 10 to 20: integral 30, derivative 10
+	if got := l.update("n", 30, now); got != 55 {
+		t.Errorf("second step: expected 55, got %v", got)
+	}
+	for i := 0; i < 1000; i++ {
+		l.update("n", 0, now)
+	}
+	if got := l.nodes["n"].integral; got != integralLimit {
+		t.Errorf("expected integral to be bounded to %v, got %v", integralLimit, got)
+	}
+
+	l.prune(now.Add(nodeStateTTL + time.Second))
+	if _, ok := l.nodes["n"]; ok {
+		t.Errorf("expected state of node not seen for %v to be pruned", nodeStateTTL)
+	}
+}
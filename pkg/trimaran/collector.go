@@ -17,6 +17,7 @@ limitations under the License.
 package trimaran
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -25,36 +26,111 @@ import (
 	loadwatcherapi "github.com/paypal/load-watcher/pkg/watcher/api"
 
 	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
 )
 
 const (
-	metricsUpdateIntervalSeconds = 30
+	defaultMetricsUpdateIntervalSeconds = 30
+)
+
+// MetricsStatus tells whether the metrics collected from load watcher can be used.
+type MetricsStatus int
+
+const (
+	// MetricsUnavailable means that no metrics were ever collected.
+	MetricsUnavailable MetricsStatus = iota
+	// MetricsStale means that the latest metrics window is older than the configured max age.
+	MetricsStale
+	// MetricsFresh means that the latest metrics window can be used.
+	MetricsFresh
+)
+
+var (
+	// collectors holds the collectors shared by the Trimaran plugins, keyed by their spec.
+	collectors     = make(map[pluginConfig.TrimaranSpec]*Collector)
+	collectorsLock sync.Mutex
 )
 
 // Collector : get data from load watcher, encapsulating the load watcher and its operations
 //
-// Trimaran plugins have different, potentially conflicting, objectives. Thus, it is recommended not
-// to enable them concurrently. As such, they are currently designed to each have its own Collector.
-// If a need arises in the future to enable multiple Trimaran plugins, a restructuring to have a single
-// Collector, serving the multiple plugins, may be beneficial for performance reasons.
+// A single Collector is shared by all the Trimaran plugins of the process configured with the
+// same TrimaranSpec, see GetCollector.
 type Collector struct {
 	// load watcher client
 	client loadwatcherapi.Client
+	// interval between two metrics updates
+	updateInterval time.Duration
+	// age of the metrics window after which metrics are stale, zero means never
+	maxAge time.Duration
 	// data collected by load watcher
 	metrics watcher.WatcherMetrics
+	// time of the last successful metrics update
+	lastUpdate time.Time
 	// for safe access to metrics
 	mu sync.RWMutex
+
+	// number of plugins using this collector, and the function stopping its updates;
+	// both are guarded by collectorsLock
+	refs int
+	stop context.CancelFunc
+}
+
+// GetCollector : get the collector shared by the Trimaran plugins configured with trimaranSpec,
+// creating it if needed. The collector stops updating its metrics once the contexts of all the
+// plugins which got it are done.
+func GetCollector(ctx context.Context, trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
+	collectorsLock.Lock()
+	defer collectorsLock.Unlock()
+
+	spec := *trimaranSpec
+	collector, ok := collectors[spec]
+	if !ok {
+		collectorCtx, cancel := context.WithCancel(context.Background())
+		var err error
+		collector, err = NewCollector(collectorCtx, trimaranSpec)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		collector.stop = cancel
+		collectors[spec] = collector
+	}
+	collector.refs++
+	klog.V(4).InfoS("Using shared collector", "watcher", spec.WatcherAddress, "type", spec.MetricProvider.Type, "refs", collector.refs)
+
+	go func() {
+		<-ctx.Done()
+		releaseCollector(spec, collector)
+	}()
+	return collector, nil
 }
 
-// NewCollector : create an instance of a data collector
-func NewCollector(trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
+// releaseCollector : drop a reference to the shared collector, stopping it when unused
+func releaseCollector(spec pluginConfig.TrimaranSpec, collector *Collector) {
+	collectorsLock.Lock()
+	defer collectorsLock.Unlock()
+
+	collector.refs--
+	if collector.refs > 0 {
+		return
+	}
+	klog.V(4).InfoS("Stopping shared collector", "watcher", spec.WatcherAddress, "type", spec.MetricProvider.Type)
+	collector.stop()
+	if collectors[spec] == collector {
+		delete(collectors, spec)
+	}
+}
+
+// NewCollector : create an instance of a data collector, updating its metrics until ctx is done
+func NewCollector(ctx context.Context, trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
 	if err := checkSpecs(trimaranSpec); err != nil {
 		return nil, err
 	}
 	klog.V(4).InfoS("Using TrimaranSpec", "type", trimaranSpec.MetricProvider.Type,
-		"address", trimaranSpec.MetricProvider.Address, "watcher", trimaranSpec.WatcherAddress)
+		"address", trimaranSpec.MetricProvider.Address, "watcher", trimaranSpec.WatcherAddress,
+		"updateIntervalSeconds", trimaranSpec.MetricsUpdateIntervalSeconds, "maxAgeSeconds", trimaranSpec.MetricsMaxAgeSeconds)
 
 	var client loadwatcherapi.Client
 	if trimaranSpec.WatcherAddress != "" {
@@ -69,8 +145,14 @@ func NewCollector(trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
 		client, _ = loadwatcherapi.NewLibraryClient(opts)
 	}
 
+	updateIntervalSeconds := trimaranSpec.MetricsUpdateIntervalSeconds
+	if updateIntervalSeconds <= 0 {
+		updateIntervalSeconds = defaultMetricsUpdateIntervalSeconds
+	}
 	collector := &Collector{
-		client: client,
+		client:         client,
+		updateInterval: time.Duration(updateIntervalSeconds) * time.Second,
+		maxAge:         time.Duration(trimaranSpec.MetricsMaxAgeSeconds) * time.Second,
 	}
 
 	// populate metrics before returning
@@ -80,11 +162,17 @@ func NewCollector(trimaranSpec *pluginConfig.TrimaranSpec) (*Collector, error) {
 	}
 	// start periodic updates
 	go func() {
-		metricsUpdaterTicker := time.NewTicker(time.Second * metricsUpdateIntervalSeconds)
-		for range metricsUpdaterTicker.C {
-			err = collector.updateMetrics()
-			if err != nil {
-				klog.ErrorS(err, "Unable to update metrics")
+		metricsUpdaterTicker := time.NewTicker(collector.updateInterval)
+		defer metricsUpdaterTicker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				klog.V(4).InfoS("Stopped updating metrics", "watcher", trimaranSpec.WatcherAddress)
+				return
+			case <-metricsUpdaterTicker.C:
+				if err := collector.updateMetrics(); err != nil {
+					klog.ErrorS(err, "Unable to update metrics")
+				}
 			}
 		}
 	}()
@@ -115,6 +203,67 @@ func (collector *Collector) GetNodeMetrics(nodeName string) ([]watcher.Metric, *
 	return allMetrics.Data.NodeMetricsMap[nodeName].Metrics, allMetrics
 }
 
+// MetricsAge : get how old the latest metrics window is, and false if no metrics were ever collected.
+// The age is measured from the end of the window, or from the last update if the window has no end.
+func (collector *Collector) MetricsAge() (time.Duration, bool) {
+	collector.mu.RLock()
+	defer collector.mu.RUnlock()
+	if collector.metrics.Data.NodeMetricsMap == nil {
+		return 0, false
+	}
+	if collector.metrics.Window.End > 0 {
+		return time.Since(time.Unix(collector.metrics.Window.End, 0)), true
+	}
+	return time.Since(collector.lastUpdate), true
+}
+
+// MetricsStatus : tell apart missing, stale and fresh metrics
+func (collector *Collector) MetricsStatus() (MetricsStatus, time.Duration) {
+	age, ok := collector.MetricsAge()
+	if !ok {
+		return MetricsUnavailable, 0
+	}
+	if collector.maxAge > 0 && age > collector.maxAge {
+		return MetricsStale, age
+	}
+	return MetricsFresh, age
+}
+
+// metricsStatusState : the status of the metrics, checked once per scheduling cycle
+type metricsStatusState struct {
+	status MetricsStatus
+}
+
+func (s *metricsStatusState) Clone() framework.StateData {
+	return s
+}
+
+func metricsStatusStateKey(pluginName string) framework.StateKey {
+	return framework.StateKey(pluginName + "/metricsStatus")
+}
+
+// SaveMetricsStatus : check the status of the metrics once per scheduling cycle, meant to be called in PreScore,
+// and log if they are stale
+func (collector *Collector) SaveMetricsStatus(cycleState *framework.CycleState, pluginName string) {
+	status, age := collector.MetricsStatus()
+	if status == MetricsStale {
+		klog.V(4).InfoS("Metrics from watcher are stale", "plugin", pluginName, "age", age)
+	}
+	cycleState.Write(metricsStatusStateKey(pluginName), &metricsStatusState{status: status})
+}
+
+// MetricsStale : tell if the metrics are stale in this scheduling cycle, as saved by SaveMetricsStatus,
+// or as of now if the status was not saved
+func (collector *Collector) MetricsStale(cycleState *framework.CycleState, pluginName string) bool {
+	if data, err := cycleState.Read(metricsStatusStateKey(pluginName)); err == nil {
+		if s, ok := data.(*metricsStatusState); ok {
+			return s.status == MetricsStale
+		}
+	}
+	status, _ := collector.MetricsStatus()
+	return status == MetricsStale
+}
+
 // checkSpecs : check trimaran specs
 func checkSpecs(trimaranSpec *pluginConfig.TrimaranSpec) error {
 	if trimaranSpec.WatcherAddress == "" {
@@ -138,6 +287,7 @@ func (collector *Collector) updateMetrics() error {
 	}
 	collector.mu.Lock()
 	collector.metrics = *metrics
+	collector.lastUpdate = time.Now()
 	collector.mu.Unlock()
 	return nil
 }
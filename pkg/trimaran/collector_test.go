@@ -17,13 +17,17 @@ limitations under the License.
 package trimaran
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
 
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
 )
@@ -74,7 +78,7 @@ var (
 )
 
 func TestNewCollector(t *testing.T) {
-	col, err := NewCollector(&args)
+	col, err := NewCollector(context.TODO(), &args)
 	assert.NotNil(t, col)
 	assert.Nil(t, err)
 }
@@ -95,7 +99,7 @@ func TestNewCollectorSpecs(t *testing.T) {
 		MetricProvider: metricProvider,
 	}
 
-	col, err := NewCollector(&trimaranSpec)
+	col, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.Nil(t, col)
 	expectedErr := "invalid MetricProvider.Type, got " + string(metricProvider.Type)
 	assert.EqualError(t, err, expectedErr)
@@ -112,7 +116,7 @@ func TestGetAllMetrics(t *testing.T) {
 	trimaranSpec := pluginConfig.TrimaranSpec{
 		WatcherAddress: server.URL,
 	}
-	collector, err := NewCollector(&trimaranSpec)
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 
@@ -133,7 +137,7 @@ func TestUpdateMetrics(t *testing.T) {
 	trimaranSpec := pluginConfig.TrimaranSpec{
 		WatcherAddress: server.URL,
 	}
-	collector, err := NewCollector(&trimaranSpec)
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 
@@ -152,7 +156,7 @@ func TestGetNodeMetrics(t *testing.T) {
 	trimaranSpec := pluginConfig.TrimaranSpec{
 		WatcherAddress: server.URL,
 	}
-	collector, err := NewCollector(&trimaranSpec)
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 	nodeName := "node-1"
@@ -174,7 +178,7 @@ func TestGetNodeMetricsNilForNode(t *testing.T) {
 	trimaranSpec := pluginConfig.TrimaranSpec{
 		WatcherAddress: server.URL,
 	}
-	collector, err := NewCollector(&trimaranSpec)
+	collector, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.NotNil(t, collector)
 	assert.Nil(t, err)
 	nodeName := "node-1"
@@ -204,7 +208,112 @@ func TestNewCollectorLoadWatcher(t *testing.T) {
 		MetricProvider: metricProvider,
 	}
 
-	col, err := NewCollector(&trimaranSpec)
+	col, err := NewCollector(context.TODO(), &trimaranSpec)
 	assert.NotNil(t, col)
 	assert.Nil(t, err)
 }
+
+func TestGetCollectorShared(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcherResponse)
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	trimaranSpec := pluginConfig.TrimaranSpec{
+		WatcherAddress: server.URL,
+	}
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	col1, err := GetCollector(ctx1, &trimaranSpec)
+	assert.Nil(t, err)
+	col2, err := GetCollector(ctx2, &trimaranSpec)
+	assert.Nil(t, err)
+	assert.Same(t, col1, col2)
+
+	otherSpec := trimaranSpec
+	otherSpec.MetricsUpdateIntervalSeconds = 10
+	col3, err := GetCollector(ctx1, &otherSpec)
+	assert.Nil(t, err)
+	assert.NotSame(t, col1, col3)
+
+	registered := func(spec pluginConfig.TrimaranSpec) bool {
+		collectorsLock.Lock()
+		defer collectorsLock.Unlock()
+		_, ok := collectors[spec]
+		return ok
+	}
+	cancel1()
+	assert.Eventually(t, func() bool { return !registered(otherSpec) }, wait.ForeverTestTimeout, 10*time.Millisecond)
+	assert.True(t, registered(trimaranSpec))
+	cancel2()
+	assert.Eventually(t, func() bool { return !registered(trimaranSpec) }, wait.ForeverTestTimeout, 10*time.Millisecond)
+}
+
+func TestMetricsStatus(t *testing.T) {
+	now := time.Now().Unix()
+	tests := []struct {
+		name       string
+		maxAge     time.Duration
+		metrics    watcher.WatcherMetrics
+		lastUpdate time.Time
+		want       MetricsStatus
+	}{
+		{
+			name: "no metrics",
+			want: MetricsUnavailable,
+		},
+		{
+			name:    "recent window",
+			maxAge:  time.Minute,
+			metrics: watcher.WatcherMetrics{Window: watcher.Window{Start: now - 60, End: now}, Data: watcherResponse.Data},
+			want:    MetricsFresh,
+		},
+		{
+			name:    "old window",
+			maxAge:  time.Minute,
+			metrics: watcher.WatcherMetrics{Window: watcher.Window{Start: now - 600, End: now - 300}, Data: watcherResponse.Data},
+			want:    MetricsStale,
+		},
+		{
+			name:    "old window without max age",
+			metrics: watcher.WatcherMetrics{Window: watcher.Window{Start: now - 600, End: now - 300}, Data: watcherResponse.Data},
+			want:    MetricsFresh,
+		},
+		{
+			name:       "no window end, old update",
+			maxAge:     time.Minute,
+			metrics:    watcher.WatcherMetrics{Data: watcherResponse.Data},
+			lastUpdate: time.Now().Add(-5 * time.Minute),
+			want:       MetricsStale,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := &Collector{
+				maxAge:     tt.maxAge,
+				metrics:    tt.metrics,
+				lastUpdate: tt.lastUpdate,
+			}
+			status, _ := collector.MetricsStatus()
+			assert.Equal(t, tt.want, status)
+		})
+	}
+}
+
+func TestMetricsStaleOncePerCycle(t *testing.T) {
+	now := time.Now().Unix()
+	collector := &Collector{
+		maxAge:  time.Minute,
+		metrics: watcher.WatcherMetrics{Window: watcher.Window{Start: now - 600, End: now - 300}, Data: watcherResponse.Data},
+	}
+	cycleState := framework.NewCycleState()
+	assert.True(t, collector.MetricsStale(cycleState, "test"))
+
+	collector.SaveMetricsStatus(cycleState, "test")
+	// fresh metrics arriving during the cycle do not change the status saved for the cycle
+	collector.metrics = watcher.WatcherMetrics{Window: watcher.Window{Start: now - 60, End: now}, Data: watcherResponse.Data}
+	assert.True(t, collector.MetricsStale(cycleState, "test"))
+	assert.False(t, collector.MetricsStale(framework.NewCycleState(), "test"))
+}
@@ -49,17 +49,18 @@ type LoadVariationRiskBalancing struct {
 	args         *pluginConfig.LoadVariationRiskBalancingArgs
 }
 
+var _ framework.PreScorePlugin = &LoadVariationRiskBalancing{}
 var _ framework.ScorePlugin = &LoadVariationRiskBalancing{}
 
 // New : create an instance of a LoadVariationRiskBalancing plugin
-func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	klog.V(4).InfoS("Creating new instance of the LoadVariationRiskBalancing plugin")
 	// cast object into plugin arguments object
 	args, ok := obj.(*pluginConfig.LoadVariationRiskBalancingArgs)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type LoadVariationRiskBalancingArgs, got %T", obj)
 	}
-	collector, err := trimaran.NewCollector(&args.TrimaranSpec)
+	collector, err := trimaran.GetCollector(ctx, &args.TrimaranSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +78,12 @@ func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framew
 	return pl, nil
 }
 
+// PreScore : check the status of the metrics once for the scheduling cycle
+func (pl *LoadVariationRiskBalancing) PreScore(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodes []*v1.Node) *framework.Status {
+	pl.collector.SaveMetricsStatus(cycleState, Name)
+	return nil
+}
+
 // Score : evaluate score for a node
 func (pl *LoadVariationRiskBalancing) Score(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
 	klog.V(6).InfoS("Calculating score", "pod", klog.KObj(pod), "nodeName", nodeName)
@@ -86,6 +93,9 @@ func (pl *LoadVariationRiskBalancing) Score(ctx context.Context, cycleState *fra
 		return score, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
 	}
 	// get node metrics
+	if pl.collector.MetricsStale(cycleState, Name) {
+		return score, nil
+	}
 	metrics, _ := pl.collector.GetNodeMetrics(nodeName)
 	if metrics == nil {
 		klog.InfoS("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
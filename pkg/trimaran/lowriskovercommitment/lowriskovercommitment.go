@@ -58,14 +58,14 @@ type LowRiskOverCommitment struct {
 }
 
 // New : create an instance of a LowRiskOverCommitment plugin
-func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	klog.V(4).InfoS("Creating new instance of the LowRiskOverCommitment plugin")
 	// cast object into plugin arguments object
 	args, ok := obj.(*pluginConfig.LowRiskOverCommitmentArgs)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type LowRiskOverCommitmentArgs, got %T", obj)
 	}
-	collector, err := trimaran.NewCollector(&args.TrimaranSpec)
+	collector, err := trimaran.GetCollector(ctx, &args.TrimaranSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +93,7 @@ func (pl *LowRiskOverCommitment) PreScore(ctx context.Context, cycleState *frame
 	klog.V(6).InfoS("PreScore: Calculating pod resource requests and limits", "pod", klog.KObj(pod))
 	podResourcesStateData := CreatePodResourcesStateData(pod)
 	cycleState.Write(PodResourcesKey, podResourcesStateData)
+	pl.collector.SaveMetricsStatus(cycleState, Name)
 	return nil
 }
 
@@ -126,6 +127,9 @@ func (pl *LowRiskOverCommitment) Score(ctx context.Context, cycleState *framewor
 		return score, framework.NewStatus(framework.Error, fmt.Sprintf("getting node %q from Snapshot: %v", nodeName, err))
 	}
 	// get node metrics
+	if pl.collector.MetricsStale(cycleState, Name) {
+		return score, nil
+	}
 	metrics, _ := pl.collector.GetNodeMetrics(nodeName)
 	if metrics == nil {
 		klog.InfoS("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
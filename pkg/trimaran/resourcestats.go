@@ -236,7 +236,7 @@ func SetMaxLimits(requests *framework.Resource, limits *framework.Resource) {
 	}
 	for k, v := range requests.ScalarResources {
 		if limits.ScalarResources[k] < v {
-			limits.ScalarResources[k] = v
+			limits.SetScalar(k, v)
 		}
 	}
 }
@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetloadpacking
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// Nodes which were not scored for that long are forgotten.
+	nodeStateTTL = 10 * time.Minute
+)
+
+var (
+	fallbackNodesGauge = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Subsystem:      "scheduler_target_load_packing",
+			Name:           "fallback_nodes",
+			Help:           "Number of nodes scored from their allocated resources because their load metrics are missing, by scheduler profile.",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"profile"})
+
+	registerMetrics sync.Once
+)
+
+// RegisterMetrics registers the TargetLoadPacking metrics to the legacy registry served by the scheduler.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(fallbackNodesGauge)
+	})
+}
+
+// missingMetrics is the state of a node whose load metrics are missing.
+type missingMetrics struct {
+	since    time.Time
+	lastSeen time.Time
+	fallback bool
+}
+
+// fallbackTracker remembers across scheduling cycles since when the load metrics of every
+// node are missing, and decides when a node is scored from its allocated CPU instead.
+type fallbackTracker struct {
+	// how long metrics may be missing before falling back, negative disables the fallback
+	gracePeriod time.Duration
+	clock       clock.PassiveClock
+	// the number of fallback nodes of the scheduler profile of the plugin
+	gauge metrics.GaugeMetric
+
+	sync.Mutex
+	nodes map[string]*missingMetrics
+}
+
+func newFallbackTracker(profile string, gracePeriod time.Duration, clock clock.PassiveClock) *fallbackTracker {
+	return &fallbackTracker{
+		gracePeriod: gracePeriod,
+		clock:       clock,
+		gauge:       fallbackNodesGauge.WithLabelValues(profile),
+		nodes:       make(map[string]*missingMetrics),
+	}
+}
+
+// metricsMissing records that the metrics of the node are missing and tells whether the
+// node should be scored by allocation.
+func (t *fallbackTracker) metricsMissing(nodeName string) bool {
+	if t.gracePeriod < 0 {
+		return false
+	}
+	t.Lock()
+	defer t.Unlock()
+
+	now := t.clock.Now()
+	state, ok := t.nodes[nodeName]
+	if !ok {
+		state = &missingMetrics{since: now}
+		t.nodes[nodeName] = state
+	}
+	state.lastSeen = now
+	if !state.fallback && now.Sub(state.since) >= t.gracePeriod {
+		state.fallback = true
+		klog.InfoS("Metrics missing for node; scoring it by allocated resources", "nodeName", nodeName, "missingSince", state.since)
+		t.updateGauge()
+	}
+	return state.fallback
+}
+
+// metricsFound records that the metrics of the node are available again.
+func (t *fallbackTracker) metricsFound(nodeName string) {
+	if t.gracePeriod < 0 {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+
+	state, ok := t.nodes[nodeName]
+	if !ok {
+		return
+	}
+	delete(t.nodes, nodeName)
+	if state.fallback {
+		klog.InfoS("Metrics available again for node; scoring it by load", "nodeName", nodeName)
+		t.updateGauge()
+	}
+}
+
+// fallbackNodes returns the sorted names of the nodes currently scored by allocation.
+func (t *fallbackTracker) fallbackNodes() []string {
+	t.Lock()
+	defer t.Unlock()
+
+	var names []string
+	for name, state := range t.nodes {
+		if state.fallback {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// prune forgets the nodes which were not scored recently, e.g. because they were deleted.
+// It is called once per scheduling cycle rather than on every node scored.
+func (t *fallbackTracker) prune() {
+	if t.gracePeriod < 0 {
+		return
+	}
+	t.Lock()
+	defer t.Unlock()
+
+	now := t.clock.Now()
+	pruned := false
+	for name, state := range t.nodes {
+		if now.Sub(state.lastSeen) > nodeStateTTL {
+			delete(t.nodes, name)
+			pruned = pruned || state.fallback
+		}
+	}
+	if pruned {
+		t.updateGauge()
+	}
+}
+
+func (t *fallbackTracker) updateGauge() {
+	count := 0
+	for _, state := range t.nodes {
+		if state.fallback {
+			count++
+		}
+	}
+	t.gauge.Set(float64(count))
+}
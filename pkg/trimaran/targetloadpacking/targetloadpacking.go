@@ -15,7 +15,7 @@ limitations under the License.
 */
 
 /*
-targetloadpacking package provides K8s scheduler plugin for best-fit variant of bin packing based on CPU utilization around a target load
+targetloadpacking package provides K8s scheduler plugin for best-fit variant of bin packing based on CPU and memory utilization around a target load
 It contains plugin for Score extension point.
 */
 
@@ -27,16 +27,19 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/clock"
 
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
-	cfgv1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
+	"sigs.k8s.io/scheduler-plugins/apis/config/validation"
 	"sigs.k8s.io/scheduler-plugins/pkg/trimaran"
 )
 
@@ -46,61 +49,118 @@ const (
 	metricsAgentReportingIntervalSeconds = 60
 )
 
-var (
-	requestsMilliCores           = cfgv1.DefaultRequestsMilliCores
-	hostTargetUtilizationPercent = cfgv1.DefaultTargetUtilizationPercent
-	requestsMultiplier           float64
-)
+// metricTypes maps the packed resources to the type of their load watcher metrics.
+var metricTypes = map[v1.ResourceName]string{
+	v1.ResourceCPU:    watcher.CPU,
+	v1.ResourceMemory: watcher.Memory,
+}
+
+// packedResource is a resource the plugin packs nodes around a target utilization of.
+// Resource amounts are in millicores for CPU and in bytes for memory.
+type packedResource struct {
+	name              v1.ResourceName
+	metricType        string
+	targetUtilization float64
+	weight            int64
+	// predicted usage of containers without requests or limits
+	defaultRequest int64
+}
 
 type TargetLoadPacking struct {
 	handle       framework.Handle
 	eventHandler *trimaran.PodAssignEventHandler
 	collector    *trimaran.Collector
+	fallback     *fallbackTracker
 	args         *pluginConfig.TargetLoadPackingArgs
+	resources    []packedResource
+	scoreMode    pluginConfig.TargetLoadPackingScoreMode
+	// predicted usage multiplier of the requests of containers without limits
+	requestsMultiplier float64
 }
 
+var _ framework.PreScorePlugin = &TargetLoadPacking{}
 var _ framework.ScorePlugin = &TargetLoadPacking{}
 
-func New(_ context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
+func New(ctx context.Context, obj runtime.Object, handle framework.Handle) (framework.Plugin, error) {
 	klog.V(4).InfoS("Creating new instance of the TargetLoadPacking plugin")
 	// cast object into plugin arguments object
 	args, ok := obj.(*pluginConfig.TargetLoadPackingArgs)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type TargetLoadPackingArgs, got %T", obj)
 	}
-	collector, err := trimaran.NewCollector(&args.TrimaranSpec)
-	if err != nil {
+	if err := validation.ValidateTargetLoadPackingArgs(nil, args); err != nil {
 		return nil, err
 	}
-
-	hostTargetUtilizationPercent = args.TargetUtilization
-	requestsMilliCores = args.DefaultRequests.Cpu().MilliValue()
-	requestsMultiplier, err = strconv.ParseFloat(args.DefaultRequestsMultiplier, 64)
+	requestsMultiplier, err := strconv.ParseFloat(args.DefaultRequestsMultiplier, 64)
 	if err != nil {
 		return nil, errors.New("unable to parse DefaultRequestsMultiplier: " + err.Error())
 	}
+	collector, err := trimaran.GetCollector(ctx, &args.TrimaranSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := args.Resources
+	if len(resources) == 0 {
+		resources = []pluginConfig.TargetLoadPackingResource{
+			{Name: v1.ResourceCPU, TargetUtilization: args.TargetUtilization, Weight: 1},
+		}
+	}
+	packedResources := make([]packedResource, 0, len(resources))
+	for _, r := range resources {
+		packedResources = append(packedResources, packedResource{
+			name:              r.Name,
+			metricType:        metricTypes[r.Name],
+			targetUtilization: float64(r.TargetUtilization),
+			weight:            r.Weight,
+			defaultRequest:    quantityValue(r.Name, args.DefaultRequests[r.Name]),
+		})
+	}
 
 	klog.V(4).InfoS("Using TargetLoadPackingArgs",
-		"requestsMilliCores", requestsMilliCores,
+		"resources", resources,
+		"scoreMode", args.ScoreMode,
+		"defaultRequests", args.DefaultRequests,
 		"requestsMultiplier", requestsMultiplier,
-		"targetUtilization", hostTargetUtilizationPercent)
+		"fallbackGracePeriodSeconds", args.FallbackGracePeriodSeconds)
 
 	podAssignEventHandler := trimaran.New()
 	podAssignEventHandler.AddToHandle(handle)
 
+	// the metrics must be registered before the fallback tracker gets its gauge
+	RegisterMetrics()
 	pl := &TargetLoadPacking{
-		handle:       handle,
-		eventHandler: podAssignEventHandler,
-		collector:    collector,
-		args:         args,
+		handle:             handle,
+		eventHandler:       podAssignEventHandler,
+		collector:          collector,
+		fallback:           newFallbackTracker(profileName(handle), time.Duration(args.FallbackGracePeriodSeconds)*time.Second, clock.RealClock{}),
+		args:               args,
+		resources:          packedResources,
+		scoreMode:          args.ScoreMode,
+		requestsMultiplier: requestsMultiplier,
 	}
 	return pl, nil
 }
 
+// profileName returns the name of the scheduler profile of the plugin, if known.
+func profileName(handle framework.Handle) string {
+	if fwk, ok := handle.(framework.Framework); ok {
+		return fwk.ProfileName()
+	}
+	return ""
+}
+
 func (pl *TargetLoadPacking) Name() string {
 	return Name
 }
 
+// PreScore : check the status of the metrics once for the scheduling cycle
+func (pl *TargetLoadPacking) PreScore(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodes []*v1.Node) *framework.Status {
+	pl.collector.SaveMetricsStatus(cycleState, Name)
+	pl.fallback.prune()
+	return nil
+}
+
 func (pl *TargetLoadPacking) Score(ctx context.Context, cycleState *framework.CycleState, pod *v1.Pod, nodeName string) (int64, *framework.Status) {
 	score := framework.MinNodeScore
 	nodeInfo, err := pl.handle.SnapshotSharedLister().NodeInfos().Get(nodeName)
@@ -110,45 +170,80 @@ func (pl *TargetLoadPacking) Score(ctx context.Context, cycleState *framework.Cy
 
 	// get node metrics
 	metrics, allMetrics := pl.collector.GetNodeMetrics(nodeName)
+	if pl.collector.MetricsStale(cycleState, Name) {
+		metrics = nil
+	}
 	if metrics == nil {
-		klog.InfoS("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
-		// Avoid the node by scoring minimum
-		return score, nil
-		// TODO(aqadeer): If this happens for a long time, fall back to allocation based packing. This could mean maintaining failure state across cycles if scheduler doesn't provide this state
-
+		return pl.scoreWithoutMetrics(pod, nodeInfo), nil
 	}
 
-	var curPodCPUUsage int64
-	for _, container := range pod.Spec.Containers {
-		curPodCPUUsage += PredictUtilisation(&container)
+	nodeUtilPercents := make([]float64, len(pl.resources))
+	for i, r := range pl.resources {
+		utilPercent, found := nodeUtilization(metrics, r.metricType)
+		if !found {
+			klog.ErrorS(nil, "Metric not found in node metrics", "nodeName", nodeName, "resource", r.name, "nodeMetrics", metrics)
+			return pl.scoreWithoutMetrics(pod, nodeInfo), nil
+		}
+		nodeUtilPercents[i] = utilPercent
 	}
-	klog.V(6).InfoS("Predicted utilization for pod", "podName", pod.Name, "cpuUsage", curPodCPUUsage)
-	if pod.Spec.Overhead != nil {
-		curPodCPUUsage += pod.Spec.Overhead.Cpu().MilliValue()
+	pl.fallback.metricsFound(nodeName)
+
+	curPodUsage := pl.predictPodUtilisation(pod)
+	klog.V(6).InfoS("Predicted utilization for pod", "podName", pod.Name, "usage", curPodUsage)
+	missingUsage := pl.missingUtilisation(nodeName, allMetrics)
+	klog.V(6).InfoS("Missing utilization for node", "nodeName", nodeName, "missingUsage", missingUsage)
+
+	scores := make([]int64, len(pl.resources))
+	for i, r := range pl.resources {
+		nodeCap := float64(quantityValue(r.name, nodeInfo.Node().Status.Capacity[r.name]))
+		nodeUtil := (nodeUtilPercents[i] / 100) * nodeCap
+		klog.V(6).InfoS("Calculating utilization and capacity", "nodeName", nodeName, "resource", r.name, "util", nodeUtil, "capacity", nodeCap)
+
+		var predictedUsage float64
+		if nodeCap != 0 {
+			predictedUsage = 100 * (nodeUtil + float64(curPodUsage[i]) + float64(missingUsage[i])) / nodeCap
+		}
+		scores[i] = targetUtilizationScore(predictedUsage, r.targetUtilization)
+		klog.V(6).InfoS("Score for resource", "nodeName", nodeName, "resource", r.name, "predictedUsage", predictedUsage, "score", scores[i])
 	}
+	score = pl.combineScores(scores)
+	klog.V(6).InfoS("Score for host", "nodeName", nodeName, "score", score)
+	return score, framework.NewStatus(framework.Success, "")
+}
 
-	var nodeCPUUtilPercent float64
-	var cpuMetricFound bool
+// nodeUtilization returns the utilization % of a node in its metrics for the given metric type.
+func nodeUtilization(metrics []watcher.Metric, metricType string) (float64, bool) {
+	var utilPercent float64
+	var found bool
 	for _, metric := range metrics {
-		if metric.Type == watcher.CPU {
+		if metric.Type == metricType {
 			if metric.Operator == watcher.Average || metric.Operator == watcher.Latest {
-				nodeCPUUtilPercent = metric.Value
-				cpuMetricFound = true
+				utilPercent = metric.Value
+				found = true
 			}
 		}
 	}
+	return utilPercent, found
+}
 
-	if !cpuMetricFound {
-		klog.ErrorS(nil, "Cpu metric not found in node metrics", "nodeName", nodeName, "nodeMetrics", metrics)
-		return score, nil
+// predictPodUtilisation predicts the usage of every packed resource by the pod, overhead included.
+func (pl *TargetLoadPacking) predictPodUtilisation(pod *v1.Pod) []int64 {
+	usage := make([]int64, len(pl.resources))
+	for i, r := range pl.resources {
+		for _, container := range pod.Spec.Containers {
+			usage[i] += pl.predictUtilisation(&container, r)
+		}
+		usage[i] += quantityValue(r.name, pod.Spec.Overhead[r.name])
 	}
-	nodeCPUCapMillis := float64(nodeInfo.Node().Status.Capacity.Cpu().MilliValue())
-	nodeCPUUtilMillis := (nodeCPUUtilPercent / 100) * nodeCPUCapMillis
-
-	klog.V(6).InfoS("Calculating CPU utilization and capacity", "nodeName", nodeName, "cpuUtilMillis", nodeCPUUtilMillis, "cpuCapMillis", nodeCPUCapMillis)
+	return usage
+}
 
-	var missingCPUUtilMillis int64 = 0
+// missingUtilisation predicts the usage of every packed resource by the pods assigned to the
+// node which are likely not accounted for in the metrics yet.
+func (pl *TargetLoadPacking) missingUtilisation(nodeName string, allMetrics *watcher.WatcherMetrics) []int64 {
+	missingUsage := make([]int64, len(pl.resources))
 	pl.eventHandler.RLock()
+	defer pl.eventHandler.RUnlock()
 	for _, info := range pl.eventHandler.ScheduledPodsCache[nodeName] {
 		// If the time stamp of the scheduled pod is outside fetched metrics window, or it is within metrics reporting interval seconds, we predict util.
 		// Note that the second condition doesn't guarantee metrics for that pod are not reported yet as the 0 <= t <= 2*metricsAgentReportingIntervalSeconds
@@ -156,33 +251,85 @@ func (pl *TargetLoadPacking) Score(ctx context.Context, cycleState *framework.Cy
 		// counting metrics twice in case actual t is less than metricsAgentReportingIntervalSeconds
 		if info.Timestamp.Unix() > allMetrics.Window.End || info.Timestamp.Unix() <= allMetrics.Window.End &&
 			(allMetrics.Window.End-info.Timestamp.Unix()) < metricsAgentReportingIntervalSeconds {
-			for _, container := range info.Pod.Spec.Containers {
-				missingCPUUtilMillis += PredictUtilisation(&container)
+			podUsage := pl.predictPodUtilisation(info.Pod)
+			for i := range missingUsage {
+				missingUsage[i] += podUsage[i]
 			}
-			missingCPUUtilMillis += info.Pod.Spec.Overhead.Cpu().MilliValue()
-			klog.V(6).InfoS("Missing utilization for pod", "podName", info.Pod.Name, "missingCPUUtilMillis", missingCPUUtilMillis)
+			klog.V(6).InfoS("Missing utilization for pod", "podName", info.Pod.Name, "usage", podUsage)
 		}
 	}
-	pl.eventHandler.RUnlock()
-	klog.V(6).InfoS("Missing utilization for node", "nodeName", nodeName, "missingCPUUtilMillis", missingCPUUtilMillis)
+	return missingUsage
+}
 
-	var predictedCPUUsage float64
-	if nodeCPUCapMillis != 0 {
-		predictedCPUUsage = 100 * (nodeCPUUtilMillis + float64(curPodCPUUsage) + float64(missingCPUUtilMillis)) / nodeCPUCapMillis
+// scoreWithoutMetrics scores a node whose load metrics are missing: with the minimum score
+// during the grace period, then from the resources requested on the node, using the same
+// target utilization curves as when metrics are available.
+func (pl *TargetLoadPacking) scoreWithoutMetrics(pod *v1.Pod, nodeInfo *framework.NodeInfo) int64 {
+	nodeName := nodeInfo.Node().Name
+	if !pl.fallback.metricsMissing(nodeName) {
+		klog.InfoS("Failed to get metrics for node; using minimum score", "nodeName", nodeName)
+		// Avoid the node by scoring minimum
+		return framework.MinNodeScore
 	}
-	if predictedCPUUsage > float64(hostTargetUtilizationPercent) {
-		if predictedCPUUsage > 100 {
-			return score, framework.NewStatus(framework.Success, "")
+
+	podRequests := trimaran.GetResourceRequested(pod)
+	scores := make([]int64, len(pl.resources))
+	for i, r := range pl.resources {
+		nodeAlloc := float64(frameworkResourceValue(r.name, nodeInfo.Allocatable))
+		var predictedUsage float64
+		if nodeAlloc != 0 {
+			predictedUsage = 100 * float64(frameworkResourceValue(r.name, nodeInfo.Requested)+frameworkResourceValue(r.name, podRequests)) / nodeAlloc
 		}
-		penalisedScore := int64(math.Round(float64(hostTargetUtilizationPercent) * (100 - predictedCPUUsage) / (100 - float64(hostTargetUtilizationPercent))))
-		klog.V(6).InfoS("Penalised score for host", "nodeName", nodeName, "penalisedScore", penalisedScore)
-		return penalisedScore, framework.NewStatus(framework.Success, "")
+		scores[i] = targetUtilizationScore(predictedUsage, r.targetUtilization)
+		klog.V(6).InfoS("Score for resource by allocation", "nodeName", nodeName, "resource", r.name, "predictedUsage", predictedUsage, "score", scores[i])
 	}
+	score := pl.combineScores(scores)
+	klog.V(6).InfoS("Score for host by allocation", "nodeName", nodeName, "score", score)
+	return score
+}
 
-	score = int64(math.Round((100-float64(hostTargetUtilizationPercent))*
-		predictedCPUUsage/float64(hostTargetUtilizationPercent) + float64(hostTargetUtilizationPercent)))
-	klog.V(6).InfoS("Score for host", "nodeName", nodeName, "score", score)
-	return score, framework.NewStatus(framework.Success, "")
+// FallbackNodes returns the names of the nodes currently scored from their requested resources
+// because their load metrics are missing.
+func (pl *TargetLoadPacking) FallbackNodes() []string {
+	return pl.fallback.fallbackNodes()
+}
+
+// combineScores combines the scores of the packed resources into the node score.
+func (pl *TargetLoadPacking) combineScores(scores []int64) int64 {
+	if pl.scoreMode == pluginConfig.TargetLoadPackingScoreModeMin {
+		minScore := framework.MaxNodeScore
+		for _, score := range scores {
+			if score < minScore {
+				minScore = score
+			}
+		}
+		return minScore
+	}
+
+	var weightedSum, weights int64
+	for i, score := range scores {
+		weightedSum += pl.resources[i].weight * score
+		weights += pl.resources[i].weight
+	}
+	if weights == 0 {
+		return framework.MinNodeScore
+	}
+	return int64(math.Round(float64(weightedSum) / float64(weights)))
+}
+
+// targetUtilizationScore maps the predicted utilization % of a resource on a node to its score: the score
+// grows with the utilization up to the target, then decreases down to the minimum at 100%.
+func targetUtilizationScore(predictedUsage float64, targetUtilization float64) int64 {
+	if predictedUsage > targetUtilization {
+		if predictedUsage > 100 {
+			return framework.MinNodeScore
+		}
+		penalisedScore := int64(math.Round(targetUtilization * (100 - predictedUsage) / (100 - targetUtilization)))
+		klog.V(6).InfoS("Penalised score for host", "penalisedScore", penalisedScore)
+		return penalisedScore
+	}
+
+	return int64(math.Round((100-targetUtilization)*predictedUsage/targetUtilization + targetUtilization))
 }
 
 func (pl *TargetLoadPacking) ScoreExtensions() framework.ScoreExtensions {
@@ -193,13 +340,32 @@ func (pl *TargetLoadPacking) NormalizeScore(context.Context, *framework.CycleSta
 	return nil
 }
 
-// Predict utilization for a container based on its requests/limits
-func PredictUtilisation(container *v1.Container) int64 {
-	if _, ok := container.Resources.Limits[v1.ResourceCPU]; ok {
-		return container.Resources.Limits.Cpu().MilliValue()
-	} else if _, ok := container.Resources.Requests[v1.ResourceCPU]; ok {
-		return int64(math.Round(float64(container.Resources.Requests.Cpu().MilliValue()) * requestsMultiplier))
+// predictUtilisation predicts the usage of a packed resource by a container based on its requests/limits
+func (pl *TargetLoadPacking) predictUtilisation(container *v1.Container, r packedResource) int64 {
+	if limit, ok := container.Resources.Limits[r.name]; ok {
+		return quantityValue(r.name, limit)
+	} else if request, ok := container.Resources.Requests[r.name]; ok {
+		return int64(math.Round(float64(quantityValue(r.name, request)) * pl.requestsMultiplier))
 	} else {
-		return requestsMilliCores
+		return r.defaultRequest
+	}
+}
+
+// quantityValue returns a quantity of the resource in millicores for CPU and in bytes otherwise.
+func quantityValue(name v1.ResourceName, quantity resource.Quantity) int64 {
+	if name == v1.ResourceCPU {
+		return quantity.MilliValue()
+	}
+	return quantity.Value()
+}
+
+// frameworkResourceValue returns the amount of the resource in millicores for CPU and in bytes for memory.
+func frameworkResourceValue(name v1.ResourceName, res *framework.Resource) int64 {
+	switch name {
+	case v1.ResourceCPU:
+		return res.MilliCPU
+	case v1.ResourceMemory:
+		return res.Memory
 	}
+	return 0
 }
@@ -25,6 +25,7 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/paypal/load-watcher/pkg/watcher"
 	"github.com/stretchr/testify/assert"
@@ -35,6 +36,7 @@ import (
 	"k8s.io/client-go/informers"
 	testClientSet "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/util/workqueue"
+	metricstestutil "k8s.io/component-base/metrics/testutil"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/apis/config"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -43,6 +45,7 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework/runtime"
 	st "k8s.io/kubernetes/pkg/scheduler/testing"
 	tf "k8s.io/kubernetes/pkg/scheduler/testing/framework"
+	testingclock "k8s.io/utils/clock/testing"
 
 	pluginConfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	cfgv1 "sigs.k8s.io/scheduler-plugins/apis/config/v1"
@@ -88,6 +91,7 @@ func TestNew(t *testing.T) {
 		TrimaranSpec:              pluginConfig.TrimaranSpec{WatcherAddress: "http://deadbeef:2020"},
 		TargetUtilization:         cfgv1.DefaultTargetUtilizationPercent,
 		DefaultRequestsMultiplier: cfgv1.DefaultRequestsMultiplier,
+		ScoreMode:                 pluginConfig.TargetLoadPackingScoreModeWeightedSum,
 	}
 	targetLoadPackingConfig := config.PluginConfig{
 		Name: Name,
@@ -123,6 +127,7 @@ func TestTargetLoadPackingScoring(t *testing.T) {
 		TrimaranSpec:              pluginConfig.TrimaranSpec{WatcherAddress: "http://deadbeef:2020"},
 		TargetUtilization:         cfgv1.DefaultTargetUtilizationPercent,
 		DefaultRequestsMultiplier: cfgv1.DefaultRequestsMultiplier,
+		ScoreMode:                 pluginConfig.TargetLoadPackingScoreModeWeightedSum,
 	}
 	targetLoadPackingConfig := config.PluginConfig{
 		Name: Name,
@@ -256,9 +261,11 @@ func TestTargetLoadPackingScoring(t *testing.T) {
 				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
 			assert.Nil(t, err)
 			targetLoadPackingArgs := pluginConfig.TargetLoadPackingArgs{
-				TrimaranSpec:              pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
-				TargetUtilization:         cfgv1.DefaultTargetUtilizationPercent,
-				DefaultRequestsMultiplier: cfgv1.DefaultRequestsMultiplier,
+				TrimaranSpec:               pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+				TargetUtilization:          cfgv1.DefaultTargetUtilizationPercent,
+				DefaultRequestsMultiplier:  cfgv1.DefaultRequestsMultiplier,
+				ScoreMode:                  pluginConfig.TargetLoadPackingScoreModeWeightedSum,
+				FallbackGracePeriodSeconds: cfgv1.DefaultFallbackGracePeriodSeconds,
 			}
 			p, _ := New(ctx, &targetLoadPackingArgs, fh)
 			scorePlugin := p.(framework.ScorePlugin)
@@ -274,6 +281,296 @@ func TestTargetLoadPackingScoring(t *testing.T) {
 	}
 }
 
+func TestTargetLoadPackingFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcher.WatcherMetrics{})
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+		tf.RegisterScorePlugin(Name, New, 1),
+	}
+	targetLoadPackingArgs := pluginConfig.TargetLoadPackingArgs{
+		TrimaranSpec:               pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+		TargetUtilization:          cfgv1.DefaultTargetUtilizationPercent,
+		DefaultRequestsMultiplier:  cfgv1.DefaultRequestsMultiplier,
+		ScoreMode:                  pluginConfig.TargetLoadPackingScoreModeWeightedSum,
+		FallbackGracePeriodSeconds: cfgv1.DefaultFallbackGracePeriodSeconds,
+	}
+	targetLoadPackingConfig := config.PluginConfig{
+		Name: Name,
+		Args: &targetLoadPackingArgs,
+	}
+
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}
+	nodes := []*v1.Node{st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()}
+	runningPod := getPodWithContainersAndOverhead(0, 200)
+	runningPod.Spec.NodeName = "node-1"
+
+	cs := testClientSet.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	snapshot := newTestSharedLister([]*v1.Pod{runningPod}, nodes)
+	fh, err := testutil.NewFramework(ctx, registeredPlugins, []config.PluginConfig{targetLoadPackingConfig},
+		"default-scheduler", runtime.WithClientSet(cs),
+		runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+	assert.Nil(t, err)
+	p, err := New(ctx, &targetLoadPackingArgs, fh)
+	assert.Nil(t, err)
+	pl := p.(*TargetLoadPacking)
+	fakeClock := testingclock.NewFakePassiveClock(time.Now())
+	pl.fallback = newFallbackTracker("default-scheduler", time.Duration(cfgv1.DefaultFallbackGracePeriodSeconds)*time.Second, fakeClock)
+
+	pod := getPodWithContainersAndOverhead(0, 100)
+	score, status := pl.Score(ctx, framework.NewCycleState(), pod, "node-1")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, framework.MinNodeScore, score, "node should be avoided during the grace period")
+	assert.Empty(t, pl.FallbackNodes())
+
+	fakeClock.SetTime(fakeClock.Now().Add(time.Duration(cfgv1.DefaultFallbackGracePeriodSeconds) * time.Second))
+	score, status = pl.Score(ctx, framework.NewCycleState(), pod, "node-1")
+	assert.True(t, status.IsSuccess())
+	// 300m requested out of 1000m is 30% utilization, scored as (100-40)*30/40 + 40
+	assert.Equal(t, int64(85), score)
+	assert.Equal(t, []string{"node-1"}, pl.FallbackNodes())
+}
+
+func TestTargetLoadPackingMultiResource(t *testing.T) {
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}
+	nodeMetrics := func(cpu, memory float64) watcher.WatcherMetrics {
+		return watcher.WatcherMetrics{
+			Data: watcher.Data{
+				NodeMetricsMap: map[string]watcher.NodeMetrics{
+					"node-1": {
+						Metrics: []watcher.Metric{
+							{Type: watcher.CPU, Value: cpu, Operator: watcher.Latest},
+							{Type: watcher.Memory, Value: memory, Operator: watcher.Latest},
+						},
+					},
+				},
+			},
+		}
+	}
+	cpuAndMemory := []pluginConfig.TargetLoadPackingResource{
+		{Name: v1.ResourceCPU, TargetUtilization: 40, Weight: 1},
+		{Name: v1.ResourceMemory, TargetUtilization: 60, Weight: 3},
+	}
+
+	// the pod adds 10% CPU utilization and no memory; with CPU at 30%
+	// the CPU score is 85 and with memory at 50% the memory score is 93
+	tests := []struct {
+		test            string
+		resources       []pluginConfig.TargetLoadPackingResource
+		scoreMode       pluginConfig.TargetLoadPackingScoreMode
+		watcherResponse watcher.WatcherMetrics
+		expected        int64
+	}{
+		{
+			test:            "cpu only by default",
+			scoreMode:       pluginConfig.TargetLoadPackingScoreModeWeightedSum,
+			watcherResponse: nodeMetrics(20, 50),
+			expected:        85,
+		},
+		{
+			test:            "weighted sum",
+			resources:       cpuAndMemory,
+			scoreMode:       pluginConfig.TargetLoadPackingScoreModeWeightedSum,
+			watcherResponse: nodeMetrics(20, 50),
+			expected:        91,
+		},
+		{
+			test:            "min",
+			resources:       cpuAndMemory,
+			scoreMode:       pluginConfig.TargetLoadPackingScoreModeMin,
+			watcherResponse: nodeMetrics(20, 50),
+			expected:        85,
+		},
+		{
+			test:            "memory above target is penalised",
+			resources:       cpuAndMemory,
+			scoreMode:       pluginConfig.TargetLoadPackingScoreModeMin,
+			watcherResponse: nodeMetrics(20, 80),
+			expected:        30,
+		},
+		{
+			test:      "missing memory metric",
+			resources: cpuAndMemory,
+			scoreMode: pluginConfig.TargetLoadPackingScoreModeWeightedSum,
+			watcherResponse: watcher.WatcherMetrics{
+				Data: watcher.Data{
+					NodeMetricsMap: map[string]watcher.NodeMetrics{
+						"node-1": {
+							Metrics: []watcher.Metric{
+								{Type: watcher.CPU, Value: 20, Operator: watcher.Latest},
+							},
+						},
+					},
+				},
+			},
+			expected: framework.MinNodeScore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.test, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				bytes, err := json.Marshal(tt.watcherResponse)
+				assert.Nil(t, err)
+				resp.Write(bytes)
+			}))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			nodes := []*v1.Node{st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()}
+			cs := testClientSet.NewSimpleClientset()
+			informerFactory := informers.NewSharedInformerFactory(cs, 0)
+			snapshot := newTestSharedLister(nil, nodes)
+			fh, err := testutil.NewFramework(ctx, registeredPlugins, nil,
+				"default-scheduler", runtime.WithClientSet(cs),
+				runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(snapshot))
+			assert.Nil(t, err)
+			targetLoadPackingArgs := pluginConfig.TargetLoadPackingArgs{
+				TrimaranSpec:               pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+				TargetUtilization:          cfgv1.DefaultTargetUtilizationPercent,
+				DefaultRequestsMultiplier:  cfgv1.DefaultRequestsMultiplier,
+				FallbackGracePeriodSeconds: cfgv1.DefaultFallbackGracePeriodSeconds,
+				Resources:                  tt.resources,
+				ScoreMode:                  tt.scoreMode,
+			}
+			p, err := New(ctx, &targetLoadPackingArgs, fh)
+			assert.Nil(t, err)
+			score, status := p.(framework.ScorePlugin).Score(ctx, framework.NewCycleState(), getPodWithContainersAndOverhead(0, 100), "node-1")
+			assert.True(t, status.IsSuccess())
+			assert.Equal(t, tt.expected, score)
+		})
+	}
+}
+
+func TestTargetLoadPackingProfiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		bytes, err := json.Marshal(watcher.WatcherMetrics{
+			Data: watcher.Data{
+				NodeMetricsMap: map[string]watcher.NodeMetrics{
+					"node-1": {Metrics: []watcher.Metric{{Type: watcher.CPU, Value: 20, Operator: watcher.Latest}}},
+				},
+			},
+		})
+		assert.Nil(t, err)
+		resp.Write(bytes)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registeredPlugins := []tf.RegisterPluginFunc{
+		tf.RegisterBindPlugin(defaultbinder.Name, defaultbinder.New),
+		tf.RegisterQueueSortPlugin(queuesort.Name, queuesort.New),
+	}
+	nodeResources := map[v1.ResourceName]string{
+		v1.ResourceCPU:    "1000m",
+		v1.ResourceMemory: "1Gi",
+	}
+	nodes := []*v1.Node{st.MakeNode().Name("node-1").Capacity(nodeResources).Obj()}
+	cs := testClientSet.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(cs, 0)
+	fh, err := testutil.NewFramework(ctx, registeredPlugins, nil,
+		"default-scheduler", runtime.WithClientSet(cs),
+		runtime.WithInformerFactory(informerFactory), runtime.WithSnapshotSharedLister(newTestSharedLister(nil, nodes)))
+	assert.Nil(t, err)
+
+	newPlugin := func(targetUtilization int64, multiplier string) framework.ScorePlugin {
+		p, err := New(ctx, &pluginConfig.TargetLoadPackingArgs{
+			TrimaranSpec:              pluginConfig.TrimaranSpec{WatcherAddress: server.URL},
+			TargetUtilization:         targetUtilization,
+			DefaultRequestsMultiplier: multiplier,
+			ScoreMode:                 pluginConfig.TargetLoadPackingScoreModeWeightedSum,
+		}, fh)
+		assert.Nil(t, err)
+		return p.(framework.ScorePlugin)
+	}
+	first := newPlugin(40, "1")
+	second := newPlugin(80, "2")
+
+	// a burstable pod requesting 100m is predicted to use 100m with the first plugin and 200m with the second
+	pod := st.MakePod().Name("p").Req(map[v1.ResourceName]string{v1.ResourceCPU: "100m"}).Obj()
+	score, status := first.Score(ctx, framework.NewCycleState(), pod, "node-1")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int64(85), score)
+	score, status = second.Score(ctx, framework.NewCycleState(), pod, "node-1")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int64(90), score)
+	score, status = first.Score(ctx, framework.NewCycleState(), pod, "node-1")
+	assert.True(t, status.IsSuccess())
+	assert.Equal(t, int64(85), score, "settings of the second plugin should not leak into the first one")
+}
+
+func TestFallbackTracker(t *testing.T) {
+	start := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(start)
+	RegisterMetrics()
+	tracker := newFallbackTracker("profile-a", time.Minute, fakeClock)
+	other := newFallbackTracker("profile-b", time.Minute, fakeClock)
+
+	assert.False(t, tracker.metricsMissing("node-1"))
+	fakeClock.SetTime(start.Add(30 * time.Second))
+	assert.False(t, tracker.metricsMissing("node-1"))
+	assert.False(t, tracker.metricsMissing("node-2"))
+	fakeClock.SetTime(start.Add(time.Minute))
+	assert.True(t, tracker.metricsMissing("node-1"))
+	assert.False(t, tracker.metricsMissing("node-2"))
+	assert.Equal(t, []string{"node-1"}, tracker.fallbackNodes())
+	expectFallbackGauge(t, tracker, 1)
+	// the trackers of other profiles do not overwrite the gauge
+	assert.False(t, other.metricsMissing("node-3"))
+	expectFallbackGauge(t, tracker, 1)
+	expectFallbackGauge(t, other, 0)
+
+	// metrics coming back reset the grace period
+	tracker.metricsFound("node-1")
+	assert.Empty(t, tracker.fallbackNodes())
+	assert.False(t, tracker.metricsMissing("node-1"))
+
+	// nodes not scored anymore are forgotten
+	fakeClock.SetTime(start.Add(2 * time.Minute))
+	assert.True(t, tracker.metricsMissing("node-2"))
+	fakeClock.SetTime(start.Add(2*time.Minute + nodeStateTTL + time.Second))
+	tracker.prune()
+	expectFallbackGauge(t, tracker, 0)
+	assert.False(t, tracker.metricsMissing("node-1"))
+	assert.Empty(t, tracker.fallbackNodes())
+
+	disabled := newFallbackTracker("profile-c", -time.Second, fakeClock)
+	assert.False(t, disabled.metricsMissing("node-1"))
+	fakeClock.SetTime(fakeClock.Now().Add(time.Hour))
+	assert.False(t, disabled.metricsMissing("node-1"))
+}
+
+func expectFallbackGauge(t *testing.T, tracker *fallbackTracker, expected float64) {
+	t.Helper()
+	got, err := metricstestutil.GetGaugeMetricValue(tracker.gauge)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, got)
+}
+
 func BenchmarkTargetLoadPackingPlugin(b *testing.B) {
 	tests := []struct {
 		name     string
@@ -303,7 +600,11 @@ func BenchmarkTargetLoadPackingPlugin(b *testing.B) {
 		tf.RegisterScorePlugin(Name, New, 1),
 	}
 
-	bfbpArgs := pluginConfig.TargetLoadPackingArgs{}
+	bfbpArgs := pluginConfig.TargetLoadPackingArgs{
+		TargetUtilization:         cfgv1.DefaultTargetUtilizationPercent,
+		DefaultRequestsMultiplier: cfgv1.DefaultRequestsMultiplier,
+		ScoreMode:                 pluginConfig.TargetLoadPackingScoreModeWeightedSum,
+	}
 
 	for _, tt := range tests {
 		b.Run(tt.name, func(b *testing.B) {
@@ -0,0 +1,227 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"math"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+// GetElasticQuotaSelector returns the selector of the namespaces the ElasticQuota applies to
+// besides its own namespace, or nil if the ElasticQuota has no namespace selector.
+func GetElasticQuotaSelector(eq *v1alpha1.ElasticQuota) (labels.Selector, error) {
+	if eq.Spec.NamespaceSelector == nil {
+		return nil, nil
+	}
+	return metav1.LabelSelectorAsSelector(eq.Spec.NamespaceSelector)
+}
+
+// GetElasticQuotasOfNamespace returns the sorted namespaces of the ElasticQuotas that apply to the
+// namespace, given the namespace selectors of all the ElasticQuotas keyed by their namespace (nil
+// if the ElasticQuota has none): the ElasticQuota in the namespace itself, and the ElasticQuotas
+// whose selector matches the labels of the namespace. The namespace is covered by an ElasticQuota
+// only if there is exactly one of them.
+func GetElasticQuotasOfNamespace(namespace *v1.Namespace, selectors map[string]labels.Selector) []string {
+	var quotas []string
+	for quota, selector := range selectors {
+		if quota == namespace.Name || (selector != nil && selector.Matches(labels.Set(namespace.Labels))) {
+			quotas = append(quotas, quota)
+		}
+	}
+	sort.Strings(quotas)
+	return quotas
+}
+
+// ElasticQuotaShare describes an ElasticQuota whose fair share is computed by GetElasticQuotaFairShares.
+// The quantities are in the units of framework.Resource, i.e. millicores for the CPU. A resource that is
+// missing from Max is unbounded, and Used includes the usage of the descendants of the quota.
+type ElasticQuotaShare struct {
+	Parent string
+	Weight int64
+	Min    map[v1.ResourceName]int64
+	Max    map[v1.ResourceName]int64
+	Used   map[v1.ResourceName]int64
+}
+
+// GetElasticQuotaFairShares returns the fair share of each ElasticQuota, keyed like the quotas, given the
+// capacity of the cluster.
+//
+// The capacity of the cluster, or the sum of the min of the top-level quotas if greater, is split among
+// the top-level quotas, then the fair share of each quota is split among its children, and so on. Among
+// siblings, a quota that uses less than its min of a resource lends the rest of it, and the lent and idle
+// resources are split by weighted Dominant Resource Fairness: each quota that uses its min of some resources
+// grows in the proportions of its usage of them, at a pace given by its weight, until it reaches its max
+// or one of the resources runs out. The fair share of a quota is at least its min, which it can always
+// reclaim. Only the resources in the min or max of some quota have a fair share, and quotas in a cycle of
+// parents have none.
+func GetElasticQuotaFairShares(quotas map[string]*ElasticQuotaShare, capacity map[v1.ResourceName]int64) map[string]map[v1.ResourceName]int64 {
+	var roots []string
+	children := make(map[string][]string)
+	for name, q := range quotas {
+		if _, ok := quotas[q.Parent]; ok {
+			children[q.Parent] = append(children[q.Parent], name)
+		} else {
+			roots = append(roots, name)
+		}
+	}
+
+	pool := make(map[v1.ResourceName]int64, len(capacity))
+	for _, root := range roots {
+		for name, value := range quotas[root].Min {
+			pool[name] += value
+		}
+	}
+	// The capacity of the resources no quota limits is left out.
+	limited := make(map[v1.ResourceName]bool)
+	for _, q := range quotas {
+		for name := range q.Min {
+			limited[name] = true
+		}
+		for name := range q.Max {
+			limited[name] = true
+		}
+	}
+	for name, value := range capacity {
+		if limited[name] && value > pool[name] {
+			pool[name] = value
+		}
+	}
+
+	shares := make(map[string]map[v1.ResourceName]int64, len(quotas))
+	var split func(siblings []string, pool map[v1.ResourceName]int64)
+	split = func(siblings []string, pool map[v1.ResourceName]int64) {
+		sort.Strings(siblings)
+		splitPool(quotas, siblings, pool, shares)
+		// A quota is never its own descendant, so the recursion ends even with a cycle of parents,
+		// whose quotas are not reachable from the top-level quotas.
+		for _, sibling := range siblings {
+			if len(children[sibling]) > 0 {
+				split(children[sibling], shares[sibling])
+			}
+		}
+	}
+	split(roots, pool)
+	return shares
+}
+
+// splitPool splits the pool among siblings by weighted Dominant Resource Fairness, with progressive filling.
+func splitPool(quotas map[string]*ElasticQuotaShare, siblings []string, pool map[v1.ResourceName]int64, shares map[string]map[v1.ResourceName]int64) {
+	remaining := make(map[v1.ResourceName]float64, len(pool))
+	for name, value := range pool {
+		remaining[name] = float64(value)
+	}
+
+	// Each quota starts from what it uses of its min, and grows from there with a vector of
+	// resources whose dominant resource is 1. Its level is its dominant share of the pool on top
+	// of its start, and ceiling the level at which it reaches its max in some resource.
+	start := make(map[string]map[v1.ResourceName]int64, len(siblings))
+	direction := make(map[string]map[v1.ResourceName]float64, len(siblings))
+	level := make(map[string]float64, len(siblings))
+	ceiling := make(map[string]float64, len(siblings))
+	var growing []string
+	for _, sibling := range siblings {
+		q := quotas[sibling]
+		start[sibling] = make(map[v1.ResourceName]int64, len(pool))
+		direction[sibling] = make(map[v1.ResourceName]float64, len(pool))
+		var dominant float64
+		for name := range pool {
+			min, used := q.Min[name], q.Used[name]
+			if used < min {
+				// The quota lends the rest of its min.
+				start[sibling][name] = used
+			} else {
+				start[sibling][name] = min
+				if used > 0 && pool[name] > 0 {
+					direction[sibling][name] = float64(used) / float64(pool[name])
+					dominant = math.Max(dominant, direction[sibling][name])
+				}
+			}
+			remaining[name] -= float64(start[sibling][name])
+		}
+		if dominant == 0 {
+			continue
+		}
+		ceiling[sibling] = math.Inf(1)
+		for name, d := range direction[sibling] {
+			d /= dominant
+			direction[sibling][name] = d
+			if max, bounded := q.Max[name]; bounded {
+				room := float64(max-start[sibling][name]) / (d * float64(pool[name]))
+				ceiling[sibling] = math.Min(ceiling[sibling], math.Max(room, 0))
+			}
+		}
+		growing = append(growing, sibling)
+	}
+
+	// Each round, all the growing quotas grow with their weights until one reaches its ceiling or a
+	// resource runs out, and the quotas at their ceiling or needing an exhausted resource stop growing.
+	const epsilon = 1e-9
+	for len(growing) > 0 {
+		rates := make(map[v1.ResourceName]float64, len(pool))
+		for _, sibling := range growing {
+			for name, d := range direction[sibling] {
+				rates[name] += float64(quotas[sibling].weight()) * d * float64(pool[name])
+			}
+		}
+		step := math.Inf(1)
+		for name, rate := range rates {
+			step = math.Min(step, math.Max(remaining[name], 0)/rate)
+		}
+		for _, sibling := range growing {
+			step = math.Min(step, (ceiling[sibling]-level[sibling])/float64(quotas[sibling].weight()))
+		}
+		for name, rate := range rates {
+			remaining[name] -= rate * step
+		}
+		var next []string
+		for _, sibling := range growing {
+			level[sibling] += float64(quotas[sibling].weight()) * step
+			grows := ceiling[sibling]-level[sibling] > epsilon
+			for name := range direction[sibling] {
+				grows = grows && remaining[name] > epsilon*float64(pool[name])
+			}
+			if grows {
+				next = append(next, sibling)
+			}
+		}
+		growing = next
+	}
+
+	for _, sibling := range siblings {
+		shares[sibling] = make(map[v1.ResourceName]int64, len(pool))
+		for name := range pool {
+			share := start[sibling][name] + int64(math.Round(level[sibling]*direction[sibling][name]*float64(pool[name])))
+			if min := quotas[sibling].Min[name]; share < min {
+				share = min
+			}
+			shares[sibling][name] = share
+		}
+	}
+}
+
+func (q *ElasticQuotaShare) weight() int64 {
+	if q.Weight <= 0 {
+		return 1
+	}
+	return q.Weight
+}
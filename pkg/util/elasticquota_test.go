@@ -0,0 +1,195 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
+)
+
+func TestGetElasticQuotasOfNamespace(t *testing.T) {
+	selector := func(eq *v1alpha1.ElasticQuota) labels.Selector {
+		s, err := GetElasticQuotaSelector(eq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	withSelector := func(namespace string, matchLabels map[string]string) *v1alpha1.ElasticQuota {
+		eq := &v1alpha1.ElasticQuota{ObjectMeta: metav1.ObjectMeta{Name: "eq", Namespace: namespace}}
+		if matchLabels != nil {
+			eq.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: matchLabels}
+		}
+		return eq
+	}
+	selectors := map[string]labels.Selector{
+		"tenant-a": selector(withSelector("tenant-a", map[string]string{"tenant": "a"})),
+		"tenant-b": selector(withSelector("tenant-b", map[string]string{"tenant": "b"})),
+		"team-b":   selector(withSelector("team-b", map[string]string{"team": "b"})),
+		"own":      selector(withSelector("own", nil)),
+	}
+
+	tests := []struct {
+		name      string
+		namespace *v1.Namespace
+		expected  []string
+	}{
+		{
+			name:      "namespace of a quota without selector",
+			namespace: &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "own"}},
+			expected:  []string{"own"},
+		},
+		{
+			name:      "namespace of a quota with selector",
+			namespace: &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}},
+			expected:  []string{"tenant-a"},
+		},
+		{
+			name:      "namespace selected by a quota",
+			namespace: &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a-1", Labels: map[string]string{"tenant": "a"}}},
+			expected:  []string{"tenant-a"},
+		},
+		{
+			name:      "namespace selected by two quotas",
+			namespace: &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "b-1", Labels: map[string]string{"tenant": "b", "team": "b"}}},
+			expected:  []string{"team-b", "tenant-b"},
+		},
+		{
+			name:      "namespace of a quota selected by another quota",
+			namespace: &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "own", Labels: map[string]string{"tenant": "a"}}},
+			expected:  []string{"own", "tenant-a"},
+		},
+		{
+			name:      "namespace without quota",
+			namespace: &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{"tenant": "c"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetElasticQuotasOfNamespace(tt.namespace, selectors); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetElasticQuotaFairShares(t *testing.T) {
+	cpu := func(value int64) map[v1.ResourceName]int64 {
+		return map[v1.ResourceName]int64{v1.ResourceCPU: value}
+	}
+	none := map[v1.ResourceName]int64{v1.ResourceCPU: 0, v1.ResourceMemory: 0}
+	tests := []struct {
+		name     string
+		capacity map[v1.ResourceName]int64
+		quotas   map[string]*ElasticQuotaShare
+		expected map[string]map[v1.ResourceName]int64
+	}{
+		{
+			name: "resources lent by a quota are split in proportion to the weights",
+			quotas: map[string]*ElasticQuotaShare{
+				"a": {Min: cpu(4000), Used: cpu(0)},
+				"b": {Weight: 1, Min: cpu(2000), Max: cpu(6000), Used: cpu(4000)},
+				"c": {Weight: 3, Min: cpu(2000), Used: cpu(2000)},
+			},
+			expected: map[string]map[v1.ResourceName]int64{
+				"a": cpu(4000),
+				"b": cpu(3000),
+				"c": cpu(5000),
+			},
+		},
+		{
+			name: "the part of a quota above its max is split among the others",
+			quotas: map[string]*ElasticQuotaShare{
+				"a": {Min: cpu(4000), Used: cpu(0)},
+				"b": {Weight: 1, Min: cpu(2000), Max: cpu(6000), Used: cpu(4000)},
+				"c": {Weight: 3, Min: cpu(2000), Max: cpu(3000), Used: cpu(2000)},
+			},
+			expected: map[string]map[v1.ResourceName]int64{
+				"a": cpu(4000),
+				"b": cpu(5000),
+				"c": cpu(3000),
+			},
+		},
+		{
+			name: "the fair share of a quota is split among its children",
+			quotas: map[string]*ElasticQuotaShare{
+				"dept":  {Min: cpu(6000), Used: cpu(5000)},
+				"team1": {Parent: "dept", Min: cpu(3000), Used: cpu(0)},
+				"team2": {Parent: "dept", Min: cpu(3000), Used: cpu(5000)},
+				"other": {Min: cpu(2000), Used: cpu(3000)},
+			},
+			expected: map[string]map[v1.ResourceName]int64{
+				"dept":  cpu(6000),
+				"team1": cpu(3000),
+				"team2": cpu(6000),
+				"other": cpu(3000),
+			},
+		},
+		{
+			name:     "the idle capacity above the min of the quotas is split too",
+			capacity: cpu(12000),
+			quotas: map[string]*ElasticQuotaShare{
+				"a": {Min: cpu(4000), Used: cpu(0)},
+				"b": {Weight: 1, Min: cpu(2000), Used: cpu(4000)},
+				"c": {Weight: 3, Min: cpu(2000), Used: cpu(2000)},
+			},
+			expected: map[string]map[v1.ResourceName]int64{
+				"a": cpu(4000),
+				"b": cpu(4000),
+				"c": cpu(8000),
+			},
+		},
+		{
+			name:     "the resources are split on the dominant share of each quota",
+			capacity: map[v1.ResourceName]int64{v1.ResourceCPU: 10000, v1.ResourceMemory: 100},
+			quotas: map[string]*ElasticQuotaShare{
+				// a uses mostly memory and b mostly cpu: each gets more of its dominant resource,
+				// with the same dominant share of the cluster.
+				"a": {Min: none, Used: map[v1.ResourceName]int64{v1.ResourceCPU: 1000, v1.ResourceMemory: 40}},
+				"b": {Min: none, Used: map[v1.ResourceName]int64{v1.ResourceCPU: 4000, v1.ResourceMemory: 10}},
+			},
+			expected: map[string]map[v1.ResourceName]int64{
+				"a": {v1.ResourceCPU: 2000, v1.ResourceMemory: 80},
+				"b": {v1.ResourceCPU: 8000, v1.ResourceMemory: 20},
+			},
+		},
+		{
+			name: "quotas in a cycle of parents have no fair share",
+			quotas: map[string]*ElasticQuotaShare{
+				"a": {Parent: "b", Min: cpu(2000), Used: cpu(0)},
+				"b": {Parent: "a", Min: cpu(2000), Used: cpu(0)},
+				"c": {Min: cpu(2000), Used: cpu(3000)},
+			},
+			expected: map[string]map[v1.ResourceName]int64{
+				"c": cpu(2000),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetElasticQuotaFairShares(tt.quotas, tt.capacity); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
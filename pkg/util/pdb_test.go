@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	policy "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+)
+
+func TestFilterPodsWithPDBViolation(t *testing.T) {
+	makePodInfo := func(name string, labels map[string]string) *framework.PodInfo {
+		podInfo, _ := framework.NewPodInfo(st.MakePod().Namespace("ns").Name(name).Labels(labels).Obj())
+		return podInfo
+	}
+	pdb := &policy.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "pdb"},
+		Spec: policy.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "a"}},
+		},
+		Status: policy.PodDisruptionBudgetStatus{
+			DisruptionsAllowed: 1,
+			DisruptedPods:      map[string]metav1.Time{"p2": {}},
+		},
+	}
+	podInfos := []*framework.PodInfo{
+		makePodInfo("p1", map[string]string{"app": "a"}),
+		// Already disrupted: does not consume the budget.
+		makePodInfo("p2", map[string]string{"app": "a"}),
+		makePodInfo("p3", map[string]string{"app": "b"}),
+		// The budget is used up by p1.
+		makePodInfo("p4", map[string]string{"app": "a"}),
+	}
+
+	violating, nonViolating := FilterPodsWithPDBViolation(podInfos, []*policy.PodDisruptionBudget{pdb})
+	names := func(podInfos []*framework.PodInfo) []string {
+		var result []string
+		for _, podInfo := range podInfos {
+			result = append(result, podInfo.Pod.Name)
+		}
+		return result
+	}
+	if got, want := names(violating), []string{"p4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("violating = %v, want %v", got, want)
+	}
+	if got, want := names(nonViolating), []string{"p1", "p2", "p3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("nonViolating = %v, want %v", got, want)
+	}
+}
@@ -33,6 +33,7 @@ import (
 	st "k8s.io/kubernetes/pkg/scheduler/testing"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	scheconfig "sigs.k8s.io/scheduler-plugins/apis/config"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling"
 	"sigs.k8s.io/scheduler-plugins/apis/scheduling/v1alpha1"
 	"sigs.k8s.io/scheduler-plugins/pkg/capacityscheduling"
@@ -74,6 +75,12 @@ func TestCapacityScheduling(t *testing.T) {
 		Disabled: []schedapi.Plugin{{Name: "*"}},
 	}
 	cfg.Profiles[0].Plugins.Reserve.Enabled = append(cfg.Profiles[0].Plugins.Reserve.Enabled, schedapi.Plugin{Name: capacityscheduling.Name})
+	cfg.Profiles[0].PluginConfig = append(cfg.Profiles[0].PluginConfig, schedapi.PluginConfig{
+		Name: capacityscheduling.Name,
+		Args: &scheconfig.CapacitySchedulingArgs{
+			BorrowingPolicy: scheconfig.BorrowingPolicyFirstComeFirstServed,
+		},
+	})
 
 	testCtx = initTestSchedulerWithOptions(
 		t,
@@ -84,6 +84,7 @@ func TestCoschedulingPlugin(t *testing.T) {
 		Name: coscheduling.Name,
 		Args: &schedconfig.CoschedulingArgs{
 			PermitWaitingTimeSeconds: 3,
+			PreemptionMode:           schedconfig.PreemptionModeNone,
 		},
 	})
 
@@ -418,6 +419,7 @@ func TestPodgroupBackoff(t *testing.T) {
 		Args: &schedconfig.CoschedulingArgs{
 			PermitWaitingTimeSeconds: 3,
 			PodGroupBackoffSeconds:   1,
+			PreemptionMode:           schedconfig.PreemptionModeNone,
 		},
 	})
 
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	clientscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/kubernetes/pkg/scheduler"
+	schedapi "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	fwkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
+	st "k8s.io/kubernetes/pkg/scheduler/testing"
+	imageutils "k8s.io/kubernetes/test/utils/image"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	schedconfig "sigs.k8s.io/scheduler-plugins/apis/config"
+	"sigs.k8s.io/scheduler-plugins/apis/diskio"
+	diskiov1alpha1 "sigs.k8s.io/scheduler-plugins/apis/diskio/v1alpha1"
+	"sigs.k8s.io/scheduler-plugins/pkg/diskioaware"
+	"sigs.k8s.io/scheduler-plugins/pkg/diskioaware/fakedriver"
+	"sigs.k8s.io/scheduler-plugins/test/util"
+)
+
+func TestDiskIOAwarePlugin(t *testing.T) {
+	testCtx := &testContext{}
+	testCtx.Ctx, testCtx.CancelFn = context.WithCancel(context.Background())
+
+	cs := kubernetes.NewForConfigOrDie(globalKubeConfig)
+
+	scheme := runtime.NewScheme()
+	_ = clientscheme.AddToScheme(scheme)
+	_ = diskiov1alpha1.AddToScheme(scheme)
+
+	extClient, err := client.New(globalKubeConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCtx.ClientSet = cs
+	testCtx.KubeConfig = globalKubeConfig
+
+	if err := wait.Poll(100*time.Millisecond, 3*time.Second, func() (done bool, err error) {
+		groupList, _, err := cs.ServerGroupsAndResources()
+		if err != nil {
+			return false, nil
+		}
+		for _, group := range groupList {
+			if group.Name == diskio.GroupName {
+				t.Log("The CRD is ready to serve")
+				return true, nil
+			}
+		}
+		return false, nil
+	}); err != nil {
+		t.Fatalf("Timed out waiting for CRD to be ready: %v", err)
+	}
+
+	ns := fmt.Sprintf("integration-test-%v", string(uuid.NewUUID()))
+	createNamespace(t, testCtx, ns)
+
+	cfg, err := util.NewDefaultSchedulerComponentConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Profiles[0].Plugins.PreFilter.Enabled = append(cfg.Profiles[0].Plugins.PreFilter.Enabled, schedapi.Plugin{Name: diskioaware.Name})
+	cfg.Profiles[0].Plugins.Filter.Enabled = append(cfg.Profiles[0].Plugins.Filter.Enabled, schedapi.Plugin{Name: diskioaware.Name})
+	cfg.Profiles[0].Plugins.Reserve.Enabled = append(cfg.Profiles[0].Plugins.Reserve.Enabled, schedapi.Plugin{Name: diskioaware.Name})
+	cfg.Profiles[0].Plugins.PreScore = schedapi.PluginSet{
+		Enabled:  []schedapi.Plugin{{Name: diskioaware.Name}},
+		Disabled: []schedapi.Plugin{{Name: "*"}},
+	}
+	cfg.Profiles[0].Plugins.Score = schedapi.PluginSet{
+		Enabled:  []schedapi.Plugin{{Name: diskioaware.Name}},
+		Disabled: []schedapi.Plugin{{Name: "*"}},
+	}
+	cfg.Profiles[0].PluginConfig = append(cfg.Profiles[0].PluginConfig, schedapi.PluginConfig{
+		Name: diskioaware.Name,
+		Args: &schedconfig.DiskIOAwareArgs{
+			ScoringStrategy:         schedconfig.MostAllocated,
+			NodeDiskIOInfoNamespace: ns,
+		},
+	})
+
+	testCtx = initTestSchedulerWithOptions(
+		t,
+		testCtx,
+		scheduler.WithProfiles(cfg.Profiles...),
+		scheduler.WithFrameworkOutOfTreeRegistry(fwkruntime.Registry{diskioaware.Name: diskioaware.New}),
+	)
+	syncInformerFactory(testCtx)
+	go testCtx.Scheduler.Run(testCtx.Ctx)
+	defer cleanupTest(t, testCtx)
+
+	// Create two nodes, the first one with a 100M disk, the second one with a 60M disk,
+	// and a third node with no disk IO information.
+	capacities := []int64{100000000, 60000000}
+	driver := fakedriver.New(extClient, ns)
+	for i := 0; i < 3; i++ {
+		nodeName := fmt.Sprintf("fake-node-%d", i)
+		node := st.MakeNode().Name(nodeName).Label("node", nodeName).Capacity(map[v1.ResourceName]string{
+			v1.ResourcePods: "32",
+		}).Obj()
+		if _, err := cs.CoreV1().Nodes().Create(testCtx.Ctx, node, metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Failed to create Node %q: %v", nodeName, err)
+		}
+		if i >= len(capacities) {
+			continue
+		}
+		if err := driver.AddNode(testCtx.Ctx, nodeName, fakedriver.Device{
+			ID:       "disk0",
+			Name:     "/dev/sda",
+			Capacity: diskioaware.Bandwidth{Total: capacities[i]},
+		}); err != nil {
+			t.Fatalf("Failed to add Node %q to the driver: %v", nodeName, err)
+		}
+	}
+	go driver.Run(testCtx.Ctx, 100*time.Millisecond)
+
+	pause := imageutils.GetPauseImageName()
+	makePod := func(name, request string) *v1.Pod {
+		pod := st.MakePod().Namespace(ns).Name(name).Container(pause).Obj()
+		pod.Annotations = map[string]string{diskioaware.IORequestAnnotation: request}
+		return pod
+	}
+	// The first pod goes on the smaller disk, the most allocated one. The second pod does
+	// not fit on it anymore and goes on the other node. The third pod fits nowhere.
+	pods := []*v1.Pod{
+		makePod("p1", `{"rbps": "20M", "wbps": "20M"}`),
+		makePod("p2", `{"bps": "40M", "rwratio": "1:1"}`),
+		makePod("p3", `{"rbps": "80M"}`),
+	}
+	wantNodes := []string{"fake-node-1", "fake-node-0", ""}
+	defer cleanupPods(t, testCtx, pods)
+
+	for i := range pods {
+		t.Logf("Creating Pod %q", pods[i].Name)
+		if _, err := cs.CoreV1().Pods(ns).Create(testCtx.Ctx, pods[i], metav1.CreateOptions{}); err != nil {
+			t.Fatalf("Failed to create Pod %q: %v", pods[i].Name, err)
+		}
+		if wantNodes[i] == "" {
+			continue
+		}
+		if err := wait.Poll(time.Millisecond*20, wait.ForeverTestTimeout, func() (bool, error) {
+			return podScheduled(cs, ns, pods[i].Name), nil
+		}); err != nil {
+			t.Fatalf("Pod %q not scheduled: %v", pods[i].Name, err)
+		}
+	}
+
+	// Give the scheduler some time to try the third pod.
+	time.Sleep(2 * time.Second)
+	for i := range pods {
+		pod, err := cs.CoreV1().Pods(ns).Get(testCtx.Ctx, pods[i].Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Failed to get Pod %q: %v", pods[i].Name, err)
+		}
+		if pod.Spec.NodeName != wantNodes[i] {
+			t.Errorf("Pod %q scheduled on %q, want %q", pod.Name, pod.Spec.NodeName, wantNodes[i])
+		}
+		pods[i] = pod
+	}
+
+	// The reserved pods are written back for the driver.
+	for i, nodeName := range []string{"fake-node-0", "fake-node-1"} {
+		info := &diskiov1alpha1.NodeDiskIOInfo{}
+		if err := extClient.Get(testCtx.Ctx, client.ObjectKey{Namespace: ns, Name: nodeName}, info); err != nil {
+			t.Fatalf("Failed to get NodeDiskIOInfo %q: %v", nodeName, err)
+		}
+		want := []string{string(pods[1-i].UID)}
+		if !reflect.DeepEqual(info.Spec.ReservedPods, want) {
+			t.Errorf("Reserved pods of %q = %v, want %v", nodeName, info.Spec.ReservedPods, want)
+		}
+	}
+}
@@ -116,6 +116,7 @@ func TestTargetNodePackingPlugin(t *testing.T) {
 			TrimaranSpec:              config.TrimaranSpec{WatcherAddress: server.URL},
 			TargetUtilization:         cfgv1.DefaultTargetUtilizationPercent,
 			DefaultRequestsMultiplier: cfgv1.DefaultRequestsMultiplier,
+			ScoreMode:                 config.TargetLoadPackingScoreModeWeightedSum,
 		},
 	})
 
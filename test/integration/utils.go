@@ -153,11 +153,46 @@ func (e *eqWrapper) Max(max v1.ResourceList) *eqWrapper {
 	return e
 }
 
+func (e *eqWrapper) Parent(parent string) *eqWrapper {
+	e.ElasticQuota.Spec.Parent = parent
+	return e
+}
+
+func (e *eqWrapper) NamespaceSelector(matchLabels map[string]string) *eqWrapper {
+	e.ElasticQuota.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: matchLabels}
+	return e
+}
+
+func (e *eqWrapper) Weight(weight int32) *eqWrapper {
+	e.ElasticQuota.Spec.Weight = weight
+	return e
+}
+
 func (e *eqWrapper) Used(used v1.ResourceList) *eqWrapper {
 	e.ElasticQuota.Status.Used = used
 	return e
 }
 
+func (e *eqWrapper) Borrowed(borrowed v1.ResourceList) *eqWrapper {
+	e.ElasticQuota.Status.Borrowed = borrowed
+	return e
+}
+
+func (e *eqWrapper) Lent(lent v1.ResourceList) *eqWrapper {
+	e.ElasticQuota.Status.Lent = lent
+	return e
+}
+
+func (e *eqWrapper) PendingRequests(pending v1.ResourceList) *eqWrapper {
+	e.ElasticQuota.Status.PendingRequests = pending
+	return e
+}
+
+func (e *eqWrapper) FairShare(fairShare v1.ResourceList) *eqWrapper {
+	e.ElasticQuota.Status.FairShare = fairShare
+	return e
+}
+
 func (e *eqWrapper) Obj() *v1alpha1.ElasticQuota {
 	return e.ElasticQuota
 }
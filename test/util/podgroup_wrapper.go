@@ -64,6 +64,14 @@ func (p *PodGroupWrapper) MinResources(resources map[v1.ResourceName]string) *Po
 	return p
 }
 
+func (p *PodGroupWrapper) TopologyConstraint(required, preferred []string) *PodGroupWrapper {
+	p.Spec.TopologyConstraint = &v1alpha1.PodGroupTopologyConstraint{
+		RequiredTopologyKeys:  required,
+		PreferredTopologyKeys: preferred,
+	}
+	return p
+}
+
 func (p *PodGroupWrapper) Phase(phase v1alpha1.PodGroupPhase) *PodGroupWrapper {
 	p.Status.Phase = phase
 	return p